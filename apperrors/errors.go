@@ -0,0 +1,94 @@
+// Package apperrors defines the errors returned by the dao and service packages, so that callers can tell why an
+// operation failed without knowing how the database or the upstream APIs report it.
+package apperrors
+
+import "fmt"
+
+// NotFoundError is returned when the insolvency case, transaction or resource being used does not exist
+type NotFoundError struct {
+	Message string
+	Err     error
+}
+
+func (e *NotFoundError) Error() string { return e.Message }
+
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// ConflictError is returned when an operation clashes with the current state of the insolvency case, such as
+// filing a resource that has already been filed
+type ConflictError struct {
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string { return e.Message }
+
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// PreconditionFailedError is returned when a conditional update is not made because the resource no longer has
+// the etag that the client expected it to have
+type PreconditionFailedError struct {
+	Message string
+	Err     error
+}
+
+func (e *PreconditionFailedError) Error() string { return e.Message }
+
+func (e *PreconditionFailedError) Unwrap() error { return e.Err }
+
+// ClosedError is returned when a transaction that has already been closed would be updated
+type ClosedError struct {
+	Message string
+	Err     error
+}
+
+func (e *ClosedError) Error() string { return e.Message }
+
+func (e *ClosedError) Unwrap() error { return e.Err }
+
+// UpstreamUnavailableError is returned when the database or an upstream API could not be reached or did not
+// respond in time
+type UpstreamUnavailableError struct {
+	Message string
+	Timeout bool
+	Err     error
+}
+
+func (e *UpstreamUnavailableError) Error() string { return e.Message }
+
+func (e *UpstreamUnavailableError) Unwrap() error { return e.Err }
+
+// ValidationError is returned when the data supplied for an operation is not valid
+type ValidationError struct {
+	Message string
+	Err     error
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// NotFound returns a NotFoundError with the formatted message
+func NotFound(format string, args ...interface{}) error {
+	return &NotFoundError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict returns a ConflictError with the formatted message
+func Conflict(format string, args ...interface{}) error {
+	return &ConflictError{Message: fmt.Sprintf(format, args...)}
+}
+
+// PreconditionFailed returns a PreconditionFailedError with the formatted message
+func PreconditionFailed(format string, args ...interface{}) error {
+	return &PreconditionFailedError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Closed returns a ClosedError with the formatted message
+func Closed(format string, args ...interface{}) error {
+	return &ClosedError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation returns a ValidationError with the formatted message
+func Validation(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
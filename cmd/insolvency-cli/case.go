@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Case describes an insolvency case to file, from creating the case to filing its documents
+type Case struct {
+	TransactionID string `json:"transaction_id"`
+	models.InsolvencyRequest
+
+	Practitioners      []Practitioner             `json:"practitioners"`
+	Attachments        []Attachment               `json:"attachments"`
+	Resolution         *models.Resolution         `json:"resolution"`
+	StatementOfAffairs *models.StatementOfAffairs `json:"statement_of_affairs"`
+	ProgressReport     *models.ProgressReport     `json:"progress_report"`
+
+	// dir is the directory of the case description, which attachment files are relative to
+	dir string
+}
+
+// Practitioner is a practitioner to add to the case, and their appointment if they are to be appointed. Ref names
+// the practitioner in the report, and is their IP code when it is not set
+type Practitioner struct {
+	Ref string `json:"ref"`
+	models.PractitionerRequest
+	Appointment *models.PractitionerAppointment `json:"appointment"`
+}
+
+// Attachment is a file to upload to the case. The resolution, statement of affairs and progress report refer to
+// attachments by their Ref, or by the ID of an attachment already uploaded to the case
+type Attachment struct {
+	Ref            string `json:"ref"`
+	AttachmentType string `json:"attachment_type"`
+	File           string `json:"file"`
+}
+
+// LoadCase reads a case description from a YAML or JSON file. Files ending in .json are read as JSON, and any other
+// file as YAML
+func LoadCase(path string) (*Case, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading case description: [%v]", err)
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		if contents, err = yamlToJSON(contents); err != nil {
+			return nil, fmt.Errorf("error parsing case description [%s]: [%v]", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+
+	var c Case
+	if err = decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("error parsing case description [%s]: [%v]", path, err)
+	}
+	c.dir = filepath.Dir(path)
+
+	if err = c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid case description [%s]: %v", path, err)
+	}
+
+	return &c, nil
+}
+
+// yamlToJSON converts a YAML document to JSON so that the case is decoded with the JSON field names of the API
+// models
+func yamlToJSON(contents []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(normaliseYAML(document))
+}
+
+// normaliseYAML converts the unquoted dates and numbers in a YAML document back to the strings they were written
+// as, since every field of the API models is a string
+func normaliseYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normaliseYAML(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normaliseYAML(item)
+		}
+	case time.Time:
+		return v.Format("2006-01-02")
+	case int, int64, uint64, float64:
+		return fmt.Sprint(v)
+	}
+	return value
+}
+
+// validate checks the parts of the case that the API cannot, such as the references between its sections
+func (c *Case) validate() error {
+	if c.TransactionID == "" {
+		return fmt.Errorf("transaction_id is required")
+	}
+
+	practitioners := make(map[string]bool)
+	for i := range c.Practitioners {
+		p := &c.Practitioners[i]
+		if p.Ref == "" {
+			p.Ref = p.IPCode
+		}
+		if practitioners[p.Ref] {
+			return fmt.Errorf("practitioner [%s] is described more than once", p.Ref)
+		}
+		practitioners[p.Ref] = true
+	}
+
+	attachments := make(map[string]bool)
+	for i := range c.Attachments {
+		a := &c.Attachments[i]
+		if a.File == "" {
+			return fmt.Errorf("attachment %d has no file", i+1)
+		}
+		if a.Ref == "" {
+			a.Ref = filepath.Base(a.File)
+		}
+		if attachments[a.Ref] {
+			return fmt.Errorf("attachment [%s] is described more than once", a.Ref)
+		}
+		attachments[a.Ref] = true
+	}
+
+	return nil
+}
+
+// path returns the path of an attachment file, relative to the case description when it is not absolute
+func (c *Case) path(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(c.dir, file)
+}
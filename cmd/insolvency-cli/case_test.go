@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// writeCase writes a case description to a file in a temporary directory and returns its path
+func writeCase(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUnitLoadCase(t *testing.T) {
+	Convey("A YAML case description is read with the field names of the API", t, func() {
+		c, err := LoadCase("testdata/case.yaml")
+		So(err, ShouldBeNil)
+		So(c.TransactionID, ShouldEqual, "000000-111111-222222")
+		So(c.CompanyNumber, ShouldEqual, "01234567")
+		So(c.CaseType, ShouldEqual, "creditors-voluntary-liquidation")
+
+		So(c.Practitioners, ShouldHaveLength, 1)
+		So(c.Practitioners[0].Ref, ShouldEqual, "lead-liquidator")
+		So(c.Practitioners[0].IPCode, ShouldEqual, "1234")
+		So(c.Practitioners[0].Address.AddressLine1, ShouldEqual, "Crown Way")
+		So(c.Practitioners[0].Address.Premises, ShouldEqual, "1")
+		So(c.Practitioners[0].Appointment.AppointedOn, ShouldEqual, "2021-06-28")
+
+		So(c.Attachments, ShouldHaveLength, 2)
+		So(c.path(c.Attachments[0].File), ShouldEqual, filepath.Join("testdata", "resolution.pdf"))
+		So(c.Resolution.DateOfResolution, ShouldEqual, "2021-06-26")
+		So(c.Resolution.Attachments, ShouldResemble, []string{"resolution"})
+		So(c.ProgressReport, ShouldBeNil)
+	})
+
+	Convey("A JSON case description is read as JSON, and missing refs default to the IP code and file name", t, func() {
+		path := writeCase(t, "case.json", `{
+			"transaction_id": "000000-111111-222222",
+			"company_number": "01234567",
+			"practitioners": [{"ip_code": "1234"}],
+			"attachments": [{"attachment_type": "resolution", "file": "/cases/resolution.pdf"}]
+		}`)
+
+		c, err := LoadCase(path)
+		So(err, ShouldBeNil)
+		So(c.Practitioners[0].Ref, ShouldEqual, "1234")
+		So(c.Attachments[0].Ref, ShouldEqual, "resolution.pdf")
+		So(c.path(c.Attachments[0].File), ShouldEqual, "/cases/resolution.pdf")
+	})
+
+	Convey("Invalid case descriptions are rejected", t, func() {
+		_, err := LoadCase(writeCase(t, "case.yaml", "transaction_id: 1\ncompany_nmber: \"01234567\"\n"))
+		So(err.Error(), ShouldContainSubstring, `unknown field "company_nmber"`)
+
+		_, err = LoadCase(writeCase(t, "case.yaml", "company_number: \"01234567\"\n"))
+		So(err.Error(), ShouldContainSubstring, "transaction_id is required")
+
+		_, err = LoadCase(writeCase(t, "case.yaml", "transaction_id: 1\nattachments:\n  - {ref: a, file: a.pdf}\n  - {ref: a, file: b.pdf}\n"))
+		So(err.Error(), ShouldContainSubstring, "attachment [a] is described more than once")
+
+		_, err = LoadCase(writeCase(t, "case.yaml", "transaction_id: 1\nattachments:\n  - {ref: a}\n"))
+		So(err.Error(), ShouldContainSubstring, "attachment 1 has no file")
+
+		_, err = LoadCase("testdata/missing.yaml")
+		So(err.Error(), ShouldContainSubstring, "error reading case description")
+	})
+}
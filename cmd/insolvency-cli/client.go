@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// apiClient calls the insolvency API
+type apiClient struct {
+	baseURL string
+	token   string
+	headers http.Header
+	http    *http.Client
+}
+
+// response is a response from the insolvency API
+type response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// ok returns whether the request succeeded
+func (r *response) ok() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// status returns the status code and text of the response, such as 201 Created
+func (r *response) status() string {
+	return fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+}
+
+// do sends a request to the path of the API and reads the response
+func (c *apiClient) do(method, path string, body io.Reader, contentType string) (*response, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.baseURL, "/")+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: [%v]", err)
+	}
+
+	for name, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling insolvency API: [%v]", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: [%v]", err)
+	}
+
+	return &response{StatusCode: res.StatusCode, Body: resBody}, nil
+}
+
+// get sends a GET request to the path of the API
+func (c *apiClient) get(path string) (*response, error) {
+	return c.do(http.MethodGet, path, nil, "")
+}
+
+// post sends the body as JSON to the path of the API
+func (c *apiClient) post(path string, body interface{}) (*response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: [%v]", err)
+	}
+	return c.do(http.MethodPost, path, bytes.NewReader(b), "application/json")
+}
+
+// upload sends the file as a multipart attachment of the type to the path of the API
+func (c *apiClient) upload(path, attachmentType, file string) (*response, error) {
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading attachment: [%v]", err)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err = writer.WriteField("attachment_type", attachmentType); err != nil {
+		return nil, fmt.Errorf("error creating attachment request: [%v]", err)
+	}
+	part, err := writer.CreateFormFile("file", filepath.Base(file))
+	if err != nil {
+		return nil, fmt.Errorf("error creating attachment request: [%v]", err)
+	}
+	if _, err = part.Write(contents); err != nil {
+		return nil, fmt.Errorf("error creating attachment request: [%v]", err)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, fmt.Errorf("error creating attachment request: [%v]", err)
+	}
+
+	return c.do(http.MethodPost, path, body, writer.FormDataContentType())
+}
+
+// apiErrors returns the errors in an error response, whether it is a message, a problem or a list of validation
+// errors, falling back to the body itself
+func apiErrors(body []byte) []string {
+	var errorBody struct {
+		Message string `json:"message"`
+		Title   string `json:"title"`
+		Detail  string `json:"detail"`
+		Errors  []struct {
+			Field    string `json:"field"`
+			Message  string `json:"message"`
+			Error    string `json:"error"`
+			Location string `json:"location"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err != nil {
+		if text := strings.TrimSpace(string(body)); text != "" {
+			return []string{text}
+		}
+		return nil
+	}
+
+	var errs []string
+	if errorBody.Message != "" {
+		errs = append(errs, errorBody.Message)
+	}
+	if errorBody.Detail != "" {
+		errs = append(errs, errorBody.Detail)
+	} else if errorBody.Title != "" {
+		errs = append(errs, errorBody.Title)
+	}
+	for _, e := range errorBody.Errors {
+		switch {
+		case e.Field != "":
+			errs = append(errs, e.Field+": "+e.Message)
+		case e.Location != "":
+			errs = append(errs, e.Location+": "+e.Error)
+		case e.Error != "":
+			errs = append(errs, e.Error)
+		}
+	}
+
+	return errs
+}
+
+// selfID returns the ID at the end of the self link in a created resource
+func selfID(body []byte) string {
+	var resource struct {
+		Links struct {
+			Self string `json:"self"`
+		} `json:"links"`
+	}
+	if err := json.Unmarshal(body, &resource); err != nil || resource.Links.Self == "" {
+		return ""
+	}
+	return resource.Links.Self[strings.LastIndex(resource.Links.Self, "/")+1:]
+}
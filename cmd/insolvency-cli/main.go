@@ -0,0 +1,78 @@
+// Command insolvency-cli files a complete insolvency case through the insolvency API from a single YAML or JSON
+// case description, printing a step-by-step report of each request and the API's errors.
+//
+// Usage:
+//
+//	insolvency-cli [flags] case.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// headers collects the -header flags as HTTP headers
+type headers http.Header
+
+func (h headers) String() string {
+	var values []string
+	for name := range h {
+		values = append(values, name)
+	}
+	return strings.Join(values, ",")
+}
+
+func (h headers) Set(value string) error {
+	name, headerValue, found := strings.Cut(value, ":")
+	if !found {
+		return fmt.Errorf("expected name: value")
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(headerValue))
+	return nil
+}
+
+func main() {
+	requestHeaders := headers{}
+	baseURL := flag.String("base-url", "http://api.chs.local:4001", "base URL of the insolvency API")
+	token := flag.String("token", os.Getenv("INSOLVENCY_CLI_TOKEN"), "OAuth access token to call the API with, defaulting to $INSOLVENCY_CLI_TOKEN")
+	pollInterval := flag.Duration("poll-interval", 2*time.Second, "how often to check the validation status while the case is not valid")
+	pollTimeout := flag.Duration("poll-timeout", time.Minute, "how long to wait for the case to become valid, such as while attachments are scanned")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of each request to the API")
+	flag.Var(requestHeaders, "header", "header to send with every request, as 'Name: value' (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] case.yaml\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c, err := LoadCase(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	r := &runner{
+		client: &apiClient{
+			baseURL: *baseURL,
+			token:   *token,
+			headers: http.Header(requestHeaders),
+			http:    &http.Client{Timeout: *timeout},
+		},
+		out:          os.Stdout,
+		pollInterval: *pollInterval,
+		pollTimeout:  *pollTimeout,
+	}
+
+	if !r.run(c) {
+		os.Exit(1)
+	}
+}
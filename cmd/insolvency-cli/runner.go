@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// runner files a case step by step, reporting the outcome of each step and the API's errors as it goes
+type runner struct {
+	client       *apiClient
+	out          io.Writer
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+
+	steps  int
+	failed int
+}
+
+// run files the case, returning whether every step succeeded. Steps that depend on a failed step, such as
+// appointing a practitioner that could not be added, are skipped and count as failed
+func (r *runner) run(c *Case) bool {
+	insolvencyPath := "/transactions/" + c.TransactionID + "/insolvency"
+	fmt.Fprintf(r.out, "Filing %s case for company %s in transaction %s\n\n", c.CaseType, c.CompanyNumber, c.TransactionID)
+
+	res, ok := r.step("create insolvency case", func() (*response, error) {
+		return r.client.post(insolvencyPath, c.InsolvencyRequest)
+	})
+	if !ok {
+		r.skip("remaining steps", "the insolvency case could not be created")
+		return r.summarise()
+	}
+
+	for _, p := range c.Practitioners {
+		res, ok = r.step(fmt.Sprintf("add practitioner %s", p.Ref), func() (*response, error) {
+			return r.client.post(insolvencyPath+"/practitioners", p.PractitionerRequest)
+		})
+		if p.Appointment == nil {
+			continue
+		}
+		if !ok {
+			r.skip(fmt.Sprintf("appoint practitioner %s", p.Ref), "the practitioner could not be added")
+			continue
+		}
+
+		appointmentPath := insolvencyPath + "/practitioners/" + selfID(res.Body) + "/appointment"
+		r.step(fmt.Sprintf("appoint practitioner %s", p.Ref), func() (*response, error) {
+			return r.client.post(appointmentPath, p.Appointment)
+		})
+	}
+
+	attachmentIDs := make(map[string]string)
+	for _, a := range c.Attachments {
+		res, ok = r.step(fmt.Sprintf("upload %s attachment %s", a.AttachmentType, a.Ref), func() (*response, error) {
+			return r.client.upload(insolvencyPath+"/attachments", a.AttachmentType, c.path(a.File))
+		})
+		if ok {
+			attachmentIDs[a.Ref] = selfID(res.Body)
+		}
+	}
+
+	if c.Resolution != nil {
+		resolution := *c.Resolution
+		resolution.Attachments = r.attachments(resolution.Attachments, attachmentIDs)
+		r.step("file resolution", func() (*response, error) {
+			return r.client.post(insolvencyPath+"/resolution", resolution)
+		})
+	}
+
+	if c.StatementOfAffairs != nil {
+		statement := *c.StatementOfAffairs
+		statement.Attachments = r.attachments(statement.Attachments, attachmentIDs)
+		r.step("file statement of affairs", func() (*response, error) {
+			return r.client.post(insolvencyPath+"/statement-of-affairs", statement)
+		})
+	}
+
+	if c.ProgressReport != nil {
+		report := *c.ProgressReport
+		report.Attachments = r.attachments(report.Attachments, attachmentIDs)
+		r.step("file progress report", func() (*response, error) {
+			return r.client.post(insolvencyPath+"/progress-report", report)
+		})
+	}
+
+	if r.pollValidationStatus(insolvencyPath + "/validation-status") {
+		r.showFilings("/private" + insolvencyPath + "/filings")
+	} else {
+		r.skip("show filings", "the case is not valid")
+	}
+
+	return r.summarise()
+}
+
+// attachments returns the IDs of the attachments that a filing refers to. References to attachments in the case
+// description are replaced by the IDs they were uploaded with, and anything else is taken to be an ID already
+func (r *runner) attachments(refs []string, attachmentIDs map[string]string) []string {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if id, ok := attachmentIDs[ref]; ok {
+			ids = append(ids, id)
+		} else {
+			ids = append(ids, ref)
+		}
+	}
+	return ids
+}
+
+// step makes a request, reporting its status and any errors the API responded with
+func (r *runner) step(description string, request func() (*response, error)) (*response, bool) {
+	r.steps++
+
+	res, err := request()
+	if err != nil {
+		r.failed++
+		fmt.Fprintf(r.out, "%2d. %-50s FAILED\n", r.steps, description)
+		fmt.Fprintf(r.out, "      - %v\n", err)
+		return nil, false
+	}
+
+	fmt.Fprintf(r.out, "%2d. %-50s %s\n", r.steps, description, res.status())
+	if !res.ok() {
+		r.failed++
+		for _, apiErr := range apiErrors(res.Body) {
+			fmt.Fprintf(r.out, "      - %s\n", apiErr)
+		}
+		return res, false
+	}
+
+	return res, true
+}
+
+// skip reports a step that was not made because of an earlier failure
+func (r *runner) skip(description, reason string) {
+	r.steps++
+	r.failed++
+	fmt.Fprintf(r.out, "%2d. %-50s SKIPPED\n", r.steps, description)
+	fmt.Fprintf(r.out, "      - %s\n", reason)
+}
+
+// pollValidationStatus checks the validation status of the case until it is valid or the poll timeout passes,
+// since attachments are not valid until they have been scanned, and reports the errors when it is not valid
+func (r *runner) pollValidationStatus(path string) bool {
+	deadline := time.Now().Add(r.pollTimeout)
+	polls := 0
+
+	res, ok := r.step("check validation status", func() (*response, error) {
+		for {
+			polls++
+			res, err := r.client.get(path)
+			if err != nil || !res.ok() || time.Now().Add(r.pollInterval).After(deadline) {
+				return res, err
+			}
+
+			var status models.ValidationStatusResponse
+			if err = json.Unmarshal(res.Body, &status); err != nil || status.IsValid {
+				return res, nil
+			}
+			time.Sleep(r.pollInterval)
+		}
+	})
+	if !ok {
+		return false
+	}
+
+	var status models.ValidationStatusResponse
+	if err := json.Unmarshal(res.Body, &status); err != nil {
+		r.failed++
+		fmt.Fprintf(r.out, "      - error reading validation status: [%v]\n", err)
+		return false
+	}
+
+	if status.IsValid {
+		fmt.Fprintf(r.out, "      - case is valid (checked %d time(s))\n", polls)
+		return true
+	}
+
+	r.failed++
+	fmt.Fprintf(r.out, "      - case is not valid after %d check(s)\n", polls)
+	for _, validationErr := range status.Errors {
+		fmt.Fprintf(r.out, "      - %s: %s\n", validationErr.Location, validationErr.Error)
+	}
+	return false
+}
+
+// showFilings reports the filings that the case generates
+func (r *runner) showFilings(path string) {
+	res, ok := r.step("show filings", func() (*response, error) {
+		return r.client.get(path)
+	})
+	if !ok {
+		return
+	}
+
+	var filings []models.Filing
+	if err := json.Unmarshal(res.Body, &filings); err != nil {
+		r.failed++
+		fmt.Fprintf(r.out, "      - error reading filings: [%v]\n", err)
+		return
+	}
+
+	for _, filing := range filings {
+		fmt.Fprintf(r.out, "      - %s: %s\n", filing.Kind, filing.Description)
+	}
+}
+
+// summarise reports how many steps failed, returning whether they all succeeded
+func (r *runner) summarise() bool {
+	if r.failed == 0 {
+		fmt.Fprintf(r.out, "\nCompleted %d step(s) successfully\n", r.steps)
+		return true
+	}
+
+	fmt.Fprintf(r.out, "\nCompleted %d step(s) with %d failure(s)\n", r.steps, r.failed)
+	return false
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeAPI is an insolvency API that records the requests it receives and fails the routes it is told to
+type fakeAPI struct {
+	requests         []string
+	failures         map[string]string
+	invalidChecks    int
+	bodies           map[string]map[string]interface{}
+	attachmentTypes  []string
+	validationErrors string
+}
+
+// newFakeAPI starts a fakeAPI, which is stopped when the test ends
+func newFakeAPI(t *testing.T) (*fakeAPI, *apiClient) {
+	api := &fakeAPI{
+		failures: make(map[string]string),
+		bodies:   make(map[string]map[string]interface{}),
+	}
+
+	router := mux.NewRouter()
+	insolvencyPath := "/transactions/{transaction_id}/insolvency"
+	router.HandleFunc(insolvencyPath, api.created("insolvency", "")).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/practitioners", api.created("practitioner", "/practitioners/practitioner-1")).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/practitioners/{practitioner_id}/appointment", api.created("appointment", "/appointment")).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/attachments", api.uploaded).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/resolution", api.created("resolution", "/resolution")).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/statement-of-affairs", api.created("statement-of-affairs", "/statement-of-affairs")).Methods(http.MethodPost)
+	router.HandleFunc(insolvencyPath+"/validation-status", api.validationStatus).Methods(http.MethodGet)
+	router.HandleFunc("/private"+insolvencyPath+"/filings", func(w http.ResponseWriter, req *http.Request) {
+		api.requests = append(api.requests, req.Method+" "+req.URL.Path)
+		w.Write([]byte(`[{"kind": "insolvency#LRESEX", "description": "Extraordinary resolution to wind up"}, {"kind": "insolvency#600", "description": "Notice of appointment of liquidator"}]`))
+	}).Methods(http.MethodGet)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return api, &apiClient{baseURL: server.URL, http: server.Client()}
+}
+
+// created responds to a JSON request with a created resource whose self link ends in the path, unless the route
+// has been told to fail
+func (api *fakeAPI) created(route, self string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		api.requests = append(api.requests, req.Method+" "+req.URL.Path)
+
+		var body map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&body)
+		api.bodies[route] = body
+
+		if failure, ok := api.failures[route]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(failure))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"links": {"self": "/transactions/%s/insolvency%s"}}`, mux.Vars(req)["transaction_id"], self)
+	}
+}
+
+// uploaded responds to an attachment upload with an attachment whose ID is made from its type
+func (api *fakeAPI) uploaded(w http.ResponseWriter, req *http.Request) {
+	api.requests = append(api.requests, req.Method+" "+req.URL.Path)
+
+	attachmentType := req.FormValue("attachment_type")
+	api.attachmentTypes = append(api.attachmentTypes, attachmentType)
+	if _, _, err := req.FormFile("file"); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"links": {"self": "/transactions/%s/insolvency/attachments/%s-id"}}`, mux.Vars(req)["transaction_id"], attachmentType)
+}
+
+// validationStatus responds that the case is invalid for the number of invalid checks, and then that it is valid
+// unless it has validation errors
+func (api *fakeAPI) validationStatus(w http.ResponseWriter, req *http.Request) {
+	api.requests = append(api.requests, req.Method+" "+req.URL.Path)
+
+	if api.invalidChecks > 0 {
+		api.invalidChecks--
+		w.Write([]byte(`{"is_valid": false, "errors": [{"error": "attachment not scanned", "location": "attachments"}]}`))
+		return
+	}
+	if api.validationErrors != "" {
+		w.Write([]byte(`{"is_valid": false, "errors": ` + api.validationErrors + `}`))
+		return
+	}
+	w.Write([]byte(`{"is_valid": true, "errors": []}`))
+}
+
+// runCase files the example case against the API, returning whether it succeeded and the report
+func runCase(client *apiClient) (bool, string) {
+	c, err := LoadCase("testdata/case.yaml")
+	So(err, ShouldBeNil)
+
+	out := &bytes.Buffer{}
+	r := &runner{client: client, out: out, pollInterval: time.Millisecond, pollTimeout: time.Second}
+	return r.run(c), out.String()
+}
+
+func TestUnitRunCase(t *testing.T) {
+	Convey("A case is filed step by step", t, func() {
+		api, client := newFakeAPI(t)
+		api.invalidChecks = 2
+
+		ok, report := runCase(client)
+		So(ok, ShouldBeTrue)
+
+		So(api.requests, ShouldResemble, []string{
+			"POST /transactions/000000-111111-222222/insolvency",
+			"POST /transactions/000000-111111-222222/insolvency/practitioners",
+			"POST /transactions/000000-111111-222222/insolvency/practitioners/practitioner-1/appointment",
+			"POST /transactions/000000-111111-222222/insolvency/attachments",
+			"POST /transactions/000000-111111-222222/insolvency/attachments",
+			"POST /transactions/000000-111111-222222/insolvency/resolution",
+			"POST /transactions/000000-111111-222222/insolvency/statement-of-affairs",
+			"GET /transactions/000000-111111-222222/insolvency/validation-status",
+			"GET /transactions/000000-111111-222222/insolvency/validation-status",
+			"GET /transactions/000000-111111-222222/insolvency/validation-status",
+			"GET /private/transactions/000000-111111-222222/insolvency/filings",
+		})
+
+		So(api.bodies["insolvency"]["company_number"], ShouldEqual, "01234567")
+		So(api.bodies["practitioner"]["ip_code"], ShouldEqual, "1234")
+		So(api.bodies["practitioner"]["ref"], ShouldBeNil)
+		So(api.bodies["appointment"]["made_by"], ShouldEqual, "creditors")
+		So(api.attachmentTypes, ShouldResemble, []string{"resolution", "statement-of-affairs-director"})
+		So(api.bodies["resolution"]["attachments"], ShouldResemble, []interface{}{"resolution-id"})
+		So(api.bodies["statement-of-affairs"]["attachments"], ShouldResemble, []interface{}{"statement-of-affairs-director-id"})
+
+		So(report, ShouldContainSubstring, " 1. create insolvency case")
+		So(report, ShouldContainSubstring, "201 Created")
+		So(report, ShouldContainSubstring, "case is valid (checked 3 time(s))")
+		So(report, ShouldContainSubstring, "insolvency#600: Notice of appointment of liquidator")
+		So(report, ShouldContainSubstring, "Completed 9 step(s) successfully")
+	})
+
+	Convey("The API's errors are reported and dependent steps are skipped", t, func() {
+		api, client := newFakeAPI(t)
+		api.failures["practitioner"] = `{"message": "there was a problem handling your request for transaction [000000-111111-222222]"}`
+		api.failures["resolution"] = `{"type": "about:blank", "title": "Bad Request", "status": 400, "errors": [{"field": "date_of_resolution", "message": "date_of_resolution is required"}]}`
+		api.validationErrors = `[{"error": "no practitioners are appointed", "location": "practitioners"}]`
+
+		ok, report := runCase(client)
+		So(ok, ShouldBeFalse)
+
+		So(api.requests, ShouldNotContain, "POST /transactions/000000-111111-222222/insolvency/practitioners/practitioner-1/appointment")
+		So(report, ShouldContainSubstring, "400 Bad Request\n      - there was a problem handling your request for transaction [000000-111111-222222]")
+		So(report, ShouldContainSubstring, "appoint practitioner lead-liquidator")
+		So(report, ShouldContainSubstring, "SKIPPED\n      - the practitioner could not be added")
+		So(report, ShouldContainSubstring, "- Bad Request\n      - date_of_resolution: date_of_resolution is required")
+		So(report, ShouldContainSubstring, "- practitioners: no practitioners are appointed")
+		So(report, ShouldContainSubstring, "SKIPPED\n      - the case is not valid")
+		So(report, ShouldContainSubstring, "Completed 9 step(s) with 5 failure(s)")
+	})
+
+	Convey("Nothing else is attempted when the case cannot be created", t, func() {
+		api, client := newFakeAPI(t)
+		api.failures["insolvency"] = `{"message": "transaction is closed"}`
+
+		ok, report := runCase(client)
+		So(ok, ShouldBeFalse)
+		So(api.requests, ShouldHaveLength, 1)
+		So(report, ShouldContainSubstring, "- transaction is closed")
+		So(report, ShouldContainSubstring, "Completed 2 step(s) with 2 failure(s)")
+	})
+}
+
+func TestUnitAPIErrors(t *testing.T) {
+	Convey("Errors are read from every kind of error response", t, func() {
+		So(apiErrors([]byte(`{"message": "not found"}`)), ShouldResemble, []string{"not found"})
+		So(apiErrors([]byte(`{"title": "Not Found", "detail": "case not found"}`)), ShouldResemble, []string{"case not found"})
+		So(apiErrors([]byte(`{"errors": [{"error": "invalid", "location": "resolution"}]}`)), ShouldResemble, []string{"resolution: invalid"})
+		So(apiErrors([]byte("upstream timed out\n")), ShouldResemble, []string{"upstream timed out"})
+		So(apiErrors(nil), ShouldBeNil)
+	})
+}
@@ -0,0 +1,123 @@
+// Command upstream-stand-in serves stand-ins for the upstream Companies House APIs that the insolvency API calls,
+// so that it can be run end to end without them. See the standin package for the APIs it serves.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/standin"
+)
+
+// specs collects the values of a flag that can be given more than once
+type specs []string
+
+func (s *specs) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *specs) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func main() {
+	namespace := "upstream-stand-in"
+	log.Namespace = namespace
+
+	var failureSpecs, delaySpecs specs
+	bindAddr := flag.String("bind-addr", ":18100", "address the stand-in APIs are served on")
+	seedFile := flag.String("seed", "", "JSON file of companies, transactions and allowed email addresses to seed")
+	avScanDelay := flag.Duration("av-scan-delay", 0, "how long uploaded files are not-scanned before their scan completes")
+	infectedMarker := flag.String("infected-marker", standin.DefaultInfectedMarker, "text that marks an uploaded file as infected when it is in its name or contents")
+	flag.Var(&failureSpecs, "fail", "fail requests to an API, as api=status[:rate] (repeatable)")
+	flag.Var(&delaySpecs, "delay", "delay requests to an API, as api=duration (repeatable)")
+	flag.Parse()
+
+	server := standin.NewServer(standin.Options{AVScanDelay: *avScanDelay, InfectedMarker: *infectedMarker})
+
+	if *seedFile != "" {
+		seed, err := standin.LoadSeed(*seedFile)
+		if err != nil {
+			log.Error(fmt.Errorf("error loading seed: %s. Exiting", err), nil)
+			return
+		}
+		server.Seed(seed)
+		log.Info("seed loaded", log.Data{"file": *seedFile, "companies": len(seed.Companies), "transactions": len(seed.Transactions)})
+	}
+
+	failures, err := parseFailures(failureSpecs, delaySpecs)
+	if err != nil {
+		log.Error(fmt.Errorf("error configuring failures: %s. Exiting", err), nil)
+		return
+	}
+	for _, failure := range failures {
+		if err = server.SetFailure(failure); err != nil {
+			log.Error(fmt.Errorf("error configuring failures: %s. Exiting", err), nil)
+			return
+		}
+		log.Info("failure injected", log.Data{"api": failure.API, "status_code": failure.StatusCode, "rate": failure.Rate, "delay": failure.Delay})
+	}
+
+	h := &http.Server{
+		Addr:    *bindAddr,
+		Handler: server,
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		log.Info("starting server...", log.Data{"port": *bindAddr})
+		err := h.ListenAndServe()
+		log.Info("server stopping...")
+		if err != nil && err != http.ErrServerClosed {
+			log.Error(err)
+			os.Exit(1)
+		}
+	}()
+
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = h.Shutdown(ctx); err != nil {
+		log.Error(fmt.Errorf("failed to shutdown server gracefully: [%v]", err))
+	}
+}
+
+// parseFailures parses the -fail and -delay flags, combining the failure and delay of the same API
+func parseFailures(failureSpecs, delaySpecs []string) (map[string]standin.Failure, error) {
+	failures := make(map[string]standin.Failure)
+
+	for _, spec := range failureSpecs {
+		failure, err := standin.ParseFailure(spec)
+		if err != nil {
+			return nil, err
+		}
+		failure.Delay = failures[failure.API].Delay
+		failures[failure.API] = failure
+	}
+
+	for _, spec := range delaySpecs {
+		delay, err := standin.ParseDelay(spec)
+		if err != nil {
+			return nil, err
+		}
+		failure := failures[delay.API]
+		failure.API = delay.API
+		failure.Delay = delay.Delay
+		failures[delay.API] = failure
+	}
+
+	return failures, nil
+}
@@ -12,12 +12,50 @@ var mtx sync.Mutex
 
 // Config defines the configuration options for this service.
 type Config struct {
-	BindAddr                   string `env:"BIND_ADDR"                        flag:"bind-addr"                      flagDesc:"Bind address"`
-	MongoDBURL                 string `env:"MONGODB_URL"                      flag:"mongodb-url"                    flagDesc:"MongoDB server URL"`
-	Database                   string `env:"INSOLVENCY_MONGODB_DATABASE"      flag:"mongodb-database"               flagDesc:"MongoDB database for data"`
-	MongoCollection            string `env:"INSOLVENCY_MONGODB_COLLECTION"    flag:"mongodb-collection"             flagDesc:"The name of the mongodb collection"`
-	IsEfsAllowListAuthDisabled bool   `env:"DISABLE_EFS_ALLOW_LIST_AUTH"      flag:"disable-efs-allow-list-auth"    flagDesc:"Set to 'true' in order to bypass EFS allow list aspect of API authorisation"`
-	EnableNonLiveRouteHandlers bool   `env:"ENABLE_NON_LIVE_ROUTE_HANDLERS"     flag:"enable-non-live-route-handlers"   flagdesc:"Set to 'true'/'false' to respectively enable/disable form endpoints internal/external availability"`
+	BindAddr                     string `env:"BIND_ADDR"                        flag:"bind-addr"                      flagDesc:"Bind address"`
+	DatabaseBackend              string `env:"DATABASE_BACKEND"                 flag:"database-backend"               flagDesc:"Persistence backend: mongo (default), memory or sql"`
+	SQLDriver                    string `env:"SQL_DRIVER"                       flag:"sql-driver"                     flagDesc:"database/sql driver used by the sql backend (default sqlite)"`
+	SQLDataSource                string `env:"SQL_DATA_SOURCE"                  flag:"sql-data-source"                flagDesc:"Data source name of the database used by the sql backend"`
+	MongoDBURL                   string `env:"MONGODB_URL"                      flag:"mongodb-url"                    flagDesc:"MongoDB server URL"`
+	Database                     string `env:"INSOLVENCY_MONGODB_DATABASE"      flag:"mongodb-database"               flagDesc:"MongoDB database for data"`
+	MongoCollection              string `env:"INSOLVENCY_MONGODB_COLLECTION"    flag:"mongodb-collection"             flagDesc:"The name of the mongodb collection"`
+	MongoOperationTimeout        int    `env:"MONGODB_OPERATION_TIMEOUT"        flag:"mongodb-operation-timeout"      flagDesc:"Seconds allowed for each MongoDB operation (default 10)"`
+	MongoConnectAttempts         int    `env:"MONGODB_CONNECT_ATTEMPTS"         flag:"mongodb-connect-attempts"       flagDesc:"Number of times to try connecting to MongoDB at startup, with backoff between attempts (default 5)"`
+	MongoMaxPoolSize             int    `env:"MONGODB_MAX_POOL_SIZE"            flag:"mongodb-max-pool-size"          flagDesc:"Maximum number of connections in the MongoDB connection pool"`
+	MongoMinPoolSize             int    `env:"MONGODB_MIN_POOL_SIZE"            flag:"mongodb-min-pool-size"          flagDesc:"Minimum number of connections kept in the MongoDB connection pool"`
+	MongoServerSelectionTimeout  int    `env:"MONGODB_SERVER_SELECTION_TIMEOUT" flag:"mongodb-server-selection-timeout" flagDesc:"Seconds to wait for a suitable MongoDB server to be available"`
+	MongoSocketTimeout           int    `env:"MONGODB_SOCKET_TIMEOUT"           flag:"mongodb-socket-timeout"         flagDesc:"Seconds to wait for a MongoDB socket read or write"`
+	MongoReadPreference          string `env:"MONGODB_READ_PREFERENCE"          flag:"mongodb-read-preference"        flagDesc:"MongoDB read preference: primary, primaryPreferred, secondary, secondaryPreferred or nearest"`
+	MongoWriteConcern            string `env:"MONGODB_WRITE_CONCERN"            flag:"mongodb-write-concern"          flagDesc:"MongoDB write concern: majority or the number of members that must acknowledge a write"`
+	IdempotencyMongoCollection   string `env:"IDEMPOTENCY_MONGODB_COLLECTION"   flag:"idempotency-mongodb-collection" flagDesc:"The name of the mongodb collection that idempotency keys are stored in (default idempotency_keys)"`
+	IdempotencyKeyTTL            int    `env:"IDEMPOTENCY_KEY_TTL"              flag:"idempotency-key-ttl"            flagDesc:"Seconds that a response is kept for replay to requests with the same Idempotency-Key (default 86400)"`
+	IdempotencyKeyLease          int    `env:"IDEMPOTENCY_KEY_LEASE"            flag:"idempotency-key-lease"          flagDesc:"Seconds that an Idempotency-Key is reserved for the request handling it before a retry can reserve it again (default 60)"`
+	AuditMongoCollection         string `env:"AUDIT_MONGODB_COLLECTION"         flag:"audit-mongodb-collection"       flagDesc:"The name of the mongodb collection that audit events are stored in (default audit_events)"`
+	OutboxSink                   string `env:"OUTBOX_SINK"                      flag:"outbox-sink"                    flagDesc:"Where domain events in the outbox are delivered: webhook, file or stdout - events are kept in the outbox when unset"`
+	OutboxWebhookURL             string `env:"OUTBOX_WEBHOOK_URL"               flag:"outbox-webhook-url"             flagDesc:"URL that the webhook sink POSTs each domain event to"`
+	OutboxFile                   string `env:"OUTBOX_FILE"                      flag:"outbox-file"                    flagDesc:"Path of the file that the file sink appends each domain event to, one JSON object per line"`
+	OutboxRelayInterval          int    `env:"OUTBOX_RELAY_INTERVAL"            flag:"outbox-relay-interval"          flagDesc:"Seconds between each check of the outbox for domain events to deliver (default 5)"`
+	OutboxRelayBatchSize         int    `env:"OUTBOX_RELAY_BATCH_SIZE"          flag:"outbox-relay-batch-size"        flagDesc:"Maximum number of domain events delivered on each check of the outbox (default 100)"`
+	OutboxRelayLease             int    `env:"OUTBOX_RELAY_LEASE"               flag:"outbox-relay-lease"             flagDesc:"Seconds that a relay claims the cases it is delivering events for, before the relays of other instances can claim them (default 300)"`
+	OutboxRelayMaxAttempts       int    `env:"OUTBOX_RELAY_MAX_ATTEMPTS"        flag:"outbox-relay-max-attempts"      flagDesc:"Failed deliveries after which a domain event is parked in the outbox rather than delivered again (default 10)"`
+	IsMongoIndexCreationDisabled bool   `env:"DISABLE_MONGODB_INDEX_CREATION"   flag:"disable-mongodb-index-creation" flagDesc:"Set to 'true' to stop the service creating its MongoDB indexes at startup"`
+	IsEfsAllowListAuthDisabled   bool   `env:"DISABLE_EFS_ALLOW_LIST_AUTH"      flag:"disable-efs-allow-list-auth"    flagDesc:"Set to 'true' in order to bypass EFS allow list aspect of API authorisation"`
+	EfsSandboxAllowList          string `env:"EFS_SANDBOX_ALLOW_LIST"          flag:"efs-sandbox-allow-list"          flagDesc:"Comma separated emails, @domains or regex: patterns allowed when EFS allow list auth is disabled"`
+	EfsSandboxAllowListFile      string `env:"EFS_SANDBOX_ALLOW_LIST_FILE"     flag:"efs-sandbox-allow-list-file"     flagDesc:"File of sandbox allow list entries, one per line, reloaded when it changes"`
+	EfsAllowListCacheSize        int    `env:"EFS_ALLOW_LIST_CACHE_SIZE"        flag:"efs-allow-list-cache-size"        flagDesc:"Maximum number of EFS allow list results to cache - caching is disabled when unset or 0"`
+	EfsAllowListCachePositiveTTL int    `env:"EFS_ALLOW_LIST_CACHE_POSITIVE_TTL" flag:"efs-allow-list-cache-positive-ttl" flagDesc:"Seconds to cache users who are on the EFS allow list (default 300)"`
+	EfsAllowListCacheNegativeTTL int    `env:"EFS_ALLOW_LIST_CACHE_NEGATIVE_TTL" flag:"efs-allow-list-cache-negative-ttl" flagDesc:"Seconds to cache users who are not on the EFS allow list (default 60)"`
+	EfsAllowListCacheMaxStaleAge int    `env:"EFS_ALLOW_LIST_CACHE_MAX_STALE_AGE" flag:"efs-allow-list-cache-max-stale-age" flagDesc:"Seconds after expiring that a cached EFS allow list result is used when the EFS api returns an error (default 3600)"`
+	ReadinessTimeout             int    `env:"READINESS_TIMEOUT"                flag:"readiness-timeout"              flagDesc:"Seconds allowed for the readiness checks (default 2)"`
+	ReadinessUpstreamChecks      string `env:"READINESS_UPSTREAM_CHECKS"        flag:"readiness-upstream-checks"      flagDesc:"Comma separated name=url upstream APIs to check for readiness: transaction, company-profile, alpha-key, efs or file-transfer"`
+	EnableTracing                bool   `env:"ENABLE_TRACING"                   flag:"enable-tracing"                 flagDesc:"Set to 'true' to record OpenTelemetry traces"`
+	OtlpEndpoint                 string `env:"OTEL_EXPORTER_OTLP_ENDPOINT"      flag:"otel-exporter-otlp-endpoint"    flagDesc:"Base URL of the OTLP collector that traces are sent to - traces are written to stdout when unset"`
+	EnableNonLiveRouteHandlers   bool   `env:"ENABLE_NON_LIVE_ROUTE_HANDLERS"     flag:"enable-non-live-route-handlers"   flagdesc:"Set to 'true'/'false' to respectively enable/disable form endpoints internal/external availability"`
+	PractitionerRegisterFile     string `env:"PRACTITIONER_REGISTER_FILE"       flag:"practitioner-register-file"     flagDesc:"Path to a CSV or JSON file of insolvency practitioners to check IP codes against"`
+	FirmMembershipFile           string `env:"FIRM_MEMBERSHIP_FILE"             flag:"firm-membership-file"           flagDesc:"Path to a CSV or JSON file mapping user email addresses to the firm they belong to"`
+	RequireUserIsPractitioner    bool   `env:"REQUIRE_USER_IS_PRACTITIONER"     flag:"require-user-is-practitioner"   flagDesc:"Set to 'true' to only pass validation when the authenticated user is a practitioner on the case"`
+	CaseAccessAdminRole          string `env:"CASE_ACCESS_ADMIN_ROLE"           flag:"case-access-admin-role"         flagDesc:"Authorised role that gives a user access to every insolvency case, regardless of who created it"`
+	CaseAccessDenyUnowned        bool   `env:"CASE_ACCESS_DENY_UNOWNED"         flag:"case-access-deny-unowned"       flagDesc:"Set to 'true' to only give administrators access to cases with no recorded creator, once created_by has been backfilled"`
 }
 
 // Get returns a pointer to a Config instance populated with values from environment or command-line flags
@@ -6,10 +6,13 @@ const MsgCaseForTransactionNotFound = "there was a problem handling your request
 const MsgReqTransactionNotFound = "there was a problem handling your request for transaction %s not found"
 const MsgHandleReqTransactionId = "there was a problem handling your request for transaction id %s"
 const MsgHandleReqProblem = "there was a problem handling your request"
+const MsgUserNotAuthorised = "user is not authorised to perform this request"
 const MsgMissingTransactionIdInPath = "transaction ID is not in the URL path"
 const MsgMissingAttachmentIdInPath = "attachment ID is not in the URL path"
 const MsgCompanyInvalidProfileAPI = "company was not found valid when checking company profile API [%v]"
-const MsgCompanyInvalidForInsolvency = "company [%s] was not found valid for insolvency: %v"
-const MsgErrorCheckTransactionStatus = "error checking transaction status for [%v]: [%s]"
+const MsgCompanyInvalidForInsolvency = "company [%s] was not found valid for insolvency: %w"
+const MsgErrorCheckTransactionStatus = "error checking transaction status for [%v]: [%w]"
 const MsgNoUpdateTransactionClosed = "transaction [%v] is already closed and cannot be updated"
 const MsgErrorCommsFileTransferAPI = "error communicating with the File Transfer API: [%v]"
+const MsgPractitionerAlreadyAssigned = "there was a problem handling your request for transaction %s - practitioner with IP Code %s is already assigned to this case with practitioner ID [%s]"
+const MsgResourceEtagMismatch = "there was a problem handling your request for transaction %s - the %s filed for this insolvency case does not have the etag [%s]"
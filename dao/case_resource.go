@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// Types of dated resource, as stored in the type column of the dated_resources table and as the field of the case
+// data that holds them in MongoDB
+const (
+	resolutionType         = "resolution"
+	statementOfAffairsType = "statement-of-affairs"
+	progressReportType     = "progress-report"
+)
+
+// filedResourceEtag returns the etag of the resolution, statement of affairs or progress report of the type resType
+// filed with the case data, and whether one has been filed
+func filedResourceEtag(data *models.InsolvencyResourceDaoData, resType string) (string, bool) {
+	switch resType {
+	case resolutionType:
+		if data.Resolution != nil {
+			return data.Resolution.Etag, true
+		}
+	case statementOfAffairsType:
+		if data.StatementOfAffairs != nil {
+			return data.StatementOfAffairs.Etag, true
+		}
+	case progressReportType:
+		if data.ProgressReport != nil {
+			return data.ProgressReport.Etag, true
+		}
+	}
+	return "", false
+}
+
+// etagMismatchError returns the error for a resource of the type resType that was not replaced because the resource
+// filed for the case with the specified transactionID does not have the ifMatch etag
+func etagMismatchError(transactionID string, resType string, ifMatch string) error {
+	err := apperrors.PreconditionFailed(constants.MsgResourceEtagMismatch, transactionID, strings.ReplaceAll(resType, "-", " "), ifMatch)
+	log.Error(err)
+	return err
+}
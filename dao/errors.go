@@ -0,0 +1,46 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// defaultOperationTimeout is used for each database operation when no timeout is configured
+const defaultOperationTimeout = 10 * time.Second
+
+// databaseError keeps the message returned to callers of the Service while retaining the underlying database
+// error, so that the cause can still be logged or checked
+type databaseError struct {
+	message string
+	err     error
+}
+
+func (e *databaseError) Error() string {
+	return e.message
+}
+
+func (e *databaseError) Unwrap() error {
+	return e.err
+}
+
+// newDatabaseError returns the error to give callers of the Service when a database operation fails. Operations
+// that broke a unique index return a ConflictError, operations that could not reach a database server, were
+// cancelled or timed out return an UpstreamUnavailableError and any other failure returns a databaseError
+func newDatabaseError(message string, err error) error {
+	var serverSelectionErr topology.ServerSelectionError
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return &apperrors.ConflictError{Message: message, Err: err}
+	case errors.As(err, &serverSelectionErr) || errors.Is(err, mongo.ErrClientDisconnected) || errors.Is(err, context.Canceled):
+		return &apperrors.UpstreamUnavailableError{Message: message, Err: err}
+	case errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err):
+		return &apperrors.UpstreamUnavailableError{Message: message, Timeout: true, Err: err}
+	default:
+		return &databaseError{message: message, err: err}
+	}
+}
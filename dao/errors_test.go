@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitNewDatabaseError(t *testing.T) {
+	Convey("Operation broke a unique index", t, func() {
+		err := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key error"}}}
+		var conflictErr *apperrors.ConflictError
+		So(errors.As(newDatabaseError("error creating insolvency case", err), &conflictErr), ShouldBeTrue)
+		So(conflictErr.Message, ShouldEqual, "error creating insolvency case")
+	})
+
+	Convey("Operation timed out", t, func() {
+		var unavailableErr *apperrors.UpstreamUnavailableError
+		So(errors.As(newDatabaseError("error getting insolvency case", context.DeadlineExceeded), &unavailableErr), ShouldBeTrue)
+		So(unavailableErr.Timeout, ShouldBeTrue)
+		So(errors.Is(unavailableErr, context.DeadlineExceeded), ShouldBeTrue)
+	})
+
+	Convey("Database could not be reached", t, func() {
+		for _, err := range []error{
+			topology.ServerSelectionError{Wrapped: context.DeadlineExceeded},
+			fmt.Errorf("error finding insolvency case: %w", mongo.ErrClientDisconnected),
+			context.Canceled,
+		} {
+			var unavailableErr *apperrors.UpstreamUnavailableError
+			So(errors.As(newDatabaseError("error getting insolvency case", err), &unavailableErr), ShouldBeTrue)
+			So(unavailableErr.Timeout, ShouldBeFalse)
+		}
+	})
+
+	Convey("Any other error", t, func() {
+		err := newDatabaseError("error getting insolvency case", fmt.Errorf("error decoding insolvency case"))
+		So(err, ShouldHaveSameTypeAs, &databaseError{})
+		So(err.Error(), ShouldEqual, "error getting insolvency case")
+	})
+}
+
+func TestUnitOperationContext(t *testing.T) {
+	Convey("Operation timeout is applied to the context", t, func() {
+		mongoService := MongoService{OperationTimeout: time.Minute}
+
+		ctx, cancel := mongoService.operationContext(context.Background())
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		So(ok, ShouldBeTrue)
+		So(deadline, ShouldHappenWithin, time.Minute+time.Second, time.Now())
+	})
+
+	Convey("Earlier deadline from the caller is kept", t, func() {
+		mongoService := MongoService{OperationTimeout: time.Minute}
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Second)
+		defer parentCancel()
+
+		ctx, cancel := mongoService.operationContext(parent)
+		defer cancel()
+
+		parentDeadline, _ := parent.Deadline()
+		deadline, _ := ctx.Deadline()
+		So(deadline, ShouldEqual, parentDeadline)
+	})
+
+	Convey("No timeout is applied when none is configured", t, func() {
+		mongoService := MongoService{}
+
+		ctx, cancel := mongoService.operationContext(context.Background())
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		So(ok, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/companieshouse/chs.go/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// insolvencyIndexes are the indexes required on the insolvency collection. The unique index on transaction_id
+// stops two insolvency cases being created for the same transaction, and the partial index on outbox_pending finds
+// the cases with events waiting to be delivered
+var insolvencyIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "transaction_id", Value: 1}},
+		Options: options.Index().SetName("transaction_id_unique").SetUnique(true),
+	},
+	{
+		Keys:    bson.D{{Key: "data.company_number", Value: 1}},
+		Options: options.Index().SetName("company_number"),
+	},
+	{
+		Keys:    bson.D{{Key: "data.attachments.id", Value: 1}},
+		Options: options.Index().SetName("attachments_id"),
+	},
+	{
+		Keys:    bson.D{{Key: "outbox_pending", Value: 1}},
+		Options: options.Index().SetName("outbox_pending").SetPartialFilterExpression(bson.M{"outbox_pending": bson.M{"$gt": 0}}),
+	},
+}
+
+// idempotencyIndexes are the indexes required on the idempotency collection. The TTL index removes each key once
+// its expires_at time has passed
+var idempotencyIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(0),
+	},
+}
+
+// auditIndexes are the indexes required on the audit collection, for retrieving the history of a case and for
+// queries across cases
+var auditIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "transaction_id", Value: 1}, {Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("transaction_id_created_at"),
+	},
+	{
+		Keys:    bson.D{{Key: "company_number", Value: 1}, {Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("company_number_created_at"),
+	},
+	{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("user_id_created_at"),
+	},
+	{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("created_at"),
+	},
+}
+
+// EnsureIndexes creates any of the required indexes that do not already exist on the insolvency collection, and on
+// the idempotency and audit collections when they are set
+func (m *MongoService) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	if err := m.ensureCollectionIndexes(ctx, m.CollectionName, insolvencyIndexes); err != nil {
+		return err
+	}
+
+	if m.IdempotencyCollectionName != "" {
+		if err := m.ensureCollectionIndexes(ctx, m.IdempotencyCollectionName, idempotencyIndexes); err != nil {
+			return err
+		}
+	}
+
+	if m.AuditCollectionName != "" {
+		return m.ensureCollectionIndexes(ctx, m.AuditCollectionName, auditIndexes)
+	}
+	return nil
+}
+
+// ensureCollectionIndexes creates any of the indexes that do not already exist on the named collection
+func (m *MongoService) ensureCollectionIndexes(ctx context.Context, collection string, indexes []mongo.IndexModel) error {
+	names, err := m.db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("error creating indexes on collection [%s]: [%w]", collection, err)
+	}
+
+	log.Info("mongodb indexes ensured", log.Data{"collection": collection, "indexes": names})
+	return nil
+}
@@ -0,0 +1,279 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/metrics"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// instrumentedService records the duration and outcome of every operation on the wrapped Service, and traces each
+// operation in a span
+type instrumentedService struct {
+	Service
+}
+
+// NewInstrumentedService wraps the Service so that each operation is recorded in the service metrics and traces
+func NewInstrumentedService(svc Service) Service {
+	return &instrumentedService{Service: svc}
+}
+
+// startOperation starts timing and tracing a DAO operation, returning the context to run the operation with and
+// the function that records its outcome
+func startOperation(ctx context.Context, operation string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.StartSpan(ctx, "dao."+operation, attribute.String("db.operation.name", operation))
+
+	return ctx, func(err error) {
+		metrics.ObserveDAOOperation(operation, start, err)
+		tracing.EndSpan(span, err)
+	}
+}
+
+func (s *instrumentedService) CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+	ctx, done := startOperation(ctx, "CreateInsolvencyResource")
+	err := s.Service.CreateInsolvencyResource(ctx, dao)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetInsolvencyResource")
+	result, err := s.Service.GetInsolvencyResource(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+	ctx, done := startOperation(ctx, "CreatePractitionersResource")
+	err := s.Service.CreatePractitionersResource(ctx, dao, transactionID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetPractitionerResources")
+	result, err := s.Service.GetPractitionerResources(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) GetPractitionerResource(ctx context.Context, practitionerID string, transactionID string) (models.PractitionerResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetPractitionerResource")
+	result, err := s.Service.GetPractitionerResource(ctx, practitionerID, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) DeletePractitioner(ctx context.Context, practitionerID, transactionID string) error {
+	ctx, done := startOperation(ctx, "DeletePractitioner")
+	err := s.Service.DeletePractitioner(ctx, practitionerID, transactionID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID string, practitionerID string) error {
+	ctx, done := startOperation(ctx, "AppointPractitioner")
+	err := s.Service.AppointPractitioner(ctx, dao, transactionID, practitionerID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) DeletePractitionerAppointment(ctx context.Context, transactionID string, practitionerID string) error {
+	ctx, done := startOperation(ctx, "DeletePractitionerAppointment")
+	err := s.Service.DeletePractitionerAppointment(ctx, transactionID, practitionerID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) AddAttachmentToInsolvencyResource(ctx context.Context, transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error) {
+	ctx, done := startOperation(ctx, "AddAttachmentToInsolvencyResource")
+	result, err := s.Service.AddAttachmentToInsolvencyResource(ctx, transactionID, fileID, attachmentType)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID string, attachmentID string) (models.AttachmentResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetAttachmentFromInsolvencyResource")
+	result, err := s.Service.GetAttachmentFromInsolvencyResource(ctx, transactionID, attachmentID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetAttachmentResources")
+	result, err := s.Service.GetAttachmentResources(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error {
+	ctx, done := startOperation(ctx, "DeleteAttachmentResource")
+	err := s.Service.DeleteAttachmentResource(ctx, transactionID, attachmentID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID, avStatus string) error {
+	ctx, done := startOperation(ctx, "UpdateAttachmentStatus")
+	err := s.Service.UpdateAttachmentStatus(ctx, transactionID, attachmentID, avStatus)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error {
+	ctx, done := startOperation(ctx, "CreateStatementOfAffairsResource")
+	err := s.Service.CreateStatementOfAffairsResource(ctx, dao, transactionID, ifMatch)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error {
+	ctx, done := startOperation(ctx, "CreateProgressReportResource")
+	err := s.Service.CreateProgressReportResource(ctx, dao, transactionID, ifMatch)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error {
+	ctx, done := startOperation(ctx, "DeleteStatementOfAffairsResource")
+	err := s.Service.DeleteStatementOfAffairsResource(ctx, transactionID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error {
+	ctx, done := startOperation(ctx, "CreateResolutionResource")
+	err := s.Service.CreateResolutionResource(ctx, dao, transactionID, ifMatch)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetStatementOfAffairsResource")
+	result, err := s.Service.GetStatementOfAffairsResource(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetResolutionResource")
+	result, err := s.Service.GetResolutionResource(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) DeleteResolutionResource(ctx context.Context, transactionID string) error {
+	ctx, done := startOperation(ctx, "DeleteResolutionResource")
+	err := s.Service.DeleteResolutionResource(ctx, transactionID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error) {
+	ctx, done := startOperation(ctx, "GetProgressReportResource")
+	result, err := s.Service.GetProgressReportResource(ctx, transactionID)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) DeleteProgressReportResource(ctx context.Context, transactionID string) error {
+	ctx, done := startOperation(ctx, "DeleteProgressReportResource")
+	err := s.Service.DeleteProgressReportResource(ctx, transactionID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error) {
+	ctx, done := startOperation(ctx, "ReserveIdempotencyKey")
+	result, err := s.Service.ReserveIdempotencyKey(ctx, dao)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error) {
+	ctx, done := startOperation(ctx, "SetValidationFailures")
+	result, err := s.Service.SetValidationFailures(ctx, transactionID, failedRules)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error {
+	ctx, done := startOperation(ctx, "CompleteIdempotencyKey")
+	err := s.Service.CompleteIdempotencyKey(ctx, key, token, response, expiresAt)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) ReleaseIdempotencyKey(ctx context.Context, key, token string) error {
+	ctx, done := startOperation(ctx, "ReleaseIdempotencyKey")
+	err := s.Service.ReleaseIdempotencyKey(ctx, key, token)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error {
+	ctx, done := startOperation(ctx, "CreateAuditEvent")
+	err := s.Service.CreateAuditEvent(ctx, dao)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error) {
+	ctx, done := startOperation(ctx, "GetAuditEvents")
+	result, err := s.Service.GetAuditEvents(ctx, filter)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error {
+	ctx, done := startOperation(ctx, "CreateOutboxEvent")
+	err := s.Service.CreateOutboxEvent(ctx, dao)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error) {
+	ctx, done := startOperation(ctx, "GetOutboxEvents")
+	result, err := s.Service.GetOutboxEvents(ctx, limit)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	ctx, done := startOperation(ctx, "ClaimOutboxEvents")
+	result, err := s.Service.ClaimOutboxEvents(ctx, claimant, now, lease, limit)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error {
+	ctx, done := startOperation(ctx, "ReleaseOutboxClaim")
+	err := s.Service.ReleaseOutboxClaim(ctx, transactionID, claimant)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error) {
+	ctx, done := startOperation(ctx, "FailOutboxEvent")
+	result, err := s.Service.FailOutboxEvent(ctx, transactionID, eventID, maxAttempts)
+	done(err)
+	return result, err
+}
+
+func (s *instrumentedService) DeleteOutboxEvent(ctx context.Context, transactionID string, eventID string) error {
+	ctx, done := startOperation(ctx, "DeleteOutboxEvent")
+	err := s.Service.DeleteOutboxEvent(ctx, transactionID, eventID)
+	done(err)
+	return err
+}
+
+func (s *instrumentedService) Ping(ctx context.Context) error {
+	ctx, done := startOperation(ctx, "Ping")
+	err := s.Service.Ping(ctx)
+	done(err)
+	return err
+}
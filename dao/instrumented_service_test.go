@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubService returns the configured error from GetInsolvencyResource
+type stubService struct {
+	Service
+	err error
+}
+
+func (s *stubService) GetInsolvencyResource(_ context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	return models.InsolvencyResourceDao{TransactionID: transactionID}, s.err
+}
+
+func TestUnitInstrumentedService(t *testing.T) {
+	Convey("Results of the wrapped service are returned unchanged", t, func() {
+		svc := NewInstrumentedService(&stubService{})
+
+		insolvencyResource, err := svc.GetInsolvencyResource(context.Background(), "12345678")
+
+		So(err, ShouldBeNil)
+		So(insolvencyResource.TransactionID, ShouldEqual, "12345678")
+	})
+
+	Convey("Errors from the wrapped service are returned unchanged", t, func() {
+		expectedErr := errors.New("error getting insolvency case")
+		svc := NewInstrumentedService(&stubService{err: expectedErr})
+
+		_, err := svc.GetInsolvencyResource(context.Background(), "12345678")
+
+		So(err, ShouldEqual, expectedErr)
+	})
+	Convey("Each operation is traced in a span", t, func() {
+		recorder := tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+		svc := NewInstrumentedService(&stubService{err: errors.New("error getting insolvency case")})
+		_, _ = svc.GetInsolvencyResource(context.Background(), "12345678")
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 1)
+		So(spans[0].Name(), ShouldEqual, "dao.GetInsolvencyResource")
+		So(spans[0].Status().Code, ShouldEqual, codes.Error)
+	})
+}
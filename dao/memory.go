@@ -0,0 +1,1088 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryService is an implementation of the Service interface that keeps insolvency cases in memory, so that the
+// API can be run without MongoDB. It follows the same semantics and returns the same errors as the MongoService.
+// Cases are stored encoded as BSON, so that callers never share data with the store
+type MemoryService struct {
+	mtx             sync.RWMutex
+	cases           map[string][]byte
+	idempotencyKeys map[string][]byte
+	auditEvents     [][]byte
+	outbox          [][]byte
+	outboxClaims    map[string]outboxClaim
+	writtenEvents   map[string]bool
+	failedRules     map[string][]string
+}
+
+// outboxClaim is held on the outbox of a case by the claimant delivering its events, until expiresAt
+type outboxClaim struct {
+	claimant  string
+	expiresAt time.Time
+}
+
+// NewMemoryService returns a MemoryService with no insolvency cases
+func NewMemoryService() *MemoryService {
+	return &MemoryService{cases: make(map[string][]byte), idempotencyKeys: make(map[string][]byte), outboxClaims: make(map[string]outboxClaim), writtenEvents: make(map[string]bool), failedRules: make(map[string][]string)}
+}
+
+// load returns the insolvency case with the specified transactionID and whether it exists. The caller must hold
+// the lock
+func (m *MemoryService) load(transactionID string) (models.InsolvencyResourceDao, bool, error) {
+	var insolvencyResource models.InsolvencyResourceDao
+
+	stored, ok := m.cases[transactionID]
+	if !ok {
+		return insolvencyResource, false, nil
+	}
+
+	if err := bson.Unmarshal(stored, &insolvencyResource); err != nil {
+		log.Error(err)
+		return insolvencyResource, false, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	return insolvencyResource, true, nil
+}
+
+// save stores the insolvency case, replacing any case with the same transactionID. The caller must hold the lock
+func (m *MemoryService) save(insolvencyResource *models.InsolvencyResourceDao) error {
+	stored, err := bson.Marshal(insolvencyResource)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, insolvencyResource.TransactionID), err)
+	}
+
+	m.cases[insolvencyResource.TransactionID] = stored
+	return nil
+}
+
+// contextError returns the error for an operation whose context has already been cancelled or timed out
+func contextError(ctx context.Context, transactionID string) error {
+	if err := ctx.Err(); err != nil {
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+	return nil
+}
+
+// CreateInsolvencyResource will store the insolvency request in memory
+func (m *MemoryService) CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+	if err := contextError(ctx, dao.TransactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.cases[dao.TransactionID]; ok {
+		log.Info("an insolvency case already exists for this transaction id")
+		return apperrors.Conflict("an insolvency case already exists for this transaction id")
+	}
+
+	dao.ID = primitive.NewObjectID()
+
+	events, err := caseCreatedEvents(dao)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem creating an insolvency case for this transaction id: %v", err), err)
+	}
+	return m.saveWithEvents(dao, events...)
+}
+
+// GetInsolvencyResource retrieves all the data for an insolvency case with the specified transactionID
+func (m *MemoryService) GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return models.InsolvencyResourceDao{}, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return models.InsolvencyResourceDao{}, err
+	}
+	if !ok {
+		log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+		return models.InsolvencyResourceDao{}, apperrors.NotFound("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)
+	}
+
+	return insolvencyResource, nil
+}
+
+// CreatePractitionersResource stores an incoming practitioner to the list of practitioners for the insolvency case
+// with the specified transactionID
+func (m *MemoryService) CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+		return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
+	}
+
+	// Check if practitioner is already assigned to this case
+	for _, storedPractitioner := range insolvencyResource.Data.Practitioners {
+		if dao.IPCode == storedPractitioner.IPCode {
+			err = apperrors.Conflict(constants.MsgPractitionerAlreadyAssigned, transactionID, dao.IPCode, storedPractitioner.ID)
+			log.Error(err)
+			return err
+		}
+	}
+
+	// Check if there are already 5 practitioners in the case
+	if len(insolvencyResource.Data.Practitioners) >= maxPractitioners {
+		err = apperrors.Validation("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID)
+		log.Error(err)
+		return err
+	}
+
+	insolvencyResource.Data.Practitioners = append(insolvencyResource.Data.Practitioners, *dao)
+
+	return m.save(&insolvencyResource)
+}
+
+// GetPractitionerResources gets a list of all practitioners for an insolvency case with the specified transactionID
+func (m *MemoryService) GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Debug(constants.MsgCaseNotFound, log.Data{"transaction_id": transactionID})
+		return nil, nil
+	}
+
+	// Return an empty array instead of nil so the handler can check
+	// that there are no practitioners
+	if insolvencyResource.Data.Practitioners == nil {
+		return make([]models.PractitionerResourceDao, 0), nil
+	}
+
+	return insolvencyResource.Data.Practitioners, nil
+}
+
+// GetPractitionerResource gets a single practitioner for an insolvency case with the specified transactionID and practitionerID
+func (m *MemoryService) GetPractitionerResource(ctx context.Context, practitionerID string, transactionID string) (models.PractitionerResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return models.PractitionerResourceDao{}, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, _, err := m.load(transactionID)
+	if err != nil {
+		return models.PractitionerResourceDao{}, err
+	}
+
+	for _, practitioner := range insolvencyResource.Data.Practitioners {
+		if practitioner.ID == practitionerID {
+			return practitioner, nil
+		}
+	}
+
+	log.Debug(constants.MsgCaseNotFound, log.Data{"transaction_id": transactionID})
+	return models.PractitionerResourceDao{}, nil
+}
+
+// DeletePractitioner deletes a practitioner for an insolvency case with the specified transactionID and practitionerID
+func (m *MemoryService) DeletePractitioner(ctx context.Context, practitionerID string, transactionID string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err = apperrors.NotFound("there was a problem handling your request for transaction id %s - insolvency case not found", transactionID)
+		log.Error(err)
+		return err
+	}
+
+	practitioners := make([]models.PractitionerResourceDao, 0, len(insolvencyResource.Data.Practitioners))
+	for _, practitioner := range insolvencyResource.Data.Practitioners {
+		if practitioner.ID != practitionerID {
+			practitioners = append(practitioners, practitioner)
+		}
+	}
+
+	// Return error if no practitioner was removed
+	if len(practitioners) == len(insolvencyResource.Data.Practitioners) {
+		err = apperrors.NotFound("there was a problem handling your request for transaction id %s - practitioner with id %s not found", transactionID, practitionerID)
+		log.Error(err)
+		return err
+	}
+
+	insolvencyResource.Data.Practitioners = practitioners
+
+	return m.save(&insolvencyResource)
+}
+
+// AppointPractitioner adds appointment details insolvency case with the specified transactionID and practitionerID
+func (m *MemoryService) AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID string, practitionerID string) error {
+	event, err := practitionerAppointedEvent(dao, transactionID, practitionerID)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	return m.updatePractitioner(ctx, transactionID, practitionerID, event, func(practitioner *models.PractitionerResourceDao) bool {
+		if practitioner.Appointment != nil && *practitioner.Appointment == *dao {
+			return false
+		}
+		appointment := *dao
+		practitioner.Appointment = &appointment
+		return true
+	})
+}
+
+// DeletePractitionerAppointment deletes an appointment for the specified transactionID and practitionerID
+func (m *MemoryService) DeletePractitionerAppointment(ctx context.Context, transactionID string, practitionerID string) error {
+	return m.updatePractitioner(ctx, transactionID, practitionerID, nil, func(practitioner *models.PractitionerResourceDao) bool {
+		if practitioner.Appointment == nil {
+			return false
+		}
+		practitioner.Appointment = nil
+		return true
+	})
+}
+
+// updatePractitioner applies the update to the practitioner with the specified transactionID and practitionerID,
+// writing the event to the outbox if it is not nil. The update returns false if it left the practitioner unchanged
+func (m *MemoryService) updatePractitioner(ctx context.Context, transactionID string, practitionerID string, event *models.OutboxEventDao, update func(*models.PractitionerResourceDao) bool) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, _, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+
+	for i := range insolvencyResource.Data.Practitioners {
+		if insolvencyResource.Data.Practitioners[i].ID != practitionerID {
+			continue
+		}
+		// Check if the practitioner was changed
+		if !update(&insolvencyResource.Data.Practitioners[i]) {
+			err = apperrors.NotFound("item with transaction id %s or practitioner id %s not updated", transactionID, practitionerID)
+			log.Error(err)
+			return err
+		}
+		if event == nil {
+			return m.save(&insolvencyResource)
+		}
+		return m.saveWithEvents(&insolvencyResource, event)
+	}
+
+	err = apperrors.NotFound("item with transaction id %s or practitioner id %s does not exist", transactionID, practitionerID)
+	log.Error(err)
+	return err
+}
+
+// AddAttachmentToInsolvencyResource adds an attachment to the insolvency case with the specified transactionID
+func (m *MemoryService) AddAttachmentToInsolvencyResource(ctx context.Context, transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+	}
+
+	attachmentDao := models.AttachmentResourceDao{
+		ID:     fileID,
+		Type:   attachmentType,
+		Status: "submitted",
+		Links: models.AttachmentResourceLinksDao{
+			Self:     constants.TransactionsPath + transactionID + constants.AttachmentsPath + fileID,
+			Download: constants.TransactionsPath + transactionID + constants.AttachmentsPath + fileID + "/download",
+		},
+	}
+
+	insolvencyResource.Data.Attachments = append(insolvencyResource.Data.Attachments, attachmentDao)
+
+	if err = m.save(&insolvencyResource); err != nil {
+		return nil, err
+	}
+
+	return &attachmentDao, nil
+}
+
+// GetAttachmentResources retrieves all attachments filed for an Insolvency Case
+func (m *MemoryService) GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Debug(fmt.Sprintf("no insolvency case found for transaction id: [%s]", transactionID))
+		return nil, nil
+	}
+
+	// Return an empty array instead of nil to distinguish from insolvency case
+	// not found
+	if insolvencyResource.Data.Attachments == nil {
+		return make([]models.AttachmentResourceDao, 0), nil
+	}
+
+	return insolvencyResource.Data.Attachments, nil
+}
+
+// GetAttachmentFromInsolvencyResource retrieves an attachment filed for an Insolvency Case
+func (m *MemoryService) GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID string, fileID string) (models.AttachmentResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return models.AttachmentResourceDao{}, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, _, err := m.load(transactionID)
+	if err != nil {
+		return models.AttachmentResourceDao{}, err
+	}
+
+	for _, attachment := range insolvencyResource.Data.Attachments {
+		if attachment.ID == fileID {
+			return attachment, nil
+		}
+	}
+
+	log.Debug(constants.MsgCaseNotFound, log.Data{"transaction_id": transactionID})
+	return models.AttachmentResourceDao{}, nil
+}
+
+// DeleteAttachmentResource deletes an attachment filed for an Insolvency Case
+func (m *MemoryService) DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err = apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		log.Error(err)
+		return err
+	}
+
+	attachments := make([]models.AttachmentResourceDao, 0, len(insolvencyResource.Data.Attachments))
+	for _, attachment := range insolvencyResource.Data.Attachments {
+		if attachment.ID != attachmentID {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	// Return error if no attachment was removed
+	if len(attachments) == len(insolvencyResource.Data.Attachments) {
+		err = apperrors.NotFound("there was a problem handling your request for transaction id [%s] - attachment with id [%s] not found", transactionID, attachmentID)
+		log.Error(err)
+		return err
+	}
+
+	insolvencyResource.Data.Attachments = attachments
+
+	return m.save(&insolvencyResource)
+}
+
+// UpdateAttachmentStatus updates the status of an attachment filed for an Insolvency Case. The status of an
+// attachment that has been processed is left unchanged
+func (m *MemoryService) UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID string, avStatus string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	event, err := attachmentScannedEvent(transactionID, attachmentID, avStatus)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, _, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i, attachment := range insolvencyResource.Data.Attachments {
+		if attachment.ID != attachmentID {
+			continue
+		}
+		found = true
+		if attachment.Status != "processed" && attachment.Status != avStatus {
+			insolvencyResource.Data.Attachments[i].Status = avStatus
+			return m.saveWithEvents(&insolvencyResource, event)
+		}
+	}
+
+	if !found {
+		err = apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// CreateResolutionResource stores the resolution for the insolvency case
+// with the specified transactionID
+func (m *MemoryService) CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error {
+	resolutionDao := models.ResolutionResourceDao{
+		DateOfResolution: dao.DateOfResolution,
+		Attachments:      dao.Attachments,
+		Kind:             dao.Kind,
+		Etag:             dao.Etag,
+		Links:            dao.Links,
+	}
+
+	return m.createCaseResource(ctx, transactionID, resolutionType, ifMatch, func(data *models.InsolvencyResourceDaoData) {
+		data.Resolution = &resolutionDao
+	})
+}
+
+// CreateStatementOfAffairsResource stores the statement of affairs resource for the insolvency case
+// with the specified transactionID
+func (m *MemoryService) CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error {
+	statementDao := models.StatementOfAffairsResourceDao{
+		StatementDate: dao.StatementDate,
+		Attachments:   dao.Attachments,
+		Kind:          dao.Kind,
+		Etag:          dao.Etag,
+		Links:         dao.Links,
+	}
+
+	return m.createCaseResource(ctx, transactionID, statementOfAffairsType, ifMatch, func(data *models.InsolvencyResourceDaoData) {
+		data.StatementOfAffairs = &statementDao
+	})
+}
+
+// CreateProgressReportResource stores the progress report resource for the insolvency case
+// with the specified transactionID
+func (m *MemoryService) CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error {
+	progressReportDao := models.ProgressReportResourceDao{
+		FromDate:    dao.FromDate,
+		ToDate:      dao.ToDate,
+		Attachments: dao.Attachments,
+		Etag:        dao.Etag,
+		Kind:        dao.Kind,
+		Links:       dao.Links,
+	}
+
+	return m.createCaseResource(ctx, transactionID, progressReportType, ifMatch, func(data *models.InsolvencyResourceDaoData) {
+		data.ProgressReport = &progressReportDao
+	})
+}
+
+// createCaseResource files a resource of the type resType for the insolvency case with the specified
+// transactionID, using the set function to replace any already filed. When ifMatch is not empty, the resource is
+// only replaced if the filed resource has that etag
+func (m *MemoryService) createCaseResource(ctx context.Context, transactionID string, resType string, ifMatch string, set func(*models.InsolvencyResourceDaoData)) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+		return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
+	}
+
+	if etag, filed := filedResourceEtag(&insolvencyResource.Data, resType); ifMatch != "" && (!filed || etag != ifMatch) {
+		return etagMismatchError(transactionID, resType, ifMatch)
+	}
+
+	set(&insolvencyResource.Data)
+	return m.save(&insolvencyResource)
+}
+
+// GetStatementOfAffairsResource retrieves the statement of affairs filed for an Insolvency Case
+func (m *MemoryService) GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error) {
+	insolvencyResource, err := m.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.StatementOfAffairs == nil {
+		return models.StatementOfAffairsResourceDao{}, err
+	}
+
+	return *insolvencyResource.Data.StatementOfAffairs, nil
+}
+
+// GetResolutionResource retrieves the resolution filed for an Insolvency Case
+func (m *MemoryService) GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error) {
+	insolvencyResource, err := m.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.Resolution == nil {
+		return models.ResolutionResourceDao{}, err
+	}
+
+	return *insolvencyResource.Data.Resolution, nil
+}
+
+// GetProgressReportResource retrieves the progress report filed for an Insolvency Case
+func (m *MemoryService) GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error) {
+	insolvencyResource, err := m.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.ProgressReport == nil {
+		return &models.ProgressReportResourceDao{}, err
+	}
+
+	return insolvencyResource.Data.ProgressReport, nil
+}
+
+// getCase returns the insolvency case with the specified transactionID, or an empty case if it does not exist
+func (m *MemoryService) getCase(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return models.InsolvencyResourceDao{}, err
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err == nil && !ok {
+		log.Debug(constants.MsgCaseNotFound, log.Data{"transaction_id": transactionID})
+	}
+
+	return insolvencyResource, err
+}
+
+// DeleteStatementOfAffairsResource deletes the statement of affairs filed for an insolvency case
+func (m *MemoryService) DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error {
+	return m.deleteCaseResource(ctx, transactionID, "statement-of-affairs", func(data *models.InsolvencyResourceDaoData) bool {
+		deleted := data.StatementOfAffairs != nil
+		data.StatementOfAffairs = nil
+		return deleted
+	})
+}
+
+// DeleteResolutionResource deletes a resolution resource filed for an Insolvency Case
+func (m *MemoryService) DeleteResolutionResource(ctx context.Context, transactionID string) error {
+	return m.deleteCaseResource(ctx, transactionID, "resolution", func(data *models.InsolvencyResourceDaoData) bool {
+		deleted := data.Resolution != nil
+		data.Resolution = nil
+		return deleted
+	})
+}
+
+// DeleteProgressReportResource deletes the progress report filed for an insolvency case
+func (m *MemoryService) DeleteProgressReportResource(ctx context.Context, transactionID string) error {
+	return m.deleteCaseResource(ctx, transactionID, "progress-report", func(data *models.InsolvencyResourceDaoData) bool {
+		deleted := data.ProgressReport != nil
+		data.ProgressReport = nil
+		return deleted
+	})
+}
+
+// deleteCaseResource deletes the resource of the type resType from the insolvency case with the specified
+// transactionID. The unset function returns false if the case did not have the resource
+func (m *MemoryService) deleteCaseResource(ctx context.Context, transactionID string, resType string, unset func(*models.InsolvencyResourceDaoData) bool) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	insolvencyResource, ok, err := m.load(transactionID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err = apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		log.Error(err)
+		return err
+	}
+
+	if !unset(&insolvencyResource.Data) {
+		err = apperrors.NotFound("there was a problem handling your request for transaction id [%s] - %v not found", transactionID, strings.ReplaceAll(resType, "-", " "))
+		log.Error(err)
+		return err
+	}
+
+	return m.save(&insolvencyResource)
+}
+
+// SetValidationFailures stores the failed rules for the insolvency case with the specified transactionID, returning
+// those that were stored before
+func (m *MemoryService) SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.cases[transactionID]; !ok {
+		err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		log.Error(err)
+		return nil, err
+	}
+
+	previous := m.failedRules[transactionID]
+	m.failedRules[transactionID] = append([]string{}, failedRules...)
+
+	return previous, nil
+}
+
+// ReserveIdempotencyKey stores the key in memory, unless an unexpired key with the same value is already stored,
+// in which case the stored key is returned
+func (m *MemoryService) ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newDatabaseError("there was a problem reserving the idempotency key", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if stored, ok := m.idempotencyKeys[dao.Key]; ok {
+		var storedKey models.IdempotencyKeyDao
+		if err := bson.Unmarshal(stored, &storedKey); err != nil {
+			log.Error(err)
+			return nil, newDatabaseError("there was a problem reserving the idempotency key", err)
+		}
+		if storedKey.ExpiresAt.After(dao.CreatedAt) {
+			return &storedKey, nil
+		}
+	}
+
+	return nil, m.saveIdempotencyKey(dao)
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the key, keeping it until expiresAt, if the
+// key is still reserved with token
+func (m *MemoryService) CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return newDatabaseError("there was a problem storing the response for the idempotency key", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	stored, ok := m.idempotencyKeys[key]
+	if !ok {
+		return nil
+	}
+
+	var storedKey models.IdempotencyKeyDao
+	if err := bson.Unmarshal(stored, &storedKey); err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem storing the response for the idempotency key", err)
+	}
+	if storedKey.Token != token {
+		return nil
+	}
+	storedKey.Response = response
+	storedKey.ExpiresAt = expiresAt
+
+	return m.saveIdempotencyKey(&storedKey)
+}
+
+// ReleaseIdempotencyKey deletes the key from memory, if it is still reserved with token
+func (m *MemoryService) ReleaseIdempotencyKey(ctx context.Context, key, token string) error {
+	if err := ctx.Err(); err != nil {
+		return newDatabaseError("there was a problem releasing the idempotency key", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	stored, ok := m.idempotencyKeys[key]
+	if !ok {
+		return nil
+	}
+
+	var storedKey models.IdempotencyKeyDao
+	if err := bson.Unmarshal(stored, &storedKey); err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem releasing the idempotency key", err)
+	}
+	if storedKey.Token == token {
+		delete(m.idempotencyKeys, key)
+	}
+
+	return nil
+}
+
+// saveIdempotencyKey stores the idempotency key, replacing any key with the same value. The caller must hold the
+// lock
+func (m *MemoryService) saveIdempotencyKey(dao *models.IdempotencyKeyDao) error {
+	stored, err := bson.Marshal(dao)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem storing the idempotency key", err)
+	}
+
+	m.idempotencyKeys[dao.Key] = stored
+	return nil
+}
+
+// CreateAuditEvent appends the event to the audit events held in memory
+func (m *MemoryService) CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error {
+	if err := contextError(ctx, dao.TransactionID); err != nil {
+		return err
+	}
+
+	stored, err := bson.Marshal(dao)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem recording the audit event for transaction [%s]", dao.TransactionID), err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.auditEvents = append(m.auditEvents, stored)
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter, oldest first
+func (m *MemoryService) GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newDatabaseError("there was a problem retrieving the audit events", err)
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	events := []models.AuditEventDao{}
+	for _, stored := range m.auditEvents {
+		var event models.AuditEventDao
+		if err := bson.Unmarshal(stored, &event); err != nil {
+			log.Error(err)
+			return nil, newDatabaseError("there was a problem retrieving the audit events", err)
+		}
+		if matchesAuditEventFilter(event, filter) {
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+
+	return events, nil
+}
+
+// matchesAuditEventFilter returns whether the audit event is selected by the filter
+func matchesAuditEventFilter(event models.AuditEventDao, filter models.AuditEventFilter) bool {
+	switch {
+	case filter.TransactionID != "" && event.TransactionID != filter.TransactionID,
+		filter.CompanyNumber != "" && event.CompanyNumber != filter.CompanyNumber,
+		filter.UserID != "" && event.UserID != filter.UserID,
+		filter.Route != "" && event.Route != filter.Route,
+		!filter.From.IsZero() && event.CreatedAt.Before(filter.From),
+		!filter.To.IsZero() && !event.CreatedAt.Before(filter.To):
+		return false
+	}
+	return true
+}
+
+// CreateOutboxEvent appends the event to the outbox held in memory, unless an event with the same ID has been
+// written for the case before
+func (m *MemoryService) CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error {
+	if err := contextError(ctx, dao.TransactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.cases[dao.TransactionID]; !ok {
+		err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, dao.TransactionID)
+		log.Error(err)
+		return err
+	}
+
+	written := dao.TransactionID + ":" + dao.ID
+	if m.writtenEvents[written] {
+		return nil
+	}
+	if err := m.saveOutboxEvent(dao); err != nil {
+		return err
+	}
+	m.writtenEvents[written] = true
+
+	return nil
+}
+
+// GetOutboxEvents retrieves up to limit events from the outbox, oldest first
+func (m *MemoryService) GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newDatabaseError("there was a problem retrieving the outbox events", err)
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	events, err := m.loadOutbox()
+	if err != nil {
+		return nil, newDatabaseError("there was a problem retrieving the outbox events", err)
+	}
+
+	// Events are appended in the order they were written, which a stable sort keeps for events with the same time
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// ClaimOutboxEvents claims the outboxes of the cases in memory with events waiting to be delivered, returning up to
+// limit of their events that have not been parked
+func (m *MemoryService) ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newDatabaseError("there was a problem claiming the outbox events", err)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	events, err := m.loadOutbox()
+	if err != nil {
+		return nil, newDatabaseError("there was a problem claiming the outbox events", err)
+	}
+
+	// The events of each case are kept in the order they were written, and the cases are ordered by the time of
+	// their oldest event
+	pending := make(map[string][]models.OutboxEventDao)
+	transactionIDs := []string{}
+	for _, event := range events {
+		if event.Parked {
+			continue
+		}
+		if _, ok := pending[event.TransactionID]; !ok {
+			transactionIDs = append(transactionIDs, event.TransactionID)
+		}
+		pending[event.TransactionID] = append(pending[event.TransactionID], event)
+	}
+	sort.SliceStable(transactionIDs, func(i, j int) bool {
+		return pending[transactionIDs[i]][0].CreatedAt.Before(pending[transactionIDs[j]][0].CreatedAt)
+	})
+
+	claimed := []models.OutboxEventDao{}
+	for _, transactionID := range transactionIDs {
+		if limit > 0 && len(claimed) >= limit {
+			break
+		}
+		if claim, ok := m.outboxClaims[transactionID]; ok && claim.expiresAt.After(now) {
+			continue
+		}
+		m.outboxClaims[transactionID] = outboxClaim{claimant: claimant, expiresAt: now.Add(lease)}
+		claimed = append(claimed, pending[transactionID]...)
+	}
+	if limit > 0 && len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+
+	return claimed, nil
+}
+
+// ReleaseOutboxClaim removes the claim on the outbox of the case held in memory, if it is held by the claimant
+func (m *MemoryService) ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if claim, ok := m.outboxClaims[transactionID]; ok && claim.claimant == claimant {
+		delete(m.outboxClaims, transactionID)
+	}
+
+	return nil
+}
+
+// FailOutboxEvent counts a failed delivery of the event in the outbox held in memory, parking it once its
+// deliveries have failed maxAttempts times
+func (m *MemoryService) FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error) {
+	if err := contextError(ctx, transactionID); err != nil {
+		return false, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for i, stored := range m.outbox {
+		var event models.OutboxEventDao
+		if err := bson.Unmarshal(stored, &event); err != nil {
+			log.Error(err)
+			return false, newDatabaseError(fmt.Sprintf("there was a problem recording the failed delivery of the outbox event for transaction [%s]", transactionID), err)
+		}
+		if event.TransactionID != transactionID || event.ID != eventID || event.Parked {
+			continue
+		}
+
+		event.Attempts++
+		event.Parked = event.Attempts >= maxAttempts
+		encoded, err := encodeOutboxEvent(&event)
+		if err != nil {
+			return false, err
+		}
+		m.outbox[i] = encoded
+		return event.Parked, nil
+	}
+
+	return false, nil
+}
+
+// DeleteOutboxEvent removes the event from the outbox held in memory
+func (m *MemoryService) DeleteOutboxEvent(ctx context.Context, transactionID string, eventID string) error {
+	if err := contextError(ctx, transactionID); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for i, stored := range m.outbox {
+		var event models.OutboxEventDao
+		if err := bson.Unmarshal(stored, &event); err != nil {
+			log.Error(err)
+			return newDatabaseError(fmt.Sprintf("there was a problem deleting the outbox event for transaction [%s]", transactionID), err)
+		}
+		if event.TransactionID == transactionID && event.ID == eventID {
+			m.outbox = append(m.outbox[:i:i], m.outbox[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// saveWithEvents stores the insolvency case and appends the events to the outbox, storing none of them if any
+// cannot be encoded. The caller must hold the lock
+func (m *MemoryService) saveWithEvents(insolvencyResource *models.InsolvencyResourceDao, events ...*models.OutboxEventDao) error {
+	stored := make([][]byte, 0, len(events))
+	for _, event := range events {
+		encoded, err := encodeOutboxEvent(event)
+		if err != nil {
+			return err
+		}
+		stored = append(stored, encoded)
+	}
+	if err := m.save(insolvencyResource); err != nil {
+		return err
+	}
+
+	m.outbox = append(m.outbox, stored...)
+	return nil
+}
+
+// saveOutboxEvent appends the event to the outbox. The caller must hold the lock
+func (m *MemoryService) saveOutboxEvent(dao *models.OutboxEventDao) error {
+	stored, err := encodeOutboxEvent(dao)
+	if err != nil {
+		return err
+	}
+
+	m.outbox = append(m.outbox, stored)
+	return nil
+}
+
+// loadOutbox returns the events in the outbox, in the order they were written. The caller must hold the lock
+func (m *MemoryService) loadOutbox() ([]models.OutboxEventDao, error) {
+	events := make([]models.OutboxEventDao, 0, len(m.outbox))
+	for _, stored := range m.outbox {
+		var event models.OutboxEventDao
+		if err := bson.Unmarshal(stored, &event); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// encodeOutboxEvent returns the event encoded as it is held in the outbox
+func encodeOutboxEvent(dao *models.OutboxEventDao) ([]byte, error) {
+	stored, err := bson.Marshal(dao)
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError(fmt.Sprintf("there was a problem writing the outbox event for transaction [%s]", dao.TransactionID), err)
+	}
+	return stored, nil
+}
+
+// Ping always succeeds, as the in-memory store cannot be unreachable
+func (m *MemoryService) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close does nothing, as the in-memory store holds no connections
+func (m *MemoryService) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/config"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitMemoryService(t *testing.T) {
+	testServiceBehaviour(t, func(t *testing.T) Service {
+		return NewMemoryService()
+	})
+}
+
+func TestUnitNewDAOServiceBackend(t *testing.T) {
+	Convey("The memory backend does not need MongoDB", t, func() {
+		svc, err := NewDAOService(&config.Config{DatabaseBackend: BackendMemory})
+		So(err, ShouldBeNil)
+		So(svc.Ping(context.Background()), ShouldBeNil)
+	})
+
+	Convey("An unknown backend is an error", t, func() {
+		svc, err := NewDAOService(&config.Config{DatabaseBackend: "cassandra"})
+		So(svc, ShouldBeNil)
+		So(err.Error(), ShouldEqual, "unknown database backend [cassandra]")
+	})
+}
+
+func TestUnitDatabaseName(t *testing.T) {
+	Convey("The database is named after the configured backend", t, func() {
+		So(DatabaseName("", ""), ShouldEqual, "mongodb")
+		So(DatabaseName(BackendMongo, ""), ShouldEqual, "mongodb")
+		So(DatabaseName(BackendMemory, ""), ShouldEqual, "memory")
+		So(DatabaseName(BackendSQL, ""), ShouldEqual, "sqlite")
+		So(DatabaseName(BackendSQL, "postgres"), ShouldEqual, "postgres")
+	})
+}
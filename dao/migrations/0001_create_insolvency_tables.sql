@@ -0,0 +1,90 @@
+-- Insolvency cases, keyed by the transaction they are filed with
+CREATE TABLE cases (
+    transaction_id         TEXT NOT NULL PRIMARY KEY,
+    id                     TEXT NOT NULL,
+    etag                   TEXT NOT NULL,
+    kind                   TEXT NOT NULL,
+    company_number         TEXT NOT NULL,
+    case_type              TEXT NOT NULL,
+    company_name           TEXT NOT NULL,
+    self_link              TEXT NOT NULL,
+    transaction_link       TEXT NOT NULL,
+    validation_status_link TEXT NOT NULL,
+    created_by             TEXT NOT NULL,
+    firm_id                TEXT NOT NULL
+);
+
+CREATE INDEX cases_company_number ON cases (company_number);
+
+-- Practitioners on a case, in the order they were added
+CREATE TABLE practitioners (
+    transaction_id   TEXT    NOT NULL REFERENCES cases (transaction_id),
+    id               TEXT    NOT NULL,
+    position         INTEGER NOT NULL,
+    ip_code          TEXT    NOT NULL,
+    first_name       TEXT    NOT NULL,
+    last_name        TEXT    NOT NULL,
+    telephone_number TEXT    NOT NULL,
+    email            TEXT    NOT NULL,
+    premises         TEXT    NOT NULL,
+    address_line_1   TEXT    NOT NULL,
+    address_line_2   TEXT    NOT NULL,
+    country          TEXT    NOT NULL,
+    locality         TEXT    NOT NULL,
+    region           TEXT    NOT NULL,
+    postal_code      TEXT    NOT NULL,
+    po_box           TEXT    NOT NULL,
+    role             TEXT    NOT NULL,
+    self_link        TEXT    NOT NULL,
+    user_id          TEXT    NOT NULL,
+    PRIMARY KEY (transaction_id, id),
+    UNIQUE (transaction_id, ip_code)
+);
+
+-- The appointment of a practitioner, when one has been made
+CREATE TABLE appointments (
+    transaction_id  TEXT NOT NULL,
+    practitioner_id TEXT NOT NULL,
+    appointed_on    TEXT NOT NULL,
+    made_by         TEXT NOT NULL,
+    self_link       TEXT NOT NULL,
+    PRIMARY KEY (transaction_id, practitioner_id),
+    FOREIGN KEY (transaction_id, practitioner_id) REFERENCES practitioners (transaction_id, id)
+);
+
+-- Attachments uploaded for a case, in the order they were added
+CREATE TABLE attachments (
+    transaction_id TEXT    NOT NULL REFERENCES cases (transaction_id),
+    id             TEXT    NOT NULL,
+    position       INTEGER NOT NULL,
+    type           TEXT    NOT NULL,
+    status         TEXT    NOT NULL,
+    self_link      TEXT    NOT NULL,
+    download_link  TEXT    NOT NULL,
+    PRIMARY KEY (transaction_id, id)
+);
+
+CREATE INDEX attachments_id ON attachments (id);
+
+-- Resolutions, statements of affairs and progress reports filed for a case. A case has at most one of each type.
+-- The date is the date of resolution, the statement date or the start of the progress report period
+CREATE TABLE dated_resources (
+    transaction_id TEXT NOT NULL REFERENCES cases (transaction_id),
+    type           TEXT NOT NULL,
+    etag           TEXT NOT NULL,
+    kind           TEXT NOT NULL,
+    date           TEXT NOT NULL,
+    to_date        TEXT NOT NULL,
+    self_link      TEXT NOT NULL,
+    PRIMARY KEY (transaction_id, type)
+);
+
+-- The IDs of the attachments that a dated resource was filed with, in order
+CREATE TABLE dated_resource_attachments (
+    transaction_id TEXT    NOT NULL,
+    type           TEXT    NOT NULL,
+    position       INTEGER NOT NULL,
+    attachment_id  TEXT    NOT NULL,
+    PRIMARY KEY (transaction_id, type, position),
+    FOREIGN KEY (transaction_id, type) REFERENCES dated_resources (transaction_id, type)
+);
@@ -0,0 +1,9 @@
+-- Keys sent with requests in the Idempotency-Key header, with the response to replay once the request is handled.
+-- Times are stored as Unix milliseconds, and the response as JSON
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT   NOT NULL PRIMARY KEY,
+    request_hash    TEXT   NOT NULL,
+    response        TEXT,
+    created_at      BIGINT NOT NULL,
+    expires_at      BIGINT NOT NULL
+);
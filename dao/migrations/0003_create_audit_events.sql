@@ -0,0 +1,23 @@
+-- Append-only audit trail of the requests that changed a case. Times are stored as Unix milliseconds, and the
+-- changes made to the case as JSON
+CREATE TABLE audit_events (
+    id             TEXT    NOT NULL PRIMARY KEY,
+    transaction_id TEXT    NOT NULL,
+    company_number TEXT    NOT NULL,
+    user_id        TEXT    NOT NULL,
+    email          TEXT    NOT NULL,
+    route          TEXT    NOT NULL,
+    method         TEXT    NOT NULL,
+    path           TEXT    NOT NULL,
+    status_code    INTEGER NOT NULL,
+    created_at     BIGINT  NOT NULL,
+    changes        TEXT    NOT NULL
+);
+
+CREATE INDEX audit_events_transaction_id ON audit_events (transaction_id, created_at);
+
+CREATE INDEX audit_events_company_number ON audit_events (company_number, created_at);
+
+CREATE INDEX audit_events_user_id ON audit_events (user_id, created_at);
+
+CREATE INDEX audit_events_created_at ON audit_events (created_at);
@@ -0,0 +1,13 @@
+-- Domain events waiting to be delivered to downstream systems, written in the same transaction as the change to
+-- the case they describe and deleted once delivered. Times are stored as Unix milliseconds, and the data of each
+-- event as JSON
+CREATE TABLE outbox_events (
+    id             TEXT    NOT NULL PRIMARY KEY,
+    transaction_id TEXT    NOT NULL REFERENCES cases (transaction_id),
+    position       INTEGER NOT NULL,
+    type           TEXT    NOT NULL,
+    created_at     BIGINT  NOT NULL,
+    data           TEXT    NOT NULL
+);
+
+CREATE INDEX outbox_events_created_at ON outbox_events (created_at, transaction_id, position);
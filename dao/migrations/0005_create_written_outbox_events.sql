@@ -0,0 +1,7 @@
+-- The IDs of the events written to the outbox of each case by CreateOutboxEvent, kept after the events have been
+-- delivered so that an event with the same ID is never written again
+CREATE TABLE written_outbox_events (
+    transaction_id TEXT NOT NULL REFERENCES cases (transaction_id),
+    id             TEXT NOT NULL,
+    PRIMARY KEY (transaction_id, id)
+);
@@ -0,0 +1,2 @@
+-- The rules each case failed when its validation status was last checked, as a JSON array
+ALTER TABLE cases ADD COLUMN validation_failures TEXT NOT NULL DEFAULT '[]';
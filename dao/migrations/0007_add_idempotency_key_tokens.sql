@@ -0,0 +1,2 @@
+-- The token of the request that reserved each key. Keys reserved before tokens were stored have an empty token
+ALTER TABLE idempotency_keys ADD COLUMN token TEXT NOT NULL DEFAULT '';
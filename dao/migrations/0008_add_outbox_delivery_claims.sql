@@ -0,0 +1,7 @@
+-- The failed deliveries of each outbox event, and whether it has been parked after failing too many times
+ALTER TABLE outbox_events ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE outbox_events ADD COLUMN parked INTEGER NOT NULL DEFAULT 0;
+
+-- The relay delivering the outbox of each case, and when its claim expires as Unix milliseconds
+ALTER TABLE cases ADD COLUMN outbox_claimed_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE cases ADD COLUMN outbox_claim_expires_at BIGINT NOT NULL DEFAULT 0;
@@ -4,12 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -20,41 +20,81 @@ import (
 
 var client *mongo.Client
 
-func getMongoClient(mongoDBURL string) *mongo.Client {
+// maxPractitioners is the maximum number of practitioners that can be assigned to an insolvency case
+const maxPractitioners = 5
+
+// getMongoClient returns the client connected to mongodb, connecting with the configured options on first use.
+// Connecting is retried with backoff so that the service can start while mongodb is still coming up
+func getMongoClient(cfg *config.Config) (*mongo.Client, error) {
 	if client != nil {
-		return client
+		return client, nil
 	}
 
-	ctx := context.Background()
-
-	clientOptions := options.Client().ApplyURI(mongoDBURL)
-	client, err := mongo.Connect(ctx, clientOptions)
-
-	// assume the caller of this func cannot handle the case where there is no database connection so the prog must
-	// crash here as the service cannot continue.
+	clientOptions, err := mongoClientOptions(cfg)
 	if err != nil {
-		log.Error(err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	// check we can connect to the mongodb instance. failure here should result in a crash.
-	pingContext, cancel := context.WithDeadline(ctx, time.Now().Add(5*time.Second))
-	defer cancel()
-	err = client.Ping(pingContext, nil)
+	err = retryWithBackoff(cfg.MongoConnectAttempts, func() error {
+		connectedClient, err := mongo.Connect(context.Background(), clientOptions)
+		if err != nil {
+			return err
+		}
+
+		// check we can connect to the mongodb instance
+		pingContext, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		defer cancel()
+		if err = connectedClient.Ping(pingContext, nil); err != nil {
+			_ = connectedClient.Disconnect(context.Background())
+			return fmt.Errorf("ping to mongodb failed. please check the connection to mongodb and that it is running: [%v]", err)
+		}
+
+		client = connectedClient
+		return nil
+	})
 	if err != nil {
-		log.Error(errors.New("ping to mongodb timed out. please check the connection to mongodb and that it is running"))
-		os.Exit(1)
+		return nil, err
 	}
 
 	log.Info("connected to mongodb successfully")
 
-	return client
+	return client, nil
 }
 
 // MongoService is an implementation of the Service interface using MongoDB as the backend driver.
 type MongoService struct {
-	db             MongoDatabaseInterface
-	CollectionName string
+	db                        MongoDatabaseInterface
+	CollectionName            string
+	IdempotencyCollectionName string
+	AuditCollectionName       string
+	OperationTimeout          time.Duration
+}
+
+// operationContext returns the context for a single MongoService operation, cancelled when the request context is
+// cancelled or the configured operation timeout has passed
+func (m *MongoService) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.OperationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.OperationTimeout)
+}
+
+// caseWithOutbox is an insolvency case stored along with the domain events in its outbox. Events are kept on the
+// case so that they are written in the same single document update as the change they describe, which MongoDB
+// applies atomically without needing a replica set transaction. OutboxPending counts the events in the outbox that
+// have not been parked, so that the indexed cases with events waiting to be delivered are the only ones read to
+// deliver them. A relay delivering the events records its claim on the case until the claim expires
+type caseWithOutbox struct {
+	models.InsolvencyResourceDao `bson:",inline"`
+	Outbox                       []models.OutboxEventDao `bson:"outbox"`
+	OutboxPending                int                     `bson:"outbox_pending"`
+	OutboxClaimedBy              string                  `bson:"outbox_claimed_by,omitempty"`
+	OutboxClaimExpiresAt         time.Time               `bson:"outbox_claim_expires_at,omitempty"`
+}
+
+// storedOutbox is the outbox of a case, read without the rest of the case
+type storedOutbox struct {
+	Outbox []models.OutboxEventDao `bson:"outbox"`
 }
 
 // MongoDatabaseInterface is an interface that describes the mongodb driver
@@ -62,67 +102,73 @@ type MongoDatabaseInterface interface {
 	Collection(name string, opts ...*options.CollectionOptions) *mongo.Collection
 }
 
-func getMongoDatabase(mongoDBURL, databaseName string) MongoDatabaseInterface {
-	return getMongoClient(mongoDBURL).Database(databaseName)
+func getMongoDatabase(cfg *config.Config) (MongoDatabaseInterface, error) {
+	mongoClient, err := getMongoClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mongoClient.Database(cfg.Database), nil
 }
 
 // CreateInsolvencyResource will store the insolvency request into the database
-func (m *MongoService) CreateInsolvencyResource(dao *models.InsolvencyResourceDao) (error, int) {
+func (m *MongoService) CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	dao.ID = primitive.NewObjectID()
 
-	collection := m.db.Collection(m.CollectionName)
+	events, err := caseCreatedEvents(dao)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem creating an insolvency case for this transaction id: %v", err), err)
+	}
+	outbox := make([]models.OutboxEventDao, 0, len(events))
+	for _, event := range events {
+		outbox = append(outbox, *event)
+	}
 
-	filter := bson.M{"transaction_id": dao.TransactionID}
+	collection := m.db.Collection(m.CollectionName)
 
-	// Try to retrieve existing insolvency case from Mongo
-	existingInsolvency := collection.FindOne(context.Background(), filter)
-	err := existingInsolvency.Err()
+	// The unique index on transaction_id rejects a second insolvency case for the same transaction
+	_, err = collection.InsertOne(ctx, caseWithOutbox{InsolvencyResourceDao: *dao, Outbox: outbox, OutboxPending: len(outbox)})
 	if err != nil {
-		// If no documents can be found then the insolvency case can be created
-		if err == mongo.ErrNoDocuments {
-			_, err = collection.InsertOne(context.Background(), dao)
-			if err != nil {
-				log.Error(err)
-				return fmt.Errorf("there was a problem creating an insolvency case for this transaction id: %v", err), http.StatusInternalServerError
-			}
-
-			return nil, http.StatusCreated
+		if mongo.IsDuplicateKeyError(err) {
+			log.Info("an insolvency case already exists for this transaction id")
+			return &apperrors.ConflictError{Message: "an insolvency case already exists for this transaction id", Err: err}
 		}
-
-		// If there is an error but it is not ErrNoDocuments then an error happened checking the existence of the insolvency case
 		log.Error(err)
-		return fmt.Errorf("there was a problem creating an insolvency case for this transaction id: %v", err), http.StatusInternalServerError
+		return newDatabaseError(fmt.Sprintf("there was a problem creating an insolvency case for this transaction id: %v", err), err)
 	}
 
-	// If there is no error retrieving the insolvency case, then it already exists
-	log.Info("an insolvency case already exists for this transaction id")
-	return fmt.Errorf("an insolvency case already exists for this transaction id"), http.StatusConflict
+	return nil
 }
 
 // GetInsolvencyResource retrieves all the data for an insolvency case with the specified transactionID
-func (m *MongoService) GetInsolvencyResource(transactionID string) (models.InsolvencyResourceDao, error) {
+func (m *MongoService) GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
 
 	filter := bson.M{"transaction_id": transactionID}
 
 	// Retrieve insolvency case from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
-			return models.InsolvencyResourceDao{}, fmt.Errorf("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)
+			return models.InsolvencyResourceDao{}, apperrors.NotFound("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)
 		}
 		log.Error(err)
-		return models.InsolvencyResourceDao{}, fmt.Errorf("there was a problem handling your request for transaction [%s]", transactionID)
+		return models.InsolvencyResourceDao{}, newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction [%s]", transactionID), err)
 	}
 
 	err = storedInsolvency.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return models.InsolvencyResourceDao{}, fmt.Errorf("there was a problem handling your request for transaction [%s]", transactionID)
+		return models.InsolvencyResourceDao{}, newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction [%s]", transactionID), err)
 	}
 
 	return insolvencyResource, nil
@@ -130,65 +176,93 @@ func (m *MongoService) GetInsolvencyResource(transactionID string) (models.Insol
 
 // CreatePractitionersResource stores an incoming practitioner to the list of practitioners for the insolvency case
 // with the specified transactionID
-func (m *MongoService) CreatePractitionersResource(dao *models.PractitionerResourceDao, transactionID string) (error, int) {
-	var insolvencyResource models.InsolvencyResourceDao
+func (m *MongoService) CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
+	// Only add the practitioner if the case has room for another practitioner and no
+	// practitioner with the same IP code, so that concurrent requests cannot both pass
+	// these checks
+	filter := bson.M{
+		"transaction_id":             transactionID,
+		"data.practitioners.ip_code": bson.M{"$ne": dao.IPCode},
+		fmt.Sprintf("data.practitioners.%d", maxPractitioners-1): bson.M{"$exists": false},
+	}
+
+	update := bson.M{
+		"$push": bson.M{
+			"data.practitioners": dao,
+		},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	// Nothing was updated so retrieve the insolvency case to find out which condition failed
+	return checkPractitionerCanBeAdded(ctx, dao, transactionID, collection)
+}
+
+// checkPractitionerCanBeAdded works out why a practitioner could not be added to the insolvency case
+// with the specified transactionID and returns the matching error
+func checkPractitionerCanBeAdded(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string, collection *mongo.Collection) error {
+	var insolvencyResource models.InsolvencyResourceDao
+
 	filter := bson.M{"transaction_id": transactionID}
 
 	// Retrieve insolvency case from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
-			return fmt.Errorf(constants.MsgReqTransactionNotFound, transactionID), http.StatusNotFound
+			return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
 		}
 		log.Error(err)
-		return fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID), http.StatusInternalServerError
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedInsolvency.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID), http.StatusInternalServerError
-	}
-
-	maxPractitioners := 5
-
-	// Check if there are already 5 practitioners in database
-	if len(insolvencyResource.Data.Practitioners) == maxPractitioners {
-		err = fmt.Errorf("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID)
-		log.Error(err)
-		return err, http.StatusBadRequest
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	// Check if practitioner is already assigned to this case
 	for _, storedPractitioner := range insolvencyResource.Data.Practitioners {
 		if dao.IPCode == storedPractitioner.IPCode {
-			err = fmt.Errorf("there was a problem handling your request for transaction %s - practitioner with IP Code %s already is already assigned to this case", transactionID, dao.IPCode)
+			err = apperrors.Conflict(constants.MsgPractitionerAlreadyAssigned, transactionID, dao.IPCode, storedPractitioner.ID)
 			log.Error(err)
-			return err, http.StatusBadRequest
+			return err
 		}
 	}
 
-	insolvencyResource.Data.Practitioners = append(insolvencyResource.Data.Practitioners, *dao)
-
-	update := bson.M{
-		"$set": insolvencyResource,
-	}
-
-	_, err = collection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
+	// Check if there are already 5 practitioners in database
+	if len(insolvencyResource.Data.Practitioners) >= maxPractitioners {
+		err = apperrors.Validation("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID)
 		log.Error(err)
-		return fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID), http.StatusInternalServerError
+		return err
 	}
 
-	return nil, http.StatusCreated
+	// The case changed between the update and the read, so the request may be retried
+	err = apperrors.Conflict("there was a problem handling your request for transaction %s - the insolvency case was modified by another request", transactionID)
+	log.Error(err)
+	return err
 }
 
 // GetPractitionerResources gets a list of all practitioners for an insolvency case with the specified transactionID
-func (m *MongoService) GetPractitionerResources(transactionID string) ([]models.PractitionerResourceDao, error) {
+func (m *MongoService) GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
 
@@ -196,7 +270,7 @@ func (m *MongoService) GetPractitionerResources(transactionID string) ([]models.
 
 	// Retrieve insolvency case from Mongo
 	opts := options.FindOne().SetProjection(bson.M{"_id": 0, "data.practitioners": 1})
-	storedPractitioners := collection.FindOne(context.Background(), filter, opts)
+	storedPractitioners := collection.FindOne(ctx, filter, opts)
 	err := storedPractitioners.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -204,13 +278,13 @@ func (m *MongoService) GetPractitionerResources(transactionID string) ([]models.
 			return nil, nil
 		}
 		log.Error(err)
-		return nil, err
+		return nil, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedPractitioners.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return nil, err
+		return nil, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	// Return an empty array instead of nil so the handler can check
@@ -223,7 +297,9 @@ func (m *MongoService) GetPractitionerResources(transactionID string) ([]models.
 }
 
 // GetPractitionerResource gets a single practitioner for an insolvency case with the specified transactionID and practitionerID
-func (m *MongoService) GetPractitionerResource(practitionerID string, transactionID string) (models.PractitionerResourceDao, error) {
+func (m *MongoService) GetPractitionerResource(ctx context.Context, practitionerID string, transactionID string) (models.PractitionerResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
@@ -237,7 +313,7 @@ func (m *MongoService) GetPractitionerResource(practitionerID string, transactio
 
 	// Retrieve insolvency case from Mongo
 	opts := options.FindOne().SetProjection(projection)
-	practitioner := collection.FindOne(context.Background(), filter, opts)
+	practitioner := collection.FindOne(ctx, filter, opts)
 	err := practitioner.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -246,72 +322,117 @@ func (m *MongoService) GetPractitionerResource(practitionerID string, transactio
 		}
 
 		log.Error(err)
-		return models.PractitionerResourceDao{}, err
+		return models.PractitionerResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 	err = practitioner.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return models.PractitionerResourceDao{}, err
+		return models.PractitionerResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	return insolvencyResource.Data.Practitioners[0], nil
 }
 
 // DeletePractitioner deletes a practitioner for an insolvency case with the specified transactionID and practitionerID
-func (m *MongoService) DeletePractitioner(practitionerID string, transactionID string) (error, int) {
+func (m *MongoService) DeletePractitioner(ctx context.Context, practitionerID string, transactionID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
 	// Choose specific transaction for insolvency case with practitioner to be removed
 	filter := bson.M{"transaction_id": transactionID}
 
 	// Check if insolvency case exists for specified transactionID
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Error(err)
-			return fmt.Errorf("there was a problem handling your request for transaction id %s - insolvency case not found", transactionID), http.StatusNotFound
+			return apperrors.NotFound("there was a problem handling your request for transaction id %s - insolvency case not found", transactionID)
 		}
 		log.Error(err)
-		return fmt.Errorf("there was a problem handling your request for transaction id %s", transactionID), http.StatusInternalServerError
+		return newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction id %s", transactionID), err)
 	}
 
 	// Choose specific practitioner to delete
 	pullQuery := bson.M{"data.practitioners": bson.M{"id": practitionerID}}
 
-	update, err := collection.UpdateOne(context.Background(), filter, bson.M{"$pull": pullQuery})
+	update, err := collection.UpdateOne(ctx, filter, bson.M{"$pull": pullQuery})
 	if err != nil {
 		log.Error(err)
-		return fmt.Errorf("there was a problem handling your request for transaction id %s - could not delete practitioner with id %s", transactionID, practitionerID), http.StatusInternalServerError
+		return newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction id %s - could not delete practitioner with id %s", transactionID, practitionerID), err)
 	}
 
 	// Return error if Mongo could not update the document
 	if update.ModifiedCount == 0 {
-		err = fmt.Errorf("there was a problem handling your request for transaction id %s - practitioner with id %s not found", transactionID, practitionerID)
+		err = apperrors.NotFound("there was a problem handling your request for transaction id %s - practitioner with id %s not found", transactionID, practitionerID)
 		log.Error(err)
-		return err, http.StatusNotFound
+		return err
 	}
 
-	return nil, http.StatusNoContent
+	return nil
 }
 
 // AppointPractitioner adds appointment details insolvency case with the specified transactionID and practitionerID
-func (m *MongoService) AppointPractitioner(dao *models.AppointmentResourceDao, transactionID string, practitionerID string) (error, int) {
+func (m *MongoService) AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID string, practitionerID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	event, err := practitionerAppointedEvent(dao, transactionID, practitionerID)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
 
 	collection := m.db.Collection(m.CollectionName)
 
-	// Choose specific practitioner to update
-	filter := bson.M{"transaction_id": transactionID, "data.practitioners.id": practitionerID}
+	// Choose specific practitioner to update, unless it already has the appointment so that the event is only
+	// written for a new appointment
+	filter := bson.M{
+		"transaction_id": transactionID,
+		"data.practitioners": bson.M{
+			"$elemMatch": bson.M{
+				"id":          practitionerID,
+				"appointment": bson.M{"$ne": dao},
+			},
+		},
+	}
 
-	updateDocument := bson.M{"$set": bson.M{"data.practitioners.$.appointment": dao}}
+	updateDocument := bson.M{
+		"$set":  bson.M{"data.practitioners.$.appointment": dao},
+		"$push": bson.M{"outbox": event},
+		"$inc":  bson.M{"outbox_pending": 1},
+	}
+
+	err = updatePractitioner(ctx, transactionID, practitionerID, filter, updateDocument, collection)
 
-	err, status := updatePractitioner(transactionID, practitionerID, filter, updateDocument, collection)
+	var notFoundErr *apperrors.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return err
+	}
 
-	return err, status
+	// Nothing was matched so check whether the practitioner exists and already has the appointment
+	practitionerFilter := bson.M{"transaction_id": transactionID, "data.practitioners.id": practitionerID}
+	findErr := collection.FindOne(ctx, practitionerFilter, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if findErr == nil {
+		err = apperrors.NotFound("item with transaction id %s or practitioner id %s not updated", transactionID, practitionerID)
+		log.Error(err)
+		return err
+	}
+	if findErr != mongo.ErrNoDocuments {
+		log.Error(findErr)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), findErr)
+	}
+
+	return err
 }
 
 // DeletePractitionerAppointment deletes an appointment for the specified transactionID and practitionerID
-func (m *MongoService) DeletePractitionerAppointment(transactionID string, practitionerID string) (error, int) {
+func (m *MongoService) DeletePractitionerAppointment(ctx context.Context, transactionID string, practitionerID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
 	// Choose specific practitioner to update
@@ -319,35 +440,36 @@ func (m *MongoService) DeletePractitionerAppointment(transactionID string, pract
 
 	updateDocument := bson.M{"$unset": bson.M{"data.practitioners.$.appointment": ""}}
 
-	err, status := updatePractitioner(transactionID, practitionerID, filter, updateDocument, collection)
-
-	return err, status
+	return updatePractitioner(ctx, transactionID, practitionerID, filter, updateDocument, collection)
 }
 
-func updatePractitioner(transactionID string, practitionerID string, filter bson.M, updateDocument bson.M, collection *mongo.Collection) (error, int) {
-	update, err := collection.UpdateOne(context.Background(), filter, updateDocument)
+func updatePractitioner(ctx context.Context, transactionID string, practitionerID string, filter bson.M, updateDocument bson.M, collection *mongo.Collection) error {
+	update, err := collection.UpdateOne(ctx, filter, updateDocument)
 	if err != nil {
-		errMsg := fmt.Errorf("could not update practitioner appointment for practitionerID %s: %s", practitionerID, err)
+		errMsg := newDatabaseError(fmt.Sprintf("could not update practitioner appointment for practitionerID %s: %s", practitionerID, err), err)
 		log.Error(errMsg)
-		return errMsg, http.StatusInternalServerError
+		return errMsg
 	}
 	// Check if a match was found
 	if update.MatchedCount == 0 {
-		err = fmt.Errorf("item with transaction id %s or practitioner id %s does not exist", transactionID, practitionerID)
+		err = apperrors.NotFound("item with transaction id %s or practitioner id %s does not exist", transactionID, practitionerID)
 		log.Error(err)
-		return err, http.StatusNotFound
+		return err
 	}
 	// Check if Mongo updated the collection
 	if update.ModifiedCount == 0 {
-		err = fmt.Errorf("item with transaction id %s or practitioner id %s not updated", transactionID, practitionerID)
+		err = apperrors.NotFound("item with transaction id %s or practitioner id %s not updated", transactionID, practitionerID)
 		log.Error(err)
-		return err, http.StatusNotFound
+		return err
 	}
 
-	return nil, http.StatusNoContent
+	return nil
 }
 
-func (m *MongoService) AddAttachmentToInsolvencyResource(transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error) {
+func (m *MongoService) AddAttachmentToInsolvencyResource(ctx context.Context, transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
 	filter := bson.M{"transaction_id": transactionID}
@@ -368,20 +490,23 @@ func (m *MongoService) AddAttachmentToInsolvencyResource(transactionID string, f
 		},
 	}
 
-	result, err := collection.UpdateOne(context.Background(), filter, update)
+	result, err := collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return nil, fmt.Errorf("error updating mongo for transaction [%s]: [%s]", transactionID, err)
+		return nil, newDatabaseError(fmt.Sprintf("error updating mongo for transaction [%s]: [%s]", transactionID, err), err)
 	}
 
 	if result.MatchedCount != 1 || result.ModifiedCount != 1 {
-		return nil, fmt.Errorf("no documents updated")
+		return nil, apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
 	}
 
 	return &attachmentDao, nil
 }
 
 // GetAttachmentResources retrieves all attachments filed for an Insolvency Case
-func (m *MongoService) GetAttachmentResources(transactionID string) ([]models.AttachmentResourceDao, error) {
+func (m *MongoService) GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
 
@@ -389,7 +514,7 @@ func (m *MongoService) GetAttachmentResources(transactionID string) ([]models.At
 
 	// Retrieve attachments from Mongo
 	opts := options.FindOne().SetProjection(bson.M{"_id": 0, "data.attachments": 1})
-	storedAttachments := collection.FindOne(context.Background(), filter, opts)
+	storedAttachments := collection.FindOne(ctx, filter, opts)
 	err := storedAttachments.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -397,13 +522,13 @@ func (m *MongoService) GetAttachmentResources(transactionID string) ([]models.At
 			return nil, nil
 		}
 		log.Error(err)
-		return nil, err
+		return nil, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedAttachments.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return nil, err
+		return nil, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	// Return an empty array instead of nil to distinguish from insolvency case
@@ -416,7 +541,9 @@ func (m *MongoService) GetAttachmentResources(transactionID string) ([]models.At
 }
 
 // GetAttachmentFromInsolvencyResource retrieves an attachment filed for an Insolvency Case
-func (m *MongoService) GetAttachmentFromInsolvencyResource(transactionID string, fileID string) (models.AttachmentResourceDao, error) {
+func (m *MongoService) GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID string, fileID string) (models.AttachmentResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
@@ -428,7 +555,7 @@ func (m *MongoService) GetAttachmentFromInsolvencyResource(transactionID string,
 
 	// Retrieve attachment from Mongo
 	opts := options.FindOne().SetProjection(bson.M{"_id": 0, "data.attachments.$": 1})
-	storedAttachment := collection.FindOne(context.Background(), filter, opts)
+	storedAttachment := collection.FindOne(ctx, filter, opts)
 	err := storedAttachment.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -437,117 +564,123 @@ func (m *MongoService) GetAttachmentFromInsolvencyResource(transactionID string,
 		}
 
 		log.Error(err)
-		return models.AttachmentResourceDao{}, err
+		return models.AttachmentResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedAttachment.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return models.AttachmentResourceDao{}, err
+		return models.AttachmentResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	return insolvencyResource.Data.Attachments[0], nil
 }
 
 // DeleteAttachmentResource deletes an attachment filed for an Insolvency Case
-func (m *MongoService) DeleteAttachmentResource(transactionID, attachmentID string) (int, error) {
+func (m *MongoService) DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
 	// Choose specific transaction for insolvency case with attachment to be removed
 	filter := bson.M{"transaction_id": transactionID}
 
 	// Check if insolvency case exists for specified transactionID
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Error(err)
-			return http.StatusNotFound, fmt.Errorf(constants.MsgCaseForTransactionNotFound, transactionID)
+			return apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
 		}
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	// Choose specific attachment to delete
 	pullQuery := bson.M{"data.attachments": bson.M{"id": attachmentID}}
 
-	update, err := collection.UpdateOne(context.Background(), filter, bson.M{"$pull": pullQuery})
+	update, err := collection.UpdateOne(ctx, filter, bson.M{"$pull": pullQuery})
 	if err != nil {
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction id [%s] - could not delete attachment with id [%s]", transactionID, attachmentID)
+		return newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction id [%s] - could not delete attachment with id [%s]", transactionID, attachmentID), err)
 	}
 
 	// Return error if Mongo could not update the document
 	if update.ModifiedCount == 0 {
-		err = fmt.Errorf("there was a problem handling your request for transaction id [%s] - attachment with id [%s] not found", transactionID, attachmentID)
+		err = apperrors.NotFound("there was a problem handling your request for transaction id [%s] - attachment with id [%s] not found", transactionID, attachmentID)
 		log.Error(err)
-		return http.StatusNotFound, err
+		return err
 	}
 
-	return http.StatusNoContent, nil
+	return nil
 }
 
 // UpdateAttachmentStatus updates the status of an attachment filed for an Insolvency Case
-func (m *MongoService) UpdateAttachmentStatus(transactionID, attachmentID string, avStatus string) (int, error) {
-	var insolvencyResource models.InsolvencyResourceDao
+func (m *MongoService) UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID string, avStatus string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
-	// Choose specific transaction for insolvency case with attachment status to be updated
+	// Only update the status if the attachment has not been processed and does not already have the
+	// new status, so that a concurrent antivirus check cannot overwrite a processed attachment
 	filter := bson.M{
-		"transaction_id":      transactionID,
-		"data.attachments.id": attachmentID,
+		"transaction_id": transactionID,
+		"data.attachments": bson.M{
+			"$elemMatch": bson.M{
+				"id":     attachmentID,
+				"status": bson.M{"$nin": bson.A{"processed", avStatus}},
+			},
+		},
 	}
 
-	// Retrieve attachment from Mongo
-	opts := options.FindOne().SetProjection(bson.M{"_id": 0, "data.attachments.$": 1})
-	storedAttachment := collection.FindOne(context.Background(), filter, opts)
-	err := storedAttachment.Err()
+	event, err := attachmentScannedEvent(transactionID, attachmentID, avStatus)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Error(err)
-			return http.StatusNotFound, fmt.Errorf(constants.MsgCaseForTransactionNotFound, transactionID)
-		}
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
-	err = storedAttachment.Decode(&insolvencyResource)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, err
+	update := bson.M{
+		"$set":  bson.M{"data.attachments.$.status": avStatus},
+		"$push": bson.M{"outbox": event},
+		"$inc":  bson.M{"outbox_pending": 1},
 	}
 
-	if insolvencyResource.Data.Attachments[0].Status != "processed" && insolvencyResource.Data.Attachments[0].Status != avStatus {
-		update := bson.M{"$set": bson.M{
-			"data.attachments.$.status": avStatus,
-		},
-		}
-
-		// Choose specific attachment status to update
-		result, err := collection.UpdateOne(context.Background(), filter, update)
-		if err != nil {
-			log.Error(err)
-			return http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction id [%s] - could not update status of attachment with id [%s]", transactionID, attachmentID)
-		}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"_id": 1})
+	err = collection.FindOneAndUpdate(ctx, filter, update, opts).Err()
+	if err == nil {
+		return nil
+	}
+	if err != mongo.ErrNoDocuments {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction id [%s] - could not update status of attachment with id [%s]", transactionID, attachmentID), err)
+	}
 
-		// Return error if Mongo could not update the document
-		if result.ModifiedCount == 0 {
-			err = fmt.Errorf("there was a problem handling your request for transaction id [%s] - attachment with id [%s] not found", transactionID, attachmentID)
+	// Nothing was updated so check whether the attachment exists and already has its final status
+	attachmentFilter := bson.M{
+		"transaction_id":      transactionID,
+		"data.attachments.id": attachmentID,
+	}
+	err = collection.FindOne(ctx, attachmentFilter, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
 			log.Error(err)
-			return http.StatusNotFound, err
+			return apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
 		}
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
-	return http.StatusNoContent, nil
+	return nil
 }
 
 // CreateResolutionResource stores the resolution for the insolvency case
 // with the specified transactionID
-func (m *MongoService) CreateResolutionResource(dao *models.ResolutionResourceDao, transactionID string) (int, error) {
-	var insolvencyResource models.InsolvencyResourceDao
-	collection := m.db.Collection(m.CollectionName)
-
-	filter := bson.M{"transaction_id": transactionID}
+func (m *MongoService) CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	resolutionDao := models.ResolutionResourceDao{
 		DateOfResolution: dao.DateOfResolution,
@@ -557,46 +690,14 @@ func (m *MongoService) CreateResolutionResource(dao *models.ResolutionResourceDa
 		Links:            dao.Links,
 	}
 
-	// Retrieve insolvency case from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
-	err := storedInsolvency.Err()
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
-			return http.StatusNotFound, fmt.Errorf(constants.MsgReqTransactionNotFound, transactionID)
-		}
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	err = storedInsolvency.Decode(&insolvencyResource)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"data.resolution": resolutionDao,
-		},
-	}
-
-	_, err = collection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	return http.StatusCreated, nil
+	return m.createCaseResource(ctx, transactionID, resolutionType, resolutionDao, ifMatch)
 }
 
 // CreateStatementOfAffairsResource stores the statement of affairs resource for the insolvency case
 // with the specified transactionID
-func (m *MongoService) CreateStatementOfAffairsResource(dao *models.StatementOfAffairsResourceDao, transactionID string) (int, error) {
-	var insolvencyResource models.InsolvencyResourceDao
-	collection := m.db.Collection(m.CollectionName)
-
-	filter := bson.M{"transaction_id": transactionID}
+func (m *MongoService) CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	statementDao := models.StatementOfAffairsResourceDao{
 		StatementDate: dao.StatementDate,
@@ -606,41 +707,13 @@ func (m *MongoService) CreateStatementOfAffairsResource(dao *models.StatementOfA
 		Links:         dao.Links,
 	}
 
-	// Retrieve insolvency case from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
-	err := storedInsolvency.Err()
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
-			return http.StatusNotFound, fmt.Errorf(constants.MsgReqTransactionNotFound, transactionID)
-		}
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	err = storedInsolvency.Decode(&insolvencyResource)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"data.statement-of-affairs": statementDao,
-		},
-	}
-
-	_, err = collection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
-
-	return http.StatusCreated, nil
+	return m.createCaseResource(ctx, transactionID, statementOfAffairsType, statementDao, ifMatch)
 }
 
 // GetStatementOfAffairsResource retrieves the statement of affairs filed for an Insolvency Case
-func (m *MongoService) GetStatementOfAffairsResource(transactionID string) (models.StatementOfAffairsResourceDao, error) {
+func (m *MongoService) GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
@@ -650,7 +723,7 @@ func (m *MongoService) GetStatementOfAffairsResource(transactionID string) (mode
 	}
 
 	// Retrieve insolvency resource from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -659,13 +732,13 @@ func (m *MongoService) GetStatementOfAffairsResource(transactionID string) (mode
 		}
 
 		log.Error(err)
-		return models.StatementOfAffairsResourceDao{}, err
+		return models.StatementOfAffairsResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedInsolvency.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return models.StatementOfAffairsResourceDao{}, err
+		return models.StatementOfAffairsResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 	if insolvencyResource.Data.StatementOfAffairs == nil {
 		return models.StatementOfAffairsResourceDao{}, nil
@@ -675,20 +748,15 @@ func (m *MongoService) GetStatementOfAffairsResource(transactionID string) (mode
 }
 
 // DeleteStatementOfAffairsResource deletes the statement of affairs filed for an insolvency case
-func (m *MongoService) DeleteStatementOfAffairsResource(transactionID string) (int, error) {
-
-	httpStatus, err := m.DeleteResource(transactionID, "statement-of-affairs")
-	return httpStatus, err
-
+func (m *MongoService) DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error {
+	return m.DeleteResource(ctx, transactionID, "statement-of-affairs")
 }
 
 // CreateProgressReportResource stores the statement of affairs resource for the insolvency case
 // with the specified transactionID
-func (m *MongoService) CreateProgressReportResource(dao *models.ProgressReportResourceDao, transactionID string) (int, error) {
-	var insolvencyResource models.InsolvencyResourceDao
-	collection := m.db.Collection(m.CollectionName)
-
-	filter := bson.M{"transaction_id": transactionID}
+func (m *MongoService) CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	progessReportDao := models.ProgressReportResourceDao{
 		FromDate:    dao.FromDate,
@@ -699,41 +767,54 @@ func (m *MongoService) CreateProgressReportResource(dao *models.ProgressReportRe
 		Links:       dao.Links,
 	}
 
-	// Retrieve insolvency case from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
-	err := storedInsolvency.Err()
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
-			return http.StatusNotFound, fmt.Errorf(constants.MsgReqTransactionNotFound, transactionID)
-		}
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
-	}
+	return m.createCaseResource(ctx, transactionID, progressReportType, progessReportDao, ifMatch)
+}
 
-	err = storedInsolvency.Decode(&insolvencyResource)
-	if err != nil {
-		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
+// createCaseResource stores a resource under data.<resType> for the insolvency case with the specified
+// transactionID, replacing any already filed. When ifMatch is not empty the filter only matches a case whose filed
+// resource has that etag, so that a concurrent replacement cannot be overwritten
+func (m *MongoService) createCaseResource(ctx context.Context, transactionID string, resType string, resource interface{}, ifMatch string) error {
+	collection := m.db.Collection(m.CollectionName)
+
+	filter := bson.M{"transaction_id": transactionID}
+	if ifMatch != "" {
+		filter["data."+resType+".etag"] = ifMatch
 	}
 
 	update := bson.M{
 		"$set": bson.M{
-			"data.progress-report": progessReportDao,
+			"data." + resType: resource,
 		},
 	}
 
-	_, err = collection.UpdateOne(context.Background(), filter, update)
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	if result.MatchedCount == 1 {
+		return nil
+	}
+
+	// Nothing was updated so check whether the case is missing or its resource has a different etag
+	err = collection.FindOne(ctx, bson.M{"transaction_id": transactionID}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+			return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
+		}
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
-	return http.StatusCreated, nil
+	return etagMismatchError(transactionID, resType, ifMatch)
 }
 
 // GetProgressReportResource retrieves the progress report filed for an Insolvency Case
-func (m *MongoService) GetProgressReportResource(transactionID string) (*models.ProgressReportResourceDao, error) {
+func (m *MongoService) GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
@@ -743,7 +824,7 @@ func (m *MongoService) GetProgressReportResource(transactionID string) (*models.
 	}
 
 	// Retrieve insolvency resource from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -752,13 +833,13 @@ func (m *MongoService) GetProgressReportResource(transactionID string) (*models.
 		}
 
 		log.Error(err)
-		return &models.ProgressReportResourceDao{}, err
+		return &models.ProgressReportResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedInsolvency.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return &models.ProgressReportResourceDao{}, err
+		return &models.ProgressReportResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 	if insolvencyResource.Data.ProgressReport == nil {
 		return &models.ProgressReportResourceDao{}, nil
@@ -768,15 +849,14 @@ func (m *MongoService) GetProgressReportResource(transactionID string) (*models.
 }
 
 // DeleteProgressReportResource deletes the progress report filed for an insolvency case
-func (m *MongoService) DeleteProgressReportResource(transactionID string) (int, error) {
-
-	httpStatus, err := m.DeleteResource(transactionID, "progress-report")
-	return httpStatus, err
-
+func (m *MongoService) DeleteProgressReportResource(ctx context.Context, transactionID string) error {
+	return m.DeleteResource(ctx, transactionID, "progress-report")
 }
 
 // GetResolutionResource retrieves the resolution filed for an Insolvency Case
-func (m *MongoService) GetResolutionResource(transactionID string) (models.ResolutionResourceDao, error) {
+func (m *MongoService) GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
 
 	var insolvencyResource models.InsolvencyResourceDao
 	collection := m.db.Collection(m.CollectionName)
@@ -786,7 +866,7 @@ func (m *MongoService) GetResolutionResource(transactionID string) (models.Resol
 	}
 
 	// Retrieve insolvency resource from Mongo
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -795,13 +875,13 @@ func (m *MongoService) GetResolutionResource(transactionID string) (models.Resol
 		}
 
 		log.Error(err)
-		return models.ResolutionResourceDao{}, err
+		return models.ResolutionResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	err = storedInsolvency.Decode(&insolvencyResource)
 	if err != nil {
 		log.Error(err)
-		return models.ResolutionResourceDao{}, err
+		return models.ResolutionResourceDao{}, newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 	if insolvencyResource.Data.Resolution == nil {
 		return models.ResolutionResourceDao{}, nil
@@ -811,47 +891,458 @@ func (m *MongoService) GetResolutionResource(transactionID string) (models.Resol
 }
 
 // DeleteResolutionResource deletes a resolution resource filed for an Insolvency Case
-func (m *MongoService) DeleteResolutionResource(transactionID string) (int, error) {
-
-	httpStatus, err := m.DeleteResource(transactionID, "resolution")
-	return httpStatus, err
-
+func (m *MongoService) DeleteResolutionResource(ctx context.Context, transactionID string) error {
+	return m.DeleteResource(ctx, transactionID, "resolution")
 }
 
-func (m *MongoService) DeleteResource(transactionID string, resType string) (int, error) {
+func (m *MongoService) DeleteResource(ctx context.Context, transactionID string, resType string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
 	collection := m.db.Collection(m.CollectionName)
 
 	// Choose specific transaction for insolvency case with attachment to be removed
 	filter := bson.M{"transaction_id": transactionID}
 
 	// Check if insolvency case exists for specified transactionID
-	storedInsolvency := collection.FindOne(context.Background(), filter)
+	storedInsolvency := collection.FindOne(ctx, filter)
 	err := storedInsolvency.Err()
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			log.Error(err)
-			return http.StatusNotFound, fmt.Errorf(constants.MsgCaseForTransactionNotFound, transactionID)
+			return apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
 		}
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf(constants.MsgHandleReqTransactionId, transactionID)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
 	}
 
 	// Choose specific attachment to delete
 	query := bson.M{"data." + resType: ""}
 
-	update, err := collection.UpdateOne(context.Background(), filter, bson.M{"$unset": query})
+	update, err := collection.UpdateOne(ctx, filter, bson.M{"$unset": query})
 	if err != nil {
 		log.Error(err)
-		return http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction id [%s] - could not delete %v", transactionID, strings.ReplaceAll(resType, "-", " "))
+		return newDatabaseError(fmt.Sprintf("there was a problem handling your request for transaction id [%s] - could not delete %v", transactionID, strings.ReplaceAll(resType, "-", " ")), err)
 	}
 
 	// Return error if Mongo could not update the document
 	if update.ModifiedCount == 0 {
-		err = fmt.Errorf("there was a problem handling your request for transaction id [%s] - %v not found", transactionID, strings.ReplaceAll(resType, "-", " "))
+		err = apperrors.NotFound("there was a problem handling your request for transaction id [%s] - %v not found", transactionID, strings.ReplaceAll(resType, "-", " "))
+		log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// Ping checks that the mongodb deployment holding the insolvency collection can be reached
+func (m *MongoService) Ping(ctx context.Context) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	err := m.db.Collection(m.CollectionName).Database().RunCommand(ctx, bson.D{{Key: "ping", Value: 1}}).Err()
+	if err != nil {
+		return fmt.Errorf("ping to mongodb failed: [%w]", err)
+	}
+	return nil
+}
+
+// Close disconnects from mongodb. Every MongoService shares the one client, so none of them can be used afterwards
+func (m *MongoService) Close(ctx context.Context) error {
+	return Disconnect(ctx)
+}
+
+// SetValidationFailures stores the failed rules on the insolvency case with the specified transactionID, returning
+// those that were stored before in a single update
+func (m *MongoService) SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	if failedRules == nil {
+		failedRules = []string{}
+	}
+
+	var previous struct {
+		ValidationFailures []string `bson:"validation_failures"`
+	}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"validation_failures": 1}).SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, bson.M{"transaction_id": transactionID}, bson.M{"$set": bson.M{"validation_failures": failedRules}}, opts).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+			log.Error(err)
+			return nil, err
+		}
+		log.Error(err)
+		return nil, newDatabaseError(fmt.Sprintf("there was a problem storing the validation failures for transaction [%s]", transactionID), err)
+	}
+
+	return previous.ValidationFailures, nil
+}
+
+// ReserveIdempotencyKey stores the key in the idempotency collection, unless an unexpired key with the same value
+// is already stored, in which case the stored key is returned
+func (m *MongoService) ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.IdempotencyCollectionName)
+
+	// The TTL monitor only removes expired keys once a minute, so an expired key that is still stored is replaced.
+	// When an unexpired key is stored the filter does not match, and the upsert fails on the duplicate _id
+	filter := bson.M{"_id": dao.Key, "expires_at": bson.M{"$lte": dao.CreatedAt}}
+	_, err := collection.ReplaceOne(ctx, filter, dao, options.Replace().SetUpsert(true))
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem reserving the idempotency key", err)
+	}
+
+	var stored models.IdempotencyKeyDao
+	err = collection.FindOne(ctx, bson.M{"_id": dao.Key}).Decode(&stored)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, apperrors.Conflict("the idempotency key is being used by another request")
+		}
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem reserving the idempotency key", err)
+	}
+
+	return &stored, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the key, keeping it until expiresAt, if the
+// key is still reserved with token
+func (m *MongoService) CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.IdempotencyCollectionName)
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": key, "token": token}, bson.M{"$set": bson.M{"response": response, "expires_at": expiresAt}})
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem storing the response for the idempotency key", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the key from the idempotency collection, if it is still reserved with token
+func (m *MongoService) ReleaseIdempotencyKey(ctx context.Context, key, token string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.IdempotencyCollectionName)
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": key, "token": token})
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem releasing the idempotency key", err)
+	}
+
+	return nil
+}
+
+// CreateAuditEvent inserts the event into the audit collection
+func (m *MongoService) CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.AuditCollectionName)
+
+	if _, err := collection.InsertOne(ctx, dao); err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem recording the audit event for transaction [%s]", dao.TransactionID), err)
+	}
+
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter from the audit collection, oldest first
+func (m *MongoService) GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.AuditCollectionName)
+
+	query := bson.M{}
+	for field, value := range map[string]string{
+		"transaction_id": filter.TransactionID,
+		"company_number": filter.CompanyNumber,
+		"user_id":        filter.UserID,
+		"route":          filter.Route,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	if filter.Limit > 0 {
+		findOptions.SetLimit(int64(filter.Limit))
+	}
+
+	events := []models.AuditEventDao{}
+	cursor, err := collection.Find(ctx, query, findOptions)
+	if err == nil {
+		err = cursor.All(ctx, &events)
+	}
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem retrieving the audit events", err)
+	}
+
+	return events, nil
+}
+
+// CreateOutboxEvent pushes the event onto the outbox of the insolvency case it describes, unless its ID is in the
+// written_outbox_events of the case, which keeps the ID of every event written this way after it is delivered
+func (m *MongoService) CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	filter := bson.M{"transaction_id": dao.TransactionID, "written_outbox_events": bson.M{"$ne": dao.ID}}
+	update := bson.M{
+		"$push":     bson.M{"outbox": dao},
+		"$inc":      bson.M{"outbox_pending": 1},
+		"$addToSet": bson.M{"written_outbox_events": dao.ID},
+	}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem writing the outbox event for transaction [%s]", dao.TransactionID), err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// Nothing was updated so check whether the case is missing or the event has already been written
+	count, err := collection.CountDocuments(ctx, bson.M{"transaction_id": dao.TransactionID})
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem writing the outbox event for transaction [%s]", dao.TransactionID), err)
+	}
+	if count == 0 {
+		err = apperrors.NotFound(constants.MsgCaseForTransactionNotFound, dao.TransactionID)
+		log.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetOutboxEvents retrieves up to limit events from the outboxes of the insolvency cases, oldest first. Events on
+// the same case with the same time are returned in the order they were written
+func (m *MongoService) GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	// Only the cases with events waiting to be delivered are read, found with the outbox_pending index
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"outbox_pending": bson.M{"$gt": 0}}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "outbox": 1}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$outbox", "includeArrayIndex": "position"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "outbox.created_at", Value: 1}, {Key: "outbox.transaction_id", Value: 1}, {Key: "position", Value: 1}}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$outbox"}}})
+
+	events := []models.OutboxEventDao{}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &events)
+	}
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem retrieving the outbox events", err)
+	}
+
+	return events, nil
+}
+
+// ClaimOutboxEvents records the claimant on the insolvency cases with events waiting to be delivered, claiming each
+// case with a single document update that only matches if no other claimant holds an unexpired claim, and returns
+// up to limit of their events that have not been parked
+func (m *MongoService) ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	claimable := bson.M{
+		"outbox_pending": bson.M{"$gt": 0},
+		"$or": bson.A{
+			bson.M{"outbox_claim_expires_at": bson.M{"$exists": false}},
+			bson.M{"outbox_claim_expires_at": bson.M{"$lte": now}},
+		},
+	}
+
+	// Cases are claimed in the order of their oldest event that has not been parked
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: claimable}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "transaction_id": 1, "oldest": bson.M{"$min": bson.M{"$map": bson.M{
+			"input": bson.M{"$filter": bson.M{"input": "$outbox", "cond": bson.M{"$ne": bson.A{"$$this.parked", true}}}},
+			"in":    "$$this.created_at",
+		}}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "oldest", Value: 1}, {Key: "transaction_id", Value: 1}}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	var candidates []struct {
+		TransactionID string `bson:"transaction_id"`
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, &candidates)
+	}
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem claiming the outbox events", err)
+	}
+
+	claimed := []models.OutboxEventDao{}
+	for _, candidate := range candidates {
+		if limit > 0 && len(claimed) >= limit {
+			break
+		}
+
+		filter := bson.M{"transaction_id": candidate.TransactionID}
+		for key, value := range claimable {
+			filter[key] = value
+		}
+		update := bson.M{"$set": bson.M{"outbox_claimed_by": claimant, "outbox_claim_expires_at": now.Add(lease)}}
+		opts := options.FindOneAndUpdate().SetProjection(bson.M{"outbox": 1}).SetReturnDocument(options.After)
+
+		var stored storedOutbox
+		err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stored)
+		if err == mongo.ErrNoDocuments {
+			// Another claimant claimed the case first
+			continue
+		}
+		if err != nil {
+			log.Error(err)
+			return nil, newDatabaseError("there was a problem claiming the outbox events", err)
+		}
+
+		for _, event := range stored.Outbox {
+			if !event.Parked {
+				claimed = append(claimed, event)
+			}
+		}
+	}
+	if limit > 0 && len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+
+	return claimed, nil
+}
+
+// ReleaseOutboxClaim removes the claim on the outbox of the insolvency case, if it is held by the claimant
+func (m *MongoService) ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	filter := bson.M{"transaction_id": transactionID, "outbox_claimed_by": claimant}
+	update := bson.M{"$unset": bson.M{"outbox_claimed_by": "", "outbox_claim_expires_at": ""}}
+
+	if _, err := collection.UpdateOne(ctx, filter, update); err != nil {
 		log.Error(err)
-		return http.StatusNotFound, err
+		return newDatabaseError(fmt.Sprintf("there was a problem releasing the outbox claim for transaction [%s]", transactionID), err)
 	}
 
-	return http.StatusNoContent, nil
+	return nil
+}
+
+// FailOutboxEvent counts a failed delivery of the event in the outbox of the insolvency case, parking it once its
+// deliveries have failed maxAttempts times. A parked event is no longer counted in outbox_pending
+func (m *MongoService) FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error) {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	filter := bson.M{"transaction_id": transactionID, "outbox": bson.M{"$elemMatch": bson.M{"id": eventID, "parked": bson.M{"$ne": true}}}}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"outbox": 1}).SetReturnDocument(options.After)
+
+	var stored storedOutbox
+	err := collection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"outbox.$.attempts": 1}}, opts).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		log.Error(err)
+		return false, newDatabaseError(fmt.Sprintf("there was a problem recording the failed delivery of the outbox event for transaction [%s]", transactionID), err)
+	}
+
+	attempts := 0
+	for _, event := range stored.Outbox {
+		if event.ID == eventID {
+			attempts = event.Attempts
+		}
+	}
+	if attempts < maxAttempts {
+		return false, nil
+	}
+
+	update := bson.M{
+		"$set": bson.M{"outbox.$.parked": true},
+		"$inc": bson.M{"outbox_pending": -1},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return false, newDatabaseError(fmt.Sprintf("there was a problem parking the outbox event for transaction [%s]", transactionID), err)
+	}
+
+	// The event is only parked by the update that finds it not yet parked
+	return result.ModifiedCount > 0, nil
+}
+
+// DeleteOutboxEvent pulls the event from the outbox of the insolvency case with the specified transactionID
+func (m *MongoService) DeleteOutboxEvent(ctx context.Context, transactionID string, eventID string) error {
+	ctx, cancel := m.operationContext(ctx)
+	defer cancel()
+
+	collection := m.db.Collection(m.CollectionName)
+
+	// The event is matched so that the count of pending events is only reduced when the event is removed
+	filter := bson.M{"transaction_id": transactionID, "outbox.id": eventID}
+	update := bson.M{
+		"$pull": bson.M{"outbox": bson.M{"id": eventID}},
+		"$inc":  bson.M{"outbox_pending": -1},
+	}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem deleting the outbox event for transaction [%s]", transactionID), err)
+	}
 
+	return nil
 }
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/config"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+const (
+	// defaultConnectAttempts is the number of times to try connecting to mongodb at startup when none is configured
+	defaultConnectAttempts = 5
+
+	// initialConnectBackoff is the wait after the first failed attempt to connect, doubled after each failure up
+	// to maxConnectBackoff
+	initialConnectBackoff = time.Second
+	maxConnectBackoff     = 30 * time.Second
+
+	// pingTimeout is the time allowed for each ping when checking the connection to mongodb
+	pingTimeout = 5 * time.Second
+)
+
+// sleep waits between connection attempts and is replaced in tests
+var sleep = time.Sleep
+
+// mongoClientOptions builds the mongodb client options from the config. Options that are not configured are left
+// as the driver defaults
+func mongoClientOptions(cfg *config.Config) (*options.ClientOptions, error) {
+	clientOptions := options.Client().ApplyURI(cfg.MongoDBURL)
+
+	if cfg.MongoMaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(uint64(cfg.MongoMaxPoolSize))
+	}
+	if cfg.MongoMinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(uint64(cfg.MongoMinPoolSize))
+	}
+	if cfg.MongoServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(time.Duration(cfg.MongoServerSelectionTimeout) * time.Second)
+	}
+	if cfg.MongoSocketTimeout > 0 {
+		clientOptions.SetSocketTimeout(time.Duration(cfg.MongoSocketTimeout) * time.Second)
+	}
+
+	if cfg.MongoReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.MongoReadPreference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mongodb read preference [%s]: [%v]", cfg.MongoReadPreference, err)
+		}
+		readPreference, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mongodb read preference [%s]: [%v]", cfg.MongoReadPreference, err)
+		}
+		clientOptions.SetReadPreference(readPreference)
+	}
+
+	if cfg.MongoWriteConcern != "" {
+		writeConcern, err := parseWriteConcern(cfg.MongoWriteConcern)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetWriteConcern(writeConcern)
+	}
+
+	return clientOptions, nil
+}
+
+// parseWriteConcern parses a write concern of "majority" or the number of members that must acknowledge a write
+func parseWriteConcern(value string) (*writeconcern.WriteConcern, error) {
+	if value == "majority" {
+		return writeconcern.Majority(), nil
+	}
+
+	w, err := strconv.Atoi(value)
+	if err != nil || w < 0 {
+		return nil, fmt.Errorf("invalid mongodb write concern [%s]: must be \"majority\" or a number of members", value)
+	}
+
+	return &writeconcern.WriteConcern{W: w}, nil
+}
+
+// retryWithBackoff calls fn until it succeeds or has been called attempts times, doubling the wait between calls
+// up to maxConnectBackoff. The last error is returned if every attempt fails
+func retryWithBackoff(attempts int, fn func() error) error {
+	if attempts <= 0 {
+		attempts = defaultConnectAttempts
+	}
+
+	backoff := initialConnectBackoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		log.Info("retrying connection to mongodb", log.Data{"attempt": attempt, "attempts": attempts, "backoff": backoff.String(), "error": err.Error()})
+		sleep(backoff)
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+
+	return err
+}
+
+// Disconnect closes the connection to mongodb, waiting for in-use connections to be returned to the pool until the
+// context is done
+func Disconnect(ctx context.Context) error {
+	if client == nil {
+		return nil
+	}
+
+	if err := client.Disconnect(ctx); err != nil {
+		return fmt.Errorf("error disconnecting from mongodb: [%v]", err)
+	}
+	client = nil
+
+	log.Info("disconnected from mongodb")
+	return nil
+}
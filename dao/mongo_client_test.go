@@ -0,0 +1,109 @@
+package dao
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitMongoClientOptions(t *testing.T) {
+	Convey("Driver defaults are used when nothing is configured", t, func() {
+		clientOptions, err := mongoClientOptions(&config.Config{MongoDBURL: "mongodb://localhost:27017"})
+		So(err, ShouldBeNil)
+		So(clientOptions.MaxPoolSize, ShouldBeNil)
+		So(clientOptions.ServerSelectionTimeout, ShouldBeNil)
+		So(clientOptions.ReadPreference, ShouldBeNil)
+		So(clientOptions.WriteConcern, ShouldBeNil)
+	})
+
+	Convey("Configured options are applied", t, func() {
+		clientOptions, err := mongoClientOptions(&config.Config{
+			MongoDBURL:                  "mongodb://localhost:27017",
+			MongoMaxPoolSize:            50,
+			MongoMinPoolSize:            5,
+			MongoServerSelectionTimeout: 3,
+			MongoSocketTimeout:          20,
+			MongoReadPreference:         "secondaryPreferred",
+			MongoWriteConcern:           "majority",
+		})
+		So(err, ShouldBeNil)
+		So(*clientOptions.MaxPoolSize, ShouldEqual, 50)
+		So(*clientOptions.MinPoolSize, ShouldEqual, 5)
+		So(*clientOptions.ServerSelectionTimeout, ShouldEqual, 3*time.Second)
+		So(*clientOptions.SocketTimeout, ShouldEqual, 20*time.Second)
+		So(clientOptions.ReadPreference.Mode(), ShouldEqual, readpref.SecondaryPreferredMode)
+		So(clientOptions.WriteConcern.W, ShouldEqual, "majority")
+	})
+
+	Convey("Write concern can be a number of members", t, func() {
+		clientOptions, err := mongoClientOptions(&config.Config{MongoWriteConcern: "2"})
+		So(err, ShouldBeNil)
+		So(clientOptions.WriteConcern.W, ShouldEqual, 2)
+	})
+
+	Convey("Invalid read preference", t, func() {
+		clientOptions, err := mongoClientOptions(&config.Config{MongoReadPreference: "fastest"})
+		So(clientOptions, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "invalid mongodb read preference [fastest]")
+	})
+
+	Convey("Invalid write concern", t, func() {
+		clientOptions, err := mongoClientOptions(&config.Config{MongoWriteConcern: "all"})
+		So(clientOptions, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "invalid mongodb write concern [all]")
+	})
+}
+
+func TestUnitRetryWithBackoff(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	Convey("Retries until the call succeeds", t, func() {
+		waits = nil
+		calls := 0
+
+		err := retryWithBackoff(5, func() error {
+			calls++
+			if calls < 3 {
+				return fmt.Errorf("connection refused")
+			}
+			return nil
+		})
+
+		So(err, ShouldBeNil)
+		So(calls, ShouldEqual, 3)
+		So(waits, ShouldResemble, []time.Duration{time.Second, 2 * time.Second})
+	})
+
+	Convey("Returns the last error once every attempt has failed", t, func() {
+		waits = nil
+		calls := 0
+
+		err := retryWithBackoff(8, func() error {
+			calls++
+			return fmt.Errorf("connection refused %d", calls)
+		})
+
+		So(err.Error(), ShouldEqual, "connection refused 8")
+		So(calls, ShouldEqual, 8)
+		So(waits, ShouldResemble, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second})
+	})
+
+	Convey("Default number of attempts is used when none is configured", t, func() {
+		waits = nil
+		calls := 0
+
+		_ = retryWithBackoff(0, func() error {
+			calls++
+			return fmt.Errorf("connection refused")
+		})
+
+		So(calls, ShouldEqual, defaultConnectAttempts)
+	})
+}
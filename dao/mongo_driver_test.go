@@ -1,8 +1,12 @@
 package dao
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/models"
 
@@ -80,167 +84,216 @@ func TestUnitUpdateAttachmentStatusDriver(t *testing.T) {
 
 	mt := mtest.New(t, opts)
 
-	bsonData := bson.M{
-		"id":               "ID",
-		"ip_code":          "IPCode",
-		"first_name":       "FirstName",
-		"last_name":        "LastName",
-		"telephone_number": "TelephoneNumber",
-		"email":            "Email",
-	}
+	mt.Run("UpdateAttachmentStatus runs successfully", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", bson.D{{"_id", expectedInsolvency.ID}}},
+		})
 
-	bsonDataAttachment := bson.M{
-		"id":     "ID",
-		"type":   "type",
-		"status": "status",
-	}
+		mongoService.db = mt.DB
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
 
-	bsonArrays := bson.A{}
-	bsonArrays = append(bsonArrays, bsonData)
+		assert.Nil(t, err)
 
-	bsonAttachmentArrays := bson.A{}
-	bsonAttachmentArrays = append(bsonAttachmentArrays, bsonDataAttachment)
+		// The status is only updated if the attachment has not been processed
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("query").Document()
+		statuses, _ := filter.Lookup("data.attachments", "$elemMatch", "status", "$nin").Array().Values()
+		assert.Equal(t, len(statuses), 2)
+		assert.Equal(t, statuses[0].StringValue(), "processed")
+		assert.Equal(t, statuses[1].StringValue(), "avStatus")
 
-	bsonInsolvency := bson.D{
-		{"company_number", "CompanyNumber"},
-		{"case_type", "CaseType"},
-		{"company_name", "CompanyName"},
-		{"practitioners", bsonArrays},
-		{"attachments", bsonAttachmentArrays},
-	}
+		// The attachment scanned event is pushed onto the outbox in the same update as the status
+		update := mt.GetAllStartedEvents()[0].Command.Lookup("update").Document()
+		assert.Equal(t, update.Lookup("$push", "outbox", "type").StringValue(), EventAttachmentScanned)
+	})
+
+	mt.Run("UpdateAttachmentStatus runs with error on FindOneAndUpdate", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+		mongoService.db = mt.DB
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
+
+		assert.NotNil(t, err)
+		assert.IsType(t, &databaseError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not update status of attachment with id [attachmentID]")
+	})
+
+	mt.Run("UpdateAttachmentStatus runs successfully with attachment already processed", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", nil},
+		})
 
-	mt.Run("UpdateAttachmentStatus runs successfully", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", bsonInsolvency},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		mongoService.db = mt.DB
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(
-			bson.E{Key: "n", Value: 1},
-			bson.E{Key: "nModified", Value: 1},
-			bson.E{Key: "upserted", Value: rsSlice},
-		))
+		assert.Nil(t, err)
+	})
+
+	mt.Run("UpdateAttachmentStatus runs with attachment not found", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", nil},
+		})
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
 
-		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
+		assert.NotNil(t, err)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - insolvency case not found")
 	})
 
 	mt.Run("UpdateAttachmentStatus runs with error on FindOne", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", nil},
+		})
 
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
 
-		_, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
-
+		assert.NotNil(t, err)
+		assert.IsType(t, &databaseError{}, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
+}
 
-	mt.Run("UpdateAttachmentStatus runs successfully with status not processed", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", bsonInsolvency},
-		}))
+func TestUnitCreateInsolvencyResourceDriver(t *testing.T) {
+	t.Parallel()
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(
-			bson.E{Key: "n", Value: 1},
-			bson.E{Key: "nModified", Value: 1},
-			bson.E{Key: "upserted", Value: rsSlice},
-		))
+	mongoService, commandError, expectedInsolvency, opts, _ := setDriverUp()
+
+	mt := mtest.New(t, opts)
 
+	mt.Run("CreateInsolvencyResource with error on InsertOne", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
+		err := mongoService.CreateInsolvencyResource(context.Background(), &expectedInsolvency)
 
-		assert.NotNil(t, err)
-		assert.Equal(t, code, 500)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not update status of attachment with id [attachmentID]")
+		assert.Equal(t, err.Error(), "there was a problem creating an insolvency case for this transaction id: (Name) Message")
+		assert.IsType(t, &databaseError{}, err)
 	})
 
-	mt.Run("UpdateAttachmentStatus runs with error on UpdateOne", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", bsonInsolvency},
+	mt.Run("CreateInsolvencyResource with insolvency case already existing", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: insolvency index: transaction_id_unique",
 		}))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
+		err := mongoService.CreateInsolvencyResource(context.Background(), &expectedInsolvency)
 
-		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not update status of attachment with id [attachmentID]")
-		assert.Equal(t, code, 500)
+		assert.Equal(t, err.Error(), "an insolvency case already exists for this transaction id")
+		assert.IsType(t, &apperrors.ConflictError{}, err)
+	})
+
+	mt.Run("CreateInsolvencyResource with successful created one", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateInsolvencyResource(context.Background(), &expectedInsolvency)
+
+		assert.Nil(t, err)
 
+		// The case is inserted with the case created event, and an event for its appointed practitioner, in its outbox
+		document := mt.GetAllStartedEvents()[0].Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, document.Lookup("transaction_id").StringValue(), expectedInsolvency.TransactionID)
+		assert.Equal(t, document.Lookup("outbox").Array().Index(0).Value().Document().Lookup("type").StringValue(), EventCaseCreated)
+		assert.Equal(t, document.Lookup("outbox").Array().Index(1).Value().Document().Lookup("type").StringValue(), EventPractitionerAppointed)
+		assert.Equal(t, document.Lookup("outbox_pending").Int32(), int32(2))
 	})
+}
 
-	mt.Run("UpdateAttachmentStatus runs successfully with ModifiedCount zero", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", bsonInsolvency},
-		}))
+func TestUnitEnsureIndexesDriver(t *testing.T) {
+	t.Parallel()
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+	mongoService, commandError, _, opts, _ := setDriverUp()
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(
-			bson.E{Key: "n", Value: 1},
-			bson.E{Key: "nModified", Value: 0},
-			bson.E{Key: "upserted", Value: rsSlice},
-		))
+	mt := mtest.New(t, opts)
+
+	mt.Run("EnsureIndexes creates the indexes", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		mongoService.db = mt.DB
+		err := mongoService.EnsureIndexes(context.Background())
+
+		assert.Nil(t, err)
+
+		indexes, _ := mt.GetAllStartedEvents()[0].Command.Lookup("indexes").Array().Values()
+		assert.Equal(t, len(indexes), 4)
+		assert.Equal(t, indexes[0].Document().Lookup("name").StringValue(), "transaction_id_unique")
+		assert.Equal(t, indexes[0].Document().Lookup("unique").Boolean(), true)
+		assert.Equal(t, indexes[1].Document().Lookup("name").StringValue(), "company_number")
+		assert.Equal(t, indexes[2].Document().Lookup("name").StringValue(), "attachments_id")
+		assert.Equal(t, indexes[3].Document().Lookup("name").StringValue(), "outbox_pending")
+		assert.Equal(t, indexes[3].Document().Lookup("partialFilterExpression", "outbox_pending", "$gt").Int32(), int32(0))
+	})
+
+	mt.Run("EnsureIndexes creates the TTL index on the idempotency collection", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+
+		mongoService.db = mt.DB
+		mongoService.IdempotencyCollectionName = "idempotency_keys"
+		defer func() { mongoService.IdempotencyCollectionName = "" }()
+		err := mongoService.EnsureIndexes(context.Background())
+
+		assert.Nil(t, err)
+
+		events := mt.GetAllStartedEvents()
+		assert.Equal(t, len(events), 2)
+		assert.Equal(t, events[1].Command.Lookup("createIndexes").StringValue(), "idempotency_keys")
+		indexes, _ := events[1].Command.Lookup("indexes").Array().Values()
+		assert.Equal(t, indexes[0].Document().Lookup("name").StringValue(), "expires_at_ttl")
+		assert.Equal(t, indexes[0].Document().Lookup("expireAfterSeconds").Int32(), int32(0))
+	})
+
+	mt.Run("EnsureIndexes runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
+		err := mongoService.EnsureIndexes(context.Background())
 
 		assert.NotNil(t, err)
-		assert.Equal(t, code, 404)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - attachment with id [attachmentID] not found")
+		assert.Contains(t, err.Error(), "error creating indexes on collection")
 	})
 }
 
-func TestUnitCreateInsolvencyResourceDriver(t *testing.T) {
+func TestUnitPingDriver(t *testing.T) {
 	t.Parallel()
 
-	mongoService, commandError, expectedInsolvency, opts, _ := setDriverUp()
+	mongoService, commandError, _, opts, _ := setDriverUp()
 
 	mt := mtest.New(t, opts)
 
-	mt.Run("CreateInsolvencyResource with error findone", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+	mt.Run("Ping runs successfully", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
 
 		mongoService.db = mt.DB
-		err, _ := mongoService.CreateInsolvencyResource(&expectedInsolvency)
+		err := mongoService.Ping(context.Background())
 
-		assert.NotNil(t, err.Error())
-		assert.Equal(t, err.Error(), "there was a problem creating an insolvency case for this transaction id: (Name) Message")
+		assert.Nil(t, err)
+		assert.Equal(t, mt.GetAllStartedEvents()[0].CommandName, "ping")
 	})
 
-	mt.Run("CreateInsolvencyResource with successful created one", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-		}))
+	mt.Run("Ping runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, _ := mongoService.CreateInsolvencyResource(&expectedInsolvency)
+		err := mongoService.Ping(context.Background())
 
-		assert.Equal(t, err.Error(), "an insolvency case already exists for this transaction id")
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "ping to mongodb failed")
 	})
 }
 
@@ -255,7 +308,7 @@ func TestUnitGetInsolvencyResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.GetInsolvencyResource("transactionID")
+		_, err := mongoService.GetInsolvencyResource(context.Background(), "transactionID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction [transactionID]")
 	})
@@ -269,7 +322,7 @@ func TestUnitGetInsolvencyResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		insolvencyResource, err := mongoService.GetInsolvencyResource("transactionID")
+		insolvencyResource, err := mongoService.GetInsolvencyResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, insolvencyResource)
@@ -309,13 +362,18 @@ func TestUnitCreatePractitionersResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.CreatePractitionersResource(&practitionerResourceDao, "transactionID")
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
 
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
 
 	mt.Run("CreatePractitionersResource runs with error decode", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
 			{"transaction_id", expectedInsolvency.TransactionID},
@@ -325,16 +383,37 @@ func TestUnitCreatePractitionersResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.CreatePractitionersResource(&practitionerResourceDao, "transactionID")
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
+	})
+
+	mt.Run("CreatePractitionersResource runs with insolvency case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
+
+		mongoService.db = mt.DB
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID not found")
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 	})
 
 	mt.Run("CreatePractitionersResource runs successfully with Practitioners equals 5", func(mt *mtest.T) {
 		bsonArraysPratitioners := bson.A{}
-		bsonArraysPratitioners = append(bsonArraysPratitioners, bsonData, bsonData, bsonData, bsonData, bsonData)
+		for i := 0; i < 5; i++ {
+			bsonArraysPratitioners = append(bsonArraysPratitioners, bson.M{
+				"id":      fmt.Sprintf("ID%d", i),
+				"ip_code": fmt.Sprintf("IPCode%d", i),
+			})
+		}
 		bsonInsolvencyPratitioners := bson.D{
 			{"company_number", "CompanyNumber"},
 			{"case_type", "CaseType"},
@@ -342,6 +421,11 @@ func TestUnitCreatePractitionersResourceDriver(t *testing.T) {
 			{"practitioners", bsonArraysPratitioners},
 		}
 
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
 			{"transaction_id", expectedInsolvency.TransactionID},
@@ -353,14 +437,19 @@ func TestUnitCreatePractitionersResourceDriver(t *testing.T) {
 		practitionerResourceDao = models.PractitionerResourceDao{IPCode: "IPCode"}
 
 		mongoService.db = mt.DB
-		err, code := mongoService.CreatePractitionersResource(&practitionerResourceDao, "transactionID")
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID already has 5 practitioners")
-		assert.Equal(t, code, 400)
+		assert.IsType(t, &apperrors.ValidationError{}, err)
 	})
 
-	mt.Run("CreatePractitionersResource runs successfully with Update One", func(mt *mtest.T) {
+	mt.Run("CreatePractitionersResource runs with practitioner already assigned to the case", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
 			{"transaction_id", expectedInsolvency.TransactionID},
@@ -369,21 +458,29 @@ func TestUnitCreatePractitionersResourceDriver(t *testing.T) {
 			{"data", bsonInsolvency},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		practitionerResourceDao = models.PractitionerResourceDao{IPCode: "IPCode"}
 
-		mt.AddMockResponses(bson.D{
-			{"ok", 1},
-			{"nModified", 1},
-		})
+		mongoService.db = mt.DB
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID - practitioner with IP Code IPCode is already assigned to this case with practitioner ID [ID]")
+		assert.IsType(t, &apperrors.ConflictError{}, err)
+	})
+
+	mt.Run("CreatePractitionersResource runs successfully with Update One", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 1},
+		))
 
 		mongoService.db = mt.DB
 
 		practitionerResourceDao := models.PractitionerResourceDao{}
 
-		err, code := mongoService.CreatePractitionersResource(&practitionerResourceDao, "transactionID")
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResourceDao, "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 201)
 	})
 }
 
@@ -398,10 +495,10 @@ func TestUnitGetPractitionerResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		practitioner, err := mongoService.GetPractitionerResource("practitionerID", "transactionID")
+		practitioner, err := mongoService.GetPractitionerResource(context.Background(), "practitionerID", "transactionID")
 
 		assert.NotNil(t, practitioner)
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 
 	mt.Run("GetPractitionerResource runs successfully", func(mt *mtest.T) {
@@ -432,7 +529,7 @@ func TestUnitGetPractitionerResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		insolvencyResource, err := mongoService.GetPractitionerResource("practitionerID", "transactionID")
+		insolvencyResource, err := mongoService.GetPractitionerResource(context.Background(), "practitionerID", "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, insolvencyResource)
@@ -468,9 +565,9 @@ func TestUnitGetPractitionerResourcesDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.GetPractitionerResources("transactionID")
+		_, err := mongoService.GetPractitionerResources(context.Background(), "transactionID")
 
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 
 	mt.Run("GetPractitionerResource runs Practitioners Nil", func(mt *mtest.T) {
@@ -490,7 +587,7 @@ func TestUnitGetPractitionerResourcesDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		insolvencyResource, err := mongoService.GetPractitionerResources("transactionID")
+		insolvencyResource, err := mongoService.GetPractitionerResources(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, insolvencyResource)
@@ -513,7 +610,7 @@ func TestUnitGetPractitionerResourcesDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		insolvencyResource, err := mongoService.GetPractitionerResources("transactionID")
+		insolvencyResource, err := mongoService.GetPractitionerResources(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, insolvencyResource)
@@ -556,7 +653,7 @@ func TestUnitDeletePractitionerDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, _ := mongoService.DeletePractitioner("practitionerID", "transactionID")
+		err := mongoService.DeletePractitioner(context.Background(), "practitionerID", "transactionID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
@@ -578,11 +675,11 @@ func TestUnitDeletePractitionerDriver(t *testing.T) {
 		})
 
 		mongoService.db = mt.DB
-		err, code := mongoService.DeletePractitioner("practitionerID", "transactionID")
+		err := mongoService.DeletePractitioner(context.Background(), "practitionerID", "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID - practitioner with id practitionerID not found")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -603,10 +700,9 @@ func TestUnitDeletePractitionerDriver(t *testing.T) {
 		})
 
 		mongoService.db = mt.DB
-		err, code := mongoService.DeletePractitioner("practitionerID", "transactionID")
+		err := mongoService.DeletePractitioner(context.Background(), "practitionerID", "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
 	})
 }
@@ -647,34 +743,59 @@ func TestUnitAppointPractitionerDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		err, _ := mongoService.AppointPractitioner(&appointmentResource, "transactionID", "practitionerID")
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "transactionID", "practitionerID")
 
 		assert.Equal(t, err.Error(), "could not update practitioner appointment for practitionerID practitionerID: (Name) Message")
 	})
 
 	mt.Run("AppointPractitioner runs with zero MatchedCount", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
+
+		mongoService.db = mt.DB
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "practitionerID", "transactionID")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID does not exist")
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+
+	})
+
+	mt.Run("AppointPractitioner runs with the appointment already stored", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", bsonInsolvency},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		mongoService.db = mt.DB
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "practitionerID", "transactionID")
 
-		mt.AddMockResponses(bson.D{
-			{"ok", 1},
-			{"nModified", 1},
-		})
+		assert.NotNil(t, err)
+		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID not updated")
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+	})
+
+	mt.Run("AppointPractitioner runs with error checking the practitioner", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.AppointPractitioner(&appointmentResource, "practitionerID", "transactionID")
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "practitionerID", "transactionID")
 
 		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID does not exist")
-		assert.Equal(t, code, 404)
-
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id practitionerID")
 	})
 
 	mt.Run("AppointPractitioner runs with zero ModifiedCount", func(mt *mtest.T) {
@@ -695,11 +816,11 @@ func TestUnitAppointPractitionerDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.AppointPractitioner(&appointmentResource, "practitionerID", "transactionID")
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "practitionerID", "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID not updated")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -721,11 +842,14 @@ func TestUnitAppointPractitionerDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.AppointPractitioner(&appointmentResource, "practitionerID", "transactionID")
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "practitionerID", "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
+		// The practitioner appointed event is pushed onto the outbox in the same update as the appointment
+		update := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		assert.Equal(t, update.Lookup("$push", "outbox", "type").StringValue(), EventPractitionerAppointed)
+		assert.Equal(t, update.Lookup("$push", "outbox", "transaction_id").StringValue(), "practitionerID")
 	})
 }
 
@@ -759,7 +883,7 @@ func TestUnitDeletePractitionerAppointmentDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		err, _ := mongoService.DeletePractitionerAppointment("transactionID", "practitionerID")
+		err := mongoService.DeletePractitionerAppointment(context.Background(), "transactionID", "practitionerID")
 
 		assert.Equal(t, err.Error(), "could not update practitioner appointment for practitionerID practitionerID: (Name) Message")
 	})
@@ -781,11 +905,11 @@ func TestUnitDeletePractitionerAppointmentDriver(t *testing.T) {
 		})
 
 		mongoService.db = mt.DB
-		err, code := mongoService.DeletePractitionerAppointment("practitionerID", "transactionID")
+		err := mongoService.DeletePractitionerAppointment(context.Background(), "practitionerID", "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID does not exist")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -807,11 +931,11 @@ func TestUnitDeletePractitionerAppointmentDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.DeletePractitionerAppointment("practitionerID", "transactionID")
+		err := mongoService.DeletePractitionerAppointment(context.Background(), "practitionerID", "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "item with transaction id practitionerID or practitioner id transactionID not updated")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -833,10 +957,9 @@ func TestUnitDeletePractitionerAppointmentDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		err, code := mongoService.DeletePractitionerAppointment("practitionerID", "transactionID")
+		err := mongoService.DeletePractitionerAppointment(context.Background(), "practitionerID", "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
 	})
 }
@@ -866,7 +989,7 @@ func TestUnitAddAttachmentToInsolvencyResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		attachmentDao, err := mongoService.AddAttachmentToInsolvencyResource("transactionID", "fileID", "attachmentType")
+		attachmentDao, err := mongoService.AddAttachmentToInsolvencyResource(context.Background(), "transactionID", "fileID", "attachmentType")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, attachmentDao)
@@ -894,18 +1017,18 @@ func TestUnitAddAttachmentToInsolvencyResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		attachmentDao, err := mongoService.AddAttachmentToInsolvencyResource("transactionID", "fileID", "attachmentType")
+		attachmentDao, err := mongoService.AddAttachmentToInsolvencyResource(context.Background(), "transactionID", "fileID", "attachmentType")
 
 		assert.NotNil(t, err)
 		assert.Nil(t, attachmentDao)
-		assert.Equal(t, err.Error(), "no documents updated")
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 	})
 
 	mt.Run("AddAttachmentToInsolvencyResource runs with error", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.AddAttachmentToInsolvencyResource("transactionID", "fileID", "attachmentType")
+		_, err := mongoService.AddAttachmentToInsolvencyResource(context.Background(), "transactionID", "fileID", "attachmentType")
 
 		assert.Equal(t, err.Error(), "error updating mongo for transaction [transactionID]: [(Name) Message]")
 	})
@@ -957,7 +1080,7 @@ func TestUnitGetAttachmentResourcesDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		attachmentResourceDao, err := mongoService.GetAttachmentResources("transactionID")
+		attachmentResourceDao, err := mongoService.GetAttachmentResources(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, attachmentResourceDao)
@@ -985,7 +1108,7 @@ func TestUnitGetAttachmentResourcesDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		attachmentResourceDao, err := mongoService.GetAttachmentResources("transactionID")
+		attachmentResourceDao, err := mongoService.GetAttachmentResources(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, attachmentResourceDao)
@@ -995,9 +1118,9 @@ func TestUnitGetAttachmentResourcesDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.GetAttachmentResources("transactionID")
+		_, err := mongoService.GetAttachmentResources(context.Background(), "transactionID")
 
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 }
 
@@ -1012,9 +1135,9 @@ func TestUnitGetAttachmentFromInsolvencyResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.GetAttachmentFromInsolvencyResource("transactionID", "fileID")
+		_, err := mongoService.GetAttachmentFromInsolvencyResource(context.Background(), "transactionID", "fileID")
 
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 
 	mt.Run("GetAttachmentFromInsolvencyResource runs successfully", func(mt *mtest.T) {
@@ -1056,7 +1179,7 @@ func TestUnitGetAttachmentFromInsolvencyResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		attachmentResourceDao, err := mongoService.GetAttachmentFromInsolvencyResource("transactionID", "fileID")
+		attachmentResourceDao, err := mongoService.GetAttachmentFromInsolvencyResource(context.Background(), "transactionID", "fileID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, attachmentResourceDao)
@@ -1110,10 +1233,9 @@ func TestUnitDeleteAttachmentResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteAttachmentResource("transactionID", "attachmentID")
+		err := mongoService.DeleteAttachmentResource(context.Background(), "transactionID", "attachmentID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
 	})
 
@@ -1122,7 +1244,7 @@ func TestUnitDeleteAttachmentResourceDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		_, err := mongoService.DeleteAttachmentResource("transactionID", "attachmentID")
+		err := mongoService.DeleteAttachmentResource(context.Background(), "transactionID", "attachmentID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
@@ -1139,11 +1261,11 @@ func TestUnitDeleteAttachmentResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteAttachmentResource("transactionID", "attachmentID")
+		err := mongoService.DeleteAttachmentResource(context.Background(), "transactionID", "attachmentID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not delete attachment with id [attachmentID]")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 
 	})
 
@@ -1174,11 +1296,11 @@ func TestUnitDeleteAttachmentResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteAttachmentResource("transactionID", "attachmentID")
+		err := mongoService.DeleteAttachmentResource(context.Background(), "transactionID", "attachmentID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - attachment with id [attachmentID] not found")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -1193,73 +1315,77 @@ func TestUnitCreateResolutionResourceDriver(t *testing.T) {
 
 	resolutionResourceDao := models.ResolutionResourceDao{}
 
-	mt.Run("CreateResolutionResource runs with error", func(mt *mtest.T) {
+	mt.Run("CreateResolutionResource runs with error on UpdateOne", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateResolutionResource(&resolutionResourceDao, "transactionID")
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResourceDao, "transactionID", "")
 
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
 
-	mt.Run("CreateResolutionResource runs successfully with findone", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
-		}))
+	mt.Run("CreateResolutionResource runs successfully with UpdateOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 1},
+		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateResolutionResource(&resolutionResourceDao, "transactionID")
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResourceDao, "transactionID", "etag")
 
-		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
-		assert.Equal(t, code, 500)
+		assert.Nil(t, err)
+
+		// The resolution is only replaced if the filed one has the If-Match etag
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, filter.Lookup("data.resolution.etag").StringValue(), "etag")
 	})
 
-	mt.Run("CreateResolutionResource runs with error on UpdateOne", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
-		}))
+	mt.Run("CreateResolutionResource runs with insolvency case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateResolutionResource(&resolutionResourceDao, "transactionID")
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResourceDao, "transactionID", "")
 
-		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID not found")
 	})
 
-	mt.Run("CreateResolutionResource runs with successfully on UpdateOne", func(mt *mtest.T) {
+	mt.Run("CreateResolutionResource runs with a different etag filed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		mongoService.db = mt.DB
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResourceDao, "transactionID", "etag")
 
-		mt.AddMockResponses(bson.D{
-			{"ok", 1},
-			{"nModified", 1},
-		})
+		assert.IsType(t, &apperrors.PreconditionFailedError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID - the resolution filed for this insolvency case does not have the etag [etag]")
+	})
+
+	mt.Run("CreateResolutionResource runs with error on FindOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateResolutionResource(&resolutionResourceDao, "transactionID")
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResourceDao, "transactionID", "")
 
-		assert.Nil(t, err)
-		assert.Equal(t, code, 201)
+		assert.IsType(t, &databaseError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -1272,90 +1398,78 @@ func TestUnitCreateStatementOfAffairsResourceDriver(t *testing.T) {
 
 	statementOfAffairsResourceDao := models.StatementOfAffairsResourceDao{}
 
-	mt.Run("CreateStatementOfAffairsResource runs successfully with findone", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
-		}))
+	mt.Run("CreateStatementOfAffairsResource runs with error on UpdateOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
-		mt.AddMockResponses(bson.D{
-			{"ok", 1},
-			{"value", bson.D{
-				{"_id", expectedInsolvency.ID},
-				{"transaction_id", expectedInsolvency.TransactionID},
-				{"etag", expectedInsolvency.Etag},
-				{"kind", expectedInsolvency.Kind},
-				{"data", expectedInsolvency.Data},
-			}},
-		})
+		mongoService.db = mt.DB
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementOfAffairsResourceDao, "transactionID", "")
+
+		assert.IsType(t, &databaseError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
+	})
 
+	mt.Run("CreateStatementOfAffairsResource runs successfully with UpdateOne", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
 			bson.E{Key: "n", Value: 1},
-			bson.E{Key: "nModified", Value: 0},
-			bson.E{Key: "upserted", Value: rsSlice},
+			bson.E{Key: "nModified", Value: 1},
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateStatementOfAffairsResource(&statementOfAffairsResourceDao, "transactionID")
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementOfAffairsResourceDao, "transactionID", "etag")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 201)
+
+		// The statement of affairs is only replaced if the filed one has the If-Match etag
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, filter.Lookup("data.statement-of-affairs.etag").StringValue(), "etag")
 	})
-	mt.Run("CreateStatementOfAffairsResource runs with error in FindOne", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+	mt.Run("CreateStatementOfAffairsResource runs with insolvency case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateStatementOfAffairsResource(&statementOfAffairsResourceDao, "transactionID")
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementOfAffairsResourceDao, "transactionID", "")
 
-		assert.Equal(t, code, 500)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID not found")
 	})
 
-	mt.Run("CreateStatementOfAffairsResource runs with error on UpdateOne", func(mt *mtest.T) {
+	mt.Run("CreateStatementOfAffairsResource runs with a different etag filed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
-
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateStatementOfAffairsResource(&statementOfAffairsResourceDao, "transactionID")
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementOfAffairsResourceDao, "transactionID", "etag")
 
-		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &apperrors.PreconditionFailedError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID - the statement of affairs filed for this insolvency case does not have the etag [etag]")
 	})
 
-	mt.Run("CreateStatementOfAffairsResource with successfully on UpdateOne", func(mt *mtest.T) {
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
-			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
-			{"kind", expectedInsolvency.Kind},
-			{"data", expectedInsolvency.Data},
-		}))
+	mt.Run("CreateStatementOfAffairsResource runs with error on FindOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
 
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
-		mt.AddMockResponses(bson.D{
-			{"ok", 1},
-			{"nModified", 1},
-		})
-
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateStatementOfAffairsResource(&statementOfAffairsResourceDao, "transactionID")
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementOfAffairsResourceDao, "transactionID", "")
 
-		assert.Nil(t, err)
-		assert.Equal(t, code, 201)
+		assert.IsType(t, &databaseError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
-
 }
 
 func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
@@ -1404,10 +1518,10 @@ func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		_, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 
 	mt.Run("GetStatementOfAffairsResource runs successfully with findone", func(mt *mtest.T) {
@@ -1420,7 +1534,7 @@ func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		statementOfAffairsResourceDao, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		statementOfAffairsResourceDao, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.Equal(t, statementOfAffairsResourceDao.StatementDate, string("statement_date"))
@@ -1431,7 +1545,7 @@ func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
 
 		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 		mongoService.db = mt.DB
-		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.Equal(t, models.StatementOfAffairsResourceDao{}, statementOfAffairsDao)
 		assert.Nil(t, err)
@@ -1443,9 +1557,9 @@ func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
-		assert.Equal(t, "error decoding key transaction_id: cannot decode array into a string type", err.Error())
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 		assert.Equal(t, models.StatementOfAffairsResourceDao{}, statementOfAffairsDao)
 	})
 
@@ -1468,7 +1582,7 @@ func TestUnitGetStatementOfAffairsResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		statementOfAffairsDao, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.Equal(t, models.StatementOfAffairsResourceDao{}, statementOfAffairsDao)
@@ -1505,7 +1619,7 @@ func TestUnitDeleteStatementOfAffairsResourceDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		_, err := mongoService.DeleteStatementOfAffairsResource("transactionID")
+		err := mongoService.DeleteStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
@@ -1522,11 +1636,11 @@ func TestUnitDeleteStatementOfAffairsResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteStatementOfAffairsResource("transactionID")
+		err := mongoService.DeleteStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not delete statement of affairs")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 
 	})
 
@@ -1548,11 +1662,11 @@ func TestUnitDeleteStatementOfAffairsResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteStatementOfAffairsResource("transactionID")
+		err := mongoService.DeleteStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - statement of affairs not found")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -1574,10 +1688,9 @@ func TestUnitDeleteStatementOfAffairsResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteStatementOfAffairsResource("transactionID")
+		err := mongoService.DeleteStatementOfAffairsResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
 	})
 }
@@ -1587,40 +1700,81 @@ func TestUnitCreateProgressReportResourceDriver(t *testing.T) {
 
 	mongoService, commandError, expectedInsolvency, opts, _ := setDriverUp()
 
-	progressReportResourceDao := models.ProgressReportResourceDao{}
-
 	mt := mtest.New(t, opts)
 
-	mt.Run("CreateProgressReportResource with error findone", func(mt *mtest.T) {
+	progressReportResourceDao := models.ProgressReportResourceDao{}
+
+	mt.Run("CreateProgressReportResource runs with error on UpdateOne", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		_, err := mongoService.CreateProgressReportResource(&progressReportResourceDao, "transactionID")
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReportResourceDao, "transactionID", "")
 
-		assert.NotNil(t, err.Error())
+		assert.IsType(t, &databaseError{}, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
 
-	mt.Run("CreateProgressReportResource with successful created one", func(mt *mtest.T) {
+	mt.Run("CreateProgressReportResource runs successfully with UpdateOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 1},
+			bson.E{Key: "nModified", Value: 1},
+		))
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReportResourceDao, "transactionID", "etag")
+
+		assert.Nil(t, err)
+
+		// The progress report is only replaced if the filed one has the If-Match etag
+		filter := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, filter.Lookup("data.progress-report.etag").StringValue(), "etag")
+	})
+
+	mt.Run("CreateProgressReportResource runs with insolvency case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReportResourceDao, "transactionID", "")
+
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID not found")
+	})
+
+	mt.Run("CreateProgressReportResource runs with a different etag filed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
+		))
+
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{
 			{"_id", expectedInsolvency.ID},
-			{"transaction_id", expectedInsolvency.TransactionID},
-			{"etag", expectedInsolvency.Etag},
 		}))
 
-		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+		mongoService.db = mt.DB
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReportResourceDao, "transactionID", "etag")
 
+		assert.IsType(t, &apperrors.PreconditionFailedError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction transactionID - the progress report filed for this insolvency case does not have the etag [etag]")
+	})
+
+	mt.Run("CreateProgressReportResource runs with error on FindOne", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
-			bson.E{Key: "n", Value: 1},
-			bson.E{Key: "nModified", Value: 1},
-			bson.E{Key: "upserted", Value: rsSlice},
+			bson.E{Key: "n", Value: 0},
+			bson.E{Key: "nModified", Value: 0},
 		))
 
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
 		mongoService.db = mt.DB
-		code, err := mongoService.CreateProgressReportResource(&progressReportResourceDao, "transactionID")
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReportResourceDao, "transactionID", "")
 
-		assert.Nil(t, err)
-		assert.Equal(t, code, 201)
+		assert.IsType(t, &databaseError{}, err)
+		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -1660,7 +1814,7 @@ func TestUnitGetProgressReportResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.ProgressReportResourceDao", mtest.FirstBatch, bsonProgress))
 
 		mongoService.db = mt.DB
-		progressReportResource, err := mongoService.GetProgressReportResource("transactionID")
+		progressReportResource, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.Equal(t, progressReportResource.FromDate, string("from_date"))
@@ -1697,7 +1851,7 @@ func TestUnitGetProgressReportResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "models.ProgressReportResourceDao", mtest.FirstBatch, bsonProgress))
 
 		mongoService.db = mt.DB
-		progressReportResource, err := mongoService.GetProgressReportResource("transactionID")
+		progressReportResource, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, &models.ProgressReportResourceDao{}, progressReportResource)
@@ -1707,9 +1861,9 @@ func TestUnitGetProgressReportResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		progressReportResource, err := mongoService.GetProgressReportResource("transactionID")
+		progressReportResource, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 		assert.Equal(t, &models.ProgressReportResourceDao{}, progressReportResource)
 	})
 
@@ -1717,7 +1871,7 @@ func TestUnitGetProgressReportResourceDriver(t *testing.T) {
 
 		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 		mongoService.db = mt.DB
-		progressReportDao, err := mongoService.GetProgressReportResource("transactionID")
+		progressReportDao, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
 		assert.Equal(t, &models.ProgressReportResourceDao{}, progressReportDao)
 		assert.Nil(t, err)
@@ -1741,7 +1895,7 @@ func TestUnitGetProgressReportResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		progressReportDao, err := mongoService.GetProgressReportResource("transactionID")
+		progressReportDao, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.Equal(t, &models.ProgressReportResourceDao{}, progressReportDao)
@@ -1779,7 +1933,7 @@ func TestUnitDeleteProgressReportResourceDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		_, err := mongoService.DeleteProgressReportResource("transactionID")
+		err := mongoService.DeleteProgressReportResource(context.Background(), "transactionID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
@@ -1796,11 +1950,11 @@ func TestUnitDeleteProgressReportResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteProgressReportResource("transactionID")
+		err := mongoService.DeleteProgressReportResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not delete progress report")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 
 	})
 
@@ -1822,11 +1976,11 @@ func TestUnitDeleteProgressReportResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteProgressReportResource("transactionID")
+		err := mongoService.DeleteProgressReportResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - progress report not found")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -1848,10 +2002,9 @@ func TestUnitDeleteProgressReportResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteProgressReportResource("transactionID")
+		err := mongoService.DeleteProgressReportResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
 
 	})
 }
@@ -1919,17 +2072,17 @@ func TestUnitGetResolutionResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.GetResolutionResource("transactionID")
+		code, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, code)
-		assert.Equal(t, err.Error(), "(Name) Message")
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 	})
 
 	mt.Run("GetResolutionResource - no insolvency case found", func(mt *mtest.T) {
 
 		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch))
 		mongoService.db = mt.DB
-		resolutionDao, err := mongoService.GetResolutionResource("transactionID")
+		resolutionDao, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
 		assert.Equal(t, models.ResolutionResourceDao{}, resolutionDao)
 		assert.Nil(t, err)
@@ -1941,9 +2094,9 @@ func TestUnitGetResolutionResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		resolutionDao, err := mongoService.GetResolutionResource("transactionID")
+		resolutionDao, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
-		assert.Equal(t, "error decoding key transaction_id: cannot decode array into a string type", err.Error())
+		assert.Equal(t, "there was a problem handling your request for transaction id transactionID", err.Error())
 		assert.Equal(t, models.ResolutionResourceDao{}, resolutionDao)
 	})
 
@@ -1957,7 +2110,7 @@ func TestUnitGetResolutionResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		resolutionDao, err := mongoService.GetResolutionResource("transactionID")
+		resolutionDao, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.Equal(t, models.ResolutionResourceDao{}, resolutionDao)
@@ -1973,7 +2126,7 @@ func TestUnitGetResolutionResourceDriver(t *testing.T) {
 		}))
 
 		mongoService.db = mt.DB
-		resolutionDao, err := mongoService.GetResolutionResource("transactionID")
+		resolutionDao, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
 		assert.Nil(t, err)
 		assert.NotNil(t, resolutionDao.DateOfResolution)
@@ -2010,7 +2163,7 @@ func TestUnitDeleteResolutionResourceDriver(t *testing.T) {
 
 		mongoService.db = mt.DB
 
-		_, err := mongoService.DeleteResolutionResource("transactionID")
+		err := mongoService.DeleteResolutionResource(context.Background(), "transactionID")
 
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id transactionID")
 	})
@@ -2027,11 +2180,11 @@ func TestUnitDeleteResolutionResourceDriver(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteResolutionResource("transactionID")
+		err := mongoService.DeleteResolutionResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - could not delete resolution")
-		assert.Equal(t, code, 500)
+		assert.IsType(t, &databaseError{}, err)
 
 	})
 
@@ -2053,11 +2206,11 @@ func TestUnitDeleteResolutionResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteResolutionResource("transactionID")
+		err := mongoService.DeleteResolutionResource(context.Background(), "transactionID")
 
 		assert.NotNil(t, err)
 		assert.Equal(t, err.Error(), "there was a problem handling your request for transaction id [transactionID] - resolution not found")
-		assert.Equal(t, code, 404)
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
 
 	})
 
@@ -2079,10 +2232,256 @@ func TestUnitDeleteResolutionResourceDriver(t *testing.T) {
 		))
 
 		mongoService.db = mt.DB
-		code, err := mongoService.DeleteResolutionResource("transactionID")
+		err := mongoService.DeleteResolutionResource(context.Background(), "transactionID")
+
+		assert.Nil(t, err)
+
+	})
+}
+
+func TestUnitOutboxEventsDriver(t *testing.T) {
+	t.Parallel()
+
+	mongoService, commandError, _, opts, _ := setDriverUp()
+
+	mt := mtest.New(t, opts)
+
+	createdAt := time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC)
+	event := models.OutboxEventDao{
+		ID:            "eventID",
+		Type:          EventFilingsGenerated,
+		TransactionID: "transactionID",
+		CreatedAt:     createdAt,
+		Data:          `{"filings":["insolvency#600"]}`,
+	}
+
+	mt.Run("CreateOutboxEvent pushes the event onto the outbox of the case", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateOutboxEvent(context.Background(), &event)
+
+		assert.Nil(t, err)
+		statement := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, statement.Lookup("q", "written_outbox_events", "$ne").StringValue(), "eventID")
+		update := statement.Lookup("u").Document()
+		assert.Equal(t, update.Lookup("$push", "outbox", "id").StringValue(), "eventID")
+		assert.Equal(t, update.Lookup("$inc", "outbox_pending").Int32(), int32(1))
+		assert.Equal(t, update.Lookup("$addToSet", "written_outbox_events").StringValue(), "eventID")
+	})
+
+	mt.Run("CreateOutboxEvent writes nothing when the event has already been written", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{{"n", 1}}),
+		)
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateOutboxEvent(context.Background(), &event)
+
+		assert.Nil(t, err)
+	})
+
+	mt.Run("CreateOutboxEvent runs with the case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch),
+		)
+
+		mongoService.db = mt.DB
+		err := mongoService.CreateOutboxEvent(context.Background(), &event)
+
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+	})
+
+	mt.Run("GetOutboxEvents returns the events from the outboxes of the cases", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.OutboxEventDao", mtest.FirstBatch, bson.D{
+			{"id", event.ID},
+			{"type", event.Type},
+			{"transaction_id", event.TransactionID},
+			{"created_at", event.CreatedAt},
+			{"data", event.Data},
+		}))
+
+		mongoService.db = mt.DB
+		events, err := mongoService.GetOutboxEvents(context.Background(), 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].ID, "eventID")
+		assert.True(t, events[0].CreatedAt.Equal(createdAt))
+		assert.Equal(t, events[0].Data, event.Data)
+	})
+
+	mt.Run("GetOutboxEvents only reads the cases with events waiting to be delivered", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "models.OutboxEventDao", mtest.FirstBatch))
+
+		mongoService.db = mt.DB
+		_, err := mongoService.GetOutboxEvents(context.Background(), 10)
+
+		assert.Nil(t, err)
+
+		// Cases whose events have all been delivered are excluded by the first stage, before any are unwound
+		stages, _ := mt.GetAllStartedEvents()[0].Command.Lookup("pipeline").Array().Values()
+		match := stages[0].Document().Lookup("$match").Document()
+		assert.Equal(t, match.Lookup("outbox_pending", "$gt").Int32(), int32(0))
+		elements, _ := match.Elements()
+		assert.Equal(t, len(elements), 1)
+	})
+
+	mt.Run("GetOutboxEvents runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+		mongoService.db = mt.DB
+		_, err := mongoService.GetOutboxEvents(context.Background(), 10)
+
+		assert.IsType(t, &databaseError{}, err)
+	})
+
+	mt.Run("DeleteOutboxEvent pulls the event from the outbox of the case", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		mongoService.db = mt.DB
+		err := mongoService.DeleteOutboxEvent(context.Background(), "transactionID", "eventID")
 
 		assert.Nil(t, err)
-		assert.Equal(t, code, 204)
+		statement := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, statement.Lookup("q", "outbox.id").StringValue(), "eventID")
+		update := statement.Lookup("u").Document()
+		assert.Equal(t, update.Lookup("$pull", "outbox", "id").StringValue(), "eventID")
+		assert.Equal(t, update.Lookup("$inc", "outbox_pending").Int32(), int32(-1))
+	})
+
+	eventDocument := func(id string, parked bool) bson.D {
+		return bson.D{{"id", id}, {"type", event.Type}, {"transaction_id", event.TransactionID}, {"created_at", event.CreatedAt}, {"data", event.Data}, {"parked", parked}}
+	}
+
+	mt.Run("ClaimOutboxEvents claims each case that no other claimant holds, returning its events that are not parked", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "models.InsolvencyResourceDao", mtest.FirstBatch, bson.D{{"transaction_id", "transactionID"}}, bson.D{{"transaction_id", "claimedID"}}),
+			bson.D{{"ok", 1}, {"value", bson.D{{"outbox", bson.A{eventDocument("parkedID", true), eventDocument("eventID", false)}}}}},
+			bson.D{{"ok", 1}, {"value", nil}},
+		)
+
+		mongoService.db = mt.DB
+		events, err := mongoService.ClaimOutboxEvents(context.Background(), "relay", createdAt, time.Minute, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].ID, "eventID")
+
+		started := mt.GetAllStartedEvents()
+		assert.Equal(t, len(started), 3)
+		claim := started[1].Command
+		assert.Equal(t, claim.Lookup("query", "transaction_id").StringValue(), "transactionID")
+		assert.Equal(t, claim.Lookup("query", "outbox_pending", "$gt").Int32(), int32(0))
+		assert.Equal(t, claim.Lookup("update", "$set", "outbox_claimed_by").StringValue(), "relay")
+		assert.Equal(t, claim.Lookup("update", "$set", "outbox_claim_expires_at").Time().UTC(), createdAt.Add(time.Minute))
+	})
+
+	mt.Run("ClaimOutboxEvents runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+		mongoService.db = mt.DB
+		_, err := mongoService.ClaimOutboxEvents(context.Background(), "relay", createdAt, time.Minute, 10)
+
+		assert.IsType(t, &databaseError{}, err)
+	})
+
+	mt.Run("ReleaseOutboxClaim only removes the claim of the claimant", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		mongoService.db = mt.DB
+		err := mongoService.ReleaseOutboxClaim(context.Background(), "transactionID", "relay")
+
+		assert.Nil(t, err)
+		statement := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, statement.Lookup("q", "outbox_claimed_by").StringValue(), "relay")
+		_, err = statement.Lookup("u", "$unset").Document().LookupErr("outbox_claim_expires_at")
+		assert.Nil(t, err)
+	})
+
+	mt.Run("FailOutboxEvent counts the failed delivery without parking the event", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", bson.D{{"outbox", bson.A{append(eventDocument("eventID", false), bson.E{Key: "attempts", Value: 1})}}}}})
+
+		mongoService.db = mt.DB
+		parked, err := mongoService.FailOutboxEvent(context.Background(), "transactionID", "eventID", 3)
+
+		assert.Nil(t, err)
+		assert.False(t, parked)
+		started := mt.GetAllStartedEvents()
+		assert.Equal(t, len(started), 1)
+		assert.Equal(t, started[0].Command.Lookup("update", "$inc", "outbox.$.attempts").Int32(), int32(1))
+	})
+
+	mt.Run("FailOutboxEvent parks the event once its deliveries have failed the maximum times", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{"ok", 1}, {"value", bson.D{{"outbox", bson.A{append(eventDocument("eventID", false), bson.E{Key: "attempts", Value: 3})}}}}},
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		mongoService.db = mt.DB
+		parked, err := mongoService.FailOutboxEvent(context.Background(), "transactionID", "eventID", 3)
+
+		assert.Nil(t, err)
+		assert.True(t, parked)
+		update := mt.GetAllStartedEvents()[1].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+		assert.True(t, update.Lookup("$set", "outbox.$.parked").Boolean())
+		assert.Equal(t, update.Lookup("$inc", "outbox_pending").Int32(), int32(-1))
+	})
+
+	mt.Run("FailOutboxEvent runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+		mongoService.db = mt.DB
+		_, err := mongoService.FailOutboxEvent(context.Background(), "transactionID", "eventID", 3)
+
+		assert.IsType(t, &databaseError{}, err)
+	})
+}
+
+func TestUnitSetValidationFailuresDriver(t *testing.T) {
+	t.Parallel()
+
+	mongoService, commandError, _, opts, _ := setDriverUp()
+
+	mt := mtest.New(t, opts)
+
+	mt.Run("SetValidationFailures returns the failures stored before", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{"ok", 1},
+			{"value", bson.D{{"validation_failures", bson.A{"no resolution"}}}},
+		})
+
+		mongoService.db = mt.DB
+		previous, err := mongoService.SetValidationFailures(context.Background(), "transactionID", []string{"practitioner"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, previous, []string{"no resolution"})
+
+		// The failures are replaced and the previous failures read in the same command
+		command := mt.GetAllStartedEvents()[0].Command
+		failures, _ := command.Lookup("update", "$set", "validation_failures").Array().Values()
+		assert.Equal(t, len(failures), 1)
+		assert.Equal(t, failures[0].StringValue(), "practitioner")
+		assert.False(t, command.Lookup("new").Boolean())
+	})
+
+	mt.Run("SetValidationFailures runs with insolvency case not found", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", nil}})
+
+		mongoService.db = mt.DB
+		_, err := mongoService.SetValidationFailures(context.Background(), "transactionID", nil)
+
+		assert.IsType(t, &apperrors.NotFoundError{}, err)
+	})
+
+	mt.Run("SetValidationFailures runs with error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(commandError))
+
+		mongoService.db = mt.DB
+		_, err := mongoService.SetValidationFailures(context.Background(), "transactionID", nil)
 
+		assert.IsType(t, &databaseError{}, err)
 	})
 }
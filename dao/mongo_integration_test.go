@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const integrationDatabase = "insolvency_integration_test"
+
+// TestIntegrationMongoService runs the Service behaviour tests against the MongoDB instance at MONGODB_URL,
+// using a new collection for each test case
+func TestIntegrationMongoService(t *testing.T) {
+	mongoDBURL := os.Getenv("MONGODB_URL")
+	if mongoDBURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoDBURL))
+	if err != nil {
+		t.Fatalf("error connecting to mongodb: [%v]", err)
+	}
+	t.Cleanup(func() {
+		mongoClient.Disconnect(context.Background())
+	})
+	database := mongoClient.Database(integrationDatabase)
+
+	testServiceBehaviour(t, func(t *testing.T) Service {
+		collection := fmt.Sprintf("suite_%d", time.Now().UnixNano())
+		t.Cleanup(func() {
+			for _, name := range []string{collection, collection + "_idempotency", collection + "_audit"} {
+				if err := database.Collection(name).Drop(context.Background()); err != nil {
+					t.Logf("error dropping collection [%s]: [%v]", name, err)
+				}
+			}
+		})
+
+		mongoService := &MongoService{
+			db:                        database,
+			CollectionName:            collection,
+			IdempotencyCollectionName: collection + "_idempotency",
+			AuditCollectionName:       collection + "_audit",
+			OperationTimeout:          defaultOperationTimeout,
+		}
+		if err := mongoService.EnsureIndexes(context.Background()); err != nil {
+			t.Fatalf("error creating indexes: [%v]", err)
+		}
+
+		return mongoService
+	})
+}
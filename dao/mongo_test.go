@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"context"
 	"testing"
 
 	"github.com/companieshouse/insolvency-api/config"
@@ -13,7 +14,8 @@ import (
 )
 
 func NewGetMongoDatabase(mongoDBURL, databaseName string) MongoDatabaseInterface {
-	return getMongoClient(mongoDBURL).Database(databaseName)
+	mongoClient, _ := getMongoClient(&config.Config{MongoDBURL: mongoDBURL})
+	return mongoClient.Database(databaseName)
 }
 
 func setUp(t *testing.T) MongoService {
@@ -39,9 +41,9 @@ func TestUnitCreateInsolvencyResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		err, _ := mongoService.CreateInsolvencyResource(&expectedInsolvency)
+		err := mongoService.CreateInsolvencyResource(context.Background(), &expectedInsolvency)
 
-		So(err.Error(), ShouldEqual, "there was a problem creating an insolvency case for this transaction id: the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem creating an insolvency case for this transaction id: the Insert operation must have a Deployment set before Execute can be called")
 	})
 }
 
@@ -51,7 +53,7 @@ func TestUnitGetInsolvencyResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetInsolvencyResource("transactionID")
+		_, err := mongoService.GetInsolvencyResource(context.Background(), "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction [transactionID]")
 	})
@@ -65,7 +67,7 @@ func TestUnitCreatePractitionersResource(t *testing.T) {
 
 		practitionerResource := models.PractitionerResourceDao{}
 
-		err, _ := mongoService.CreatePractitionersResource(&practitionerResource, "transactionID")
+		err := mongoService.CreatePractitionersResource(context.Background(), &practitionerResource, "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -77,9 +79,9 @@ func TestUnitGetPractitionerResources(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetPractitionerResources("transactionID")
+		_, err := mongoService.GetPractitionerResources(context.Background(), "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -89,9 +91,9 @@ func TestUnitGetPractitionerResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetPractitionerResource("practitionerID", "transactionID")
+		_, err := mongoService.GetPractitionerResource(context.Background(), "practitionerID", "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -101,7 +103,7 @@ func TestUnitDeletePractitioner(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		err, _ := mongoService.DeletePractitioner("practitionerID", "transactionID")
+		err := mongoService.DeletePractitioner(context.Background(), "practitionerID", "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -115,7 +117,7 @@ func TestUnitAppointPractitioner(t *testing.T) {
 
 		appointmentResource := models.AppointmentResourceDao{}
 
-		err, _ := mongoService.AppointPractitioner(&appointmentResource, "transactionID", "practitionerID")
+		err := mongoService.AppointPractitioner(context.Background(), &appointmentResource, "transactionID", "practitionerID")
 
 		So(err.Error(), ShouldEqual, "could not update practitioner appointment for practitionerID practitionerID: the Update operation must have a Deployment set before Execute can be called")
 	})
@@ -127,7 +129,7 @@ func TestUnitDeletePractitionerAppointment(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		err, _ := mongoService.DeletePractitionerAppointment("transactionID", "practitionerID")
+		err := mongoService.DeletePractitionerAppointment(context.Background(), "transactionID", "practitionerID")
 
 		So(err.Error(), ShouldEqual, "could not update practitioner appointment for practitionerID practitionerID: the Update operation must have a Deployment set before Execute can be called")
 	})
@@ -139,7 +141,7 @@ func TestUnitAddAttachmentToInsolvencyResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.AddAttachmentToInsolvencyResource("transactionID", "fileID", "attachmentType")
+		_, err := mongoService.AddAttachmentToInsolvencyResource(context.Background(), "transactionID", "fileID", "attachmentType")
 
 		So(err.Error(), ShouldEqual, "error updating mongo for transaction [transactionID]: [the Update operation must have a Deployment set before Execute can be called]")
 	})
@@ -151,9 +153,9 @@ func TestUnitGetAttachmentResources(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetAttachmentResources("transactionID")
+		_, err := mongoService.GetAttachmentResources(context.Background(), "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -163,9 +165,9 @@ func TestUnitGetAttachmentFromInsolvencyResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetAttachmentFromInsolvencyResource("transactionID", "fileID")
+		_, err := mongoService.GetAttachmentFromInsolvencyResource(context.Background(), "transactionID", "fileID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -175,7 +177,7 @@ func TestUnitDeleteAttachmentResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.DeleteAttachmentResource("transactionID", "attachmentID")
+		err := mongoService.DeleteAttachmentResource(context.Background(), "transactionID", "attachmentID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -187,9 +189,9 @@ func TestUnitUpdateAttachmentStatus(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.UpdateAttachmentStatus("transactionID", "attachmentID", "avStatus")
+		err := mongoService.UpdateAttachmentStatus(context.Background(), "transactionID", "attachmentID", "avStatus")
 
-		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id [transactionID] - could not update status of attachment with id [attachmentID]")
 	})
 }
 
@@ -201,7 +203,7 @@ func TestUnitCreateResolutionResource(t *testing.T) {
 
 		resolutionResource := models.ResolutionResourceDao{}
 
-		_, err := mongoService.CreateResolutionResource(&resolutionResource, "transactionID")
+		err := mongoService.CreateResolutionResource(context.Background(), &resolutionResource, "transactionID", "")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -215,7 +217,7 @@ func TestUnitCreateStatementOfAffairsResource(t *testing.T) {
 
 		statementResource := models.StatementOfAffairsResourceDao{}
 
-		_, err := mongoService.CreateStatementOfAffairsResource(&statementResource, "transactionID")
+		err := mongoService.CreateStatementOfAffairsResource(context.Background(), &statementResource, "transactionID", "")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -227,9 +229,9 @@ func TestUnitGetStatementOfAffairsResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetStatementOfAffairsResource("transactionID")
+		_, err := mongoService.GetStatementOfAffairsResource(context.Background(), "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -239,7 +241,7 @@ func TestUnitDeleteStatementOfAffairsResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.DeleteStatementOfAffairsResource("transactionID")
+		err := mongoService.DeleteStatementOfAffairsResource(context.Background(), "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -253,7 +255,7 @@ func TestUnitCreateProgressReportResource(t *testing.T) {
 
 		progressReport := models.ProgressReportResourceDao{}
 
-		_, err := mongoService.CreateProgressReportResource(&progressReport, "transactionID")
+		err := mongoService.CreateProgressReportResource(context.Background(), &progressReport, "transactionID", "")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -265,9 +267,9 @@ func TestUnitGetProgressReportResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetProgressReportResource("transactionID")
+		_, err := mongoService.GetProgressReportResource(context.Background(), "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -277,7 +279,7 @@ func TestUnitDeleteProgressReportResource(t *testing.T) {
 
 		MongoService := setUp(t)
 
-		_, err := MongoService.DeleteProgressReportResource("transactionID")
+		err := MongoService.DeleteProgressReportResource(context.Background(), "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 
@@ -290,9 +292,9 @@ func TestUnitGetResolutionResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.GetResolutionResource("transactionID")
+		_, err := mongoService.GetResolutionResource(context.Background(), "transactionID")
 
-		So(err.Error(), ShouldEqual, "the Find operation must have a Deployment set before Execute can be called")
+		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
 }
 
@@ -301,7 +303,7 @@ func TestUnitDeleteResolutionResource(t *testing.T) {
 
 		mongoService := setUp(t)
 
-		_, err := mongoService.DeleteResolutionResource("transactionID")
+		err := mongoService.DeleteResolutionResource(context.Background(), "transactionID")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
@@ -312,7 +314,7 @@ func TestUnitDeleteResource(t *testing.T) {
 
 		MongoService := setUp(t)
 
-		_, err := MongoService.DeleteResource("transactionID", "progress-report")
+		err := MongoService.DeleteResource(context.Background(), "transactionID", "progress-report")
 
 		So(err.Error(), ShouldEqual, "there was a problem handling your request for transaction id transactionID")
 	})
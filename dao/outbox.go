@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/google/uuid"
+)
+
+// Types of the domain events written to the outbox
+const (
+	EventCaseCreated           = "insolvency-case.created"
+	EventPractitionerAppointed = "practitioner.appointed"
+	EventAttachmentScanned     = "attachment.scanned"
+	EventFilingsGenerated      = "filings.generated"
+)
+
+// NewOutboxEvent returns a domain event of the eventType for the case with the specified transactionID, holding
+// the data encoded as JSON
+func NewOutboxEvent(eventType string, transactionID string, data interface{}) (*models.OutboxEventDao, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s event for transaction [%s]: [%w]", eventType, transactionID, err)
+	}
+
+	return &models.OutboxEventDao{
+		ID:            uuid.NewString(),
+		Type:          eventType,
+		TransactionID: transactionID,
+		CreatedAt:     time.Now().UTC().Truncate(time.Millisecond),
+		Data:          string(encoded),
+	}, nil
+}
+
+// FilingsGeneratedEvent returns the event written when the filings of the case with the specified transactionID
+// are first generated, holding the kind of each filing. Its ID is derived from the transactionID, so that it is
+// only written once however many times the filings are generated
+func FilingsGeneratedEvent(transactionID string, kinds []string) (*models.OutboxEventDao, error) {
+	event, err := NewOutboxEvent(EventFilingsGenerated, transactionID, map[string][]string{"filings": kinds})
+	if err != nil {
+		return nil, err
+	}
+
+	event.ID = "filings-generated-" + transactionID
+	return event, nil
+}
+
+// caseCreatedEvents returns the events written when an insolvency case is created. A case that is imported is
+// created with its practitioners already appointed, so an event for each appointment follows the one for the case
+func caseCreatedEvents(dao *models.InsolvencyResourceDao) ([]*models.OutboxEventDao, error) {
+	event, err := NewOutboxEvent(EventCaseCreated, dao.TransactionID, map[string]string{
+		"company_number": dao.Data.CompanyNumber,
+		"company_name":   dao.Data.CompanyName,
+		"case_type":      dao.Data.CaseType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*models.OutboxEventDao{event}
+	for _, practitioner := range dao.Data.Practitioners {
+		if practitioner.Appointment == nil {
+			continue
+		}
+		event, err := practitionerAppointedEvent(practitioner.Appointment, dao.TransactionID, practitioner.ID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// practitionerAppointedEvent returns the event written when a practitioner is appointed to an insolvency case
+func practitionerAppointedEvent(dao *models.AppointmentResourceDao, transactionID string, practitionerID string) (*models.OutboxEventDao, error) {
+	return NewOutboxEvent(EventPractitionerAppointed, transactionID, map[string]string{
+		"practitioner_id": practitionerID,
+		"appointed_on":    dao.AppointedOn,
+		"made_by":         dao.MadeBy,
+	})
+}
+
+// attachmentScannedEvent returns the event written when the antivirus check of an attachment changes its status
+func attachmentScannedEvent(transactionID string, attachmentID string, avStatus string) (*models.OutboxEventDao, error) {
+	return NewOutboxEvent(EventAttachmentScanned, transactionID, map[string]string{
+		"attachment_id": attachmentID,
+		"status":        avStatus,
+	})
+}
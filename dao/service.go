@@ -1,6 +1,10 @@
 package dao
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/models"
 )
@@ -8,78 +12,223 @@ import (
 // Service interface declares how to interact with the persistence layer regardless of underlying technology
 type Service interface {
 	// CreateInsolvencyResource will persist a newly created resource
-	CreateInsolvencyResource(dao *models.InsolvencyResourceDao) (error, int)
+	CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error
 
 	// GetInsolvencyResource will retrieve an Insolvency Resource
-	GetInsolvencyResource(transactionID string) (models.InsolvencyResourceDao, error)
+	GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error)
 
 	// CreatePractitionersResource will persist a newly created practitioner resource
-	CreatePractitionersResource(dao *models.PractitionerResourceDao, transactionID string) (error, int)
+	CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error
 
 	// GetPractitionerResources will retrieve a list of persisted practitioners
-	GetPractitionerResources(transactionID string) ([]models.PractitionerResourceDao, error)
+	GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error)
 
 	// GetPractitionerResource will retrieve a practitioner from the insolvency resource
-	GetPractitionerResource(practitionerID string, transactionID string) (models.PractitionerResourceDao, error)
+	GetPractitionerResource(ctx context.Context, practitionerID string, transactionID string) (models.PractitionerResourceDao, error)
 
 	// DeletePractitioner will delete a practitioner from the Insolvency resource
-	DeletePractitioner(practitionerID, transactionID string) (error, int)
+	DeletePractitioner(ctx context.Context, practitionerID, transactionID string) error
 
 	// AppointPractitioner will appoint add appointment details to a practitioner resource
-	AppointPractitioner(dao *models.AppointmentResourceDao, transactionID string, practitionerID string) (error, int)
+	AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID string, practitionerID string) error
 
 	// DeletePractitionerAppointment will delete the appointment for a practitioner
-	DeletePractitionerAppointment(transactionID string, practitionerID string) (error, int)
+	DeletePractitionerAppointment(ctx context.Context, transactionID string, practitionerID string) error
 
 	// AddAttachmentToInsolvencyResource will add an attachment to an insolvency resource
-	AddAttachmentToInsolvencyResource(transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error)
+	AddAttachmentToInsolvencyResource(ctx context.Context, transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error)
 
 	// GetAttachmentFromInsolvencyResource will retrieve an attachment from an insolvency resource
-	GetAttachmentFromInsolvencyResource(transactionID string, attachmentID string) (models.AttachmentResourceDao, error)
+	GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID string, attachmentID string) (models.AttachmentResourceDao, error)
 
 	// GetAttachmentResources retrieves all attachments filed for an Insolvency Case
-	GetAttachmentResources(transactionID string) ([]models.AttachmentResourceDao, error)
+	GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error)
 
 	// DeleteAttachmentResource deletes an attachment in an Insolvency Case
-	DeleteAttachmentResource(transactionID, attachmentID string) (int, error)
+	DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error
 
 	// UpdateAttachmentStatus updates the status of an attachment for an Insolvency Case
-	UpdateAttachmentStatus(transactionID, attachmentID, avStatus string) (int, error)
+	UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID, avStatus string) error
 
-	// CreateStatementOfAffairsResource creates the statement of affairs resource for an Insolvency Case
-	CreateStatementOfAffairsResource(dao *models.StatementOfAffairsResourceDao, transactionID string) (int, error)
+	// CreateStatementOfAffairsResource creates the statement of affairs resource for an Insolvency Case, replacing any
+	// already filed. When ifMatch is not empty, it is only stored if the filed statement has that etag
+	CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error
 
-	// CreateProgressReportResource creates the progress report resource for an Insolvency Case
-	CreateProgressReportResource(dao *models.ProgressReportResourceDao, transactionID string) (int, error)
+	// CreateProgressReportResource creates the progress report resource for an Insolvency Case, replacing any
+	// already filed. When ifMatch is not empty, it is only stored if the filed progress report has that etag
+	CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error
 
 	// DeleteStatementOfAffairsResource deletes the statement of affairs filed for an insolvency case
-	DeleteStatementOfAffairsResource(transactionID string) (int, error)
+	DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error
 
-	// CreateResolutionResource creates the resolution resource for an Insolvency Case
-	CreateResolutionResource(dao *models.ResolutionResourceDao, transactionID string) (int, error)
+	// CreateResolutionResource creates the resolution resource for an Insolvency Case, replacing any already filed.
+	// When ifMatch is not empty, it is only stored if the filed resolution has that etag
+	CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error
 
 	// GetStatementOfAffairsResource retrieves the statement of affairs resource from an Insolvency Case
-	GetStatementOfAffairsResource(transactionID string) (models.StatementOfAffairsResourceDao, error)
+	GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error)
 
 	// GetResolutionResource retrieves the resolution resource from an Insolvency Case
-	GetResolutionResource(transactionID string) (models.ResolutionResourceDao, error)
+	GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error)
 
 	// DeleteResolutionResource deletes a resolution for an Insolvency Case
-	DeleteResolutionResource(transactionID string) (int, error)
+	DeleteResolutionResource(ctx context.Context, transactionID string) error
 
 	//GetProgressReportResource retrieves the progress report resource from an Insolvency case
-	GetProgressReportResource(transactionID string) (*models.ProgressReportResourceDao, error)
+	GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error)
 
 	//DeleteProgressReportResource deletes a progress report for an insolvency case
-	DeleteProgressReportResource(transactionID string) (int, error)
+	DeleteProgressReportResource(ctx context.Context, transactionID string) error
+
+	// SetValidationFailures stores the rules that the insolvency case failed when its validation status was last
+	// checked, returning the rules it failed at the check before
+	SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error)
+
+	// ReserveIdempotencyKey stores the key for a request that is about to be handled. If the key has already been
+	// stored and has not expired, nothing is stored and the existing key is returned instead
+	ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error)
+
+	// CompleteIdempotencyKey stores the response to the request made with a reserved key, keeping the key until
+	// expiresAt rather than until its reservation expires. Nothing is stored unless the key is still reserved with
+	// token, so a request whose reservation has expired cannot overwrite the reservation of a retry
+	CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error
+
+	// ReleaseIdempotencyKey deletes a key reserved with token, so that the request made with it can be retried
+	ReleaseIdempotencyKey(ctx context.Context, key, token string) error
+
+	// CreateAuditEvent appends an event to the audit trail. Audit events are never updated or deleted
+	CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error
+
+	// GetAuditEvents retrieves the audit events matching the filter, oldest first
+	GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error)
+
+	// CreateOutboxEvent writes a domain event to the outbox of the insolvency case it describes, for an event that
+	// does not accompany a change to the case. Events that do are written by the operation making the change. An
+	// event is only written once for each ID, so nothing is written if an event with the same ID has been written
+	// for the case before, even if it has since been delivered
+	CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error
+
+	// GetOutboxEvents retrieves up to limit domain events that have not yet been delivered, including those that
+	// have been parked, oldest first
+	GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error)
+
+	// ClaimOutboxEvents claims the outboxes of the cases with events waiting to be delivered for the claimant, until
+	// lease has passed since now. Cases whose outbox is claimed by another claimant are skipped until that claim
+	// expires. Cases are claimed in the order of their oldest event until they hold limit events, and up to limit
+	// of their events that have not been parked are returned, with the events of each case in the order they were
+	// written
+	ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error)
+
+	// ReleaseOutboxClaim removes the claim on the outbox of the case with the specified transactionID, if it is
+	// held by the claimant, so that its events can be claimed again without waiting for the claim to expire
+	ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error
+
+	// FailOutboxEvent records a failed delivery of a domain event, parking the event once its deliveries have
+	// failed maxAttempts times. It returns whether the event was parked
+	FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error)
+
+	// DeleteOutboxEvent removes a delivered domain event from the outbox of the case with the specified transactionID
+	DeleteOutboxEvent(ctx context.Context, transactionID string, eventID string) error
+
+	// Ping checks that the persistence layer can be reached
+	Ping(ctx context.Context) error
+
+	// Close releases the connection to the persistence layer, waiting for in-use connections until the context is
+	// done. The service must not be used once it is closed
+	Close(ctx context.Context) error
+}
+
+// Database backends that can be selected with the DATABASE_BACKEND config
+const (
+	BackendMongo  = "mongo"
+	BackendMemory = "memory"
+	BackendSQL    = "sql"
+)
+
+// defaultIdempotencyCollection is the collection that idempotency keys are stored in when none is configured
+const defaultIdempotencyCollection = "idempotency_keys"
+
+// defaultAuditCollection is the collection that audit events are stored in when none is configured
+const defaultAuditCollection = "audit_events"
+
+// defaultSQLDriver is the database/sql driver used when none is configured
+const defaultSQLDriver = "sqlite"
+
+// DatabaseName returns the name of the database used by the backend, and the sql driver if it is the sql backend,
+// which the readiness check reports the database under
+func DatabaseName(backend string, sqlDriver string) string {
+	switch backend {
+	case "", BackendMongo:
+		return "mongodb"
+	case BackendSQL:
+		if sqlDriver == "" {
+			return defaultSQLDriver
+		}
+		return sqlDriver
+	default:
+		return backend
+	}
 }
 
 // NewDAOService will create a new instance of the Service interface. All details about its implementation and the
 // database driver will be hidden from outside of this package
-func NewDAOService(cfg *config.Config) Service {
-	database := getMongoDatabase(cfg.MongoDBURL, cfg.Database)
-	return &MongoService{
-		db:             database,
-		CollectionName: cfg.MongoCollection,
+func NewDAOService(cfg *config.Config) (Service, error) {
+	switch cfg.DatabaseBackend {
+	case "", BackendMongo:
+		return newMongoService(cfg)
+	case BackendMemory:
+		return NewInstrumentedService(NewMemoryService()), nil
+	case BackendSQL:
+		driverName := cfg.SQLDriver
+		if driverName == "" {
+			driverName = defaultSQLDriver
+		}
+		sqlService, err := NewSQLService(driverName, cfg.SQLDataSource)
+		if err != nil {
+			return nil, err
+		}
+		return NewInstrumentedService(sqlService), nil
+	default:
+		return nil, fmt.Errorf("unknown database backend [%s]", cfg.DatabaseBackend)
 	}
 }
+
+// newMongoService connects to MongoDB and returns a Service that stores insolvency cases in the configured collection
+func newMongoService(cfg *config.Config) (Service, error) {
+	database, err := getMongoDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	operationTimeout := time.Duration(cfg.MongoOperationTimeout) * time.Second
+	if operationTimeout <= 0 {
+		operationTimeout = defaultOperationTimeout
+	}
+
+	idempotencyCollection := cfg.IdempotencyMongoCollection
+	if idempotencyCollection == "" {
+		idempotencyCollection = defaultIdempotencyCollection
+	}
+
+	auditCollection := cfg.AuditMongoCollection
+	if auditCollection == "" {
+		auditCollection = defaultAuditCollection
+	}
+
+	mongoService := &MongoService{
+		db:                        database,
+		CollectionName:            cfg.MongoCollection,
+		IdempotencyCollectionName: idempotencyCollection,
+		AuditCollectionName:       auditCollection,
+		OperationTimeout:          operationTimeout,
+	}
+
+	// The service relies on the unique transaction_id index, so it cannot start if the indexes cannot be created
+	if !cfg.IsMongoIndexCreationDisabled {
+		if err := mongoService.EnsureIndexes(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewInstrumentedService(mongoService), nil
+}
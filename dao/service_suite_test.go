@@ -0,0 +1,771 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const suiteTransactionID = "suite-transaction-id"
+
+// testServiceBehaviour checks the behaviour that the handlers rely on from every implementation of the Service
+// interface. newService is called for each test case and must return a Service holding no insolvency cases
+func testServiceBehaviour(t *testing.T, newService func(t *testing.T) Service) {
+	ctx := context.Background()
+
+	newCase := func(svc Service) {
+		err := svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{
+			TransactionID: suiteTransactionID,
+			Data: models.InsolvencyResourceDaoData{
+				CompanyNumber: "01234567",
+				CaseType:      "creditors-voluntary-liquidation",
+				CompanyName:   "company",
+			},
+		})
+		So(err, ShouldBeNil)
+	}
+
+	practitioner := func(id string) *models.PractitionerResourceDao {
+		return &models.PractitionerResourceDao{
+			ID:        id,
+			IPCode:    "ip-" + id,
+			FirstName: "first",
+			LastName:  "last",
+			Role:      "final-liquidator",
+		}
+	}
+
+	Convey("Insolvency cases", t, func() {
+		svc := newService(t)
+
+		Convey("A created case can be retrieved", func() {
+			newCase(svc)
+
+			insolvencyResource, err := svc.GetInsolvencyResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(insolvencyResource.ID.IsZero(), ShouldBeFalse)
+			So(insolvencyResource.TransactionID, ShouldEqual, suiteTransactionID)
+			So(insolvencyResource.Data.CompanyNumber, ShouldEqual, "01234567")
+		})
+
+		Convey("A second case for the same transaction is a conflict", func() {
+			newCase(svc)
+
+			err := svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{TransactionID: suiteTransactionID})
+			So(err, ShouldHaveSameTypeAs, &apperrors.ConflictError{})
+		})
+
+		Convey("A missing case is not found", func() {
+			_, err := svc.GetInsolvencyResource(ctx, suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+
+		Convey("A cancelled request is unavailable", func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+
+			_, err := svc.GetInsolvencyResource(cancelled, suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.UpstreamUnavailableError{})
+		})
+
+		Convey("Each validation check returns the rules failed at the check before", func() {
+			newCase(svc)
+
+			previous, err := svc.SetValidationFailures(ctx, suiteTransactionID, []string{"no resolution", "practitioner"})
+			So(err, ShouldBeNil)
+			So(previous, ShouldBeEmpty)
+
+			previous, err = svc.SetValidationFailures(ctx, suiteTransactionID, nil)
+			So(err, ShouldBeNil)
+			So(previous, ShouldResemble, []string{"no resolution", "practitioner"})
+
+			previous, err = svc.SetValidationFailures(ctx, suiteTransactionID, []string{"practitioner"})
+			So(err, ShouldBeNil)
+			So(previous, ShouldBeEmpty)
+		})
+
+		Convey("Validation failures cannot be stored for a missing case", func() {
+			_, err := svc.SetValidationFailures(ctx, suiteTransactionID, []string{"practitioner"})
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+	})
+
+	Convey("Practitioners", t, func() {
+		svc := newService(t)
+
+		Convey("Practitioners cannot be added to a missing case", func() {
+			err := svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(practitioners, ShouldBeNil)
+		})
+
+		Convey("A case without practitioners has an empty list", func() {
+			newCase(svc)
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(practitioners, ShouldNotBeNil)
+			So(practitioners, ShouldBeEmpty)
+
+			stored, err := svc.GetPractitionerResource(ctx, "1", suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(stored, ShouldResemble, models.PractitionerResourceDao{})
+		})
+
+		Convey("Added practitioners can be retrieved", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+			So(svc.CreatePractitionersResource(ctx, practitioner("2"), suiteTransactionID), ShouldBeNil)
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(practitioners, ShouldResemble, []models.PractitionerResourceDao{*practitioner("1"), *practitioner("2")})
+
+			stored, err := svc.GetPractitionerResource(ctx, "2", suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(stored, ShouldResemble, *practitioner("2"))
+		})
+
+		Convey("Changing a retrieved practitioner does not change the stored practitioner", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			practitioners[0].FirstName = "changed"
+
+			stored, err := svc.GetPractitionerResource(ctx, "1", suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(stored.FirstName, ShouldEqual, "first")
+		})
+
+		Convey("A practitioner with the same IP code is a conflict", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+
+			duplicate := practitioner("2")
+			duplicate.IPCode = "ip-1"
+			err := svc.CreatePractitionersResource(ctx, duplicate, suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.ConflictError{})
+		})
+
+		Convey("A sixth practitioner is invalid", func() {
+			newCase(svc)
+			for i := 1; i <= maxPractitioners; i++ {
+				So(svc.CreatePractitionersResource(ctx, practitioner(fmt.Sprint(i)), suiteTransactionID), ShouldBeNil)
+			}
+
+			err := svc.CreatePractitionersResource(ctx, practitioner("6"), suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.ValidationError{})
+		})
+
+		Convey("Concurrent requests cannot add more than five practitioners", func() {
+			newCase(svc)
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2*maxPractitioners)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = svc.CreatePractitionersResource(ctx, practitioner(fmt.Sprint(i)), suiteTransactionID)
+				}(i)
+			}
+			wg.Wait()
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(practitioners, ShouldHaveLength, maxPractitioners)
+		})
+
+		Convey("A deleted practitioner is removed from the case", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+
+			So(svc.DeletePractitioner(ctx, "1", suiteTransactionID), ShouldBeNil)
+
+			practitioners, err := svc.GetPractitionerResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(practitioners, ShouldBeEmpty)
+
+			err = svc.DeletePractitioner(ctx, "1", suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+
+		Convey("Practitioners cannot be deleted from a missing case", func() {
+			err := svc.DeletePractitioner(ctx, "1", suiteTransactionID)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+	})
+
+	Convey("Appointments", t, func() {
+		svc := newService(t)
+		appointment := &models.AppointmentResourceDao{AppointedOn: "2021-06-06", MadeBy: "creditors"}
+
+		Convey("An appointment is stored against the practitioner", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+
+			So(svc.AppointPractitioner(ctx, appointment, suiteTransactionID, "1"), ShouldBeNil)
+
+			stored, err := svc.GetPractitionerResource(ctx, "1", suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(stored.Appointment, ShouldResemble, appointment)
+
+			Convey("Storing the same appointment again is not an update", func() {
+				err := svc.AppointPractitioner(ctx, appointment, suiteTransactionID, "1")
+				So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			})
+
+			Convey("A deleted appointment is removed from the practitioner", func() {
+				So(svc.DeletePractitionerAppointment(ctx, suiteTransactionID, "1"), ShouldBeNil)
+
+				stored, err := svc.GetPractitionerResource(ctx, "1", suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(stored.Appointment, ShouldBeNil)
+
+				err = svc.DeletePractitionerAppointment(ctx, suiteTransactionID, "1")
+				So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			})
+		})
+
+		Convey("A missing practitioner cannot be appointed", func() {
+			newCase(svc)
+
+			err := svc.AppointPractitioner(ctx, appointment, suiteTransactionID, "1")
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+	})
+
+	Convey("Attachments", t, func() {
+		svc := newService(t)
+
+		Convey("Attachments cannot be added to a missing case", func() {
+			_, err := svc.AddAttachmentToInsolvencyResource(ctx, suiteTransactionID, "file", "resolution")
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+
+			attachments, err := svc.GetAttachmentResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(attachments, ShouldBeNil)
+		})
+
+		Convey("A case without attachments has an empty list", func() {
+			newCase(svc)
+
+			attachments, err := svc.GetAttachmentResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(attachments, ShouldNotBeNil)
+			So(attachments, ShouldBeEmpty)
+		})
+
+		Convey("An added attachment is submitted", func() {
+			newCase(svc)
+
+			attachment, err := svc.AddAttachmentToInsolvencyResource(ctx, suiteTransactionID, "file", "resolution")
+			So(err, ShouldBeNil)
+			So(attachment.Status, ShouldEqual, "submitted")
+			So(attachment.Links.Self, ShouldEqual, "/transactions/"+suiteTransactionID+"/insolvency/attachments/file")
+			So(attachment.Links.Download, ShouldEqual, attachment.Links.Self+"/download")
+
+			stored, err := svc.GetAttachmentFromInsolvencyResource(ctx, suiteTransactionID, "file")
+			So(err, ShouldBeNil)
+			So(stored, ShouldResemble, *attachment)
+
+			attachments, err := svc.GetAttachmentResources(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(attachments, ShouldResemble, []models.AttachmentResourceDao{*attachment})
+
+			Convey("The status is updated until the attachment is processed", func() {
+				So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "integrity_failed"), ShouldBeNil)
+				So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "integrity_failed"), ShouldBeNil)
+				So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "processed"), ShouldBeNil)
+				So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "submitted"), ShouldBeNil)
+
+				stored, err := svc.GetAttachmentFromInsolvencyResource(ctx, suiteTransactionID, "file")
+				So(err, ShouldBeNil)
+				So(stored.Status, ShouldEqual, "processed")
+			})
+
+			Convey("A deleted attachment is removed from the case", func() {
+				So(svc.DeleteAttachmentResource(ctx, suiteTransactionID, "file"), ShouldBeNil)
+
+				stored, err := svc.GetAttachmentFromInsolvencyResource(ctx, suiteTransactionID, "file")
+				So(err, ShouldBeNil)
+				So(stored, ShouldResemble, models.AttachmentResourceDao{})
+
+				err = svc.DeleteAttachmentResource(ctx, suiteTransactionID, "file")
+				So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			})
+		})
+
+		Convey("The status of a missing attachment cannot be updated", func() {
+			newCase(svc)
+
+			err := svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "processed")
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+	})
+
+	Convey("Resolutions, statements of affairs and progress reports", t, func() {
+		svc := newService(t)
+		resolution := &models.ResolutionResourceDao{Etag: "etag", DateOfResolution: "2021-06-06", Attachments: []string{"file"}}
+		statement := &models.StatementOfAffairsResourceDao{Etag: "etag", StatementDate: "2021-06-06", Attachments: []string{"file"}}
+		progressReport := &models.ProgressReportResourceDao{Etag: "etag", FromDate: "2021-06-06", ToDate: "2022-06-05", Attachments: []string{"file"}}
+
+		Convey("They cannot be filed for a missing case", func() {
+			So(svc.CreateResolutionResource(ctx, resolution, suiteTransactionID, ""), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			So(svc.CreateStatementOfAffairsResource(ctx, statement, suiteTransactionID, ""), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			So(svc.CreateProgressReportResource(ctx, progressReport, suiteTransactionID, ""), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			So(svc.DeleteResolutionResource(ctx, suiteTransactionID), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+
+		Convey("A case without them returns empty resources", func() {
+			newCase(svc)
+
+			storedResolution, err := svc.GetResolutionResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedResolution, ShouldResemble, models.ResolutionResourceDao{})
+
+			storedStatement, err := svc.GetStatementOfAffairsResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedStatement, ShouldResemble, models.StatementOfAffairsResourceDao{})
+
+			storedProgressReport, err := svc.GetProgressReportResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedProgressReport, ShouldResemble, &models.ProgressReportResourceDao{})
+
+			So(svc.DeleteStatementOfAffairsResource(ctx, suiteTransactionID), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+
+		Convey("Filed resources can be retrieved and deleted", func() {
+			newCase(svc)
+			So(svc.CreateResolutionResource(ctx, resolution, suiteTransactionID, ""), ShouldBeNil)
+			So(svc.CreateStatementOfAffairsResource(ctx, statement, suiteTransactionID, ""), ShouldBeNil)
+			So(svc.CreateProgressReportResource(ctx, progressReport, suiteTransactionID, ""), ShouldBeNil)
+
+			storedResolution, err := svc.GetResolutionResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedResolution, ShouldResemble, *resolution)
+
+			storedStatement, err := svc.GetStatementOfAffairsResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedStatement, ShouldResemble, *statement)
+
+			storedProgressReport, err := svc.GetProgressReportResource(ctx, suiteTransactionID)
+			So(err, ShouldBeNil)
+			So(storedProgressReport, ShouldResemble, progressReport)
+
+			So(svc.DeleteResolutionResource(ctx, suiteTransactionID), ShouldBeNil)
+			So(svc.DeleteStatementOfAffairsResource(ctx, suiteTransactionID), ShouldBeNil)
+			So(svc.DeleteProgressReportResource(ctx, suiteTransactionID), ShouldBeNil)
+
+			So(svc.DeleteResolutionResource(ctx, suiteTransactionID), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			So(svc.DeleteStatementOfAffairsResource(ctx, suiteTransactionID), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+			So(svc.DeleteProgressReportResource(ctx, suiteTransactionID), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+		})
+		Convey("Filed resources are replaced", func() {
+			newCase(svc)
+			So(svc.CreateResolutionResource(ctx, resolution, suiteTransactionID, ""), ShouldBeNil)
+			So(svc.CreateStatementOfAffairsResource(ctx, statement, suiteTransactionID, ""), ShouldBeNil)
+			So(svc.CreateProgressReportResource(ctx, progressReport, suiteTransactionID, ""), ShouldBeNil)
+
+			replacementResolution := &models.ResolutionResourceDao{Etag: "replacement", DateOfResolution: "2021-07-07", Attachments: []string{"other"}}
+			replacementStatement := &models.StatementOfAffairsResourceDao{Etag: "replacement", StatementDate: "2021-07-07", Attachments: []string{"other"}}
+			replacementProgressReport := &models.ProgressReportResourceDao{Etag: "replacement", FromDate: "2021-07-07", ToDate: "2022-07-06", Attachments: []string{"other"}}
+
+			Convey("without a precondition", func() {
+				So(svc.CreateResolutionResource(ctx, replacementResolution, suiteTransactionID, ""), ShouldBeNil)
+				So(svc.CreateStatementOfAffairsResource(ctx, replacementStatement, suiteTransactionID, ""), ShouldBeNil)
+				So(svc.CreateProgressReportResource(ctx, replacementProgressReport, suiteTransactionID, ""), ShouldBeNil)
+
+				storedResolution, err := svc.GetResolutionResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedResolution, ShouldResemble, *replacementResolution)
+
+				storedStatement, err := svc.GetStatementOfAffairsResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedStatement, ShouldResemble, *replacementStatement)
+
+				storedProgressReport, err := svc.GetProgressReportResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedProgressReport, ShouldResemble, replacementProgressReport)
+			})
+
+			Convey("when the filed resource has the If-Match etag", func() {
+				So(svc.CreateResolutionResource(ctx, replacementResolution, suiteTransactionID, resolution.Etag), ShouldBeNil)
+				So(svc.CreateStatementOfAffairsResource(ctx, replacementStatement, suiteTransactionID, statement.Etag), ShouldBeNil)
+				So(svc.CreateProgressReportResource(ctx, replacementProgressReport, suiteTransactionID, progressReport.Etag), ShouldBeNil)
+
+				storedResolution, err := svc.GetResolutionResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedResolution, ShouldResemble, *replacementResolution)
+			})
+
+			Convey("but not when the filed resource has a different etag", func() {
+				So(svc.CreateResolutionResource(ctx, replacementResolution, suiteTransactionID, "stale"), ShouldHaveSameTypeAs, &apperrors.PreconditionFailedError{})
+				So(svc.CreateStatementOfAffairsResource(ctx, replacementStatement, suiteTransactionID, "stale"), ShouldHaveSameTypeAs, &apperrors.PreconditionFailedError{})
+				So(svc.CreateProgressReportResource(ctx, replacementProgressReport, suiteTransactionID, "stale"), ShouldHaveSameTypeAs, &apperrors.PreconditionFailedError{})
+
+				storedResolution, err := svc.GetResolutionResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedResolution, ShouldResemble, *resolution)
+
+				storedStatement, err := svc.GetStatementOfAffairsResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedStatement, ShouldResemble, *statement)
+
+				storedProgressReport, err := svc.GetProgressReportResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(storedProgressReport, ShouldResemble, progressReport)
+			})
+		})
+	})
+
+	Convey("Idempotency keys", t, func() {
+		svc := newService(t)
+
+		now := time.Now()
+		key := func(hash string, createdAt time.Time) *models.IdempotencyKeyDao {
+			return &models.IdempotencyKeyDao{Key: "user:key", Token: hash + "-token", RequestHash: hash, CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour)}
+		}
+
+		Convey("A new key is reserved and a reserved key is returned", func() {
+			stored, err := svc.ReserveIdempotencyKey(ctx, key("hash", now))
+			So(err, ShouldBeNil)
+			So(stored, ShouldBeNil)
+
+			stored, err = svc.ReserveIdempotencyKey(ctx, key("other-hash", now))
+			So(err, ShouldBeNil)
+			So(stored.RequestHash, ShouldEqual, "hash")
+			So(stored.Response, ShouldBeNil)
+		})
+
+		Convey("The response to a completed key is returned", func() {
+			_, err := svc.ReserveIdempotencyKey(ctx, key("hash", now))
+			So(err, ShouldBeNil)
+
+			response := &models.IdempotentResponseDao{StatusCode: 201, Header: map[string][]string{"Content-Type": {"application/json"}}, Body: []byte(`{"id":"1"}`)}
+			So(svc.CompleteIdempotencyKey(ctx, "user:key", "hash-token", response, now.Add(24*time.Hour)), ShouldBeNil)
+
+			stored, err := svc.ReserveIdempotencyKey(ctx, key("hash", now))
+			So(err, ShouldBeNil)
+			So(stored.Response, ShouldResemble, response)
+		})
+
+		Convey("A completed key is kept after its reservation has expired", func() {
+			_, err := svc.ReserveIdempotencyKey(ctx, key("hash", now))
+			So(err, ShouldBeNil)
+
+			response := &models.IdempotentResponseDao{StatusCode: 201, Header: map[string][]string{}, Body: []byte(`{"id":"1"}`)}
+			So(svc.CompleteIdempotencyKey(ctx, "user:key", "hash-token", response, now.Add(24*time.Hour)), ShouldBeNil)
+
+			stored, err := svc.ReserveIdempotencyKey(ctx, key("hash", now.Add(2*time.Hour)))
+			So(err, ShouldBeNil)
+			So(stored.Response, ShouldResemble, response)
+		})
+
+		Convey("A released or expired key can be reserved again", func() {
+			_, err := svc.ReserveIdempotencyKey(ctx, key("hash", now))
+			So(err, ShouldBeNil)
+			So(svc.ReleaseIdempotencyKey(ctx, "user:key", "hash-token"), ShouldBeNil)
+
+			stored, err := svc.ReserveIdempotencyKey(ctx, key("other-hash", now))
+			So(err, ShouldBeNil)
+			So(stored, ShouldBeNil)
+
+			stored, err = svc.ReserveIdempotencyKey(ctx, key("hash", now.Add(2*time.Hour)))
+			So(err, ShouldBeNil)
+			So(stored, ShouldBeNil)
+		})
+
+		Convey("A request whose reservation has expired cannot complete or release the reservation of a retry", func() {
+			_, err := svc.ReserveIdempotencyKey(ctx, key("stale", now))
+			So(err, ShouldBeNil)
+
+			stored, err := svc.ReserveIdempotencyKey(ctx, key("retry", now.Add(2*time.Hour)))
+			So(err, ShouldBeNil)
+			So(stored, ShouldBeNil)
+
+			response := &models.IdempotentResponseDao{StatusCode: 201, Header: map[string][]string{}, Body: []byte(`{"id":"1"}`)}
+			So(svc.CompleteIdempotencyKey(ctx, "user:key", "stale-token", response, now.Add(24*time.Hour)), ShouldBeNil)
+			So(svc.ReleaseIdempotencyKey(ctx, "user:key", "stale-token"), ShouldBeNil)
+
+			stored, err = svc.ReserveIdempotencyKey(ctx, key("other-hash", now.Add(2*time.Hour)))
+			So(err, ShouldBeNil)
+			So(stored.Token, ShouldEqual, "retry-token")
+			So(stored.RequestHash, ShouldEqual, "retry")
+			So(stored.Response, ShouldBeNil)
+		})
+	})
+
+	Convey("Audit events", t, func() {
+		svc := newService(t)
+
+		start := time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC)
+		event := func(id, transactionID, userID string, minutes int) *models.AuditEventDao {
+			return &models.AuditEventDao{
+				ID:            id,
+				TransactionID: transactionID,
+				CompanyNumber: "company-" + transactionID,
+				UserID:        userID,
+				Email:         userID + "@companieshouse.gov.uk",
+				Route:         "deletePractitioner",
+				Method:        "DELETE",
+				Path:          "/transactions/" + transactionID + "/insolvency/practitioners/1",
+				StatusCode:    204,
+				CreatedAt:     start.Add(time.Duration(minutes) * time.Minute),
+				Changes:       []models.AuditChangeDao{{Path: "data.practitioners[id=1]", Before: `{"id":"1"}`}},
+			}
+		}
+
+		So(svc.CreateAuditEvent(ctx, event("3", "1", "user2", 2)), ShouldBeNil)
+		So(svc.CreateAuditEvent(ctx, event("1", "1", "user1", 0)), ShouldBeNil)
+		So(svc.CreateAuditEvent(ctx, event("2", "2", "user1", 1)), ShouldBeNil)
+
+		ids := func(events []models.AuditEventDao) []string {
+			eventIDs := []string{}
+			for _, e := range events {
+				eventIDs = append(eventIDs, e.ID)
+			}
+			return eventIDs
+		}
+
+		Convey("Events are returned oldest first with the changes they recorded", func() {
+			events, err := svc.GetAuditEvents(ctx, models.AuditEventFilter{})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"1", "2", "3"})
+			So(events[0].CreatedAt.Equal(start), ShouldBeTrue)
+			So(events[0].Email, ShouldEqual, "user1@companieshouse.gov.uk")
+			So(events[0].Changes, ShouldResemble, []models.AuditChangeDao{{Path: "data.practitioners[id=1]", Before: `{"id":"1"}`}})
+		})
+
+		Convey("Events are filtered by case, company, user and time, and limited", func() {
+			events, err := svc.GetAuditEvents(ctx, models.AuditEventFilter{TransactionID: "1"})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"1", "3"})
+
+			events, err = svc.GetAuditEvents(ctx, models.AuditEventFilter{CompanyNumber: "company-2"})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"2"})
+
+			events, err = svc.GetAuditEvents(ctx, models.AuditEventFilter{UserID: "user1", Route: "deletePractitioner"})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"1", "2"})
+
+			events, err = svc.GetAuditEvents(ctx, models.AuditEventFilter{From: start.Add(time.Minute), To: start.Add(2 * time.Minute)})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"2"})
+
+			events, err = svc.GetAuditEvents(ctx, models.AuditEventFilter{Limit: 2})
+			So(err, ShouldBeNil)
+			So(ids(events), ShouldResemble, []string{"1", "2"})
+
+			events, err = svc.GetAuditEvents(ctx, models.AuditEventFilter{UserID: "user3"})
+			So(err, ShouldBeNil)
+			So(events, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Outbox events", t, func() {
+		svc := newService(t)
+
+		types := func(events []models.OutboxEventDao) []string {
+			eventTypes := []string{}
+			for _, e := range events {
+				eventTypes = append(eventTypes, e.Type)
+			}
+			return eventTypes
+		}
+
+		Convey("Changes to a case write their events in the order they were made", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+			appointment := &models.AppointmentResourceDao{AppointedOn: "2021-06-06", MadeBy: "creditors"}
+			So(svc.AppointPractitioner(ctx, appointment, suiteTransactionID, "1"), ShouldBeNil)
+			So(svc.AppointPractitioner(ctx, appointment, suiteTransactionID, "1"), ShouldNotBeNil)
+			_, err := svc.AddAttachmentToInsolvencyResource(ctx, suiteTransactionID, "file", "resolution")
+			So(err, ShouldBeNil)
+			So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "processed"), ShouldBeNil)
+			So(svc.UpdateAttachmentStatus(ctx, suiteTransactionID, "file", "processed"), ShouldBeNil)
+
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(types(events), ShouldResemble, []string{EventCaseCreated, EventPractitionerAppointed, EventAttachmentScanned})
+			So(events[0].TransactionID, ShouldEqual, suiteTransactionID)
+			So(events[0].Data, ShouldContainSubstring, `"company_number":"01234567"`)
+			So(events[1].Data, ShouldContainSubstring, `"practitioner_id":"1"`)
+			So(events[2].Data, ShouldContainSubstring, `"status":"processed"`)
+
+			Convey("Events are limited, and removed once delivered", func() {
+				events, err := svc.GetOutboxEvents(ctx, 2)
+				So(err, ShouldBeNil)
+				So(types(events), ShouldResemble, []string{EventCaseCreated, EventPractitionerAppointed})
+
+				So(svc.DeleteOutboxEvent(ctx, suiteTransactionID, events[0].ID), ShouldBeNil)
+				So(svc.DeleteOutboxEvent(ctx, suiteTransactionID, events[0].ID), ShouldBeNil)
+
+				events, err = svc.GetOutboxEvents(ctx, 0)
+				So(err, ShouldBeNil)
+				So(types(events), ShouldResemble, []string{EventPractitionerAppointed, EventAttachmentScanned})
+
+				// Removing the events does not change the case
+				insolvencyResource, err := svc.GetInsolvencyResource(ctx, suiteTransactionID)
+				So(err, ShouldBeNil)
+				So(insolvencyResource.Data.Practitioners[0].Appointment, ShouldResemble, appointment)
+			})
+
+			Convey("An event that does not accompany a change is written to the outbox of its case", func() {
+				event, err := NewOutboxEvent(EventFilingsGenerated, suiteTransactionID, map[string][]string{"filings": {"insolvency#600"}})
+				So(err, ShouldBeNil)
+				So(svc.CreateOutboxEvent(ctx, event), ShouldBeNil)
+
+				events, err := svc.GetOutboxEvents(ctx, 0)
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 4)
+				So(events[3].ID, ShouldEqual, event.ID)
+				So(events[3].CreatedAt.Equal(event.CreatedAt), ShouldBeTrue)
+				So(events[3].Data, ShouldEqual, `{"filings":["insolvency#600"]}`)
+			})
+
+			Convey("An event is only written once for each ID, even once it has been delivered", func() {
+				event, err := FilingsGeneratedEvent(suiteTransactionID, []string{"insolvency#600"})
+				So(err, ShouldBeNil)
+				So(event.ID, ShouldEqual, "filings-generated-"+suiteTransactionID)
+				So(svc.CreateOutboxEvent(ctx, event), ShouldBeNil)
+				So(svc.CreateOutboxEvent(ctx, event), ShouldBeNil)
+
+				events, err := svc.GetOutboxEvents(ctx, 0)
+				So(err, ShouldBeNil)
+				So(types(events), ShouldResemble, []string{EventCaseCreated, EventPractitionerAppointed, EventAttachmentScanned, EventFilingsGenerated})
+
+				So(svc.DeleteOutboxEvent(ctx, suiteTransactionID, event.ID), ShouldBeNil)
+				So(svc.CreateOutboxEvent(ctx, event), ShouldBeNil)
+
+				events, err = svc.GetOutboxEvents(ctx, 0)
+				So(err, ShouldBeNil)
+				So(types(events), ShouldResemble, []string{EventCaseCreated, EventPractitionerAppointed, EventAttachmentScanned})
+			})
+		})
+
+		Convey("A case created with its practitioners appointed writes an event for each appointment", func() {
+			appointed := practitioner("1")
+			appointed.Appointment = &models.AppointmentResourceDao{AppointedOn: "2021-06-06", MadeBy: "creditors"}
+			err := svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{
+				TransactionID: suiteTransactionID,
+				Data: models.InsolvencyResourceDaoData{
+					CompanyNumber: "01234567",
+					CaseType:      "creditors-voluntary-liquidation",
+					CompanyName:   "company",
+					Practitioners: []models.PractitionerResourceDao{*appointed, *practitioner("2")},
+				},
+			})
+			So(err, ShouldBeNil)
+
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(types(events), ShouldResemble, []string{EventCaseCreated, EventPractitionerAppointed})
+			So(events[1].TransactionID, ShouldEqual, suiteTransactionID)
+			So(events[1].Data, ShouldContainSubstring, `"practitioner_id":"1"`)
+			So(events[1].Data, ShouldContainSubstring, `"appointed_on":"2021-06-06"`)
+		})
+
+		Convey("The outbox of a case is delivered by one claimant at a time", func() {
+			newCase(svc)
+			So(svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{
+				TransactionID: "transaction-2",
+				Data:          models.InsolvencyResourceDaoData{CompanyNumber: "07654321", CaseType: "creditors-voluntary-liquidation"},
+			}), ShouldBeNil)
+			now := time.Now()
+			transactionIDs := func(events []models.OutboxEventDao) []string {
+				ids := []string{}
+				for _, e := range events {
+					ids = append(ids, e.TransactionID)
+				}
+				return ids
+			}
+
+			claimed, err := svc.ClaimOutboxEvents(ctx, "relay1", now, time.Minute, 1)
+			So(err, ShouldBeNil)
+			So(transactionIDs(claimed), ShouldResemble, []string{suiteTransactionID})
+
+			claimed, err = svc.ClaimOutboxEvents(ctx, "relay2", now, time.Minute, 10)
+			So(err, ShouldBeNil)
+			So(transactionIDs(claimed), ShouldResemble, []string{"transaction-2"})
+
+			claimed, err = svc.ClaimOutboxEvents(ctx, "relay3", now, time.Minute, 10)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeEmpty)
+
+			Convey("A claim is only released by its claimant", func() {
+				So(svc.ReleaseOutboxClaim(ctx, suiteTransactionID, "relay2"), ShouldBeNil)
+				claimed, err := svc.ClaimOutboxEvents(ctx, "relay3", now, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(claimed, ShouldBeEmpty)
+
+				So(svc.ReleaseOutboxClaim(ctx, suiteTransactionID, "relay1"), ShouldBeNil)
+				claimed, err = svc.ClaimOutboxEvents(ctx, "relay3", now, time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(transactionIDs(claimed), ShouldResemble, []string{suiteTransactionID})
+			})
+
+			Convey("An expired claim can be taken by another claimant", func() {
+				claimed, err := svc.ClaimOutboxEvents(ctx, "relay3", now.Add(2*time.Minute), time.Minute, 10)
+				So(err, ShouldBeNil)
+				So(transactionIDs(claimed), ShouldResemble, []string{suiteTransactionID, "transaction-2"})
+			})
+		})
+
+		Convey("An event is parked once its deliveries have failed the maximum times", func() {
+			newCase(svc)
+			So(svc.CreatePractitionersResource(ctx, practitioner("1"), suiteTransactionID), ShouldBeNil)
+			So(svc.AppointPractitioner(ctx, &models.AppointmentResourceDao{AppointedOn: "2021-06-06", MadeBy: "creditors"}, suiteTransactionID, "1"), ShouldBeNil)
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			created := events[0]
+
+			parked, err := svc.FailOutboxEvent(ctx, suiteTransactionID, created.ID, 2)
+			So(err, ShouldBeNil)
+			So(parked, ShouldBeFalse)
+			parked, err = svc.FailOutboxEvent(ctx, suiteTransactionID, created.ID, 2)
+			So(err, ShouldBeNil)
+			So(parked, ShouldBeTrue)
+			parked, err = svc.FailOutboxEvent(ctx, suiteTransactionID, created.ID, 2)
+			So(err, ShouldBeNil)
+			So(parked, ShouldBeFalse)
+
+			// The parked event is kept in the outbox, but the later events of its case are claimed without it
+			events, err = svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(events, ShouldHaveLength, 2)
+			So(events[0].Parked, ShouldBeTrue)
+			So(events[0].Attempts, ShouldEqual, 2)
+
+			claimed, err := svc.ClaimOutboxEvents(ctx, "relay", time.Now(), time.Minute, 10)
+			So(err, ShouldBeNil)
+			So(types(claimed), ShouldResemble, []string{EventPractitionerAppointed})
+			So(claimed[0].Attempts, ShouldEqual, 0)
+		})
+
+		Convey("An event cannot be written for a missing case", func() {
+			event, err := NewOutboxEvent(EventFilingsGenerated, suiteTransactionID, nil)
+			So(err, ShouldBeNil)
+
+			err = svc.CreateOutboxEvent(ctx, event)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(events, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,1402 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	// Registers the "sqlite" driver, which needs no external server
+	_ "modernc.org/sqlite"
+)
+
+// SQLService is an implementation of the Service interface that stores insolvency cases in a relational database
+// through database/sql, using the schema created by the migrations in the migrations directory. It follows the
+// same semantics and returns the same errors as the MongoService
+type SQLService struct {
+	db               *sql.DB
+	driverName       string
+	OperationTimeout time.Duration
+}
+
+// datedResource holds the columns of a resolution, statement of affairs or progress report
+type datedResource struct {
+	etag        string
+	kind        string
+	date        string
+	toDate      string
+	self        string
+	attachments []string
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx, so that cases can be read inside or outside a transaction
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// NewSQLService opens the database with the named driver and data source, brings its schema up to date and returns
+// an SQLService that stores insolvency cases in it
+func NewSQLService(driverName, dataSourceName string) (*SQLService, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sql database: [%v]", err)
+	}
+
+	if driverName == "sqlite" {
+		// SQLite allows a single writer, so use a single connection rather than fail concurrent requests as busy.
+		// This also keeps an in-memory database, as each connection to ":memory:" opens a separate database
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error enabling sqlite foreign keys: [%v]", err)
+		}
+	}
+
+	s := &SQLService{
+		db:               db,
+		driverName:       driverName,
+		OperationTimeout: defaultOperationTimeout,
+	}
+
+	if err := s.Migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// rebind replaces the ? placeholders in the query with the numbered placeholders used by PostgreSQL drivers
+func (s *SQLService) rebind(query string) string {
+	if s.driverName != "postgres" && s.driverName != "pgx" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// operationContext returns the context for a single SQLService operation, cancelled when the request context is
+// cancelled or the operation timeout has passed
+func (s *SQLService) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.OperationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.OperationTimeout)
+}
+
+// inTransaction runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise
+func (s *SQLService) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sqlError returns the error to give callers of the Service. Errors already describing the outcome of the
+// operation are returned unchanged, while errors from the database are logged and wrapped
+func sqlError(transactionID string, err error) error {
+	switch err.(type) {
+	case nil, *apperrors.NotFoundError, *apperrors.ConflictError, *apperrors.ValidationError, *apperrors.PreconditionFailedError:
+		return err
+	}
+
+	log.Error(err)
+	return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+}
+
+// update loads the insolvency case with the specified transactionID in a transaction and passes it to fn, along
+// with whether it exists. The case is locked first, so that concurrent updates to the same case are applied one
+// at a time
+func (s *SQLService) update(ctx context.Context, transactionID string, fn func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, s.rebind("UPDATE cases SET etag = etag WHERE transaction_id = ?"), transactionID); err != nil {
+			return err
+		}
+
+		insolvencyResource, ok, err := s.loadCase(ctx, tx, transactionID)
+		if err != nil {
+			return err
+		}
+
+		return fn(tx, &insolvencyResource, ok)
+	})
+
+	return sqlError(transactionID, err)
+}
+
+// getCase returns the insolvency case with the specified transactionID and whether it exists
+func (s *SQLService) getCase(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, bool, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	insolvencyResource, ok, err := s.loadCase(ctx, s.db, transactionID)
+	if err != nil {
+		return models.InsolvencyResourceDao{}, false, sqlError(transactionID, err)
+	}
+	if !ok {
+		log.Debug(constants.MsgCaseNotFound, log.Data{"transaction_id": transactionID})
+	}
+
+	return insolvencyResource, ok, nil
+}
+
+// loadCase reads the insolvency case with the specified transactionID from each of the tables it is stored in.
+// Each query is read to the end before the next is made, as a transaction or SQLite database has one connection
+func (s *SQLService) loadCase(ctx context.Context, q queryer, transactionID string) (models.InsolvencyResourceDao, bool, error) {
+	insolvencyResource := models.InsolvencyResourceDao{TransactionID: transactionID}
+
+	var id string
+	err := q.QueryRowContext(ctx, s.rebind(`SELECT id, etag, kind, company_number, case_type, company_name, self_link,
+		transaction_link, validation_status_link, created_by, firm_id FROM cases WHERE transaction_id = ?`), transactionID).Scan(
+		&id, &insolvencyResource.Etag, &insolvencyResource.Kind, &insolvencyResource.Data.CompanyNumber,
+		&insolvencyResource.Data.CaseType, &insolvencyResource.Data.CompanyName, &insolvencyResource.Links.Self,
+		&insolvencyResource.Links.Transaction, &insolvencyResource.Links.ValidationStatus,
+		&insolvencyResource.CreatedBy, &insolvencyResource.FirmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.InsolvencyResourceDao{}, false, nil
+	}
+	if err != nil {
+		return models.InsolvencyResourceDao{}, false, err
+	}
+
+	if insolvencyResource.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return models.InsolvencyResourceDao{}, false, err
+	}
+
+	if insolvencyResource.Data.Practitioners, err = s.loadPractitioners(ctx, q, transactionID); err != nil {
+		return models.InsolvencyResourceDao{}, false, err
+	}
+
+	if insolvencyResource.Data.Attachments, err = s.loadAttachments(ctx, q, transactionID); err != nil {
+		return models.InsolvencyResourceDao{}, false, err
+	}
+
+	if err = s.loadDatedResources(ctx, q, transactionID, &insolvencyResource.Data); err != nil {
+		return models.InsolvencyResourceDao{}, false, err
+	}
+
+	return insolvencyResource, true, nil
+}
+
+// loadPractitioners reads the practitioners on a case, with their appointments, in the order they were added
+func (s *SQLService) loadPractitioners(ctx context.Context, q queryer, transactionID string) ([]models.PractitionerResourceDao, error) {
+	rows, err := q.QueryContext(ctx, s.rebind(`SELECT p.id, p.ip_code, p.first_name, p.last_name, p.telephone_number,
+		p.email, p.premises, p.address_line_1, p.address_line_2, p.country, p.locality, p.region, p.postal_code,
+		p.po_box, p.role, p.self_link, p.user_id, a.appointed_on, a.made_by, a.self_link
+		FROM practitioners p LEFT JOIN appointments a
+		ON a.transaction_id = p.transaction_id AND a.practitioner_id = p.id
+		WHERE p.transaction_id = ? ORDER BY p.position`), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var practitioners []models.PractitionerResourceDao
+	for rows.Next() {
+		var p models.PractitionerResourceDao
+		var appointedOn, madeBy, appointmentSelf sql.NullString
+		err := rows.Scan(&p.ID, &p.IPCode, &p.FirstName, &p.LastName, &p.TelephoneNumber, &p.Email,
+			&p.Address.Premises, &p.Address.AddressLine1, &p.Address.AddressLine2, &p.Address.Country,
+			&p.Address.Locality, &p.Address.Region, &p.Address.PostalCode, &p.Address.POBox, &p.Role,
+			&p.Links.Self, &p.UserID, &appointedOn, &madeBy, &appointmentSelf)
+		if err != nil {
+			return nil, err
+		}
+
+		if appointedOn.Valid {
+			p.Appointment = &models.AppointmentResourceDao{
+				AppointedOn: appointedOn.String,
+				MadeBy:      madeBy.String,
+				Links:       models.AppointmentResourceLinksDao{Self: appointmentSelf.String},
+			}
+		}
+
+		practitioners = append(practitioners, p)
+	}
+
+	return practitioners, rows.Err()
+}
+
+// loadAttachments reads the attachments for a case in the order they were added
+func (s *SQLService) loadAttachments(ctx context.Context, q queryer, transactionID string) ([]models.AttachmentResourceDao, error) {
+	rows, err := q.QueryContext(ctx, s.rebind(`SELECT id, type, status, self_link, download_link
+		FROM attachments WHERE transaction_id = ? ORDER BY position`), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.AttachmentResourceDao
+	for rows.Next() {
+		var a models.AttachmentResourceDao
+		if err := rows.Scan(&a.ID, &a.Type, &a.Status, &a.Links.Self, &a.Links.Download); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// loadDatedResources reads the resolution, statement of affairs and progress report filed for a case into data
+func (s *SQLService) loadDatedResources(ctx context.Context, q queryer, transactionID string, data *models.InsolvencyResourceDaoData) error {
+	resources, err := s.loadDatedResourceRows(ctx, q, transactionID)
+	if err != nil || len(resources) == 0 {
+		return err
+	}
+
+	rows, err := q.QueryContext(ctx, s.rebind(`SELECT type, attachment_id FROM dated_resource_attachments
+		WHERE transaction_id = ? ORDER BY type, position`), transactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var resType, attachmentID string
+		if err := rows.Scan(&resType, &attachmentID); err != nil {
+			return err
+		}
+		if r, ok := resources[resType]; ok {
+			r.attachments = append(r.attachments, attachmentID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if r, ok := resources[resolutionType]; ok {
+		data.Resolution = &models.ResolutionResourceDao{
+			Etag:             r.etag,
+			Kind:             r.kind,
+			DateOfResolution: r.date,
+			Attachments:      r.attachments,
+			Links:            models.ResolutionResourceLinksDao{Self: r.self},
+		}
+	}
+	if r, ok := resources[statementOfAffairsType]; ok {
+		data.StatementOfAffairs = &models.StatementOfAffairsResourceDao{
+			Etag:          r.etag,
+			Kind:          r.kind,
+			StatementDate: r.date,
+			Attachments:   r.attachments,
+			Links:         models.StatementOfAffairsResourceLinksDao{Self: r.self},
+		}
+	}
+	if r, ok := resources[progressReportType]; ok {
+		data.ProgressReport = &models.ProgressReportResourceDao{
+			FromDate:    r.date,
+			ToDate:      r.toDate,
+			Attachments: r.attachments,
+			Etag:        r.etag,
+			Kind:        r.kind,
+			Links:       models.ProgressReportResourceLinksDao{Self: r.self},
+		}
+	}
+
+	return nil
+}
+
+// loadDatedResourceRows reads the dated resources filed for a case, by type
+func (s *SQLService) loadDatedResourceRows(ctx context.Context, q queryer, transactionID string) (map[string]*datedResource, error) {
+	rows, err := q.QueryContext(ctx, s.rebind(`SELECT type, etag, kind, date, to_date, self_link
+		FROM dated_resources WHERE transaction_id = ?`), transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := make(map[string]*datedResource)
+	for rows.Next() {
+		var resType string
+		var r datedResource
+		if err := rows.Scan(&resType, &r.etag, &r.kind, &r.date, &r.toDate, &r.self); err != nil {
+			return nil, err
+		}
+		resources[resType] = &r
+	}
+
+	return resources, rows.Err()
+}
+
+// insertCase writes the insolvency case and everything it holds
+func (s *SQLService) insertCase(ctx context.Context, tx *sql.Tx, dao *models.InsolvencyResourceDao) error {
+	_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO cases (transaction_id, id, etag, kind, company_number,
+		case_type, company_name, self_link, transaction_link, validation_status_link, created_by, firm_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		dao.TransactionID, dao.ID.Hex(), dao.Etag, dao.Kind, dao.Data.CompanyNumber, dao.Data.CaseType,
+		dao.Data.CompanyName, dao.Links.Self, dao.Links.Transaction, dao.Links.ValidationStatus, dao.CreatedBy,
+		dao.FirmID)
+	if err != nil {
+		return err
+	}
+
+	for i := range dao.Data.Practitioners {
+		if err := s.insertPractitioner(ctx, tx, dao.TransactionID, i+1, &dao.Data.Practitioners[i]); err != nil {
+			return err
+		}
+	}
+
+	for i, attachment := range dao.Data.Attachments {
+		if err := s.insertAttachment(ctx, tx, dao.TransactionID, i+1, attachment); err != nil {
+			return err
+		}
+	}
+
+	if r := dao.Data.Resolution; r != nil {
+		err := s.insertDatedResource(ctx, tx, dao.TransactionID, resolutionType, datedResource{
+			etag: r.Etag, kind: r.Kind, date: r.DateOfResolution, self: r.Links.Self, attachments: r.Attachments,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if r := dao.Data.StatementOfAffairs; r != nil {
+		err := s.insertDatedResource(ctx, tx, dao.TransactionID, statementOfAffairsType, datedResource{
+			etag: r.Etag, kind: r.Kind, date: r.StatementDate, self: r.Links.Self, attachments: r.Attachments,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if r := dao.Data.ProgressReport; r != nil {
+		err := s.insertDatedResource(ctx, tx, dao.TransactionID, progressReportType, datedResource{
+			etag: r.Etag, kind: r.Kind, date: r.FromDate, toDate: r.ToDate, self: r.Links.Self, attachments: r.Attachments,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertPractitioner writes the practitioner, and their appointment if they have one, at the position given
+func (s *SQLService) insertPractitioner(ctx context.Context, tx *sql.Tx, transactionID string, position int, p *models.PractitionerResourceDao) error {
+	_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO practitioners (transaction_id, id, position, ip_code,
+		first_name, last_name, telephone_number, email, premises, address_line_1, address_line_2, country,
+		locality, region, postal_code, po_box, role, self_link, user_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		transactionID, p.ID, position, p.IPCode, p.FirstName, p.LastName, p.TelephoneNumber, p.Email,
+		p.Address.Premises, p.Address.AddressLine1, p.Address.AddressLine2, p.Address.Country, p.Address.Locality,
+		p.Address.Region, p.Address.PostalCode, p.Address.POBox, p.Role, p.Links.Self, p.UserID)
+	if err != nil || p.Appointment == nil {
+		return err
+	}
+
+	return s.insertAppointment(ctx, tx, transactionID, p.ID, p.Appointment)
+}
+
+// insertAppointment writes the appointment of the practitioner with the specified practitionerID
+func (s *SQLService) insertAppointment(ctx context.Context, tx *sql.Tx, transactionID string, practitionerID string, a *models.AppointmentResourceDao) error {
+	_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO appointments (transaction_id, practitioner_id, appointed_on,
+		made_by, self_link) VALUES (?, ?, ?, ?, ?)`),
+		transactionID, practitionerID, a.AppointedOn, a.MadeBy, a.Links.Self)
+	return err
+}
+
+// insertAttachment writes the attachment at the position given
+func (s *SQLService) insertAttachment(ctx context.Context, tx *sql.Tx, transactionID string, position int, a models.AttachmentResourceDao) error {
+	_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO attachments (transaction_id, id, position, type, status,
+		self_link, download_link) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		transactionID, a.ID, position, a.Type, a.Status, a.Links.Self, a.Links.Download)
+	return err
+}
+
+// insertDatedResource writes a dated resource of the type resType and the IDs of the attachments it was filed with
+func (s *SQLService) insertDatedResource(ctx context.Context, tx *sql.Tx, transactionID string, resType string, r datedResource) error {
+	_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO dated_resources (transaction_id, type, etag, kind, date,
+		to_date, self_link) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		transactionID, resType, r.etag, r.kind, r.date, r.toDate, r.self)
+	if err != nil {
+		return err
+	}
+
+	for i, attachmentID := range r.attachments {
+		_, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO dated_resource_attachments (transaction_id, type, position,
+			attachment_id) VALUES (?, ?, ?, ?)`), transactionID, resType, i+1, attachmentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertOutboxEvent writes the event after the last event in the outbox of its case
+func (s *SQLService) insertOutboxEvent(ctx context.Context, tx *sql.Tx, e *models.OutboxEventDao) error {
+	position, err := s.nextPosition(ctx, tx, "outbox_events", e.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO outbox_events (id, transaction_id, position, type, created_at,
+		data) VALUES (?, ?, ?, ?, ?, ?)`),
+		e.ID, e.TransactionID, position, e.Type, e.CreatedAt.UnixMilli(), e.Data)
+	return err
+}
+
+// nextPosition returns the position after the last practitioner, attachment or outbox event on a case
+func (s *SQLService) nextPosition(ctx context.Context, tx *sql.Tx, table string, transactionID string) (int, error) {
+	var position int
+	err := tx.QueryRowContext(ctx, s.rebind("SELECT COALESCE(MAX(position), 0) + 1 FROM "+table+" WHERE transaction_id = ?"), transactionID).Scan(&position)
+	return position, err
+}
+
+// CreateInsolvencyResource will store the insolvency request in the database
+func (s *SQLService) CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	dao.ID = primitive.NewObjectID()
+
+	events, err := caseCreatedEvents(dao)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem creating an insolvency case for this transaction id: %v", err), err)
+	}
+
+	err = s.inTransaction(ctx, func(tx *sql.Tx) error {
+		if err := s.insertCase(ctx, tx, dao); err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := s.insertOutboxEvent(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	// The insert fails on the primary key when a case already exists for the transaction, which drivers report
+	// differently, so check for the case rather than the error
+	if _, ok, loadErr := s.loadCase(ctx, s.db, dao.TransactionID); loadErr == nil && ok {
+		log.Info("an insolvency case already exists for this transaction id")
+		return apperrors.Conflict("an insolvency case already exists for this transaction id")
+	}
+
+	log.Error(err)
+	return newDatabaseError(fmt.Sprintf("there was a problem creating an insolvency case for this transaction id: %v", err), err)
+}
+
+// GetInsolvencyResource retrieves all the data for an insolvency case with the specified transactionID
+func (s *SQLService) GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	insolvencyResource, ok, err := s.getCase(ctx, transactionID)
+	if err != nil {
+		return models.InsolvencyResourceDao{}, err
+	}
+	if !ok {
+		return models.InsolvencyResourceDao{}, apperrors.NotFound("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)
+	}
+
+	return insolvencyResource, nil
+}
+
+// CreatePractitionersResource stores an incoming practitioner to the list of practitioners for the insolvency case
+// with the specified transactionID
+func (s *SQLService) CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+			return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
+		}
+
+		// Check if practitioner is already assigned to this case
+		for _, storedPractitioner := range insolvencyResource.Data.Practitioners {
+			if dao.IPCode == storedPractitioner.IPCode {
+				err := apperrors.Conflict(constants.MsgPractitionerAlreadyAssigned, transactionID, dao.IPCode, storedPractitioner.ID)
+				log.Error(err)
+				return err
+			}
+		}
+
+		// Check if there are already 5 practitioners in the case
+		if len(insolvencyResource.Data.Practitioners) >= maxPractitioners {
+			err := apperrors.Validation("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID)
+			log.Error(err)
+			return err
+		}
+
+		position, err := s.nextPosition(ctx, tx, "practitioners", transactionID)
+		if err != nil {
+			return err
+		}
+
+		return s.insertPractitioner(ctx, tx, transactionID, position, dao)
+	})
+}
+
+// GetPractitionerResources gets a list of all practitioners for an insolvency case with the specified transactionID
+func (s *SQLService) GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error) {
+	insolvencyResource, ok, err := s.getCase(ctx, transactionID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// Return an empty array instead of nil so the handler can check
+	// that there are no practitioners
+	if insolvencyResource.Data.Practitioners == nil {
+		return make([]models.PractitionerResourceDao, 0), nil
+	}
+
+	return insolvencyResource.Data.Practitioners, nil
+}
+
+// GetPractitionerResource gets a single practitioner for an insolvency case with the specified transactionID and practitionerID
+func (s *SQLService) GetPractitionerResource(ctx context.Context, practitionerID string, transactionID string) (models.PractitionerResourceDao, error) {
+	insolvencyResource, _, err := s.getCase(ctx, transactionID)
+	if err != nil {
+		return models.PractitionerResourceDao{}, err
+	}
+
+	for _, practitioner := range insolvencyResource.Data.Practitioners {
+		if practitioner.ID == practitionerID {
+			return practitioner, nil
+		}
+	}
+
+	return models.PractitionerResourceDao{}, nil
+}
+
+// DeletePractitioner deletes a practitioner for an insolvency case with the specified transactionID and practitionerID
+func (s *SQLService) DeletePractitioner(ctx context.Context, practitionerID string, transactionID string) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			err := apperrors.NotFound("there was a problem handling your request for transaction id %s - insolvency case not found", transactionID)
+			log.Error(err)
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM appointments WHERE transaction_id = ? AND practitioner_id = ?"), transactionID, practitionerID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, s.rebind("DELETE FROM practitioners WHERE transaction_id = ? AND id = ?"), transactionID, practitionerID)
+		if err != nil {
+			return err
+		}
+
+		// Return error if no practitioner was removed
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			err = apperrors.NotFound("there was a problem handling your request for transaction id %s - practitioner with id %s not found", transactionID, practitionerID)
+			log.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// AppointPractitioner adds appointment details insolvency case with the specified transactionID and practitionerID
+func (s *SQLService) AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID string, practitionerID string) error {
+	event, err := practitionerAppointedEvent(dao, transactionID, practitionerID)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	return s.updatePractitioner(ctx, transactionID, practitionerID, func(tx *sql.Tx, practitioner *models.PractitionerResourceDao) (bool, error) {
+		if practitioner.Appointment != nil && *practitioner.Appointment == *dao {
+			return false, nil
+		}
+
+		_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM appointments WHERE transaction_id = ? AND practitioner_id = ?"), transactionID, practitionerID)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.insertAppointment(ctx, tx, transactionID, practitionerID, dao); err != nil {
+			return false, err
+		}
+		return true, s.insertOutboxEvent(ctx, tx, event)
+	})
+}
+
+// DeletePractitionerAppointment deletes an appointment for the specified transactionID and practitionerID
+func (s *SQLService) DeletePractitionerAppointment(ctx context.Context, transactionID string, practitionerID string) error {
+	return s.updatePractitioner(ctx, transactionID, practitionerID, func(tx *sql.Tx, practitioner *models.PractitionerResourceDao) (bool, error) {
+		if practitioner.Appointment == nil {
+			return false, nil
+		}
+
+		_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM appointments WHERE transaction_id = ? AND practitioner_id = ?"), transactionID, practitionerID)
+		return true, err
+	})
+}
+
+// updatePractitioner applies the update to the practitioner with the specified transactionID and practitionerID.
+// The update returns false if it left the practitioner unchanged
+func (s *SQLService) updatePractitioner(ctx context.Context, transactionID string, practitionerID string, update func(*sql.Tx, *models.PractitionerResourceDao) (bool, error)) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		for i := range insolvencyResource.Data.Practitioners {
+			if insolvencyResource.Data.Practitioners[i].ID != practitionerID {
+				continue
+			}
+
+			updated, err := update(tx, &insolvencyResource.Data.Practitioners[i])
+			if err != nil {
+				return err
+			}
+			// Check if the practitioner was changed
+			if !updated {
+				err = apperrors.NotFound("item with transaction id %s or practitioner id %s not updated", transactionID, practitionerID)
+				log.Error(err)
+				return err
+			}
+			return nil
+		}
+
+		err := apperrors.NotFound("item with transaction id %s or practitioner id %s does not exist", transactionID, practitionerID)
+		log.Error(err)
+		return err
+	})
+}
+
+// AddAttachmentToInsolvencyResource adds an attachment to the insolvency case with the specified transactionID
+func (s *SQLService) AddAttachmentToInsolvencyResource(ctx context.Context, transactionID string, fileID string, attachmentType string) (*models.AttachmentResourceDao, error) {
+	attachmentDao := models.AttachmentResourceDao{
+		ID:     fileID,
+		Type:   attachmentType,
+		Status: "submitted",
+		Links: models.AttachmentResourceLinksDao{
+			Self:     constants.TransactionsPath + transactionID + constants.AttachmentsPath + fileID,
+			Download: constants.TransactionsPath + transactionID + constants.AttachmentsPath + fileID + "/download",
+		},
+	}
+
+	err := s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			return apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		}
+
+		position, err := s.nextPosition(ctx, tx, "attachments", transactionID)
+		if err != nil {
+			return err
+		}
+
+		return s.insertAttachment(ctx, tx, transactionID, position, attachmentDao)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachmentDao, nil
+}
+
+// GetAttachmentResources retrieves all attachments filed for an Insolvency Case
+func (s *SQLService) GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error) {
+	insolvencyResource, ok, err := s.getCase(ctx, transactionID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	// Return an empty array instead of nil to distinguish from insolvency case
+	// not found
+	if insolvencyResource.Data.Attachments == nil {
+		return make([]models.AttachmentResourceDao, 0), nil
+	}
+
+	return insolvencyResource.Data.Attachments, nil
+}
+
+// GetAttachmentFromInsolvencyResource retrieves an attachment filed for an Insolvency Case
+func (s *SQLService) GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID string, fileID string) (models.AttachmentResourceDao, error) {
+	insolvencyResource, _, err := s.getCase(ctx, transactionID)
+	if err != nil {
+		return models.AttachmentResourceDao{}, err
+	}
+
+	for _, attachment := range insolvencyResource.Data.Attachments {
+		if attachment.ID == fileID {
+			return attachment, nil
+		}
+	}
+
+	return models.AttachmentResourceDao{}, nil
+}
+
+// DeleteAttachmentResource deletes an attachment filed for an Insolvency Case
+func (s *SQLService) DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+			log.Error(err)
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, s.rebind("DELETE FROM attachments WHERE transaction_id = ? AND id = ?"), transactionID, attachmentID)
+		if err != nil {
+			return err
+		}
+
+		// Return error if no attachment was removed
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			err = apperrors.NotFound("there was a problem handling your request for transaction id [%s] - attachment with id [%s] not found", transactionID, attachmentID)
+			log.Error(err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// UpdateAttachmentStatus updates the status of an attachment filed for an Insolvency Case. The status of an
+// attachment that has been processed is left unchanged
+func (s *SQLService) UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID string, avStatus string) error {
+	event, err := attachmentScannedEvent(transactionID, attachmentID, avStatus)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf(constants.MsgHandleReqTransactionId, transactionID), err)
+	}
+
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		for _, attachment := range insolvencyResource.Data.Attachments {
+			if attachment.ID != attachmentID {
+				continue
+			}
+			if attachment.Status == "processed" || attachment.Status == avStatus {
+				return nil
+			}
+
+			_, err := tx.ExecContext(ctx, s.rebind("UPDATE attachments SET status = ? WHERE transaction_id = ? AND id = ?"), avStatus, transactionID, attachmentID)
+			if err != nil {
+				return err
+			}
+			return s.insertOutboxEvent(ctx, tx, event)
+		}
+
+		err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		log.Error(err)
+		return err
+	})
+}
+
+// CreateResolutionResource stores the resolution for the insolvency case
+// with the specified transactionID
+func (s *SQLService) CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error {
+	return s.createCaseResource(ctx, transactionID, resolutionType, ifMatch, datedResource{
+		etag: dao.Etag, kind: dao.Kind, date: dao.DateOfResolution, self: dao.Links.Self, attachments: dao.Attachments,
+	})
+}
+
+// CreateStatementOfAffairsResource stores the statement of affairs resource for the insolvency case
+// with the specified transactionID
+func (s *SQLService) CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error {
+	return s.createCaseResource(ctx, transactionID, statementOfAffairsType, ifMatch, datedResource{
+		etag: dao.Etag, kind: dao.Kind, date: dao.StatementDate, self: dao.Links.Self, attachments: dao.Attachments,
+	})
+}
+
+// CreateProgressReportResource stores the progress report resource for the insolvency case
+// with the specified transactionID
+func (s *SQLService) CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error {
+	return s.createCaseResource(ctx, transactionID, progressReportType, ifMatch, datedResource{
+		etag: dao.Etag, kind: dao.Kind, date: dao.FromDate, toDate: dao.ToDate, self: dao.Links.Self, attachments: dao.Attachments,
+	})
+}
+
+// createCaseResource files a dated resource of the type resType for the insolvency case with the specified
+// transactionID, replacing any already filed. When ifMatch is not empty, the resource is only replaced if the filed
+// resource has that etag
+func (s *SQLService) createCaseResource(ctx context.Context, transactionID string, resType string, ifMatch string, r datedResource) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			log.Debug(constants.MsgResourceNotFound, log.Data{"transaction_id": transactionID})
+			return apperrors.NotFound(constants.MsgReqTransactionNotFound, transactionID)
+		}
+
+		etag, filed := filedResourceEtag(&insolvencyResource.Data, resType)
+		if ifMatch != "" && (!filed || etag != ifMatch) {
+			return etagMismatchError(transactionID, resType, ifMatch)
+		}
+
+		if filed {
+			if err := s.deleteDatedResource(ctx, tx, transactionID, resType); err != nil {
+				return err
+			}
+		}
+
+		return s.insertDatedResource(ctx, tx, transactionID, resType, r)
+	})
+}
+
+// GetStatementOfAffairsResource retrieves the statement of affairs filed for an Insolvency Case
+func (s *SQLService) GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error) {
+	insolvencyResource, _, err := s.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.StatementOfAffairs == nil {
+		return models.StatementOfAffairsResourceDao{}, err
+	}
+
+	return *insolvencyResource.Data.StatementOfAffairs, nil
+}
+
+// GetResolutionResource retrieves the resolution filed for an Insolvency Case
+func (s *SQLService) GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error) {
+	insolvencyResource, _, err := s.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.Resolution == nil {
+		return models.ResolutionResourceDao{}, err
+	}
+
+	return *insolvencyResource.Data.Resolution, nil
+}
+
+// GetProgressReportResource retrieves the progress report filed for an Insolvency Case
+func (s *SQLService) GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error) {
+	insolvencyResource, _, err := s.getCase(ctx, transactionID)
+	if err != nil || insolvencyResource.Data.ProgressReport == nil {
+		return &models.ProgressReportResourceDao{}, err
+	}
+
+	return insolvencyResource.Data.ProgressReport, nil
+}
+
+// DeleteStatementOfAffairsResource deletes the statement of affairs filed for an insolvency case
+func (s *SQLService) DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error {
+	return s.deleteCaseResource(ctx, transactionID, statementOfAffairsType)
+}
+
+// DeleteResolutionResource deletes a resolution resource filed for an Insolvency Case
+func (s *SQLService) DeleteResolutionResource(ctx context.Context, transactionID string) error {
+	return s.deleteCaseResource(ctx, transactionID, resolutionType)
+}
+
+// DeleteProgressReportResource deletes the progress report filed for an insolvency case
+func (s *SQLService) DeleteProgressReportResource(ctx context.Context, transactionID string) error {
+	return s.deleteCaseResource(ctx, transactionID, progressReportType)
+}
+
+// deleteCaseResource deletes the dated resource of the type resType from the insolvency case with the specified
+// transactionID
+func (s *SQLService) deleteCaseResource(ctx context.Context, transactionID string, resType string) error {
+	return s.update(ctx, transactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+			log.Error(err)
+			return err
+		}
+
+		if _, filed := filedResourceEtag(&insolvencyResource.Data, resType); !filed {
+			err := apperrors.NotFound("there was a problem handling your request for transaction id [%s] - %v not found", transactionID, strings.ReplaceAll(resType, "-", " "))
+			log.Error(err)
+			return err
+		}
+
+		return s.deleteDatedResource(ctx, tx, transactionID, resType)
+	})
+}
+
+// deleteDatedResource deletes the dated resource of the type resType and the IDs of the attachments it was filed with
+func (s *SQLService) deleteDatedResource(ctx context.Context, tx *sql.Tx, transactionID string, resType string) error {
+	_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM dated_resource_attachments WHERE transaction_id = ? AND type = ?"), transactionID, resType)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, s.rebind("DELETE FROM dated_resources WHERE transaction_id = ? AND type = ?"), transactionID, resType)
+	return err
+}
+
+// SetValidationFailures stores the failed rules in the validation_failures column of the insolvency case with the
+// specified transactionID, returning those that were stored before
+func (s *SQLService) SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	if failedRules == nil {
+		failedRules = []string{}
+	}
+	stored, err := json.Marshal(failedRules)
+	if err != nil {
+		return nil, sqlError(transactionID, err)
+	}
+
+	var previous []string
+	err = s.inTransaction(ctx, func(tx *sql.Tx) error {
+		// The case is locked first, so that concurrent checks each get the failures stored by the one before
+		if _, err := tx.ExecContext(ctx, s.rebind("UPDATE cases SET etag = etag WHERE transaction_id = ?"), transactionID); err != nil {
+			return err
+		}
+
+		var current string
+		err := tx.QueryRowContext(ctx, s.rebind("SELECT validation_failures FROM cases WHERE transaction_id = ?"), transactionID).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.NotFound(constants.MsgCaseForTransactionNotFound, transactionID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(current), &previous); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s.rebind("UPDATE cases SET validation_failures = ? WHERE transaction_id = ?"), string(stored), transactionID)
+		return err
+	})
+	if err != nil {
+		return nil, sqlError(transactionID, err)
+	}
+
+	return previous, nil
+}
+
+// ReserveIdempotencyKey stores the key in the idempotency_keys table, unless an unexpired key with the same value
+// is already stored, in which case the stored key is returned
+func (s *SQLService) ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	var stored *models.IdempotencyKeyDao
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM idempotency_keys WHERE idempotency_key = ? AND expires_at <= ?"), dao.Key, dao.CreatedAt.UnixMilli())
+		if err != nil {
+			return err
+		}
+
+		stored, err = s.loadIdempotencyKey(ctx, tx, dao.Key)
+		if err != nil || stored != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s.rebind("INSERT INTO idempotency_keys (idempotency_key, token, request_hash, created_at, expires_at) VALUES (?, ?, ?, ?, ?)"),
+			dao.Key, dao.Token, dao.RequestHash, dao.CreatedAt.UnixMilli(), dao.ExpiresAt.UnixMilli())
+		return err
+	})
+	if err != nil {
+		// A concurrent request may have stored the same key first
+		if stored, loadErr := s.loadIdempotencyKey(ctx, s.db, dao.Key); loadErr == nil && stored != nil {
+			return stored, nil
+		}
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem reserving the idempotency key", err)
+	}
+
+	return stored, nil
+}
+
+// loadIdempotencyKey returns the stored idempotency key with the specified value, or nil if it is not stored
+func (s *SQLService) loadIdempotencyKey(ctx context.Context, q queryer, key string) (*models.IdempotencyKeyDao, error) {
+	var token, requestHash string
+	var response sql.NullString
+	var createdAt, expiresAt int64
+
+	err := q.QueryRowContext(ctx, s.rebind("SELECT token, request_hash, response, created_at, expires_at FROM idempotency_keys WHERE idempotency_key = ?"), key).
+		Scan(&token, &requestHash, &response, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &models.IdempotencyKeyDao{
+		Key:         key,
+		Token:       token,
+		RequestHash: requestHash,
+		CreatedAt:   time.UnixMilli(createdAt),
+		ExpiresAt:   time.UnixMilli(expiresAt),
+	}
+	if response.Valid {
+		stored.Response = &models.IdempotentResponseDao{}
+		if err := json.Unmarshal([]byte(response.String), stored.Response); err != nil {
+			return nil, err
+		}
+	}
+
+	return stored, nil
+}
+
+// CompleteIdempotencyKey stores the response to the request made with the key, keeping it until expiresAt, if the
+// key is still reserved with token
+func (s *SQLService) CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	encoded, err := json.Marshal(response)
+	if err == nil {
+		_, err = s.db.ExecContext(ctx, s.rebind("UPDATE idempotency_keys SET response = ?, expires_at = ? WHERE idempotency_key = ? AND token = ?"), string(encoded), expiresAt.UnixMilli(), key, token)
+	}
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem storing the response for the idempotency key", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the key from the idempotency_keys table, if it is still reserved with token
+func (s *SQLService) ReleaseIdempotencyKey(ctx context.Context, key, token string) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM idempotency_keys WHERE idempotency_key = ? AND token = ?"), key, token); err != nil {
+		log.Error(err)
+		return newDatabaseError("there was a problem releasing the idempotency key", err)
+	}
+
+	return nil
+}
+
+// CreateAuditEvent inserts the event into the audit_events table
+func (s *SQLService) CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	changes, err := json.Marshal(dao.Changes)
+	if err == nil {
+		_, err = s.db.ExecContext(ctx, s.rebind("INSERT INTO audit_events (id, transaction_id, company_number, user_id, email, route, method, path, status_code, created_at, changes) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+			dao.ID, dao.TransactionID, dao.CompanyNumber, dao.UserID, dao.Email, dao.Route, dao.Method, dao.Path, dao.StatusCode, dao.CreatedAt.UnixMilli(), string(changes))
+	}
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem recording the audit event for transaction [%s]", dao.TransactionID), err)
+	}
+
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter from the audit_events table, oldest first
+func (s *SQLService) GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	var conditions []string
+	var args []interface{}
+	for _, condition := range []struct {
+		column string
+		value  string
+	}{
+		{"transaction_id", filter.TransactionID},
+		{"company_number", filter.CompanyNumber},
+		{"user_id", filter.UserID},
+		{"route", filter.Route},
+	} {
+		if condition.value != "" {
+			conditions = append(conditions, condition.column+" = ?")
+			args = append(args, condition.value)
+		}
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From.UnixMilli())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To.UnixMilli())
+	}
+
+	query := "SELECT id, transaction_id, company_number, user_id, email, route, method, path, status_code, created_at, changes FROM audit_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	events, err := s.queryAuditEvents(ctx, s.rebind(query), args...)
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem retrieving the audit events", err)
+	}
+
+	return events, nil
+}
+
+// queryAuditEvents returns the audit events selected by the query
+func (s *SQLService) queryAuditEvents(ctx context.Context, query string, args ...interface{}) ([]models.AuditEventDao, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEventDao{}
+	for rows.Next() {
+		var event models.AuditEventDao
+		var createdAt int64
+		var changes string
+		err := rows.Scan(&event.ID, &event.TransactionID, &event.CompanyNumber, &event.UserID, &event.Email, &event.Route,
+			&event.Method, &event.Path, &event.StatusCode, &createdAt, &changes)
+		if err != nil {
+			return nil, err
+		}
+
+		event.CreatedAt = time.UnixMilli(createdAt).UTC()
+		if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// CreateOutboxEvent writes the event to the outbox_events table, unless an event with the same ID has been written
+// for the case before, which the written_outbox_events table records
+func (s *SQLService) CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error {
+	return s.update(ctx, dao.TransactionID, func(tx *sql.Tx, insolvencyResource *models.InsolvencyResourceDao, ok bool) error {
+		if !ok {
+			err := apperrors.NotFound(constants.MsgCaseForTransactionNotFound, dao.TransactionID)
+			log.Error(err)
+			return err
+		}
+
+		var written int
+		err := tx.QueryRowContext(ctx, s.rebind("SELECT COUNT(*) FROM written_outbox_events WHERE transaction_id = ? AND id = ?"), dao.TransactionID, dao.ID).Scan(&written)
+		if err != nil || written > 0 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, s.rebind("INSERT INTO written_outbox_events (transaction_id, id) VALUES (?, ?)"), dao.TransactionID, dao.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.insertOutboxEvent(ctx, tx, dao)
+	})
+}
+
+// GetOutboxEvents retrieves up to limit events from the outbox_events table, oldest first. Events on the same case
+// with the same time are returned in the order they were written
+func (s *SQLService) GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	query := "SELECT " + outboxEventColumns + " FROM outbox_events ORDER BY created_at, transaction_id, position"
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	events, err := s.queryOutboxEvents(ctx, s.db, query)
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem retrieving the outbox events", err)
+	}
+
+	return events, nil
+}
+
+// outboxEventColumns are the columns of the outbox_events table read by queryOutboxEvents
+const outboxEventColumns = "id, transaction_id, type, created_at, data, attempts, parked"
+
+// queryOutboxEvents returns the outbox events selected by the query, which must select the outboxEventColumns
+func (s *SQLService) queryOutboxEvents(ctx context.Context, q queryer, query string, args ...interface{}) ([]models.OutboxEventDao, error) {
+	rows, err := q.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEventDao{}
+	for rows.Next() {
+		var event models.OutboxEventDao
+		var createdAt int64
+		var parked int
+		if err := rows.Scan(&event.ID, &event.TransactionID, &event.Type, &createdAt, &event.Data, &event.Attempts, &parked); err != nil {
+			return nil, err
+		}
+
+		event.CreatedAt = time.UnixMilli(createdAt).UTC()
+		event.Parked = parked != 0
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ClaimOutboxEvents claims the outboxes of the cases with events in the outbox_events table that have not been
+// parked, recording the claimant on each case, and returns up to limit of their events that have not been parked
+func (s *SQLService) ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	claimed, err := s.claimOutboxEvents(ctx, claimant, now, lease, limit)
+	if err != nil {
+		log.Error(err)
+		return nil, newDatabaseError("there was a problem claiming the outbox events", err)
+	}
+
+	return claimed, nil
+}
+
+// claimOutboxEvents claims the outboxes of cases one at a time, so that each claim is a single update that only
+// succeeds if no other claimant holds an unexpired claim on the case
+func (s *SQLService) claimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	query := `SELECT o.transaction_id FROM outbox_events o JOIN cases c ON c.transaction_id = o.transaction_id
+		WHERE o.parked = 0 AND c.outbox_claim_expires_at <= ? GROUP BY o.transaction_id ORDER BY MIN(o.created_at), o.transaction_id`
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), now.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	transactionIDs := []string{}
+	for rows.Next() {
+		var transactionID string
+		if err := rows.Scan(&transactionID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		transactionIDs = append(transactionIDs, transactionID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	claimed := []models.OutboxEventDao{}
+	for _, transactionID := range transactionIDs {
+		if limit > 0 && len(claimed) >= limit {
+			break
+		}
+
+		result, err := s.db.ExecContext(ctx, s.rebind("UPDATE cases SET outbox_claimed_by = ?, outbox_claim_expires_at = ? WHERE transaction_id = ? AND outbox_claim_expires_at <= ?"),
+			claimant, now.Add(lease).UnixMilli(), transactionID, now.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if updated == 0 {
+			// Another claimant claimed the case first
+			continue
+		}
+
+		events, err := s.queryOutboxEvents(ctx, s.db, "SELECT "+outboxEventColumns+" FROM outbox_events WHERE transaction_id = ? AND parked = 0 ORDER BY position", transactionID)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, events...)
+	}
+	if limit > 0 && len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+
+	return claimed, nil
+}
+
+// ReleaseOutboxClaim removes the claim on the outbox of the case in the cases table, if it is held by the claimant
+func (s *SQLService) ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, s.rebind("UPDATE cases SET outbox_claimed_by = '', outbox_claim_expires_at = 0 WHERE transaction_id = ? AND outbox_claimed_by = ?"), transactionID, claimant)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem releasing the outbox claim for transaction [%s]", transactionID), err)
+	}
+
+	return nil
+}
+
+// FailOutboxEvent counts a failed delivery of the event in the outbox_events table, parking it once its deliveries
+// have failed maxAttempts times
+func (s *SQLService) FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error) {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	var parked bool
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, s.rebind("UPDATE outbox_events SET attempts = attempts + 1 WHERE transaction_id = ? AND id = ? AND parked = 0"), transactionID, eventID)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, s.rebind("UPDATE outbox_events SET parked = 1 WHERE transaction_id = ? AND id = ? AND parked = 0 AND attempts >= ?"), transactionID, eventID, maxAttempts)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		parked = updated > 0
+		return err
+	})
+	if err != nil {
+		log.Error(err)
+		return false, newDatabaseError(fmt.Sprintf("there was a problem recording the failed delivery of the outbox event for transaction [%s]", transactionID), err)
+	}
+
+	return parked, nil
+}
+
+// DeleteOutboxEvent deletes the event from the outbox_events table
+func (s *SQLService) DeleteOutboxEvent(ctx context.Context, transactionID string, eventID string) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, s.rebind("DELETE FROM outbox_events WHERE transaction_id = ? AND id = ?"), transactionID, eventID)
+	if err != nil {
+		log.Error(err)
+		return newDatabaseError(fmt.Sprintf("there was a problem deleting the outbox event for transaction [%s]", transactionID), err)
+	}
+
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (s *SQLService) Ping(ctx context.Context) error {
+	ctx, cancel := s.operationContext(ctx)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping to sql database failed: [%w]", err)
+	}
+	return nil
+}
+
+// Close closes the database. Queries that have started are allowed to finish, regardless of the context
+func (s *SQLService) Close(ctx context.Context) error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("error closing sql database: [%v]", err)
+	}
+
+	log.Info("closed sql database")
+	return nil
+}
@@ -0,0 +1,140 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+)
+
+// migrationFiles holds the SQL migrations, named with the version they migrate the schema to followed by a
+// description, for example 0001_create_insolvency_tables.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration is a single change to the SQL schema
+type migration struct {
+	version    int
+	name       string
+	statements []string
+}
+
+// loadMigrations returns the embedded migrations in version order
+func loadMigrations() ([]migration, error) {
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(names))
+	for _, name := range names {
+		base := strings.TrimPrefix(name, "migrations/")
+		version, err := strconv.Atoi(strings.SplitN(base, "_", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("migration [%s] is not named with its version: [%v]", base, err)
+		}
+
+		contents, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		// Statements are run one at a time as not every driver can run several in one call
+		migrations = append(migrations, migration{version: version, name: base, statements: splitStatements(string(contents))})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// splitStatements splits a migration into the statements separated by semicolons in it. Semicolons in quoted
+// strings, quoted identifiers and comments do not end a statement, but statements that contain others, such as
+// trigger bodies or PostgreSQL dollar-quoted functions, are not supported and must be avoided in migrations
+func splitStatements(contents string) []string {
+	var statements []string
+	start := 0
+	add := func(end int) {
+		if statement := contents[start:end]; strings.TrimSpace(statement) != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	for i := 0; i < len(contents); i++ {
+		switch {
+		case contents[i] == '\'' || contents[i] == '"':
+			// A quote is escaped by doubling it, which is the same as ending the quoted text and starting another
+			if end := strings.IndexByte(contents[i+1:], contents[i]); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(contents)
+			}
+		case strings.HasPrefix(contents[i:], "--"):
+			if end := strings.IndexByte(contents[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(contents)
+			}
+		case strings.HasPrefix(contents[i:], "/*"):
+			if end := strings.Index(contents[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(contents)
+			}
+		case contents[i] == ';':
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(contents))
+
+	return statements
+}
+
+// Migrate brings the SQL schema up to date, running each migration that has not been run yet in its own
+// transaction and recording its version in the schema_migrations table
+func (s *SQLService) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("error loading sql migrations: [%v]", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY)"); err != nil {
+		return fmt.Errorf("error creating schema_migrations table: [%v]", err)
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("error reading schema version: [%v]", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+			for _, statement := range m.statements {
+				if _, err := tx.ExecContext(ctx, statement); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.rebind("INSERT INTO schema_migrations (version) VALUES (?)"), m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error running sql migration [%s]: [%v]", m.name, err)
+		}
+
+		log.Info("ran sql migration", log.Data{"migration": m.name})
+	}
+
+	return nil
+}
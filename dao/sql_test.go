@@ -0,0 +1,71 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/config"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitSQLService(t *testing.T) {
+	testServiceBehaviour(t, func(t *testing.T) Service {
+		svc, err := NewSQLService("sqlite", ":memory:")
+		if err != nil {
+			t.Fatalf("error creating sql service: [%v]", err)
+		}
+		t.Cleanup(func() { svc.Close(context.Background()) })
+		return svc
+	})
+}
+
+func TestUnitSQLMigrate(t *testing.T) {
+	Convey("Migrations that have already run are not run again", t, func() {
+		svc, err := NewSQLService("sqlite", ":memory:")
+		So(err, ShouldBeNil)
+		defer svc.Close(context.Background())
+
+		So(svc.Migrate(t.Context()), ShouldBeNil)
+
+		var migrations int
+		So(svc.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&migrations), ShouldBeNil)
+		So(migrations, ShouldEqual, 8)
+	})
+}
+
+func TestUnitSQLSplitStatements(t *testing.T) {
+	Convey("Migrations are split on the semicolons that end statements", t, func() {
+		statements := splitStatements("CREATE TABLE a (id INTEGER);\nCREATE TABLE b (id INTEGER);\n")
+		So(statements, ShouldResemble, []string{"CREATE TABLE a (id INTEGER)", "\nCREATE TABLE b (id INTEGER)"})
+	})
+
+	Convey("Semicolons in strings, identifiers and comments do not end a statement", t, func() {
+		contents := "-- create a; b\nINSERT INTO \"a;b\" VALUES ('it''s; quoted');\n/* c; d */ SELECT 1;"
+
+		So(splitStatements(contents), ShouldResemble, []string{
+			"-- create a; b\nINSERT INTO \"a;b\" VALUES ('it''s; quoted')",
+			"\n/* c; d */ SELECT 1",
+		})
+	})
+}
+
+func TestUnitSQLClose(t *testing.T) {
+	Convey("The database opened by NewDAOService is closed by the service", t, func() {
+		svc, err := NewDAOService(&config.Config{DatabaseBackend: BackendSQL, SQLDataSource: ":memory:"})
+		So(err, ShouldBeNil)
+		So(svc.Ping(t.Context()), ShouldBeNil)
+
+		So(svc.Close(t.Context()), ShouldBeNil)
+		So(svc.Ping(t.Context()), ShouldNotBeNil)
+	})
+}
+
+func TestUnitSQLRebind(t *testing.T) {
+	Convey("Placeholders are numbered for PostgreSQL drivers", t, func() {
+		query := "SELECT id FROM cases WHERE transaction_id = ? AND firm_id = ?"
+
+		So((&SQLService{driverName: "sqlite"}).rebind(query), ShouldEqual, query)
+		So((&SQLService{driverName: "postgres"}).rebind(query), ShouldEqual, "SELECT id FROM cases WHERE transaction_id = $1 AND firm_id = $2")
+	})
+}
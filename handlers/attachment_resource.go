@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	"github.com/companieshouse/insolvency-api/models"
@@ -15,11 +16,11 @@ import (
 )
 
 // HandleSubmitAttachment receives an attachment to be stored against the Insolvency case
-func HandleSubmitAttachment(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleSubmitAttachment(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction is valid
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "attachment", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "attachment", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
@@ -35,49 +36,34 @@ func HandleSubmitAttachment(svc dao.Service, helperService utils.HelperService)
 		}
 
 		// Validate that the provided attachment details are correct
-		validationErrs, err := service.ValidateAttachmentDetails(svc, transactionID, attachmentType, header)
+		validationErrs, err := service.ValidateAttachmentDetails(req.Context(), svc, transactionID, attachmentType, header)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error validating attachment details: [%s]", err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction ID [%s]", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction ID [%s]: %w", transactionID, err))
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request", validationErrs)
 			return
 		}
 
-		fileID, responseType, err := service.UploadAttachment(file, header, req)
+		fileID, responseType, err := clients.UploadAttachment(file, header, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error uploading attachment: [%v]", err), log.Data{"service_response_type": responseType.String()})
-
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem uploading the attachment")
 			return
 		}
 		if responseType != service.Success {
 			log.ErrorR(req, fmt.Errorf("file upload was unsuccessful"))
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				log.ErrorR(req, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem uploading the attachment")
 			return
 		}
 
-		attachmentDao, err := svc.AddAttachmentToInsolvencyResource(transactionID, fileID, attachmentType)
+		attachmentDao, err := svc.AddAttachmentToInsolvencyResource(req.Context(), transactionID, fileID, attachmentType)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to add attachment to insolvency resource in db for transaction [%s]: %v", transactionID, err))
-			m := models.NewMessageResponse(constants.MsgHandleReqProblem)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
@@ -98,7 +84,7 @@ func HandleSubmitAttachment(svc dao.Service, helperService utils.HelperService)
 }
 
 // HandleGetAttachmentDetails receives an attachment to be stored against the Insolvency case
-func HandleGetAttachmentDetails(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleGetAttachmentDetails(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		transactionID := utils.GetTransactionIDFromVars(vars)
@@ -120,11 +106,10 @@ func HandleGetAttachmentDetails(svc dao.Service, helperService utils.HelperServi
 		log.InfoR(req, fmt.Sprintf("start GET request for attachment with transaction id: %s, attachment id: %s", transactionID, attachmentID))
 
 		// Calls the database and returns attachment stored against the Insolvency case
-		attachmentDao, err := svc.GetAttachmentFromInsolvencyResource(transactionID, attachmentID)
+		attachmentDao, err := svc.GetAttachmentFromInsolvencyResource(req.Context(), transactionID, attachmentID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get attachment from insolvency resource in db for transaction [%s] with attachment id of [%s]: %v", transactionID, attachmentID, err))
-			m := models.NewMessageResponse(constants.MsgHandleReqProblem)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if attachmentDao == (models.AttachmentResourceDao{}) {
@@ -134,16 +119,10 @@ func HandleGetAttachmentDetails(svc dao.Service, helperService utils.HelperServi
 		}
 
 		// Calls File Transfer API to get attachment details
-		GetAttachmentDetailsResponse, responseType, err := service.GetAttachmentDetails(attachmentID, req)
+		GetAttachmentDetailsResponse, responseType, err := clients.GetAttachmentDetails(attachmentID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error getting attachment details: [%v]", err), log.Data{"service_response_type": responseType.String()})
-
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem getting the attachment details")
 			return
 		}
 
@@ -164,7 +143,7 @@ func HandleGetAttachmentDetails(svc dao.Service, helperService utils.HelperServi
 }
 
 // HandleDownloadAttachment download an attachment which is stored against an Insolvency case
-func HandleDownloadAttachment(svc dao.Service) http.Handler {
+func HandleDownloadAttachment(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		transactionID := utils.GetTransactionIDFromVars(vars)
@@ -184,11 +163,10 @@ func HandleDownloadAttachment(svc dao.Service) http.Handler {
 		}
 
 		// Get attachment data from DB to check if attachment ID is valid
-		attachmentResource, err := svc.GetAttachmentFromInsolvencyResource(transactionID, attachmentID)
+		attachmentResource, err := svc.GetAttachmentFromInsolvencyResource(req.Context(), transactionID, attachmentID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get attachment from insolvency db resource for transaction [%s] with attachment id [%s]: %v", transactionID, attachmentID, err))
-			m := models.NewMessageResponse(constants.MsgHandleReqProblem)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if attachmentResource == (models.AttachmentResourceDao{}) {
@@ -198,17 +176,10 @@ func HandleDownloadAttachment(svc dao.Service) http.Handler {
 		}
 
 		// get data from File Transfer API to check antivirus is complete
-		attachmentDetails, responseType, err := service.GetAttachmentDetails(attachmentID, req)
+		attachmentDetails, responseType, err := clients.GetAttachmentDetails(attachmentID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error getting attachment details: [%v]", err), log.Data{"service_response_type": responseType.String()})
-
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				log.ErrorR(req, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem getting the attachment details")
 			return
 		}
 		if attachmentDetails.AVStatus != "clean" {
@@ -218,34 +189,22 @@ func HandleDownloadAttachment(svc dao.Service) http.Handler {
 			return
 		}
 
-		responseType, err = service.DownloadAttachment(attachmentID, req, w)
+		responseType, err = clients.DownloadAttachment(attachmentID, req, w)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error downloading attachment: [%v]", err), log.Data{"service_response_type": responseType.String()})
-
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem downloading the attachment")
 			return
 		}
 		if responseType != service.Success {
 			log.ErrorR(req, fmt.Errorf("file download was unsuccessful"))
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				log.ErrorR(req, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem downloading the attachment")
 			return
 		}
 	})
 }
 
 // HandleDeleteAttachment deletes an attachment resource from the DB and deletes the stored file
-func HandleDeleteAttachment(svc dao.Service) http.Handler {
+func HandleDeleteAttachment(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		transactionID := utils.GetTransactionIDFromVars(vars)
@@ -267,42 +226,46 @@ func HandleDeleteAttachment(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start DELETE request for attachment with transaction id: %s, attachment id: %s", transactionID, attachmentID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if isTransactionClosed {
-			log.ErrorR(req, fmt.Errorf("transaction [%v] is already closed and cannot be updated", transactionID))
-			m := models.NewMessageResponse(fmt.Sprintf("transaction [%v] is already closed and cannot be updated", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = apperrors.Closed("transaction [%v] is already closed and cannot be updated", transactionID)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
-		responseType, err := service.DeleteAttachment(attachmentID, req)
+		responseType, err := clients.DeleteAttachment(attachmentID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error deleting attachment: [%v]", err), log.Data{"service_response_type": responseType.String()})
-
-			status, err := utils.ResponseTypeToStatus(responseType.String())
-			if err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-			w.WriteHeader(status)
+			writeFileTransferError(w, req, responseType, "there was a problem deleting the attachment")
 			return
 		}
 
 		// Delete attachment from DB
-		statusCode, err := svc.DeleteAttachmentResource(transactionID, attachmentID)
+		err = svc.DeleteAttachmentResource(req.Context(), transactionID, attachmentID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		utils.WriteJSONWithStatus(w, req, "", http.StatusNoContent)
 	})
 }
+
+// writeFileTransferError writes the response for an unsuccessful call to the File Transfer API, with the status
+// matching the response type it returned
+func writeFileTransferError(w http.ResponseWriter, req *http.Request, responseType service.ResponseType, message string) {
+	status, err := utils.ResponseTypeToStatus(responseType.String())
+	if err != nil {
+		log.ErrorR(req, err)
+		status = http.StatusInternalServerError
+	}
+	utils.WriteJSONWithStatus(w, req, models.NewMessageResponse(message), status)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/go-session-handler/httpsession"
 	"github.com/companieshouse/go-session-handler/session"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/dao"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
@@ -39,7 +40,7 @@ func serveHandleSubmitAttachment(body []byte, service dao.Service, tranIDSet boo
 		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
 	}
 
-	handler := HandleSubmitAttachment(service, helperService)
+	handler := HandleSubmitAttachment(service, sdkClients, helperService)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -148,8 +149,8 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		}
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		res := serveHandleSubmitAttachment((body).Bytes(), mockService, true, mockHelperService, rec)
 
@@ -169,8 +170,8 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		}
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		res := serveHandleSubmitAttachment((body).Bytes(), mockService, true, mockHelperService, rec)
 
@@ -196,8 +197,8 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		}
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(attachments, nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(attachments, nil)
 
 		res := serveHandleSubmitAttachment((body).Bytes(), mockService, true, mockHelperService, rec)
 
@@ -217,8 +218,8 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		}
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		res := serveHandleSubmitAttachment((body).Bytes(), mockService, true, mockHelperService, rec)
 
@@ -234,9 +235,9 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodPost, `=~.*`, httpmock.NewStringResponder(http.StatusCreated, `{"id": "12345"}`))
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().AddAttachmentToInsolvencyResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().AddAttachmentToInsolvencyResource(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		body, err := getBodyWithFile("resolution", pdfFilePath)
 		if err != nil {
@@ -257,8 +258,8 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodPost, `=~.*`, httpmock.NewStringResponder(http.StatusCreated, `{"id": "12345"}`))
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(nil, fmt.Errorf("err"))
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(nil, fmt.Errorf("err"))
 
 		body, err := getBodyWithFile("resolution", pdfFilePath)
 		if err != nil {
@@ -283,9 +284,9 @@ func TestUnitHandleSubmitAttachment(t *testing.T) {
 		}
 
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().AddAttachmentToInsolvencyResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(&daoResponse, nil)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().AddAttachmentToInsolvencyResource(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&daoResponse, nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil).AnyTimes()
 
 		body, err := getBodyWithFile("resolution", pdfFilePath)
@@ -311,7 +312,7 @@ func serveHandleGetAttachmentDetails(service dao.Service, tranIDSet bool, attach
 	req = mux.SetURLVars(req, vars)
 	res := httptest.NewRecorder()
 
-	handler := HandleGetAttachmentDetails(service, helperService)
+	handler := HandleGetAttachmentDetails(service, sdkClients, helperService)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -358,7 +359,7 @@ func TestUnitHandleGetAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, `=~.*`, httpmock.NewStringResponder(http.StatusOK, `{"name": "file"}`))
 
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return an error
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(models.AttachmentResourceDao{}, fmt.Errorf("failed to get attachment from insolvency resource in db for transaction [%s] with attachment id of [%s]: %v", transactionID, attachmentID, err))
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(models.AttachmentResourceDao{}, fmt.Errorf("failed to get attachment from insolvency resource in db for transaction [%s] with attachment id of [%s]: %v", transactionID, attachmentID, err))
 
 		res := serveHandleGetAttachmentDetails(mockService, true, true, mockHelperService)
 
@@ -374,7 +375,7 @@ func TestUnitHandleGetAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, `=~.*`, httpmock.NewStringResponder(http.StatusOK, `{"name": "file"}`))
 
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return nothing
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(models.AttachmentResourceDao{}, nil).Times(1)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(models.AttachmentResourceDao{}, nil).Times(1)
 
 		res := serveHandleGetAttachmentDetails(mockService, true, true, mockHelperService)
 
@@ -398,7 +399,7 @@ func TestUnitHandleGetAttachment(t *testing.T) {
 		}
 
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return the attachment
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(attachment, nil)
 
 		res := serveHandleGetAttachmentDetails(mockService, true, true, mockHelperService)
 
@@ -421,7 +422,7 @@ func serveHandleDownloadAttachment(body []byte, service dao.Service, tranIDSet b
 
 	res := httptest.NewRecorder()
 
-	handler := HandleDownloadAttachment(service)
+	handler := HandleDownloadAttachment(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -462,7 +463,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		defer mockCtrl.Finish()
 
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(models.AttachmentResourceDao{}, fmt.Errorf("err"))
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(models.AttachmentResourceDao{}, fmt.Errorf("err"))
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -474,7 +475,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		defer mockCtrl.Finish()
 
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(models.AttachmentResourceDao{}, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(models.AttachmentResourceDao{}, nil)
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -490,7 +491,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 			ID: attachmentID,
 		}
 
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(response, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(response, nil)
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -509,7 +510,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 		response := models.AttachmentResourceDao{
 			ID: attachmentID,
 		}
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(response, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(response, nil)
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -529,7 +530,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 		response := models.AttachmentResourceDao{
 			ID: attachmentID,
 		}
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(response, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(response, nil)
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -550,7 +551,7 @@ func TestUnitHandleDownloadAttachment(t *testing.T) {
 		response := models.AttachmentResourceDao{
 			ID: attachmentID,
 		}
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, attachmentID).Return(response, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, attachmentID).Return(response, nil)
 
 		body, _ := json.Marshal(&models.InsolvencyRequest{})
 		res := serveHandleDownloadAttachment(body, mockService, true, true)
@@ -571,7 +572,7 @@ func serveHandleDeleteAttachment(service dao.Service, tranIDSet bool, attachment
 	req = mux.SetURLVars(req, vars)
 	res := httptest.NewRecorder()
 
-	handler := HandleDeleteAttachment(service)
+	handler := HandleDeleteAttachment(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -645,7 +646,7 @@ func TestUnitHandleDeleteAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodDelete, `=~.*`, httpmock.NewStringResponder(http.StatusNoContent, ``))
 
 		// Expect DeleteAttachmentResource to be called once and return an error
-		mockService.EXPECT().DeleteAttachmentResource(transactionID, attachmentID).Return(http.StatusInternalServerError, fmt.Errorf("err"))
+		mockService.EXPECT().DeleteAttachmentResource(gomock.Any(), transactionID, attachmentID).Return(fmt.Errorf("err"))
 
 		res := serveHandleDeleteAttachment(mockService, true, true)
 
@@ -663,7 +664,7 @@ func TestUnitHandleDeleteAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodDelete, `=~.*`, httpmock.NewStringResponder(http.StatusNoContent, ``))
 
 		// Expect DeleteAttachmentResource to be called once and return a not found
-		mockService.EXPECT().DeleteAttachmentResource(transactionID, attachmentID).Return(http.StatusNotFound, fmt.Errorf("attachment not found"))
+		mockService.EXPECT().DeleteAttachmentResource(gomock.Any(), transactionID, attachmentID).Return(apperrors.NotFound("attachment not found"))
 
 		res := serveHandleDeleteAttachment(mockService, true, true)
 
@@ -681,7 +682,7 @@ func TestUnitHandleDeleteAttachment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodDelete, `=~.*`, httpmock.NewStringResponder(http.StatusNoContent, ``))
 
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return an error
-		mockService.EXPECT().DeleteAttachmentResource(transactionID, attachmentID).Return(http.StatusNoContent, nil)
+		mockService.EXPECT().DeleteAttachmentResource(gomock.Any(), transactionID, attachmentID).Return(nil)
 
 		res := serveHandleDeleteAttachment(mockService, true, true)
 
@@ -0,0 +1,552 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/interceptors"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/transformers"
+	"github.com/companieshouse/insolvency-api/utils"
+)
+
+// HandleImportCase creates a complete insolvency case from a single request, with its practitioners and their
+// appointments, attachments that have already been uploaded to the File Transfer API, and its resolution,
+// statement of affairs and progress report. Each section is checked by the same validators as the endpoint that
+// creates it, and the case is only stored if every section is valid, otherwise the errors found in each section
+// are returned
+func HandleImportCase(svc dao.Service, clients service.Clients, helperService utils.HelperService, practitionerRegister service.PractitionerRegister, firms service.FirmMembership) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+
+		// Check transaction is valid
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "case import", clients.CheckIfTransactionClosed)
+		if !isValidTransaction {
+			return
+		}
+
+		// Decode the incoming request to create the whole case
+		var request models.CaseImportRequest
+		err := json.NewDecoder(req.Body).Decode(&request)
+		isValidDecoded := helperService.HandleBodyDecodedValidation(w, req, transactionID, err)
+		if !isValidDecoded {
+			return
+		}
+
+		// The route only requires permission to update cases, so check the user could also have made the
+		// appointments and added the attachments one at a time
+		if !holdsImportPermissions(w, req, request) {
+			return
+		}
+
+		// Check with transaction API that provided transaction ID exists
+		err = clients.CheckTransactionID(transactionID, req)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("transaction id [%s] was not found valid for case import against company [%s] when checking transaction api: [%v]",
+				transactionID, request.CompanyNumber, err))
+			utils.WriteErrorResponse(w, req, fmt.Errorf("transaction id [%s] was not found valid for insolvency: %w", transactionID, err))
+			return
+		}
+
+		// Each section is added to a copy of the case held in memory, so that the validators see the sections before
+		// them exactly as they would had they been created by separate requests
+		ci := &caseImport{
+			req:                  req,
+			transactionID:        transactionID,
+			staging:              dao.NewMemoryService(),
+			clients:              clients,
+			helperService:        helperService,
+			practitionerRegister: practitionerRegister,
+			firms:                firms,
+		}
+
+		err = ci.importCase(request)
+		if err != nil {
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
+			return
+		}
+		if len(ci.report.Sections) > 0 {
+			writeCaseImportReport(w, req, ci.report)
+			return
+		}
+
+		model, err := ci.staging.GetInsolvencyResource(req.Context(), transactionID)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("failed to get imported insolvency case for transaction [%s]: %v", transactionID, err))
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction [%s]: %w", transactionID, err))
+			return
+		}
+
+		// Store the whole case in a single write, so that it is never seen with only some of its sections
+		err = svc.CreateInsolvencyResource(req.Context(), &model)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("failed to create imported insolvency resource in database for transaction [%s]: %v", transactionID, err))
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction [%s]: %w", transactionID, err))
+			return
+		}
+
+		// Patch transaction API with new insolvency resource
+		err = clients.PatchTransactionWithInsolvencyResource(transactionID, &model, req)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("error patching transaction api with insolvency resource [%s]: [%v]", model.Links.Self, err))
+			utils.WriteErrorResponse(w, req, fmt.Errorf("error patching transaction api with insolvency resource [%s]: [%w]", model.Links.Self, err))
+			return
+		}
+
+		log.InfoR(req, fmt.Sprintf("successfully imported insolvency case with transaction ID: %s", transactionID))
+
+		utils.WriteJSONWithStatus(w, req, transformers.InsolvencyResourceDaoToCreatedResponse(&model), http.StatusCreated)
+	})
+}
+
+// holdsImportPermissions checks that the user holds the permissions of the routes that would otherwise be used to
+// appoint the practitioners and add the attachments in the request, writing the response if they do not
+func holdsImportPermissions(w http.ResponseWriter, req *http.Request, request models.CaseImportRequest) bool {
+	var required []interceptors.Permission
+	for _, practitioner := range request.Practitioners {
+		if practitioner.Appointment != nil {
+			required = append(required, interceptors.PermissionAppointPractitioners)
+			break
+		}
+	}
+	if len(request.Attachments) > 0 {
+		required = append(required, interceptors.PermissionManageAttachments)
+	}
+
+	for _, permission := range required {
+		ok, err := permission.HeldBy(req)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("error decoding token permissions: [%v]", err))
+			m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			return false
+		}
+		if !ok {
+			log.InfoR(req, "case import unauthorised", log.Data{"permission_key": permission.Key, "permission_value": permission.Value})
+			m := models.NewMessageResponse(constants.MsgUserNotAuthorised)
+			utils.WriteJSONWithStatus(w, req, m, http.StatusUnauthorized)
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeCaseImportReport writes the response for an imported case that failed validation. Clients that accept
+// application/problem+json are sent a problem with an error for each failure, with the section as its field
+func writeCaseImportReport(w http.ResponseWriter, req *http.Request, report models.CaseImportReport) {
+	log.ErrorR(req, fmt.Errorf("invalid request - case import failed validation: %v", report.Sections))
+
+	if utils.AcceptsProblemJSON(req) {
+		var fieldErrs []models.FieldError
+		for _, section := range report.Sections {
+			for _, message := range section.Errors {
+				fieldErrs = append(fieldErrs, models.FieldError{Field: section.Section, Message: message})
+			}
+		}
+		utils.WriteFieldErrors(w, req, report.Message, fieldErrs)
+		return
+	}
+
+	utils.WriteJSONWithStatus(w, req, report, http.StatusBadRequest)
+}
+
+// caseImport adds the sections of an imported case to the staging service, recording the errors in each section
+type caseImport struct {
+	req                  *http.Request
+	transactionID        string
+	staging              *dao.MemoryService
+	clients              service.Clients
+	helperService        utils.HelperService
+	practitionerRegister service.PractitionerRegister
+	firms                service.FirmMembership
+	report               models.CaseImportReport
+}
+
+// fail records errors against a section of the case
+func (ci *caseImport) fail(section string, errs ...string) {
+	if ci.report.Message == "" {
+		ci.report.Message = fmt.Sprintf("the case for transaction [%s] was not imported", ci.transactionID)
+	}
+
+	for i := range ci.report.Sections {
+		if ci.report.Sections[i].Section == section {
+			ci.report.Sections[i].Errors = append(ci.report.Sections[i].Errors, errs...)
+			return
+		}
+	}
+	ci.report.Sections = append(ci.report.Sections, models.CaseImportSectionReport{Section: section, Errors: errs})
+}
+
+// failFields records the field errors from validating a section of the case, returning whether there were any
+func (ci *caseImport) failFields(section string, fieldErrs []models.FieldError) bool {
+	for _, fieldErr := range fieldErrs {
+		ci.fail(section, fieldErr.Message)
+	}
+	return len(fieldErrs) > 0
+}
+
+// failErr records an error returned while adding a section of the case, unless it is a server error, which is
+// returned so that the import is abandoned
+func (ci *caseImport) failErr(section string, err error) error {
+	if utils.ErrorStatus(err) >= http.StatusInternalServerError {
+		return fmt.Errorf("there was a problem importing %s for transaction [%s]: %w", section, ci.transactionID, err)
+	}
+	ci.fail(section, err.Error())
+	return nil
+}
+
+// importCase adds each section of the case to the staging service. Sections that depend on the case are only
+// added once the case is valid
+func (ci *caseImport) importCase(request models.CaseImportRequest) error {
+	ok, err := ci.importInsolvencyResource(request.InsolvencyRequest)
+	if err != nil || !ok {
+		return err
+	}
+
+	for i, practitioner := range request.Practitioners {
+		if err = ci.importPractitioner(fmt.Sprintf("practitioners[%d]", i), practitioner); err != nil {
+			return err
+		}
+	}
+
+	for i, attachment := range request.Attachments {
+		if err = ci.importAttachment(fmt.Sprintf("attachments[%d]", i), attachment); err != nil {
+			return err
+		}
+	}
+
+	if request.Resolution != nil {
+		if err = ci.importResolution(*request.Resolution); err != nil {
+			return err
+		}
+	}
+
+	if request.StatementOfAffairs != nil {
+		if err = ci.importStatementOfAffairs(*request.StatementOfAffairs); err != nil {
+			return err
+		}
+	}
+
+	if request.ProgressReport != nil {
+		if err = ci.importProgressReport(*request.ProgressReport); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importInsolvencyResource checks the company and case type and adds the case, returning whether it was valid
+func (ci *caseImport) importInsolvencyResource(request models.InsolvencyRequest) (bool, error) {
+	const section = "case"
+
+	if ci.failFields(section, utils.Validate(request)) {
+		return false, nil
+	}
+
+	// Check case type of incoming request is CVL
+	if request.CaseType != constants.CVL.String() {
+		ci.fail(section, fmt.Sprintf("case type is not creditors-voluntary-liquidation for transaction %s", ci.transactionID))
+		return false, nil
+	}
+
+	// Check with company profile API if company exists, and that its name and other details are valid
+	companyProfile, err := ci.clients.CheckCompanyExists(&request, ci.req)
+	if err == nil {
+		err = ci.clients.CheckCompanyNameAlphaKey(companyProfile.CompanyName, &request, ci.req)
+	}
+	if err == nil {
+		err = service.CheckCompanyDetailsAreValid(companyProfile)
+	}
+	if err != nil {
+		log.ErrorR(ci.req, fmt.Errorf(constants.MsgCompanyInvalidProfileAPI, err))
+		return false, ci.failErr(section, fmt.Errorf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
+	}
+
+	model := transformers.InsolvencyResourceRequestToDB(&request, ci.transactionID, ci.helperService)
+	if model == nil {
+		return false, fmt.Errorf("there was a problem handling your request for transaction id [%s]", ci.transactionID)
+	}
+
+	// Record the user creating the case and the firm they belong to, so that access can be shared with colleagues
+	userDetails, _ := ci.req.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+	model.CreatedBy = userDetails.ID
+	model.FirmID, err = service.GetUserFirmID(ci.firms, userDetails)
+	if err != nil {
+		return false, fmt.Errorf("there was a problem handling your request for transaction id [%s]: %w", ci.transactionID, err)
+	}
+
+	if err = ci.staging.CreateInsolvencyResource(ci.req.Context(), model); err != nil {
+		return false, ci.failErr(section, err)
+	}
+
+	return true, nil
+}
+
+// importPractitioner adds a practitioner to the case, and appoints them if the request includes their appointment
+func (ci *caseImport) importPractitioner(section string, practitioner models.CaseImportPractitioner) error {
+	request := practitioner.PractitionerRequest
+
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	// Validates that the provided practitioner details are in the correct format
+	validationErrs, err := service.ValidatePractitionerDetails(ci.req.Context(), ci.staging, ci.transactionID, request)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	ci.failFields(section, validationErrs)
+
+	// Check that the practitioner is on the insolvency practitioner register
+	registerErrs, err := service.ValidatePractitionerAgainstRegister(ci.practitionerRegister, request)
+	if err != nil {
+		return fmt.Errorf("failed to check the practitioner against the insolvency practitioner register: %w", err)
+	}
+	ci.failFields(section, registerErrs)
+
+	// Check if practitioner role supplied is valid
+	if ok := constants.IsInRoleList(request.Role); !ok {
+		ci.fail(section, fmt.Sprintf("the practitioner role supplied is not valid %s", request.Role))
+		return nil
+	}
+	if len(validationErrs) > 0 || len(registerErrs) > 0 {
+		return nil
+	}
+
+	practitionerDao := transformers.PractitionerResourceRequestToDB(&request, ci.transactionID)
+
+	// Link the practitioner to the user account the practitioner register holds for their IP code
+	practitionerDao.UserID, err = service.GetRegisteredUserID(ci.practitionerRegister, request.IPCode)
+	if err != nil {
+		return fmt.Errorf("failed to check the practitioner against the insolvency practitioner register: %w", err)
+	}
+
+	if err = ci.staging.CreatePractitionersResource(ci.req.Context(), practitionerDao, ci.transactionID); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	if practitioner.Appointment == nil {
+		return nil
+	}
+	return ci.importAppointment(section+".appointment", *practitioner.Appointment, practitionerDao.ID)
+}
+
+// importAppointment appoints a practitioner that has been added to the case
+func (ci *caseImport) importAppointment(section string, request models.PractitionerAppointment, practitionerID string) error {
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	// Check if made_by supplied is valid
+	if ok := constants.IsAppointmentMadeByInList(request.MadeBy); !ok {
+		ci.fail(section, fmt.Sprintf("the appointment made_by supplied is not valid: [%s]", request.MadeBy))
+		return nil
+	}
+
+	// Validate all appointment details are of the correct format and criteria
+	validationErrs, err := service.ValidateAppointmentDetails(ci.staging, ci.clients, request, ci.transactionID, practitionerID, ci.req)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if ci.failFields(section, validationErrs) {
+		return nil
+	}
+
+	appointmentDao := transformers.PractitionerAppointmentRequestToDB(&request, ci.transactionID, practitionerID)
+	if err = ci.staging.AppointPractitioner(ci.req.Context(), appointmentDao, ci.transactionID, practitionerID); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	return nil
+}
+
+// importAttachment adds an attachment that has already been uploaded to the File Transfer API to the case. The
+// attachment is validated using the details of the file held by the File Transfer API
+func (ci *caseImport) importAttachment(section string, request models.CaseImportAttachment) error {
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	existing, err := ci.staging.GetAttachmentFromInsolvencyResource(ci.req.Context(), ci.transactionID, request.ID)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if existing != (models.AttachmentResourceDao{}) {
+		ci.fail(section, fmt.Sprintf("attachment [%s] is included more than once", request.ID))
+		return nil
+	}
+
+	attachmentDetails, responseType, err := ci.clients.GetAttachmentDetails(request.ID, ci.req)
+	if err != nil {
+		log.ErrorR(ci.req, fmt.Errorf("error getting attachment details: [%v]", err), log.Data{"service_response_type": responseType.String()})
+		ci.fail(section, fmt.Sprintf("there was a problem getting the details of attachment [%s]", request.ID))
+		return nil
+	}
+
+	header := &multipart.FileHeader{
+		Filename: attachmentDetails.Name,
+		Size:     attachmentDetails.Size,
+		Header:   textproto.MIMEHeader{"Content-Type": {attachmentDetails.ContentType}},
+	}
+
+	// Validate that the provided attachment details are correct
+	validationErrs, err := service.ValidateAttachmentDetails(ci.req.Context(), ci.staging, ci.transactionID, request.AttachmentType, header)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if ci.failFields(section, validationErrs) {
+		return nil
+	}
+
+	if _, err = ci.staging.AddAttachmentToInsolvencyResource(ci.req.Context(), ci.transactionID, request.ID, request.AttachmentType); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	return nil
+}
+
+// checkAttachments records an error against the section for each of its attachments that has not been added to
+// the case or is not one of the attachment types, returning whether they were all valid
+func (ci *caseImport) checkAttachments(section string, attachmentIDs []string, responseMessage string, attachmentTypes ...string) (bool, error) {
+	valid := true
+	for _, attachmentID := range attachmentIDs {
+		attachment, err := ci.staging.GetAttachmentFromInsolvencyResource(ci.req.Context(), ci.transactionID, attachmentID)
+		if err != nil {
+			return false, ci.failErr(section, err)
+		}
+		if attachment == (models.AttachmentResourceDao{}) {
+			ci.fail(section, fmt.Sprintf("attachment [%s] is not one of the attachments on the case", attachmentID))
+			valid = false
+			continue
+		}
+
+		isType := false
+		for _, attachmentType := range attachmentTypes {
+			isType = isType || attachment.Type == attachmentType
+		}
+		if !isType {
+			ci.fail(section, fmt.Sprintf("%s: attachment [%s] is of type [%s]", responseMessage, attachmentID, attachment.Type))
+			valid = false
+		}
+	}
+	return valid, nil
+}
+
+// importResolution adds the resolution to the case
+func (ci *caseImport) importResolution(request models.Resolution) error {
+	const section = "resolution"
+
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	// Validate the provided statement details are in the correct format
+	if ci.failFields(section, service.ValidateResolutionRequest(request)) {
+		return nil
+	}
+
+	resolutionDao := transformers.ResolutionResourceRequestToDB(&request, ci.transactionID, ci.helperService)
+	if resolutionDao == nil {
+		return fmt.Errorf("there was a problem handling your request for transaction id [%s]", ci.transactionID)
+	}
+
+	// Validate the provided resolution date is in the correct format
+	validationErrs, err := service.ValidateResolutionDate(ci.staging, ci.clients, resolutionDao, ci.transactionID, ci.req)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if ci.failFields(section, validationErrs) {
+		return nil
+	}
+
+	ok, err := ci.checkAttachments(section, resolutionDao.Attachments, "attachment is not a resolution", constants.Resolution.String())
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = ci.staging.CreateResolutionResource(ci.req.Context(), resolutionDao, ci.transactionID, ""); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	return nil
+}
+
+// importStatementOfAffairs adds the statement of affairs to the case
+func (ci *caseImport) importStatementOfAffairs(request models.StatementOfAffairs) error {
+	const section = "statement_of_affairs"
+
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	statementDao := transformers.StatementOfAffairsResourceRequestToDB(&request, ci.transactionID, ci.helperService)
+	if statementDao == nil {
+		return fmt.Errorf("there was a problem handling your request for transaction id [%s]", ci.transactionID)
+	}
+
+	// Validate the provided statement details are in the correct format
+	validationErrs, err := service.ValidateStatementDetails(ci.staging, ci.clients, statementDao, ci.transactionID, ci.req)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if ci.failFields(section, validationErrs) {
+		return nil
+	}
+
+	ok, err := ci.checkAttachments(section, statementDao.Attachments,
+		"attachment is not a "+constants.StatementOfAffairsDirector.String()+", "+constants.StatementOfAffairsLiquidator.String()+" or a "+constants.StatementOfConcurrence.String(),
+		constants.StatementOfAffairsDirector.String(), constants.StatementOfAffairsLiquidator.String(), constants.StatementOfConcurrence.String())
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = ci.staging.CreateStatementOfAffairsResource(ci.req.Context(), statementDao, ci.transactionID, ""); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	return nil
+}
+
+// importProgressReport adds the progress report to the case
+func (ci *caseImport) importProgressReport(request models.ProgressReport) error {
+	const section = "progress_report"
+
+	if ci.failFields(section, utils.Validate(request)) {
+		return nil
+	}
+
+	progressReportDao := transformers.ProgressReportResourceRequestToDB(&request, ci.transactionID, ci.helperService)
+	if progressReportDao == nil {
+		return fmt.Errorf("there was a problem handling your request for transaction id [%s]", ci.transactionID)
+	}
+
+	// Validate the provided progress report details are in the correct format
+	validationErrs, err := service.ValidateProgressReportDetails(ci.staging, ci.clients, progressReportDao, ci.transactionID, ci.req)
+	if err != nil {
+		return ci.failErr(section, err)
+	}
+	if ci.failFields(section, validationErrs) {
+		return nil
+	}
+
+	ok, err := ci.checkAttachments(section, progressReportDao.Attachments, "attachment is not a progress-report", constants.ProgressReport.String())
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = ci.staging.CreateProgressReportResource(ci.req.Context(), progressReportDao, ci.transactionID, ""); err != nil {
+		return ci.failErr(section, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// caseImportPermissions are the token permissions needed to import a case with appointments and attachments
+const caseImportPermissions = authentication.PermissionKeyInsolvencyCases + "=update,appoint_practitioners,manage_attachments"
+
+// caseImportFakes are the fake upstream APIs that a case is imported against
+type caseImportFakes struct {
+	companies    *service.FakeCompanyProfileClient
+	transactions *service.FakeTransactionClient
+	files        *service.FakeFileTransferClient
+	clients      service.Clients
+}
+
+// newCaseImportFakes returns fakes of the upstream APIs holding an open transaction and a company that a case can
+// be filed against
+func newCaseImportFakes() *caseImportFakes {
+	fakes := &caseImportFakes{
+		companies: service.NewFakeCompanyProfileClient(companieshouseapi.CompanyProfile{
+			CompanyNumber:  companyNumber,
+			CompanyName:    companyName,
+			CompanyStatus:  "active",
+			Jurisdiction:   "england-wales",
+			Type:           "private-shares-exemption-30",
+			DateOfCreation: "2000-06-26",
+		}),
+		transactions: service.NewFakeTransactionClient(transactionID),
+		files:        service.NewFakeFileTransferClient(),
+	}
+	fakes.clients = service.Clients{
+		CompanyProfile: fakes.companies,
+		Transaction:    fakes.transactions,
+		AlphaKey:       &service.FakeAlphaKeyClient{},
+		FileTransfer:   fakes.files,
+	}
+
+	return fakes
+}
+
+// upload stores a file with the fake File Transfer API, returning its ID
+func (f *caseImportFakes) upload(t *testing.T, name string) string {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", name)
+	part.Write([]byte("%PDF-1.4"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileID, err := f.files.UploadFile(req, file, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fileID
+}
+
+// caseImportRequest returns a request to import a valid case, with a practitioner who is appointed and a resolution
+// and statement of affairs filed with the uploaded attachments
+func caseImportRequest(resolutionID, statementID string) models.CaseImportRequest {
+	return models.CaseImportRequest{
+		InsolvencyRequest: models.InsolvencyRequest{
+			CompanyNumber: companyNumber,
+			CompanyName:   companyName,
+			CaseType:      "creditors-voluntary-liquidation",
+		},
+		Practitioners: []models.CaseImportPractitioner{{
+			PractitionerRequest: models.PractitionerRequest{
+				IPCode:    "1234",
+				FirstName: "Jo",
+				LastName:  "Bloggs",
+				Email:     "jo.bloggs@example.com",
+				Address: models.Address{
+					Premises:     "1",
+					AddressLine1: "Crown Way",
+					Locality:     "Cardiff",
+					PostalCode:   "CF14 3UZ",
+				},
+				Role: "final-liquidator",
+			},
+			Appointment: &models.PractitionerAppointment{AppointedOn: "2021-06-28", MadeBy: "creditors"},
+		}},
+		Attachments: []models.CaseImportAttachment{
+			{ID: resolutionID, AttachmentType: "resolution"},
+			{ID: statementID, AttachmentType: "statement-of-affairs-director"},
+		},
+		Resolution:         &models.Resolution{DateOfResolution: "2021-06-26", Attachments: []string{resolutionID}},
+		StatementOfAffairs: &models.StatementOfAffairs{StatementDate: "2021-06-26", Attachments: []string{statementID}},
+	}
+}
+
+func serveHandleImportCase(svc dao.Service, clients service.Clients, request interface{}, permissions string, accept string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(request)
+
+	ctx := context.WithValue(context.Background(), authentication.ContextKeyUserDetails, authentication.AuthUserDetails{ID: "user-1", Email: "jo.bloggs@example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/transactions/"+transactionID+"/insolvency/import", bytes.NewReader(body)).WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+	req.Header.Set("ERIC-Authorised-Token-Permissions", permissions)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	res := httptest.NewRecorder()
+	HandleImportCase(svc, clients, utils.NewHelperService(), nil, nil).ServeHTTP(res, req)
+	return res
+}
+
+func TestUnitHandleImportCase(t *testing.T) {
+	Convey("A valid case is stored with every section and added to the transaction", t, func() {
+		fakes := newCaseImportFakes()
+		resolutionID := fakes.upload(t, "resolution.pdf")
+		statementID := fakes.upload(t, "statement.pdf")
+		svc := dao.NewMemoryService()
+
+		res := serveHandleImportCase(svc, fakes.clients, caseImportRequest(resolutionID, statementID), caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusCreated)
+
+		var created models.CreatedInsolvencyResource
+		So(json.Unmarshal(res.Body.Bytes(), &created), ShouldBeNil)
+		So(created.CompanyNumber, ShouldEqual, companyNumber)
+
+		insolvencyResource, err := svc.GetInsolvencyResource(context.Background(), transactionID)
+		So(err, ShouldBeNil)
+		So(insolvencyResource.CreatedBy, ShouldEqual, "user-1")
+		So(insolvencyResource.Data.Practitioners, ShouldHaveLength, 1)
+		So(insolvencyResource.Data.Practitioners[0].IPCode, ShouldEqual, "00001234")
+		So(insolvencyResource.Data.Practitioners[0].Appointment.AppointedOn, ShouldEqual, "2021-06-28")
+		So(insolvencyResource.Data.Attachments, ShouldHaveLength, 2)
+		So(insolvencyResource.Data.Resolution.Attachments, ShouldResemble, []string{resolutionID})
+		So(insolvencyResource.Data.StatementOfAffairs.Attachments, ShouldResemble, []string{statementID})
+
+		events, err := svc.GetOutboxEvents(context.Background(), 0)
+		So(err, ShouldBeNil)
+		So(events, ShouldHaveLength, 2)
+		So(events[0].Type, ShouldEqual, dao.EventCaseCreated)
+		So(events[1].Type, ShouldEqual, dao.EventPractitionerAppointed)
+		So(events[1].Data, ShouldContainSubstring, `"practitioner_id":"`+insolvencyResource.Data.Practitioners[0].ID+`"`)
+
+		_, patched := fakes.transactions.PatchedInsolvencyResource(transactionID)
+		So(patched, ShouldBeTrue)
+	})
+
+	Convey("The errors in every section are reported and nothing is stored", t, func() {
+		fakes := newCaseImportFakes()
+		resolutionID := fakes.upload(t, "resolution.pdf")
+		svc := dao.NewMemoryService()
+
+		request := caseImportRequest(resolutionID, "missing-file")
+		request.Practitioners[0].Role = "receiver"
+		request.Practitioners = append(request.Practitioners, models.CaseImportPractitioner{
+			PractitionerRequest: request.Practitioners[0].PractitionerRequest,
+			Appointment:         &models.PractitionerAppointment{AppointedOn: "2021-06-28", MadeBy: "company"},
+		})
+		request.Practitioners[1].IPCode = "5678"
+		request.Practitioners[1].Role = "final-liquidator"
+		request.Resolution.DateOfResolution = "1999-01-01"
+		request.StatementOfAffairs.Attachments = []string{resolutionID}
+
+		res := serveHandleImportCase(svc, fakes.clients, request, caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+
+		var report models.CaseImportReport
+		So(json.Unmarshal(res.Body.Bytes(), &report), ShouldBeNil)
+		So(report.Message, ShouldContainSubstring, "was not imported")
+
+		sections := make(map[string]string)
+		for _, section := range report.Sections {
+			sections[section.Section] = section.Errors[0]
+		}
+		So(sections, ShouldHaveLength, 5)
+		So(sections["practitioners[0]"], ShouldContainSubstring, "the practitioner role must be final-liquidator")
+		So(sections["practitioners[1].appointment"], ShouldContainSubstring, "made_by cannot be [company]")
+		So(sections["attachments[1]"], ShouldContainSubstring, "there was a problem getting the details of attachment [missing-file]")
+		So(sections["resolution"], ShouldContainSubstring, "should not be in the future or before the company was incorporated")
+		So(sections["statement_of_affairs"], ShouldContainSubstring, "is of type [resolution]")
+
+		_, err := svc.GetInsolvencyResource(context.Background(), transactionID)
+		So(err, ShouldNotBeNil)
+		_, patched := fakes.transactions.PatchedInsolvencyResource(transactionID)
+		So(patched, ShouldBeFalse)
+	})
+
+	Convey("Clients accepting problems are sent a field error for each failure", t, func() {
+		fakes := newCaseImportFakes()
+		request := caseImportRequest("", "")
+		request.Attachments = nil
+		request.Resolution = nil
+		request.StatementOfAffairs = nil
+		request.Practitioners[0].Email = "not-an-email"
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, request, caseImportPermissions, utils.ProblemContentType)
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+		So(res.Header().Get("Content-Type"), ShouldEqual, utils.ProblemContentType)
+
+		var problem models.ProblemResource
+		So(json.Unmarshal(res.Body.Bytes(), &problem), ShouldBeNil)
+		So(problem.Errors, ShouldResemble, []models.FieldError{{Field: "practitioners[0]", Message: "email must be a valid email address"}})
+	})
+
+	Convey("Nothing but the case is checked when the company is not valid", t, func() {
+		fakes := newCaseImportFakes()
+		request := caseImportRequest("", "")
+		request.CompanyNumber = "99999999"
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, request, caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+
+		var report models.CaseImportReport
+		So(json.Unmarshal(res.Body.Bytes(), &report), ShouldBeNil)
+		So(report.Sections, ShouldHaveLength, 1)
+		So(report.Sections[0].Section, ShouldEqual, "case")
+		So(report.Sections[0].Errors[0], ShouldContainSubstring, "company not found")
+	})
+
+	Convey("Appointments and attachments need the permissions of their own routes", t, func() {
+		fakes := newCaseImportFakes()
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, caseImportRequest("a", "b"), authentication.PermissionKeyInsolvencyCases+"=read,manage_attachments", "")
+		So(res.Code, ShouldEqual, http.StatusUnauthorized)
+
+		res = serveHandleImportCase(dao.NewMemoryService(), fakes.clients, caseImportRequest("a", "b"), authentication.PermissionKeyInsolvencyCases+"=read,appoint_practitioners", "")
+		So(res.Code, ShouldEqual, http.StatusUnauthorized)
+	})
+
+	Convey("The update permission implies the permissions for appointments and attachments", t, func() {
+		fakes := newCaseImportFakes()
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, caseImportRequest(fakes.upload(t, "resolution.pdf"), fakes.upload(t, "statement.pdf")), authentication.PermissionKeyInsolvencyCases+"=update", "")
+		So(res.Code, ShouldEqual, http.StatusCreated)
+	})
+
+	Convey("A case that already exists is not replaced", t, func() {
+		fakes := newCaseImportFakes()
+		svc := dao.NewMemoryService()
+		So(svc.CreateInsolvencyResource(context.Background(), &models.InsolvencyResourceDao{TransactionID: transactionID}), ShouldBeNil)
+
+		res := serveHandleImportCase(svc, fakes.clients, caseImportRequest(fakes.upload(t, "resolution.pdf"), fakes.upload(t, "statement.pdf")), caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusConflict)
+	})
+
+	Convey("The import is abandoned when an upstream API cannot be reached", t, func() {
+		fakes := newCaseImportFakes()
+		fakes.companies.Err = errors.New("connection refused")
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, caseImportRequest("a", "b"), caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusInternalServerError)
+	})
+
+	Convey("The transaction must be open", t, func() {
+		fakes := newCaseImportFakes()
+		fakes.transactions.SetTransactionStatus(transactionID, "closed")
+
+		res := serveHandleImportCase(dao.NewMemoryService(), fakes.clients, caseImportRequest("a", "b"), caseImportPermissions, "")
+		So(res.Code, ShouldEqual, http.StatusForbidden)
+	})
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/gorilla/mux"
+	"github.com/jarcoal/httpmock"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	integrationDatabase = "insolvency_integration_test"
+	parallelRequests    = 10
+)
+
+// newUnitService returns a MemoryService, so that the concurrency tests also run as unit tests
+func newUnitService(*testing.T) dao.Service {
+	return dao.NewMemoryService()
+}
+
+// newIntegrationService returns a MongoService backed by a new collection in the MongoDB instance at
+// MONGODB_URL, which is dropped when the test finishes. The test is skipped if MONGODB_URL is not set
+func newIntegrationService(t *testing.T) dao.Service {
+	mongoDBURL := os.Getenv("MONGODB_URL")
+	if mongoDBURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	collection := fmt.Sprintf("concurrency_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoDBURL))
+		if err != nil {
+			t.Logf("error connecting to mongodb to drop collection [%s]: [%v]", collection, err)
+			return
+		}
+		defer client.Disconnect(context.Background())
+		if err := client.Database(integrationDatabase).Collection(collection).Drop(context.Background()); err != nil {
+			t.Logf("error dropping collection [%s]: [%v]", collection, err)
+		}
+	})
+
+	svc, err := dao.NewDAOService(&config.Config{
+		MongoDBURL:      mongoDBURL,
+		Database:        integrationDatabase,
+		MongoCollection: collection,
+	})
+	if err != nil {
+		t.Fatalf("error creating DAO service: [%v]", err)
+	}
+
+	return svc
+}
+
+// seedInsolvencyCase stores a CVL insolvency case with a single attachment of the supplied type
+func seedInsolvencyCase(svc dao.Service, attachmentType string) {
+	err := svc.CreateInsolvencyResource(context.Background(), &models.InsolvencyResourceDao{
+		TransactionID: transactionID,
+		Data: models.InsolvencyResourceDaoData{
+			CompanyNumber: "1234",
+			CaseType:      constants.CVL.String(),
+			CompanyName:   companyName,
+		},
+	})
+	So(err, ShouldBeNil)
+
+	if attachmentType != "" {
+		_, err = svc.AddAttachmentToInsolvencyResource(context.Background(), transactionID, "123456789", attachmentType)
+		So(err, ShouldBeNil)
+	}
+}
+
+// serveConcurrently serves the requests at the same time and counts the responses by status code
+func serveConcurrently(n int, serve func(i int) *httptest.ResponseRecorder) map[int]int {
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	start := make(chan struct{})
+	statusCodes := make(map[int]int)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			res := serve(i)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			statusCodes[res.Code]++
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return statusCodes
+}
+
+// serveCreateCaseResource serves a request to file a resolution, statement of affairs or progress report with the
+// supplied If-Match header, which is left out if empty
+func serveCreateCaseResource(handler http.Handler, path string, body []byte, ifMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func TestUnitConcurrentCreateCaseResource(t *testing.T) {
+	testConcurrentCreateCaseResource(t, newUnitService)
+}
+
+func TestIntegrationConcurrentCreateCaseResource(t *testing.T) {
+	testConcurrentCreateCaseResource(t, newIntegrationService)
+}
+
+func testConcurrentCreateCaseResource(t *testing.T, newService func(t *testing.T) dao.Service) {
+	helperService := utils.NewHelperService()
+
+	resources := []struct {
+		name           string
+		attachmentType string
+		path           string
+		body           interface{}
+		handler        func(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler
+	}{
+		{"resolution", "resolution", "/transactions/123456789/insolvency/resolution", generateResolution(), HandleCreateResolution},
+		{"statement of affairs", constants.StatementOfAffairsDirector.String(), "/transactions/123456789/insolvency/statement-of-affairs", generateStatement(), HandleCreateStatementOfAffairs},
+		{"progress report", "progress-report", "/transactions/123456789/insolvency/progress-report", generateProgressReport(), HandleCreateProgressReport},
+	}
+
+	for _, resource := range resources {
+		Convey("Only one of several concurrent requests replaces the "+resource.name+" with the same If-Match etag", t, func() {
+			svc := newService(t)
+			seedInsolvencyCase(svc, resource.attachmentType)
+
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+			httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
+
+			handler := resource.handler(svc, sdkClients, helperService)
+			body, _ := json.Marshal(resource.body)
+
+			res := serveCreateCaseResource(handler, resource.path, body, "")
+			So(res.Code, ShouldEqual, http.StatusCreated)
+
+			var filed struct {
+				Etag string `json:"etag"`
+			}
+			So(json.Unmarshal(res.Body.Bytes(), &filed), ShouldBeNil)
+			So(filed.Etag, ShouldNotBeEmpty)
+
+			statusCodes := serveConcurrently(parallelRequests, func(int) *httptest.ResponseRecorder {
+				return serveCreateCaseResource(handler, resource.path, body, filed.Etag)
+			})
+
+			So(statusCodes[http.StatusCreated], ShouldEqual, 1)
+			So(statusCodes[http.StatusPreconditionFailed], ShouldEqual, parallelRequests-1)
+		})
+	}
+}
+
+func TestUnitConcurrentCreatePractitionersResource(t *testing.T) {
+	testConcurrentCreatePractitionersResource(t, newUnitService)
+}
+
+func TestIntegrationConcurrentCreatePractitionersResource(t *testing.T) {
+	testConcurrentCreatePractitionersResource(t, newIntegrationService)
+}
+
+func testConcurrentCreatePractitionersResource(t *testing.T, newService func(t *testing.T) dao.Service) {
+	helperService := utils.NewHelperService()
+
+	Convey("Concurrent requests cannot add more than five practitioners", t, func() {
+		svc := newService(t)
+		seedInsolvencyCase(svc, "")
+
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+
+		statusCodes := serveConcurrently(parallelRequests, func(i int) *httptest.ResponseRecorder {
+			practitioner := generatePractitioner()
+			practitioner.IPCode = fmt.Sprintf("%d", 1000+i)
+			body, _ := json.Marshal(practitioner)
+			return serveHandleCreatePractitionersResource(body, svc, helperService, true, httptest.NewRecorder())
+		})
+
+		So(statusCodes[http.StatusCreated], ShouldEqual, 5)
+		So(statusCodes[http.StatusBadRequest], ShouldEqual, parallelRequests-5)
+
+		practitioners, err := svc.GetPractitionerResources(context.Background(), transactionID)
+		So(err, ShouldBeNil)
+		So(practitioners, ShouldHaveLength, 5)
+	})
+
+	Convey("Concurrent requests cannot add the same practitioner twice", t, func() {
+		svc := newService(t)
+		seedInsolvencyCase(svc, "")
+
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+
+		body, _ := json.Marshal(generatePractitioner())
+
+		statusCodes := serveConcurrently(parallelRequests, func(int) *httptest.ResponseRecorder {
+			return serveHandleCreatePractitionersResource(body, svc, helperService, true, httptest.NewRecorder())
+		})
+
+		So(statusCodes[http.StatusCreated], ShouldEqual, 1)
+		So(statusCodes[http.StatusConflict], ShouldEqual, parallelRequests-1)
+	})
+}
+
+func TestUnitConcurrentUpdateAttachmentStatus(t *testing.T) {
+	testConcurrentUpdateAttachmentStatus(t, newUnitService)
+}
+
+func TestIntegrationConcurrentUpdateAttachmentStatus(t *testing.T) {
+	testConcurrentUpdateAttachmentStatus(t, newIntegrationService)
+}
+
+func testConcurrentUpdateAttachmentStatus(t *testing.T, newService func(t *testing.T) dao.Service) {
+	Convey("A processed attachment is not overwritten by a concurrent antivirus result", t, func() {
+		svc := newService(t)
+		seedInsolvencyCase(svc, "resolution")
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		errs := make(chan error, parallelRequests)
+		for i := 0; i < parallelRequests; i++ {
+			status := "processed"
+			if i%2 == 1 {
+				status = "integrity_failed"
+			}
+
+			wg.Add(1)
+			go func(status string) {
+				defer wg.Done()
+				<-start
+				err := svc.UpdateAttachmentStatus(context.Background(), transactionID, "123456789", status)
+				errs <- err
+			}(status)
+		}
+		close(start)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			So(err, ShouldBeNil)
+		}
+
+		// Once processed, the attachment keeps that status whichever order the updates ran in
+		attachment, err := svc.GetAttachmentFromInsolvencyResource(context.Background(), transactionID, "123456789")
+		So(err, ShouldBeNil)
+		So(attachment.Status, ShouldEqual, "processed")
+	})
+}
+
+func TestUnitConcurrentCreateInsolvencyResource(t *testing.T) {
+	testConcurrentCreateInsolvencyResource(t, newUnitService)
+}
+
+func TestIntegrationConcurrentCreateInsolvencyResource(t *testing.T) {
+	testConcurrentCreateInsolvencyResource(t, newIntegrationService)
+}
+
+func testConcurrentCreateInsolvencyResource(t *testing.T, newService func(t *testing.T) dao.Service) {
+	Convey("Only one insolvency case is created for a transaction", t, func() {
+		svc := newService(t)
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		statusCodes := make(chan int, parallelRequests)
+		for i := 0; i < parallelRequests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				err := svc.CreateInsolvencyResource(context.Background(), &models.InsolvencyResourceDao{TransactionID: transactionID})
+				statusCode := http.StatusCreated
+				if err != nil {
+					statusCode = utils.ErrorStatus(err)
+				}
+				statusCodes <- statusCode
+			}()
+		}
+		close(start)
+		wg.Wait()
+		close(statusCodes)
+
+		counts := make(map[int]int)
+		for statusCode := range statusCodes {
+			counts[statusCode]++
+		}
+		So(counts[http.StatusCreated], ShouldEqual, 1)
+		So(counts[http.StatusConflict], ShouldEqual, parallelRequests-1)
+	})
+}
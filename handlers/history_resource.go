@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/transformers"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultAuditHistoryLimit is the number of audit events returned for a query across cases when no limit is given
+	defaultAuditHistoryLimit = 100
+
+	// maxAuditHistoryLimit is the largest number of audit events returned for a query across cases
+	maxAuditHistoryLimit = 1000
+)
+
+// HandleGetCaseHistory retrieves the audit trail of the changes made to the insolvency case with the specified
+// transactionID, oldest first
+func HandleGetCaseHistory(svc dao.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Check for a transaction id in request
+		transactionID := utils.GetTransactionIDFromVars(mux.Vars(req))
+		if transactionID == "" {
+			log.ErrorR(req, fmt.Errorf("there is no transaction id in the url path"))
+			m := models.NewMessageResponse("transaction id is not in the url path")
+			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			return
+		}
+
+		log.InfoR(req, fmt.Sprintf("start GET request for history of insolvency case with transaction id: %s", transactionID))
+
+		events, err := svc.GetAuditEvents(req.Context(), models.AuditEventFilter{TransactionID: transactionID})
+		if err != nil {
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
+			return
+		}
+
+		// A case without any history has not been created, unless it was created before changes were audited
+		if len(events) == 0 {
+			if _, err := svc.GetInsolvencyResource(req.Context(), transactionID); err != nil {
+				log.ErrorR(req, err)
+				utils.WriteErrorResponse(w, req, err)
+				return
+			}
+		}
+
+		utils.WriteJSONWithStatus(w, req, transformers.AuditEventDaoListToResponseList(events), http.StatusOK)
+	})
+}
+
+// HandleGetAuditHistory retrieves the audit events across all insolvency cases that match the transaction_id,
+// company_number, user_id, route, from and to query parameters, oldest first and up to the limit parameter
+func HandleGetAuditHistory(svc dao.Service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		filter, err := auditEventFilter(req)
+		if err != nil {
+			log.ErrorR(req, err)
+			m := models.NewMessageResponse(err.Error())
+			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			return
+		}
+
+		log.InfoR(req, "start GET request for audit history", log.Data{"transaction_id": filter.TransactionID, "company_number": filter.CompanyNumber, "user_id": filter.UserID, "route": filter.Route})
+
+		events, err := svc.GetAuditEvents(req.Context(), filter)
+		if err != nil {
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
+			return
+		}
+
+		utils.WriteJSONWithStatus(w, req, transformers.AuditEventDaoListToResponseList(events), http.StatusOK)
+	})
+}
+
+// auditEventFilter returns the filter given by the query parameters of a request for audit history
+func auditEventFilter(req *http.Request) (models.AuditEventFilter, error) {
+	query := req.URL.Query()
+	filter := models.AuditEventFilter{
+		TransactionID: query.Get("transaction_id"),
+		CompanyNumber: query.Get("company_number"),
+		UserID:        query.Get("user_id"),
+		Route:         query.Get("route"),
+		Limit:         defaultAuditHistoryLimit,
+	}
+
+	for name, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be an RFC 3339 date and time, such as 2021-06-28T09:00:00Z", name)
+		}
+		*t = parsed
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditHistoryLimit {
+			return filter, fmt.Errorf("limit must be a number from 1 to %d", maxAuditHistoryLimit)
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
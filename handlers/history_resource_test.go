@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/dao"
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveGetCaseHistoryRequest(svc dao.Service, tranIDSet bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/transactions/"+transactionID+"/insolvency/history", nil)
+	if tranIDSet {
+		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+	}
+	res := httptest.NewRecorder()
+
+	HandleGetCaseHistory(svc).ServeHTTP(res, req)
+
+	return res
+}
+
+func serveGetAuditHistoryRequest(svc dao.Service, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/private/insolvency/history?"+query, nil)
+	res := httptest.NewRecorder()
+
+	HandleGetAuditHistory(svc).ServeHTTP(res, req)
+
+	return res
+}
+
+// historyService returns a store holding a case for the transaction and audit events for it and another case
+func historyService(t *testing.T) *dao.MemoryService {
+	svc := dao.NewMemoryService()
+	ctx := context.Background()
+	if err := svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{TransactionID: transactionID, Data: models.InsolvencyResourceDaoData{CompanyNumber: "01234567"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC)
+	for i, event := range []models.AuditEventDao{
+		{TransactionID: transactionID, CompanyNumber: "01234567", UserID: "user1", Route: "createInsolvencyResource"},
+		{TransactionID: "other", CompanyNumber: "07654321", UserID: "user2", Route: "createInsolvencyResource"},
+		{TransactionID: transactionID, CompanyNumber: "01234567", UserID: "user2", Route: "deletePractitioner",
+			Changes: []models.AuditChangeDao{{Path: "data.practitioners[id=1]", Before: `{"id":"1"}`}}},
+	} {
+		event.ID = fmt.Sprintf("event%d", i+1)
+		event.CreatedAt = start.Add(time.Duration(i) * time.Hour)
+		if err := svc.CreateAuditEvent(ctx, &event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return svc
+}
+
+func auditEventIDs(res *httptest.ResponseRecorder) []string {
+	var events []models.AuditEvent
+	So(json.Unmarshal(res.Body.Bytes(), &events), ShouldBeNil)
+
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestUnitHandleGetCaseHistory(t *testing.T) {
+	Convey("Must need a transaction ID in the url", t, func() {
+		res := serveGetCaseHistoryRequest(dao.NewMemoryService(), false)
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+	})
+
+	Convey("The history of the case is returned oldest first", t, func() {
+		res := serveGetCaseHistoryRequest(historyService(t), true)
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(auditEventIDs(res), ShouldResemble, []string{"event1", "event3"})
+		So(res.Body.String(), ShouldContainSubstring, `"changes":[{"path":"data.practitioners[id=1]","before":{"id":"1"}}]`)
+	})
+
+	Convey("A case without history returns an empty list", t, func() {
+		svc := dao.NewMemoryService()
+		So(svc.CreateInsolvencyResource(context.Background(), &models.InsolvencyResourceDao{TransactionID: transactionID}), ShouldBeNil)
+
+		res := serveGetCaseHistoryRequest(svc, true)
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldEqual, "[]\n")
+	})
+
+	Convey("A case that does not exist is not found", t, func() {
+		res := serveGetCaseHistoryRequest(dao.NewMemoryService(), true)
+		So(res.Code, ShouldEqual, http.StatusNotFound)
+	})
+
+	Convey("Error retrieving the history", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().GetAuditEvents(gomock.Any(), models.AuditEventFilter{TransactionID: transactionID}).Return(nil, fmt.Errorf("database error"))
+
+		res := serveGetCaseHistoryRequest(mockService, true)
+		So(res.Code, ShouldEqual, http.StatusInternalServerError)
+	})
+}
+
+func TestUnitHandleGetAuditHistory(t *testing.T) {
+	Convey("Events across cases are filtered by the query parameters", t, func() {
+		svc := historyService(t)
+
+		res := serveGetAuditHistoryRequest(svc, "")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(auditEventIDs(res), ShouldResemble, []string{"event1", "event2", "event3"})
+
+		So(auditEventIDs(serveGetAuditHistoryRequest(svc, "user_id=user2")), ShouldResemble, []string{"event2", "event3"})
+		So(auditEventIDs(serveGetAuditHistoryRequest(svc, "company_number=07654321")), ShouldResemble, []string{"event2"})
+		So(auditEventIDs(serveGetAuditHistoryRequest(svc, "route=deletePractitioner&transaction_id="+transactionID)), ShouldResemble, []string{"event3"})
+		So(auditEventIDs(serveGetAuditHistoryRequest(svc, "from=2021-06-28T11:00:00Z&to=2021-06-28T12:00:00Z")), ShouldResemble, []string{"event2"})
+		So(auditEventIDs(serveGetAuditHistoryRequest(svc, "limit=1")), ShouldResemble, []string{"event1"})
+	})
+
+	Convey("Invalid query parameters are rejected", t, func() {
+		svc := dao.NewMemoryService()
+
+		res := serveGetAuditHistoryRequest(svc, "from=yesterday")
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+		So(res.Body.String(), ShouldContainSubstring, "from must be an RFC 3339 date and time")
+
+		res = serveGetAuditHistoryRequest(svc, "limit=1001")
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+		So(res.Body.String(), ShouldContainSubstring, "limit must be a number from 1 to 1000")
+	})
+
+	Convey("The default limit is applied", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().GetAuditEvents(gomock.Any(), models.AuditEventFilter{UserID: "user1", Limit: defaultAuditHistoryLimit}).Return(nil, nil)
+
+		res := serveGetAuditHistoryRequest(mockService, "user_id=user1")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldEqual, "[]\n")
+	})
+}
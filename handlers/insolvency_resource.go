@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
-	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/metrics"
 	"github.com/companieshouse/insolvency-api/models"
 	"github.com/companieshouse/insolvency-api/service"
 	"github.com/companieshouse/insolvency-api/transformers"
@@ -16,8 +21,8 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// HandleCreateInsolvencyResource creates an insolvency resource
-func HandleCreateInsolvencyResource(svc dao.Service, helperService utils.HelperService) http.Handler {
+// HandleCreateInsolvencyResource creates an insolvency resource owned by the authenticated user and their firm
+func HandleCreateInsolvencyResource(svc dao.Service, clients service.Clients, helperService utils.HelperService, firms service.FirmMembership) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction id exists in path
@@ -53,31 +58,27 @@ func HandleCreateInsolvencyResource(svc dao.Service, helperService utils.HelperS
 		}
 
 		// Check with transaction API that provided transaction ID exists
-		err, httpStatus := service.CheckTransactionID(transactionID, req)
+		err = clients.CheckTransactionID(transactionID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("transaction id [%s] was not found valid for insolvency request against company [%s] when checking transaction api: [%v]",
 				transactionID, request.CompanyNumber, err))
-			m := models.NewMessageResponse(fmt.Sprintf("transaction id [%s] was not found valid for insolvency: %v", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("transaction id [%s] was not found valid for insolvency: %w", transactionID, err))
 			return
 		}
 
 		// Check with company profile API if company exists
-		var companyProfile *companieshouseapi.CompanyProfile
-		err, httpStatus, companyProfile = service.CheckCompanyExists(&request, req)
+		companyProfile, err := clients.CheckCompanyExists(&request, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf(constants.MsgCompanyInvalidProfileAPI, err))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			utils.WriteErrorResponse(w, req, fmt.Errorf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
 			return
 		}
 
 		// Check with alphakey service if company name valid
-		err, httpStatus = service.CheckCompanyNameAlphaKey(companyProfile.CompanyName, &request, req)
+		err = clients.CheckCompanyNameAlphaKey(companyProfile.CompanyName, &request, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf(constants.MsgCompanyInvalidProfileAPI, err))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			utils.WriteErrorResponse(w, req, fmt.Errorf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
 			return
 		}
 
@@ -85,8 +86,7 @@ func HandleCreateInsolvencyResource(svc dao.Service, helperService utils.HelperS
 		err = service.CheckCompanyDetailsAreValid(companyProfile)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf(constants.MsgCompanyInvalidProfileAPI, err))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+			utils.WriteErrorResponse(w, req, fmt.Errorf(constants.MsgCompanyInvalidForInsolvency, request.CompanyNumber, err))
 			return
 		}
 
@@ -98,20 +98,29 @@ func HandleCreateInsolvencyResource(svc dao.Service, helperService utils.HelperS
 			return
 		}
 
-		err, httpStatus = svc.CreateInsolvencyResource(model)
+		// Record the user creating the case and the firm they belong to, so that access can be shared with colleagues
+		userDetails, _ := req.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+		model.CreatedBy = userDetails.ID
+		model.FirmID, err = service.GetUserFirmID(firms, userDetails)
+		if err != nil {
+			log.ErrorR(req, err)
+			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction id [%s]", transactionID))
+			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			return
+		}
+
+		err = svc.CreateInsolvencyResource(req.Context(), model)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to create insolvency resource in database for transaction [%s]: %v", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction [%s]: %v", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction [%s]: %w", transactionID, err))
 			return
 		}
 
 		// Patch transaction API with new insolvency resource
-		err, httpStatus = service.PatchTransactionWithInsolvencyResource(transactionID, model, req)
+		err = clients.PatchTransactionWithInsolvencyResource(transactionID, model, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error patching transaction api with insolvency resource [%s]: [%v]", model.Links.Self, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error patching transaction api with insolvency resource [%s]: [%v]", model.Links.Self, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("error patching transaction api with insolvency resource [%s]: [%w]", model.Links.Self, err))
 			return
 		}
 
@@ -122,7 +131,7 @@ func HandleCreateInsolvencyResource(svc dao.Service, helperService utils.HelperS
 }
 
 // HandleGetValidationStatus returns whether a created insolvency case is acceptable to be closed by the transaction API
-func HandleGetValidationStatus(svc dao.Service) http.Handler {
+func HandleGetValidationStatus(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check for a transaction id in request
@@ -137,10 +146,11 @@ func HandleGetValidationStatus(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for validating insolvency resource with transaction id: %s", transactionID))
 
-		insolvencyResource, err := svc.GetInsolvencyResource(transactionID)
+		insolvencyResource, err := svc.GetInsolvencyResource(req.Context(), transactionID)
 		if err != nil {
 			// Check if insolvency case was not found
-			if err.Error() == fmt.Sprintf("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID) {
+			var notFoundErr *apperrors.NotFoundError
+			if errors.As(err, &notFoundErr) {
 				message := fmt.Sprintf("insolvency case with transactionID [%s] not found", transactionID)
 				log.Info(message)
 				m := models.NewMessageResponse(message)
@@ -149,19 +159,44 @@ func HandleGetValidationStatus(svc dao.Service) http.Handler {
 				return
 			}
 			log.ErrorR(req, fmt.Errorf("error getting insolvency resource from DB: [%s]", err))
+			utils.WriteErrorResponse(w, req, err)
+			return
+		}
+
+		cfg, err := config.Get()
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("error getting config: [%v]", err))
 			m := models.NewMessageResponse("there was a problem handling your request")
 			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
 			return
 		}
 
 		validationErrors := service.ValidateInsolvencyDetails(insolvencyResource)
-		antivirusValidationErrors := service.ValidateAntivirus(svc, insolvencyResource, req)
+		antivirusValidationErrors := service.ValidateAntivirus(svc, clients, insolvencyResource, req)
 
 		// If antivirus check has failed, set case false and append antivirus validation error to existing validation errors
 		if len(*antivirusValidationErrors) > 0 {
 			*validationErrors = append(*validationErrors, *antivirusValidationErrors...)
 		}
 
+		// If enabled, the authenticated user must be one of the practitioners on the case
+		if cfg.RequireUserIsPractitioner {
+			userDetails, _ := req.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			*validationErrors = append(*validationErrors, *service.ValidateUserIsPractitioner(insolvencyResource, userDetails)...)
+		}
+
+		// The rules the case failed replace those it failed before, so checking the status again does not count them twice
+		failedRules := make([]string, 0, len(*validationErrors))
+		for _, validationError := range *validationErrors {
+			failedRules = append(failedRules, validationError.Location)
+		}
+		previousRules, err := svc.SetValidationFailures(req.Context(), transactionID, failedRules)
+		if err != nil {
+			log.ErrorR(req, fmt.Errorf("error storing validation failures for insolvency case: [%v]", err))
+		} else {
+			metrics.CaseValidated(previousRules, failedRules)
+		}
+
 		isCaseValid := true
 		if len(*validationErrors) > 0 {
 			log.InfoR(req, fmt.Sprintf("case for transaction id [%s] was not found valid for submission for reason(s): [%v]", transactionID, *validationErrors))
@@ -176,7 +211,7 @@ func HandleGetValidationStatus(svc dao.Service) http.Handler {
 }
 
 // HandleGetFilings returns the resource in filings format for the filing-resource-handler to send to CHIPS
-func HandleGetFilings(svc dao.Service) http.Handler {
+func HandleGetFilings(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check for a transaction id in request
@@ -192,11 +227,11 @@ func HandleGetFilings(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start GET request for filings resource for transaction id: %s", transactionID))
 
 		// Check if transaction is closed before generating filings
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if !isTransactionClosed {
@@ -206,11 +241,17 @@ func HandleGetFilings(svc dao.Service) http.Handler {
 			return
 		}
 
-		filings, err := service.GenerateFilings(svc, transactionID)
+		filings, err := service.GenerateFilings(req.Context(), svc, transactionID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("error generating filings for [%v]: [%s]", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error generating filings for [%v]: [%s]", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("error generating filings for [%v]: [%w]", transactionID, err))
+			return
+		}
+
+		// Downstream systems are told once that the filings of the closed case were generated. The event is only
+		// written the first time, and a failure to write it is retried by the next request rather than failing this one
+		if err := recordFilingsGenerated(req.Context(), svc, transactionID, filings); err != nil {
+			log.ErrorR(req, fmt.Errorf("error recording filings generated for [%v]: [%s]", transactionID, err))
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully finished GET request for filings resource for transaction id: %s", transactionID))
@@ -218,3 +259,18 @@ func HandleGetFilings(svc dao.Service) http.Handler {
 		utils.WriteJSONWithStatus(w, req, filings, http.StatusOK)
 	})
 }
+
+// recordFilingsGenerated writes the filings generated event for the case to the outbox, holding the kind of each
+// filing, unless it has been written before
+func recordFilingsGenerated(ctx context.Context, svc dao.Service, transactionID string, filings []models.Filing) error {
+	kinds := make([]string, 0, len(filings))
+	for _, filing := range filings {
+		kinds = append(kinds, filing.Kind)
+	}
+
+	event, err := dao.FilingsGeneratedEvent(transactionID, kinds)
+	if err != nil {
+		return err
+	}
+	return svc.CreateOutboxEvent(ctx, event)
+}
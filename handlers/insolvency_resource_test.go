@@ -13,13 +13,17 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/go-session-handler/httpsession"
 	"github.com/companieshouse/go-session-handler/session"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
 	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -69,8 +73,13 @@ var alphakeyResponse = `
 `
 
 func serveHandleCreateInsolvencyResource(body []byte, service dao.Service, tranIDSet bool, helperService utils.HelperService, res *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	return serveHandleCreateInsolvencyResourceAsUser(body, service, tranIDSet, helperService, nil, authentication.AuthUserDetails{}, res)
+}
+
+func serveHandleCreateInsolvencyResourceAsUser(body []byte, svc dao.Service, tranIDSet bool, helperService utils.HelperService, firms service.FirmMembership, userDetails authentication.AuthUserDetails, res *httptest.ResponseRecorder) *httptest.ResponseRecorder {
 	ctx := context.WithValue(context.Background(), httpsession.ContextKeySession, &session.Session{})
-	handler := HandleCreateInsolvencyResource(service, helperService)
+	ctx = context.WithValue(ctx, authentication.ContextKeyUserDetails, userDetails)
+	handler := HandleCreateInsolvencyResource(svc, sdkClients, helperService, firms)
 
 	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewReader(body)).WithContext(ctx)
 
@@ -180,7 +189,7 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyName:   companyName,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -206,7 +215,7 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -228,7 +237,7 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -253,7 +262,7 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -278,7 +287,7 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -306,11 +315,11 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		// Expect CreateInsolvencyResource to be called once and return an error
-		mockService.EXPECT().CreateInsolvencyResource(gomock.Any()).Return(errors.New("insolvency case already exists"), http.StatusConflict).Times(1)
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).Return(apperrors.Conflict("insolvency case already exists")).Times(1)
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil).AnyTimes()
 
 		res := serveHandleCreateInsolvencyResource(body, mockService, true, mockHelperService, rec)
@@ -337,12 +346,12 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 		// Expect CreateInsolvencyResource to be called once and return an error
-		mockService.EXPECT().CreateInsolvencyResource(gomock.Any()).Return(errors.New("error when creating mongo resource"), http.StatusInternalServerError).Times(1)
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).Return(errors.New("error when creating mongo resource")).Times(1)
 
 		res := serveHandleCreateInsolvencyResource(body, mockService, true, mockHelperService, rec)
 
@@ -371,12 +380,12 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 		// Expect CreateInsolvencyResource to be called once and not return an error
-		mockService.EXPECT().CreateInsolvencyResource(gomock.Any()).Return(nil, http.StatusCreated).Times(1)
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleCreateInsolvencyResource(body, mockService, true, mockHelperService, rec)
 
@@ -405,12 +414,12 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 		// Expect CreateInsolvencyResource to be called once and not return an error
-		mockService.EXPECT().CreateInsolvencyResource(gomock.Any()).Return(nil, http.StatusCreated).Times(1)
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleCreateInsolvencyResource(body, mockService, true, mockHelperService, rec)
 
@@ -439,28 +448,82 @@ func TestUnitHandleCreateInsolvencyResource(t *testing.T) {
 			CompanyNumber: companyNumber,
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 		// Expect CreateInsolvencyResource to be called once and not return an error
-		mockService.EXPECT().CreateInsolvencyResource(gomock.Any()).Return(nil, http.StatusCreated).Times(1)
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleCreateInsolvencyResource(body, mockService, true, mockHelperService, rec)
 
 		So(res.Code, ShouldEqual, http.StatusCreated)
 	})
+
+	Convey("Case is recorded against the user creating it and their firm", t, func() {
+		mockService, mockHelperService, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return a valid transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+
+		// Expect the company profile api to be called and return a valid company
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/company/01234567", httpmock.NewStringResponder(http.StatusOK, companyProfileResponse))
+
+		// Expect the alphakeyservice api to be called and return an alphakey
+		httpmock.RegisterResponder(http.MethodGet, "http://localhost:18103/alphakey?name=companyName", httpmock.NewStringResponder(http.StatusOK, alphakeyResponse))
+
+		// Expect the transaction api to be patched and return a success
+		httpmock.RegisterResponder(http.MethodPatch, "http://localhost:4001/private/transactions/12345678", httpmock.NewStringResponder(http.StatusNoContent, transactionProfileResponse))
+
+		body, _ := json.Marshal(&models.InsolvencyRequest{
+			CaseType:      constants.CVL.String(),
+			CompanyName:   companyName,
+			CompanyNumber: companyNumber,
+		})
+		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
+		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
+		// Expect CreateInsolvencyResource to be called once with the owner and firm recorded
+		var createdCase *models.InsolvencyResourceDao
+		mockService.EXPECT().CreateInsolvencyResource(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+			createdCase = dao
+			return nil
+		}).Times(1)
+
+		firms := stubFirmMembership{"joe@bloggs.com": "firm1"}
+		userDetails := authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"}
+
+		res := serveHandleCreateInsolvencyResourceAsUser(body, mockService, true, mockHelperService, firms, userDetails, rec)
+
+		So(res.Code, ShouldEqual, http.StatusCreated)
+		So(createdCase.CreatedBy, ShouldEqual, "user1234")
+		So(createdCase.FirmID, ShouldEqual, "firm1")
+	})
+}
+
+// stubFirmMembership is a firm membership that returns firm IDs from a map
+type stubFirmMembership map[string]string
+
+func (s stubFirmMembership) GetFirmID(email string) (string, error) {
+	return s[email], nil
 }
 
 func serveHandleGetValidationStatus(service dao.Service, tranIDSet bool) *httptest.ResponseRecorder {
+	return serveHandleGetValidationStatusAsUser(service, tranIDSet, authentication.AuthUserDetails{})
+}
+
+func serveHandleGetValidationStatusAsUser(service dao.Service, tranIDSet bool, userDetails authentication.AuthUserDetails) *httptest.ResponseRecorder {
 	path := constants.TransactionsPath + transactionID + constants.ValidationStatusPath
-	req := httptest.NewRequest(http.MethodGet, path, nil)
+	ctx := context.WithValue(context.Background(), authentication.ContextKeyUserDetails, userDetails)
+	req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
 	if tranIDSet {
 		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
 	}
 	res := httptest.NewRecorder()
 
-	handler := HandleGetValidationStatus(service)
+	handler := HandleGetValidationStatus(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -474,7 +537,7 @@ func serveHandleGetFilings(service dao.Service, tranIDSet bool) *httptest.Respon
 	}
 	res := httptest.NewRecorder()
 
-	handler := HandleGetFilings(service)
+	handler := HandleGetFilings(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -533,7 +596,7 @@ func TestUnitHandleGetValidationStatus(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		// Expect GetInsolvencyResource to be called once and return an error for the insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, apperrors.NotFound("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID)).Times(1)
 
 		res := serveHandleGetValidationStatus(mockService, true)
 
@@ -546,12 +609,12 @@ func TestUnitHandleGetValidationStatus(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		// Expect GetInsolvencyResource to be called once and return an error for the insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, errors.New("error getting insolvency case from DB")).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, errors.New("error getting insolvency case from DB")).Times(1)
 
 		res := serveHandleGetValidationStatus(mockService, true)
 
 		So(res.Code, ShouldEqual, http.StatusInternalServerError)
-		So(res.Body.String(), ShouldContainSubstring, `there was a problem handling your request`)
+		So(res.Body.String(), ShouldContainSubstring, `error getting insolvency case from DB`)
 	})
 
 	Convey("Case is found valid for submission", t, func() {
@@ -559,7 +622,8 @@ func TestUnitHandleGetValidationStatus(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(createInsolvencyResource(), nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(createInsolvencyResource(), nil).Times(1)
+		mockService.EXPECT().SetValidationFailures(gomock.Any(), transactionID, []string{}).Return(nil, nil).Times(1)
 
 		res := serveHandleGetValidationStatus(mockService, true)
 
@@ -567,6 +631,52 @@ func TestUnitHandleGetValidationStatus(t *testing.T) {
 		So(res.Body.String(), ShouldContainSubstring, `"is_valid":true`)
 		So(res.Body.String(), ShouldContainSubstring, `"errors":[]`)
 	})
+
+	Convey("Case is validated when the validation failures cannot be stored", t, func() {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(createInsolvencyResource(), nil).Times(1)
+		mockService.EXPECT().SetValidationFailures(gomock.Any(), transactionID, []string{}).Return(nil, errors.New("error storing validation failures")).Times(1)
+
+		res := serveHandleGetValidationStatus(mockService, true)
+
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldContainSubstring, `"is_valid":true`)
+	})
+
+	Convey("Authenticated user must be a practitioner on the case", t, func() {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		cfg, _ := config.Get()
+		cfg.RequireUserIsPractitioner = true
+		defer func() { cfg.RequireUserIsPractitioner = false }()
+
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners[0].Email = "joe@bloggs.com"
+
+		Convey("User is not a practitioner on the case", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyCase, nil).Times(1)
+			mockService.EXPECT().SetValidationFailures(gomock.Any(), transactionID, gomock.Any()).Return(nil, nil).Times(1)
+
+			res := serveHandleGetValidationStatusAsUser(mockService, true, authentication.AuthUserDetails{Email: "jane@doe.com", ID: "user1234"})
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Body.String(), ShouldContainSubstring, `"is_valid":false`)
+			So(res.Body.String(), ShouldContainSubstring, "the authenticated user must be a practitioner on insolvency case")
+		})
+
+		Convey("User is a practitioner on the case", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyCase, nil).Times(1)
+			mockService.EXPECT().SetValidationFailures(gomock.Any(), transactionID, gomock.Any()).Return(nil, nil).Times(1)
+
+			res := serveHandleGetValidationStatusAsUser(mockService, true, authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"})
+
+			So(res.Code, ShouldEqual, http.StatusOK)
+			So(res.Body.String(), ShouldContainSubstring, `"is_valid":true`)
+		})
+	})
 }
 
 func TestUnitHandleGetFilings(t *testing.T) {
@@ -627,7 +737,7 @@ func TestUnitHandleGetFilings(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponseClosed))
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error")).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error")).Times(1)
 
 		res := serveHandleGetFilings(mockService, true)
 
@@ -645,7 +755,14 @@ func TestUnitHandleGetFilings(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponseClosed))
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(createInsolvencyResource(), nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(createInsolvencyResource(), nil).Times(1)
+
+		// Expect the filings generated event to be written with the kind of each filing
+		var event *models.OutboxEventDao
+		mockService.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *models.OutboxEventDao) error {
+			event = e
+			return nil
+		}).Times(1)
 
 		res := serveHandleGetFilings(mockService, true)
 
@@ -654,6 +771,29 @@ func TestUnitHandleGetFilings(t *testing.T) {
 		So(res.Body.String(), ShouldContainSubstring, `"company_name":"companyName"`)
 		So(res.Body.String(), ShouldContainSubstring, `"company_number":"01234567"`)
 		So(res.Body.String(), ShouldContainSubstring, `"kind":"insolvency#600"`)
+		So(event.ID, ShouldEqual, "filings-generated-"+transactionID)
+		So(event.Type, ShouldEqual, dao.EventFilingsGenerated)
+		So(event.TransactionID, ShouldEqual, transactionID)
+		So(event.Data, ShouldEqual, `{"filings":["insolvency#600"]}`)
+	})
+
+	Convey("The filings are returned when the filings generated event cannot be recorded", t, func() {
+		httpmock.Activate()
+		mockCtrl := gomock.NewController(t)
+		defer httpmock.DeactivateAndReset()
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+
+		// Expect the transaction api to be called and return a closed transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponseClosed))
+
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(createInsolvencyResource(), nil).Times(1)
+		mockService.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).Return(fmt.Errorf("error")).Times(1)
+
+		res := serveHandleGetFilings(mockService, true)
+
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldContainSubstring, `"kind":"insolvency#600"`)
 	})
 
 	Convey("Generate filing for LRESEX case with no practitioners", t, func() {
@@ -679,7 +819,8 @@ func TestUnitHandleGetFilings(t *testing.T) {
 		}}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyCase, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyCase, nil).Times(1)
+		mockService.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleGetFilings(mockService, true)
 
@@ -715,7 +856,8 @@ func TestUnitHandleGetFilings(t *testing.T) {
 		}}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyCase, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyCase, nil).Times(1)
+		mockService.EXPECT().CreateOutboxEvent(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleGetFilings(mockService, true)
 
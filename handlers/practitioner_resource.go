@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	"github.com/companieshouse/insolvency-api/models"
@@ -17,11 +18,11 @@ import (
 
 // HandleCreatePractitionersResource updates the insolvency resource with the
 // incoming list of practitioners
-func HandleCreatePractitionersResource(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleCreatePractitionersResource(svc dao.Service, clients service.Clients, helperService utils.HelperService, practitionerRegister service.PractitionerRegister) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction is valid
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "practitioners", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "practitioners", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
@@ -42,17 +43,29 @@ func HandleCreatePractitionersResource(svc dao.Service, helperService utils.Help
 		}
 
 		// Validates that the provided practitioner details are in the correct format
-		validationErrs, err := service.ValidatePractitionerDetails(svc, transactionID, request)
+		validationErrs, err := service.ValidatePractitionerDetails(req.Context(), svc, transactionID, request)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse("failed to validate the practitioner request supplied")
+			utils.WriteErrorResponse(w, req, fmt.Errorf("failed to validate the practitioner request supplied: %w", err))
+			return
+		}
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", validationErrs)
+			return
+		}
+
+		// Check that the practitioner is on the insolvency practitioner register
+		registerErrs, err := service.ValidatePractitionerAgainstRegister(practitionerRegister, request)
+		if err != nil {
+			log.ErrorR(req, err)
+			m := models.NewMessageResponse("failed to check the practitioner against the insolvency practitioner register")
 			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request body: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(registerErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed practitioner register validation on the following: %s", utils.FieldErrorMessages(registerErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", registerErrs)
 			return
 		}
 
@@ -66,12 +79,20 @@ func HandleCreatePractitionersResource(svc dao.Service, helperService utils.Help
 
 		practitionerDao := transformers.PractitionerResourceRequestToDB(&request, transactionID)
 
+		// Link the practitioner to the user account the practitioner register holds for their IP code
+		practitionerDao.UserID, err = service.GetRegisteredUserID(practitionerRegister, request.IPCode)
+		if err != nil {
+			log.ErrorR(req, err)
+			m := models.NewMessageResponse("failed to check the practitioner against the insolvency practitioner register")
+			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			return
+		}
+
 		// Store practitioners resource in Mongo
-		err, statusCode := svc.CreatePractitionersResource(practitionerDao, transactionID)
+		err = svc.CreatePractitionersResource(req.Context(), practitionerDao, transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
@@ -97,11 +118,10 @@ func HandleGetPractitionerResources(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for practitioners resource with transaction id: %s", transactionID))
 
-		practitionerResources, err := svc.GetPractitionerResources(transactionID)
+		practitionerResources, err := svc.GetPractitionerResources(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if practitionerResources == nil {
@@ -138,11 +158,10 @@ func HandleGetPractitionerResource(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start GET request for practitioner resource with transaction id: %s and practitioner id: %s", transactionID, practitionerID))
 
 		// Get practitioner from DB
-		practitioner, err := svc.GetPractitionerResource(practitionerID, transactionID)
+		practitioner, err := svc.GetPractitionerResource(req.Context(), practitionerID, transactionID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get practitioner with id [%s]: [%s]", practitionerID, err))
-			m := models.NewMessageResponse("there was a problem handling your request")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
@@ -162,7 +181,7 @@ func HandleGetPractitionerResource(svc dao.Service) http.Handler {
 
 // HandleDeletePractitioner deletes a practitioner from the insolvency case with
 // the specified transactionID and IPCode
-func HandleDeletePractitioner(svc dao.Service) http.Handler {
+func HandleDeletePractitioner(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// Check for a transaction id in request
 		vars := mux.Vars(req)
@@ -177,38 +196,37 @@ func HandleDeletePractitioner(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start DELETE request for practitioner resource with transaction id: %s and practitioner id: %s", transactionID, practitionerID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgErrorCheckTransactionStatus, transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if isTransactionClosed {
-			log.ErrorR(req, fmt.Errorf(constants.MsgNoUpdateTransactionClosed, transactionID))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgNoUpdateTransactionClosed, transactionID))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = apperrors.Closed(constants.MsgNoUpdateTransactionClosed, transactionID)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		// Delete practitioner from Mongo
-		err, statusCode := svc.DeletePractitioner(practitionerID, transactionID)
+		err = svc.DeletePractitioner(req.Context(), practitionerID, transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully deleted practitioner with transaction ID: %s and practitioner ID: %s, from mongo", transactionID, practitionerID))
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
 
 // HandleAppointPractitioner adds appointment details to a practitioner resource on a transaction
-func HandleAppointPractitioner(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleAppointPractitioner(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction id & practitioner id exist in path
@@ -223,8 +241,8 @@ func HandleAppointPractitioner(svc dao.Service, helperService utils.HelperServic
 		log.InfoR(req, fmt.Sprintf("start POST request for practitioner appointment with transaction ID: [%s] and practitioner ID: [%s]", transactionID, practitionerID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
-		isValidTransactionNotClosed := helperService.HandleTransactionNotClosedValidation(w, req, transactionID, isTransactionClosed, httpStatus, err)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
+		isValidTransactionNotClosed := helperService.HandleTransactionNotClosedValidation(w, req, transactionID, isTransactionClosed, err)
 		if !isValidTransactionNotClosed {
 			return
 		}
@@ -253,38 +271,34 @@ func HandleAppointPractitioner(svc dao.Service, helperService utils.HelperServic
 		}
 
 		// Validate all appointment details are of the correct format and criteria
-		validationErrs, err := service.ValidateAppointmentDetails(svc, request, transactionID, practitionerID, req)
+		validationErrs, err := service.ValidateAppointmentDetails(svc, clients, request, transactionID, practitionerID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to validate appointment details: [%s]", err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction ID [%s]", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction ID [%s]: %w", transactionID, err))
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request body: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", validationErrs)
 			return
 		}
 
 		practitionerAppointmentDao := transformers.PractitionerAppointmentRequestToDB(&request, transactionID, practitionerID)
 
 		// Store appointment in DB
-		err, statusCode := svc.AppointPractitioner(practitionerAppointmentDao, transactionID, practitionerID)
+		err = svc.AppointPractitioner(req.Context(), practitionerAppointmentDao, transactionID, practitionerID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully added practitioner appointment with transaction ID [%s] and practitioner ID [%s] to mongo", transactionID, practitionerID))
 
-		practitioner, err := svc.GetPractitionerResource(practitionerID, transactionID)
+		practitioner, err := svc.GetPractitionerResource(req.Context(), practitionerID, transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
@@ -319,11 +333,10 @@ func HandleGetPractitionerAppointment(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for appointments resource with transaction ID: [%s] and practitioner ID: [%s]", transactionID, practitionerID))
 
-		practitioner, err := svc.GetPractitionerResource(practitionerID, transactionID)
+		practitioner, err := svc.GetPractitionerResource(req.Context(), practitionerID, transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
@@ -353,7 +366,7 @@ func HandleGetPractitionerAppointment(svc dao.Service) http.Handler {
 
 // HandleDeletePractitionerAppointment deletes an appointment
 // for the specified transactionID and practitionerID
-func HandleDeletePractitionerAppointment(svc dao.Service) http.Handler {
+func HandleDeletePractitionerAppointment(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		transactionID, practitionerID, err := getTransactionIDAndPractitionerIDFromVars(vars)
@@ -367,29 +380,28 @@ func HandleDeletePractitionerAppointment(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start GET request for appointments resource with transaction ID: [%s] and practitioner ID: [%s]", transactionID, practitionerID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgErrorCheckTransactionStatus, transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if isTransactionClosed {
-			log.ErrorR(req, fmt.Errorf(constants.MsgNoUpdateTransactionClosed, transactionID))
-			m := models.NewMessageResponse(fmt.Sprintf(constants.MsgNoUpdateTransactionClosed, transactionID))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = apperrors.Closed(constants.MsgNoUpdateTransactionClosed, transactionID)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
-		err, statusCode := svc.DeletePractitionerAppointment(transactionID, practitionerID)
+		err = svc.DeletePractitionerAppointment(req.Context(), transactionID, practitionerID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
-		w.WriteHeader(statusCode)
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,10 +11,12 @@ import (
 	"testing"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
 	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
@@ -24,13 +27,17 @@ import (
 const practitionerID = "00001234"
 
 func serveHandleCreatePractitionersResource(body []byte, service dao.Service, helperService utils.HelperService, tranIDSet bool, res *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	return serveHandleCreatePractitionersResourceWithRegister(body, service, helperService, nil, tranIDSet, res)
+}
+
+func serveHandleCreatePractitionersResourceWithRegister(body []byte, svc dao.Service, helperService utils.HelperService, register service.PractitionerRegister, tranIDSet bool, res *httptest.ResponseRecorder) *httptest.ResponseRecorder {
 	path := "/transactions/123456789/insolvency/practitioners"
 	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
 	if tranIDSet {
 		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
 	}
 
-	handler := HandleCreatePractitionersResource(service, helperService)
+	handler := HandleCreatePractitionersResource(svc, sdkClients, helperService, register)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -305,7 +312,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner.Role = "error-role"
 		body, _ := json.Marshal(practitioner)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, helperService, true, rec)
 
@@ -323,7 +330,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		// Expect GetInsolvencyResource to return a valid insolvency case
 		insolvencyCase := generateInsolvencyResource()
 		insolvencyCase.Data.CaseType = constants.CVL.String()
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(insolvencyCase, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(insolvencyCase, nil)
 
 		practitioner := generatePractitioner()
 		practitioner.Role = constants.Receiver.String()
@@ -346,7 +353,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner.Role = constants.Receiver.String()
 		body, _ := json.Marshal(practitioner)
 		// Expect GetInsolvencyResource to return an error
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error retrieving insolvency case"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error retrieving insolvency case"))
 
 		res := serveHandleCreatePractitionersResource(body, mockService, helperService, true, rec)
 
@@ -366,7 +373,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner.Email = ""
 		body, _ := json.Marshal(practitioner)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, helperService, true, rec)
 
@@ -385,7 +392,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner.FirstName = "J4ck"
 		body, _ := json.Marshal(practitioner)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, helperService, true, rec)
 
@@ -400,7 +407,7 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		// Expect the transaction api to be called and return an open transaction
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		practitioner := generatePractitioner()
 		practitioner.LastName = "wr0ng"
@@ -415,6 +422,72 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		So(res.Body.String(), ShouldContainSubstring, "the last name contains a character which is not allowed")
 	})
 
+	Convey("Incoming request has an IP code that does not match the practitioner register", t, func() {
+		mockService, _, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return an open transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+		// Expect GetInsolvencyResource to return a valid insolvency case
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
+
+		practitioner := generatePractitioner()
+		body, _ := json.Marshal(practitioner)
+		register := &stubPractitionerRegister{practitioner: &service.RegisteredPractitioner{IPCode: practitioner.IPCode, FirstName: "Jane", LastName: "Doe", Authorised: true}}
+
+		res := serveHandleCreatePractitionersResourceWithRegister(body, mockService, helperService, register, true, rec)
+
+		So(res.Code, ShouldEqual, http.StatusBadRequest)
+		So(res.Body.String(), ShouldContainSubstring, "invalid request body: first_name does not match the insolvency practitioner register for ip_code [1234], last_name does not match the insolvency practitioner register for ip_code [1234]")
+	})
+
+	Convey("Clients accepting problems are sent a field error for each register mismatch", t, func() {
+		mockService, _, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return an open transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+		// Expect GetInsolvencyResource to return a valid insolvency case
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
+
+		practitioner := generatePractitioner()
+		body, _ := json.Marshal(practitioner)
+		register := &stubPractitionerRegister{practitioner: &service.RegisteredPractitioner{IPCode: practitioner.IPCode, FirstName: "Jane", LastName: "Doe", Authorised: true}}
+
+		req := httptest.NewRequest(http.MethodPost, "/transactions/123456789/insolvency/practitioners", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+		req.Header.Set("Accept", utils.ProblemContentType)
+		HandleCreatePractitionersResource(mockService, sdkClients, helperService, register).ServeHTTP(rec, req)
+
+		So(rec.Code, ShouldEqual, http.StatusBadRequest)
+		So(rec.Header().Get("Content-Type"), ShouldEqual, utils.ProblemContentType)
+
+		var problem models.ProblemResource
+		So(json.Unmarshal(rec.Body.Bytes(), &problem), ShouldBeNil)
+		So(problem.Errors, ShouldResemble, []models.FieldError{
+			{Field: "first_name", Message: "first_name does not match the insolvency practitioner register for ip_code [1234]"},
+			{Field: "last_name", Message: "last_name does not match the insolvency practitioner register for ip_code [1234]"},
+		})
+	})
+
+	Convey("Error checking the practitioner register", t, func() {
+		mockService, _, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return an open transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+		// Expect GetInsolvencyResource to return a valid insolvency case
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
+
+		body, _ := json.Marshal(generatePractitioner())
+		register := &stubPractitionerRegister{err: fmt.Errorf("register unavailable")}
+
+		res := serveHandleCreatePractitionersResourceWithRegister(body, mockService, helperService, register, true, rec)
+
+		So(res.Code, ShouldEqual, http.StatusInternalServerError)
+		So(res.Body.String(), ShouldContainSubstring, "failed to check the practitioner against the insolvency practitioner register")
+	})
+
 	Convey("Generic error when adding practitioners resource to mongo", t, func() {
 		mockService, mockHelperService, rec := mock_dao.CreateTestObjects(t)
 		httpmock.Activate()
@@ -425,13 +498,13 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner := generatePractitioner()
 		body, _ := json.Marshal(practitioner)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		// Expect CreatePractitionersResource to be called once and return an error
-		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction %s", transactionID), http.StatusInternalServerError).Times(1)
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, mockHelperService, true, rec)
 
@@ -449,13 +522,13 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner := generatePractitioner()
 		body, _ := json.Marshal(practitioner)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		// Expect CreatePractitionersResource to be called once and return an error
-		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction %s not found", transactionID), http.StatusNotFound).Times(1)
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).Return(apperrors.NotFound("there was a problem handling your request for transaction %s not found", transactionID)).Times(1)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, mockHelperService, true, rec)
 
@@ -474,13 +547,13 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner := generatePractitioner()
 		body, _ := json.Marshal(practitioner)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		// Expect CreatePractitionersResource to be called once and return an error
-		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID), http.StatusBadRequest).Times(1)
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).Return(apperrors.Validation("there was a problem handling your request for transaction %s already has 5 practitioners", transactionID)).Times(1)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, mockHelperService, true, rec)
 
@@ -489,6 +562,31 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		So(res.Body.String(), ShouldContainSubstring, "already has 5 practitioners")
 	})
 
+	Convey("Error adding practitioners resource to mongo - practitioner with IP code already assigned to case", t, func() {
+		mockService, mockHelperService, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return an open transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+
+		practitioner := generatePractitioner()
+		body, _ := json.Marshal(practitioner)
+		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		// Expect CreatePractitionersResource to be called once and return a conflict
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).Return(apperrors.Conflict(constants.MsgPractitionerAlreadyAssigned, transactionID, practitioner.IPCode, practitionerID)).Times(1)
+		// Expect GetInsolvencyResource to return a valid insolvency case
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
+
+		res := serveHandleCreatePractitionersResource(body, mockService, mockHelperService, true, rec)
+
+		So(res.Code, ShouldEqual, http.StatusConflict)
+		So(res.Body.String(), ShouldContainSubstring, "practitioner with IP Code 1234 is already assigned to this case")
+		So(res.Body.String(), ShouldContainSubstring, "practitioner ID ["+practitionerID+"]")
+	})
+
 	Convey("Successfully add insolvency resource to mongo", t, func() {
 		mockService, mockHelperService, rec := mock_dao.CreateTestObjects(t)
 		httpmock.Activate()
@@ -502,18 +600,63 @@ func TestUnitHandleCreatePractitionersResource(t *testing.T) {
 		practitioner := generatePractitioner()
 		body, _ := json.Marshal(practitioner)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		// Expect CreatePractitionersResource to be called once and not return an error
-		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), transactionID).Return(nil, http.StatusCreated).Times(1)
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).Return(nil).Times(1)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(insolvencyCase, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(insolvencyCase, nil)
 
 		res := serveHandleCreatePractitionersResource(body, mockService, mockHelperService, true, rec)
 
 		So(res.Code, ShouldEqual, http.StatusCreated)
 	})
+
+	Convey("Practitioner is linked to the user account held on the practitioner register", t, func() {
+		mockService, mockHelperService, rec := mock_dao.CreateTestObjects(t)
+		httpmock.Activate()
+
+		// Expect the transaction api to be called and return an open transaction
+		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
+
+		insolvencyCase := generateInsolvencyResource()
+		insolvencyCase.Data.CaseType = constants.CVL.String()
+
+		practitioner := generatePractitioner()
+		body, _ := json.Marshal(practitioner)
+		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		// Expect CreatePractitionersResource to be called once with the practitioner linked to the user
+		var createdPractitioner *models.PractitionerResourceDao
+		mockService.EXPECT().CreatePractitionersResource(gomock.Any(), gomock.Any(), transactionID).DoAndReturn(func(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+			createdPractitioner = dao
+			return nil
+		}).Times(1)
+		// Expect GetInsolvencyResource to return a valid insolvency case
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(insolvencyCase, nil)
+
+		register := &stubPractitionerRegister{practitioner: &service.RegisteredPractitioner{IPCode: practitioner.IPCode, FirstName: "Joe", LastName: "Bloggs", Authorised: true, UserID: "user1234"}}
+		req := httptest.NewRequest(http.MethodPost, "/transactions/123456789/insolvency/practitioners", bytes.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+
+		HandleCreatePractitionersResource(mockService, sdkClients, mockHelperService, register).ServeHTTP(rec, req)
+
+		So(rec.Code, ShouldEqual, http.StatusCreated)
+		So(createdPractitioner.UserID, ShouldEqual, "user1234")
+	})
+}
+
+// stubPractitionerRegister is a practitioner register that returns a fixed practitioner or error
+type stubPractitionerRegister struct {
+	practitioner *service.RegisteredPractitioner
+	err          error
+}
+
+func (s *stubPractitionerRegister) GetPractitioner(ipCode string) (*service.RegisteredPractitioner, error) {
+	return s.practitioner, s.err
 }
 
 func generatePractitioner() models.PractitionerRequest {
@@ -568,7 +711,7 @@ func TestUnitHandleGetPractitionerResources(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetPractitionersResource to be called once and return an error
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(nil, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(nil, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 
 		res := serveGetPractitionerResourcesRequest(mockService, true)
 
@@ -581,7 +724,7 @@ func TestUnitHandleGetPractitionerResources(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetPractitionersResource to be called once and return nil, nil
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(nil, nil).Times(1)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(nil, nil).Times(1)
 
 		res := serveGetPractitionerResourcesRequest(mockService, true)
 
@@ -595,7 +738,7 @@ func TestUnitHandleGetPractitionerResources(t *testing.T) {
 		mockService := mock_dao.NewMockService(mockCtrl)
 		var practitionerResources []models.PractitionerResourceDao
 		// Expect GetPractitionersResource to be called once and return empty list, nil
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionerResources, nil).Times(1)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionerResources, nil).Times(1)
 
 		res := serveGetPractitionerResourcesRequest(mockService, true)
 
@@ -620,7 +763,7 @@ func TestUnitHandleGetPractitionerResources(t *testing.T) {
 			},
 		}
 		// Expect GetPractitionersResource to be called once and return list of practitioners, nil
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionerResources, nil).Times(1)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionerResources, nil).Times(1)
 
 		res := serveGetPractitionerResourcesRequest(mockService, true)
 
@@ -687,7 +830,7 @@ func TestUnitHandleGetPractitionerResource(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetPractitionerResource to return an error
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error retrieving practitioner"))
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error retrieving practitioner"))
 
 		res := serveGetPractitionerResourceRequest(mockService, true, true)
 
@@ -700,7 +843,7 @@ func TestUnitHandleGetPractitionerResource(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetPractitionerResource to return an empty practitioner resource
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
 
 		res := serveGetPractitionerResourceRequest(mockService, true, true)
 
@@ -726,7 +869,7 @@ func TestUnitHandleGetPractitionerResource(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetPractitionerResource to successfully return a practitioner resource
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(practitionerResource, nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(practitionerResource, nil)
 
 		res := serveGetPractitionerResourceRequest(mockService, true, true)
 
@@ -749,7 +892,7 @@ func serveDeletePractitionerRequest(service dao.Service, tranIdSet bool, practId
 	}
 	res := httptest.NewRecorder()
 
-	handler := HandleDeletePractitioner(service)
+	handler := HandleDeletePractitioner(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -818,7 +961,7 @@ func TestUnitHandleDeletePractitioner(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect DeletePractitioner to be called once and return an error
-		mockService.EXPECT().DeletePractitioner(practitionerID, transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction %s", transactionID), http.StatusBadRequest).Times(1)
+		mockService.EXPECT().DeletePractitioner(gomock.Any(), practitionerID, transactionID).Return(apperrors.Validation("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 
 		res := serveDeletePractitionerRequest(mockService, true, true)
 
@@ -835,8 +978,8 @@ func TestUnitHandleDeletePractitioner(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		// Expect DeletePractitioner to be called once and return nil, 404
-		mockService.EXPECT().DeletePractitioner(practitionerID, transactionID).Return(nil, http.StatusNotFound).Times(1)
+		// Expect DeletePractitioner to be called once and return a not found error
+		mockService.EXPECT().DeletePractitioner(gomock.Any(), practitionerID, transactionID).Return(apperrors.NotFound(constants.MsgCaseNotFound)).Times(1)
 
 		res := serveDeletePractitionerRequest(mockService, true, true)
 
@@ -854,7 +997,7 @@ func TestUnitHandleDeletePractitioner(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect DeletePractitioner to be called once and return http status NoContent, nil
-		mockService.EXPECT().DeletePractitioner(practitionerID, transactionID).Return(nil, http.StatusNoContent).Times(1)
+		mockService.EXPECT().DeletePractitioner(gomock.Any(), practitionerID, transactionID).Return(nil).Times(1)
 
 		res := serveDeletePractitionerRequest(mockService, true, true)
 
@@ -875,7 +1018,7 @@ func serveHandleAppointPractitioner(body []byte, service dao.Service, helperServ
 	}
 	req = mux.SetURLVars(req, vars)
 
-	handler := HandleAppointPractitioner(service, helperService)
+	handler := HandleAppointPractitioner(service, sdkClients, helperService)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -984,7 +1127,7 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "invalid",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
@@ -1006,10 +1149,10 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(nil, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(nil, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1051,11 +1194,11 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).AnyTimes()
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).AnyTimes()
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1072,11 +1215,11 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 
 		practitionersDao := []models.PractitionerResourceDao{{ID: practitionerID}}
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
 
 		body, _ := json.Marshal(models.PractitionerAppointment{
 			AppointedOn: "2012-02-23",
@@ -1125,11 +1268,11 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1161,12 +1304,12 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
-		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"), http.StatusInternalServerError)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("err"))
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1198,13 +1341,13 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
-		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, 0)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error"))
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1236,13 +1379,13 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
-		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, 0)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1285,13 +1428,13 @@ func TestUnitHandleAppointPractitioner(t *testing.T) {
 			MadeBy:      "company",
 		})
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
-		mockService.EXPECT().GetPractitionerResources(transactionID).Return(practitionersDao, nil).Times(1)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyDao, nil)
-		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, 0)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(practitionersDao[0], nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), transactionID).Return(practitionersDao, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyDao, nil)
+		mockService.EXPECT().AppointPractitioner(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(practitionersDao[0], nil)
 
 		res := serveHandleAppointPractitioner(body, mockService, mockHelperService, true, true, rec)
 
@@ -1364,7 +1507,7 @@ func TestUnitHandleGetPractitionerAppointment(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, fmt.Errorf("error"))
 
 		body, _ := json.Marshal(models.PractitionerAppointment{
 			AppointedOn: "2012-02-23",
@@ -1380,7 +1523,7 @@ func TestUnitHandleGetPractitionerAppointment(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{}, nil)
 
 		body, _ := json.Marshal(models.PractitionerAppointment{
 			AppointedOn: "2012-02-23",
@@ -1397,7 +1540,7 @@ func TestUnitHandleGetPractitionerAppointment(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{ID: "123"}, nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.PractitionerResourceDao{ID: "123"}, nil)
 
 		body, _ := json.Marshal(models.PractitionerAppointment{
 			AppointedOn: "2012-02-23",
@@ -1425,7 +1568,7 @@ func TestUnitHandleGetPractitionerAppointment(t *testing.T) {
 		}
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any()).Return(practitionersDao, nil)
+		mockService.EXPECT().GetPractitionerResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(practitionersDao, nil)
 
 		body, _ := json.Marshal(models.PractitionerAppointment{
 			AppointedOn: "2012-02-23",
@@ -1451,7 +1594,7 @@ func serveHandleDeletePractitionerAppointment(service dao.Service, tranIdSet boo
 	req = mux.SetURLVars(req, vars)
 	res := httptest.NewRecorder()
 
-	handler := HandleDeletePractitionerAppointment(service)
+	handler := HandleDeletePractitionerAppointment(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -1514,7 +1657,7 @@ func TestUnitHandleDeletePractitionerAppointment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().DeletePractitionerAppointment(transactionID, practitionerID).Return(fmt.Errorf("err"), http.StatusBadRequest)
+		mockService.EXPECT().DeletePractitionerAppointment(gomock.Any(), transactionID, practitionerID).Return(apperrors.Validation("err"))
 
 		res := serveHandleDeletePractitionerAppointment(mockService, true, true)
 
@@ -1531,7 +1674,7 @@ func TestUnitHandleDeletePractitionerAppointment(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().DeletePractitionerAppointment(transactionID, practitionerID).Return(nil, http.StatusNoContent)
+		mockService.EXPECT().DeletePractitionerAppointment(gomock.Any(), transactionID, practitionerID).Return(nil)
 
 		res := serveHandleDeletePractitionerAppointment(mockService, true, true)
 
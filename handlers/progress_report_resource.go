@@ -15,11 +15,11 @@ import (
 )
 
 // HandleCreateProgressReport receives a progress report to be stored against the Insolvency case
-func HandleCreateProgressReport(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleCreateProgressReport(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction is valid
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "progress report", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "progress report", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
@@ -42,22 +42,20 @@ func HandleCreateProgressReport(svc dao.Service, helperService utils.HelperServi
 		}
 
 		// Validate the provided statement details are in the correct format
-		validationErrs, err := service.ValidateProgressReportDetails(svc, progressReportDao, transactionID, req)
+		validationErrs, err := service.ValidateProgressReportDetails(svc, clients, progressReportDao, transactionID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to validate progress report: [%s]", err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction ID [%s]", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction ID [%s]: %w", transactionID, err))
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request body: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", validationErrs)
 			return
 		}
 
 		// Validate if supplied attachment matches attachments associated with supplied transactionID in mongo db
-		attachment, err := svc.GetAttachmentFromInsolvencyResource(transactionID, progressReportDao.Attachments[0])
+		attachment, err := svc.GetAttachmentFromInsolvencyResource(req.Context(), transactionID, progressReportDao.Attachments[0])
 		isValidAttachment := helperService.HandleAttachmentValidation(w, req, transactionID, attachment, err)
 		if !isValidAttachment {
 			return
@@ -73,8 +71,8 @@ func HandleCreateProgressReport(svc dao.Service, helperService utils.HelperServi
 		}
 
 		// Creates the progress report resource in mongo if all previous checks pass
-		statusCode, err := svc.CreateProgressReportResource(progressReportDao, transactionID)
-		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, statusCode, err)
+		err = svc.CreateProgressReportResource(req.Context(), progressReportDao, transactionID, req.Header.Get("If-Match"))
+		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, err)
 
 		if !isValidCreateResource {
 			return
@@ -102,11 +100,10 @@ func HandleGetProgressReport(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for get progress report with transaction id: %s", transactionID))
 
-		progressReport, err := svc.GetProgressReportResource(transactionID)
+		progressReport, err := svc.GetProgressReportResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get progress report from insolvency resource in db for transaction [%s]: %v", transactionID, err))
-			m := models.NewMessageResponse("there was a problem handling your request")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if progressReport.FromDate == "" || progressReport.ToDate == "" {
@@ -122,26 +119,25 @@ func HandleGetProgressReport(svc dao.Service) http.Handler {
 }
 
 // HandleDeleteProgressReport deletes a progress report resource from an insolvency case
-func HandleDeleteProgressReport(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleDeleteProgressReport(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "progress report", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "progress report", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
 
 		// Delete progress report from DB
-		statusCode, err := svc.DeleteProgressReportResource(transactionID)
+		err := svc.DeleteProgressReportResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully deleted progress report from insolvency case with transaction ID: %s", transactionID))
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+)
+
+const (
+	// defaultReadinessTimeout is the time allowed for all the readiness checks when none is configured
+	defaultReadinessTimeout = 2 * time.Second
+
+	readinessUp   = "UP"
+	readinessDown = "DOWN"
+)
+
+// HandleReadiness checks that the database, reported under databaseName, and any configured upstream APIs can be
+// reached within the timeout. It responds with 200 when every dependency is up and 503 otherwise, with the status of
+// each check in the body. The reason a check failed is only logged, so that the endpoint does not expose details of
+// the service's dependencies
+func HandleReadiness(svc dao.Service, databaseName string, upstreamChecks []service.UpstreamCheck, timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		checks := map[string]func(context.Context) error{databaseName: svc.Ping}
+		for _, upstreamCheck := range upstreamChecks {
+			checks[upstreamCheck.Name] = upstreamCheck.Check
+		}
+
+		readiness := models.ReadinessResource{
+			Status: readinessUp,
+			Checks: make(map[string]models.DependencyReadiness, len(checks)),
+		}
+
+		// Run the checks at the same time so that a slow dependency doesn't use up the timeout for the others
+		var wg sync.WaitGroup
+		var mtx sync.Mutex
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check func(context.Context) error) {
+				defer wg.Done()
+
+				start := time.Now()
+				err := check(ctx)
+				dependency := models.DependencyReadiness{Status: readinessUp, DurationMs: time.Since(start).Milliseconds()}
+				if err != nil {
+					log.ErrorR(req, fmt.Errorf("readiness check of [%s] failed: [%v]", name, err))
+					dependency.Status = readinessDown
+				}
+
+				mtx.Lock()
+				defer mtx.Unlock()
+				readiness.Checks[name] = dependency
+				if err != nil {
+					readiness.Status = readinessDown
+				}
+			}(name, check)
+		}
+		wg.Wait()
+
+		if readiness.Status != readinessUp {
+			log.ErrorR(req, fmt.Errorf("readiness check failed"), log.Data{"checks": readiness.Checks})
+			utils.WriteJSONWithStatus(w, req, readiness, http.StatusServiceUnavailable)
+			return
+		}
+
+		utils.WriteJSONWithStatus(w, req, readiness, http.StatusOK)
+	})
+}
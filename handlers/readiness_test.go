@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func serveHandleReadiness(svc *mock_dao.MockService, upstreamChecks []service.UpstreamCheck, timeout time.Duration) (*httptest.ResponseRecorder, models.ReadinessResource) {
+	req := httptest.NewRequest(http.MethodGet, "/insolvency-api/readiness", nil)
+	res := httptest.NewRecorder()
+
+	HandleReadiness(svc, "mongodb", upstreamChecks, timeout).ServeHTTP(res, req)
+
+	var readiness models.ReadinessResource
+	_ = json.Unmarshal(res.Body.Bytes(), &readiness)
+	return res, readiness
+}
+
+func TestUnitHandleReadiness(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	Convey("MongoDB is reachable and no upstream checks are configured", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		res, readiness := serveHandleReadiness(mockService, nil, 0)
+
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(readiness.Status, ShouldEqual, "UP")
+		So(readiness.Checks, ShouldHaveLength, 1)
+		So(readiness.Checks["mongodb"].Status, ShouldEqual, "UP")
+	})
+
+	Convey("MongoDB cannot be reached", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().Ping(gomock.Any()).Return(fmt.Errorf("ping to mongodb failed"))
+
+		res, readiness := serveHandleReadiness(mockService, nil, 0)
+
+		So(res.Code, ShouldEqual, http.StatusServiceUnavailable)
+		So(readiness.Status, ShouldEqual, "DOWN")
+		So(readiness.Checks["mongodb"].Status, ShouldEqual, "DOWN")
+
+		// The reason the check failed is logged rather than returned
+		So(res.Body.String(), ShouldNotContainSubstring, "ping to mongodb failed")
+	})
+
+	Convey("MongoDB does not respond within the timeout", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		res, readiness := serveHandleReadiness(mockService, nil, 10*time.Millisecond)
+
+		So(res.Code, ShouldEqual, http.StatusServiceUnavailable)
+		So(readiness.Checks["mongodb"].Status, ShouldEqual, "DOWN")
+		So(res.Body.String(), ShouldNotContainSubstring, context.DeadlineExceeded.Error())
+	})
+
+	Convey("The database check is named after the configured backend", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/insolvency-api/readiness", nil)
+		res := httptest.NewRecorder()
+		HandleReadiness(mockService, "sqlite", nil, 0).ServeHTTP(res, req)
+
+		var readiness models.ReadinessResource
+		So(json.Unmarshal(res.Body.Bytes(), &readiness), ShouldBeNil)
+		So(readiness.Checks, ShouldHaveLength, 1)
+		So(readiness.Checks["sqlite"].Status, ShouldEqual, "UP")
+	})
+
+	Convey("Each upstream check is reported", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		upstreamChecks := []service.UpstreamCheck{
+			{Name: "transaction", URL: upstream.URL + "/up", Client: upstream.Client()},
+			{Name: "efs", URL: upstream.URL + "/down", Client: upstream.Client()},
+		}
+
+		res, readiness := serveHandleReadiness(mockService, upstreamChecks, 0)
+
+		So(res.Code, ShouldEqual, http.StatusServiceUnavailable)
+		So(readiness.Status, ShouldEqual, "DOWN")
+		So(readiness.Checks, ShouldHaveLength, 3)
+		So(readiness.Checks["mongodb"].Status, ShouldEqual, "UP")
+		So(readiness.Checks["transaction"].Status, ShouldEqual, "UP")
+		So(readiness.Checks["efs"].Status, ShouldEqual, "DOWN")
+		So(res.Body.String(), ShouldNotContainSubstring, "503")
+	})
+}
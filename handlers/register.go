@@ -3,12 +3,16 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/insolvency-api/config"
 	"github.com/companieshouse/insolvency-api/dao"
 	"github.com/companieshouse/insolvency-api/interceptors"
+	"github.com/companieshouse/insolvency-api/metrics"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/tracing"
 	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/gorilla/mux"
 )
@@ -25,8 +29,44 @@ const (
 	progressReportPath     = insolvencyPath + "/progress-report"
 )
 
+// routePermissions defines the token permission required to call each of the named public routes. Every route
+// registered on the public router must have an entry, otherwise requests for it are forbidden. Routes on the
+// private router are called by internal services with an API key, which holds no token permissions, so have no entry
+var routePermissions = interceptors.RoutePermissions{
+	"createInsolvencyResource": interceptors.PermissionCaseUpdate,
+	"getValidationStatus":      interceptors.PermissionCaseRead,
+	"importCase":               interceptors.PermissionCaseUpdate,
+	"getCaseHistory":           interceptors.PermissionCaseRead,
+
+	"createPractitionersResource": interceptors.PermissionCaseUpdate,
+	"getPractitionerResources":    interceptors.PermissionCaseRead,
+	"deletePractitioner":          interceptors.PermissionCaseUpdate,
+	"getPractitionerResource":     interceptors.PermissionCaseRead,
+
+	"appointPractitioner":           interceptors.PermissionAppointPractitioners,
+	"getPractitionerAppointment":    interceptors.PermissionCaseRead,
+	"deletePractitionerAppointment": interceptors.PermissionAppointPractitioners,
+
+	"submitAttachment":     interceptors.PermissionManageAttachments,
+	"getAttachmentDetails": interceptors.PermissionCaseRead,
+	"downloadAttachment":   interceptors.PermissionCaseRead,
+	"deleteAttachment":     interceptors.PermissionManageAttachments,
+
+	"createResolution": interceptors.PermissionCaseUpdate,
+	"getResolution":    interceptors.PermissionCaseRead,
+	"deleteResolution": interceptors.PermissionCaseUpdate,
+
+	"createStatementOfAffairs": interceptors.PermissionCaseUpdate,
+	"getStatementOfAffairs":    interceptors.PermissionCaseRead,
+	"deleteStatementOfAffairs": interceptors.PermissionCaseUpdate,
+
+	"createProgressReport": interceptors.PermissionCaseUpdate,
+	"getProgressReport":    interceptors.PermissionCaseRead,
+	"deleteProgressReport": interceptors.PermissionCaseUpdate,
+}
+
 // Register defines the endpoints for the API
-func Register(mainRouter *mux.Router, svc dao.Service, helperService utils.HelperService) {
+func Register(mainRouter *mux.Router, svc dao.Service, clients service.Clients, helperService utils.HelperService, practitionerRegister service.PractitionerRegister, firms service.FirmMembership, upstreamChecks []service.UpstreamCheck) {
 
 	userAuthInterceptor := &authentication.UserAuthenticationInterceptor{
 		AllowAPIKeyUser:                false,
@@ -38,46 +78,72 @@ func Register(mainRouter *mux.Router, svc dao.Service, helperService utils.Helpe
 		RequireElevatedAPIKeyPrivilege: true,
 	}
 
+	// Get environment config - only required whilst feature flag in use to disable
+	// non-live form handling routes unless set to true
+	cfg, err := config.Get()
+
+	// The healthcheck only shows the service is running, whereas readiness also checks its dependencies can be reached
 	mainRouter.HandleFunc("/insolvency-api/healthcheck", healthCheck).Methods(http.MethodGet).Name("healthcheck")
+	var readinessTimeout time.Duration
+	var databaseBackend, sqlDriver string
+	if err == nil {
+		readinessTimeout = time.Duration(cfg.ReadinessTimeout) * time.Second
+		databaseBackend, sqlDriver = cfg.DatabaseBackend, cfg.SQLDriver
+	}
+	mainRouter.Handle("/insolvency-api/readiness", HandleReadiness(svc, dao.DatabaseName(databaseBackend, sqlDriver), upstreamChecks, readinessTimeout)).Methods(http.MethodGet).Name("readiness")
+	mainRouter.Handle("/insolvency-api/metrics", metrics.Handler()).Methods(http.MethodGet).Name("metrics")
+
+	var idempotencyKeyTTL, idempotencyKeyLease time.Duration
+	var caseAccessAdminRole string
+	var caseAccessDenyUnowned bool
+	if err == nil {
+		idempotencyKeyTTL = time.Duration(cfg.IdempotencyKeyTTL) * time.Second
+		idempotencyKeyLease = time.Duration(cfg.IdempotencyKeyLease) * time.Second
+		caseAccessAdminRole = cfg.CaseAccessAdminRole
+		caseAccessDenyUnowned = cfg.CaseAccessDenyUnowned
+	}
 
-	// Create a public router that requires all users to be authenticated when making requests
+	// Create a public router that requires all users to be authenticated when making requests, and restricts
+	// each case to the user that created it, members of their firm and administrators. POST requests sent with an Idempotency-Key
+	// header are handled once, with the response replayed to retries, and every change to a case is audited
 	publicAppRouter := mainRouter.PathPrefix("/transactions").Subrouter()
-	publicAppRouter.Use(userAuthInterceptor.UserAuthenticationIntercept, interceptors.EmailAuthIntercept, interceptors.InsolvencyPermissionsIntercept)
+	publicAppRouter.Use(userAuthInterceptor.UserAuthenticationIntercept, interceptors.EmailAuthIntercept(clients), routePermissions.Intercept, interceptors.CaseAccessIntercept(svc, firms, caseAccessAdminRole, caseAccessDenyUnowned), interceptors.IdempotencyIntercept(svc, idempotencyKeyTTL, idempotencyKeyLease), interceptors.AuditIntercept(svc))
 
 	// Declare endpoint URIs
-	publicAppRouter.Handle(insolvencyPath, HandleCreateInsolvencyResource(svc, helperService)).Methods(http.MethodPost).Name("createInsolvencyResource")
+	publicAppRouter.Handle(insolvencyPath, HandleCreateInsolvencyResource(svc, clients, helperService, firms)).Methods(http.MethodPost).Name("createInsolvencyResource")
+
+	publicAppRouter.Handle(insolvencyPath+"/import", HandleImportCase(svc, clients, helperService, practitionerRegister, firms)).Methods(http.MethodPost).Name("importCase")
+
+	publicAppRouter.Handle(insolvencyPath+"/history", HandleGetCaseHistory(svc)).Methods(http.MethodGet).Name("getCaseHistory")
 
-	publicAppRouter.Handle(insolvencyPath+"/validation-status", HandleGetValidationStatus(svc)).Methods(http.MethodGet).Name("getValidationStatus")
+	publicAppRouter.Handle(insolvencyPath+"/validation-status", HandleGetValidationStatus(svc, clients)).Methods(http.MethodGet).Name("getValidationStatus")
 
-	publicAppRouter.Handle(insolvencyPath+"/practitioners", HandleCreatePractitionersResource(svc, helperService)).Methods(http.MethodPost).Name("createPractitionersResource")
+	publicAppRouter.Handle(insolvencyPath+"/practitioners", HandleCreatePractitionersResource(svc, clients, helperService, practitionerRegister)).Methods(http.MethodPost).Name("createPractitionersResource")
 	publicAppRouter.Handle(insolvencyPath+"/practitioners", HandleGetPractitionerResources(svc)).Methods(http.MethodGet).Name("getPractitionerResources")
-	publicAppRouter.Handle(insolvencyPath+"/practitioners/{practitioner_id}", HandleDeletePractitioner(svc)).Methods(http.MethodDelete).Name("deletePractitioner")
+	publicAppRouter.Handle(insolvencyPath+"/practitioners/{practitioner_id}", HandleDeletePractitioner(svc, clients)).Methods(http.MethodDelete).Name("deletePractitioner")
 	publicAppRouter.Handle(insolvencyPath+"/practitioners/{practitioner_id}", HandleGetPractitionerResource(svc)).Methods(http.MethodGet).Name("getPractitionerResource")
 
-	publicAppRouter.Handle(appointmentPath, HandleAppointPractitioner(svc, helperService)).Methods(http.MethodPost).Name("appointPractitioner")
+	publicAppRouter.Handle(appointmentPath, HandleAppointPractitioner(svc, clients, helperService)).Methods(http.MethodPost).Name("appointPractitioner")
 	publicAppRouter.Handle(appointmentPath, HandleGetPractitionerAppointment(svc)).Methods(http.MethodGet).Name("getPractitionerAppointment")
-	publicAppRouter.Handle(appointmentPath, HandleDeletePractitionerAppointment(svc)).Methods(http.MethodDelete).Name("deletePractitionerAppointment")
+	publicAppRouter.Handle(appointmentPath, HandleDeletePractitionerAppointment(svc, clients)).Methods(http.MethodDelete).Name("deletePractitionerAppointment")
 
-	publicAppRouter.Handle(attachmentsPath, HandleSubmitAttachment(svc, helperService)).Methods(http.MethodPost).Name("submitAttachment")
-	publicAppRouter.Handle(specificAttachmentPath, HandleGetAttachmentDetails(svc, helperService)).Methods(http.MethodGet).Name("getAttachmentDetails")
-	publicAppRouter.Handle(specificAttachmentPath+"/download", HandleDownloadAttachment(svc)).Methods(http.MethodGet).Name("downloadAttachment")
-	publicAppRouter.Handle(specificAttachmentPath, HandleDeleteAttachment(svc)).Methods(http.MethodDelete).Name("deleteAttachment")
+	publicAppRouter.Handle(attachmentsPath, HandleSubmitAttachment(svc, clients, helperService)).Methods(http.MethodPost).Name("submitAttachment")
+	publicAppRouter.Handle(specificAttachmentPath, HandleGetAttachmentDetails(svc, clients, helperService)).Methods(http.MethodGet).Name("getAttachmentDetails")
+	publicAppRouter.Handle(specificAttachmentPath+"/download", HandleDownloadAttachment(svc, clients)).Methods(http.MethodGet).Name("downloadAttachment")
+	publicAppRouter.Handle(specificAttachmentPath, HandleDeleteAttachment(svc, clients)).Methods(http.MethodDelete).Name("deleteAttachment")
 
-	publicAppRouter.Handle(resolutionPath, HandleCreateResolution(svc, helperService)).Methods(http.MethodPost).Name("createResolution")
+	publicAppRouter.Handle(resolutionPath, HandleCreateResolution(svc, clients, helperService)).Methods(http.MethodPost).Name("createResolution")
 	publicAppRouter.Handle(resolutionPath, HandleGetResolution(svc)).Methods(http.MethodGet).Name("getResolution")
-	publicAppRouter.Handle(resolutionPath, HandleDeleteResolution(svc)).Methods(http.MethodDelete).Name("deleteResolution")
+	publicAppRouter.Handle(resolutionPath, HandleDeleteResolution(svc, clients)).Methods(http.MethodDelete).Name("deleteResolution")
 
-	publicAppRouter.Handle(statementOfAffairsPath, HandleCreateStatementOfAffairs(svc, helperService)).Methods(http.MethodPost).Name("createStatementOfAffairs")
+	publicAppRouter.Handle(statementOfAffairsPath, HandleCreateStatementOfAffairs(svc, clients, helperService)).Methods(http.MethodPost).Name("createStatementOfAffairs")
 	publicAppRouter.Handle(statementOfAffairsPath, HandleGetStatementOfAffairs(svc)).Methods(http.MethodGet).Name("getStatementOfAffairs")
-	publicAppRouter.Handle(statementOfAffairsPath, HandleDeleteStatementOfAffairs(svc)).Methods(http.MethodDelete).Name("deleteStatementOfAffairs")
+	publicAppRouter.Handle(statementOfAffairsPath, HandleDeleteStatementOfAffairs(svc, clients)).Methods(http.MethodDelete).Name("deleteStatementOfAffairs")
 
-	publicAppRouter.Handle(progressReportPath, HandleCreateProgressReport(svc, helperService)).Methods(http.MethodPost).Name("createProgressReport")
+	publicAppRouter.Handle(progressReportPath, HandleCreateProgressReport(svc, clients, helperService)).Methods(http.MethodPost).Name("createProgressReport")
 	publicAppRouter.Handle(progressReportPath, HandleGetProgressReport(svc)).Methods(http.MethodGet).Name("getProgressReport")
-	publicAppRouter.Handle(progressReportPath, HandleDeleteProgressReport(svc, helperService)).Methods(http.MethodDelete).Name("deleteProgressReport")
+	publicAppRouter.Handle(progressReportPath, HandleDeleteProgressReport(svc, clients, helperService)).Methods(http.MethodDelete).Name("deleteProgressReport")
 
-	// Get environment config - only required whilst feature flag in use to disable
-	// non-live form handling routes unless set to true
-	cfg, err := config.Get()
 	// Check environment variable to enable non-live form endpoints if set to true
 	// and if so, block enable those handlers
 	if err != nil {
@@ -89,13 +155,18 @@ func Register(mainRouter *mux.Router, svc dao.Service, helperService utils.Helpe
 		log.Info("Non-live endpoints blocked")
 	}
 
-	// Create a private router that requires all users to be authenticated when making requests
+	// Create a private router that requires all users to be authenticated when making requests. Case ownership is
+	// not checked on this router so that internal services and administrators can access any case
 	privateAppRouter := mainRouter.PathPrefix("/private").Subrouter()
 	privateAppRouter.Use(privateUserAuthInterceptor.UserAuthenticationIntercept)
 
-	privateAppRouter.Handle("/transactions"+insolvencyPath+"/filings", HandleGetFilings(svc)).Methods(http.MethodGet).Name("getFilings")
+	privateAppRouter.Handle("/transactions"+insolvencyPath+"/filings", HandleGetFilings(svc, clients)).Methods(http.MethodGet).Name("getFilings")
+	// The audit history spans every case, so it is only given to internal services and administrators
+	privateAppRouter.Handle("/insolvency/history", interceptors.AdminIntercept(caseAccessAdminRole)(HandleGetAuditHistory(svc))).Methods(http.MethodGet).Name("getAuditHistory")
 
 	mainRouter.Use(log.Handler)
+	mainRouter.Use(tracing.HTTPMiddleware)
+	mainRouter.Use(metrics.HTTPMiddleware)
 	mainRouter.Use(RecoveryHandler)
 }
 
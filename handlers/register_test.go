@@ -3,23 +3,29 @@ package handlers
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/interceptors"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/service"
 	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// sdkClients are the clients that call the upstream APIs with the SDKs, which the handler tests mock with httpmock
+var sdkClients = service.NewSDKClients()
+
 func setupTestRouter(t *testing.T) *mux.Router {
 	router := mux.NewRouter()
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 	mockService := mock_dao.NewMockService(mockCtrl)
 	helperService := utils.NewHelperService()
-	Register(router, mockService, helperService)
+	Register(router, mockService, sdkClients, helperService, nil, nil, nil)
 	return router
 }
 
@@ -31,10 +37,15 @@ func TestUnitRegisterRoutes(t *testing.T) {
 		router := setupTestRouter(t)
 
 		So(router.GetRoute("healthcheck"), ShouldNotBeNil)
+		So(router.GetRoute("readiness"), ShouldNotBeNil)
+		So(router.GetRoute("metrics"), ShouldNotBeNil)
 
 		So(router.GetRoute("createInsolvencyResource"), ShouldNotBeNil)
 		So(router.GetRoute("getValidationStatus"), ShouldNotBeNil)
+		So(router.GetRoute("importCase"), ShouldNotBeNil)
 		So(router.GetRoute("getFilings"), ShouldNotBeNil)
+		So(router.GetRoute("getCaseHistory"), ShouldNotBeNil)
+		So(router.GetRoute("getAuditHistory"), ShouldNotBeNil)
 
 		So(router.GetRoute("createPractitionersResource"), ShouldNotBeNil)
 		So(router.GetRoute("getPractitionerResources"), ShouldNotBeNil)
@@ -68,10 +79,15 @@ func TestUnitRegisterRoutes(t *testing.T) {
 		router := setupTestRouter(t)
 
 		So(router.GetRoute("healthcheck"), ShouldNotBeNil)
+		So(router.GetRoute("readiness"), ShouldNotBeNil)
+		So(router.GetRoute("metrics"), ShouldNotBeNil)
 
 		So(router.GetRoute("createInsolvencyResource"), ShouldNotBeNil)
 		So(router.GetRoute("getValidationStatus"), ShouldNotBeNil)
+		So(router.GetRoute("importCase"), ShouldNotBeNil)
 		So(router.GetRoute("getFilings"), ShouldNotBeNil)
+		So(router.GetRoute("getCaseHistory"), ShouldNotBeNil)
+		So(router.GetRoute("getAuditHistory"), ShouldNotBeNil)
 
 		So(router.GetRoute("createPractitionersResource"), ShouldNotBeNil)
 		So(router.GetRoute("getPractitionerResources"), ShouldNotBeNil)
@@ -104,6 +120,41 @@ func TestUnitRegisterRoutes(t *testing.T) {
 	})
 }
 
+func TestUnitRoutePermissions(t *testing.T) {
+	Convey("Every route registered on the public router has an explicit permission", t, func() {
+		router := setupTestRouter(t)
+
+		var missing []string
+		err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			// Skip the path prefixes of the subrouters, which have no handler of their own
+			if route.GetHandler() == nil {
+				return nil
+			}
+			name := route.GetName()
+			if name == "healthcheck" || name == "readiness" || name == "metrics" {
+				return nil
+			}
+			template, _ := route.GetPathTemplate()
+			if strings.HasPrefix(template, "/private/") {
+				return nil
+			}
+			if _, ok := routePermissions[name]; !ok {
+				missing = append(missing, name+" "+template)
+			}
+			return nil
+		})
+		So(err, ShouldBeNil)
+		So(missing, ShouldBeEmpty)
+	})
+
+	Convey("Distinct permissions for attachments and appointments", t, func() {
+		So(routePermissions["submitAttachment"], ShouldResemble, interceptors.PermissionManageAttachments)
+		So(routePermissions["deleteAttachment"], ShouldResemble, interceptors.PermissionManageAttachments)
+		So(routePermissions["appointPractitioner"], ShouldResemble, interceptors.PermissionAppointPractitioners)
+		So(routePermissions["deletePractitionerAppointment"], ShouldResemble, interceptors.PermissionAppointPractitioners)
+	})
+}
+
 func TestUnitHealthCheck(t *testing.T) {
 	Convey("Healthcheck", t, func() {
 		w := httptest.ResponseRecorder{}
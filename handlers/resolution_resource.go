@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	"github.com/companieshouse/insolvency-api/models"
 	"github.com/companieshouse/insolvency-api/service"
@@ -15,11 +17,11 @@ import (
 )
 
 // HandleCreateResolution receives a resolution to be stored against the Insolvency case
-func HandleCreateResolution(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleCreateResolution(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction is valid
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "resolution", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "resolution", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
@@ -42,30 +44,27 @@ func HandleCreateResolution(svc dao.Service, helperService utils.HelperService)
 		}
 
 		// Validate the provided statement details are in the correct format
-		if errs := service.ValidateResolutionRequest(request); errs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", errs))
-			m := models.NewMessageResponse("invalid request body: " + errs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if errs := service.ValidateResolutionRequest(request); len(errs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(errs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", errs)
 			return
 		}
 
 		// Validate the provided resolution date is in the correct format
-		validationErrs, err := service.ValidateResolutionDate(svc, resolutionDao, transactionID, req)
+		validationErrs, err := service.ValidateResolutionDate(svc, clients, resolutionDao, transactionID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to validate resolution: [%s]", err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction ID [%s]", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction ID [%s]: %w", transactionID, err))
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request body: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", validationErrs)
 			return
 		}
 
 		// Validate if supplied attachment matches attachments associated with supplied transactionID in mongo db
-		attachment, err := svc.GetAttachmentFromInsolvencyResource(transactionID, resolutionDao.Attachments[0])
+		attachment, err := svc.GetAttachmentFromInsolvencyResource(req.Context(), transactionID, resolutionDao.Attachments[0])
 		isValidAttachment := helperService.HandleAttachmentValidation(w, req, transactionID, attachment, err)
 		if !isValidAttachment {
 			return
@@ -81,8 +80,8 @@ func HandleCreateResolution(svc dao.Service, helperService utils.HelperService)
 		}
 
 		// Creates the statement of affairs resource in mongo if all previous checks pass
-		statusCode, err := svc.CreateResolutionResource(resolutionDao, transactionID)
-		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, statusCode, err)
+		err = svc.CreateResolutionResource(req.Context(), resolutionDao, transactionID, req.Header.Get("If-Match"))
+		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, err)
 		if !isValidCreateResource {
 			return
 		}
@@ -109,11 +108,10 @@ func HandleGetResolution(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for get resolution with transaction id: %s", transactionID))
 
-		resolution, err := svc.GetResolutionResource(transactionID)
+		resolution, err := svc.GetResolutionResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get resolution from insolvency resource in db for transaction [%s]: %v", transactionID, err))
-			m := models.NewMessageResponse("there was a problem handling your request")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if resolution.DateOfResolution == "" {
@@ -129,7 +127,7 @@ func HandleGetResolution(svc dao.Service) http.Handler {
 }
 
 // HandleDeleteResolution deletes a resolution stored against the Insolvency Case
-func HandleDeleteResolution(svc dao.Service) http.Handler {
+func HandleDeleteResolution(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		vars := mux.Vars(req)
@@ -145,33 +143,32 @@ func HandleDeleteResolution(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start DELETE request for get resolution with transaction id: %s", transactionID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if isTransactionClosed {
-			log.ErrorR(req, fmt.Errorf("transaction [%v] is already closed and cannot be updated", transactionID))
-			m := models.NewMessageResponse(fmt.Sprintf("transaction [%v] is already closed and cannot be updated", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = apperrors.Closed("transaction [%v] is already closed and cannot be updated", transactionID)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		// Delete resolution from Mongo
-		statusCode, err := svc.DeleteResolutionResource(transactionID)
+		err = svc.DeleteResolutionResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully deleted resolution with transaction ID: %s from mongo", transactionID))
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
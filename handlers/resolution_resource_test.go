@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/dao"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
@@ -27,7 +28,7 @@ func serveHandleCreateResolution(body []byte, service dao.Service, helperService
 		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
 	}
 
-	handler := HandleCreateResolution(service, helperService)
+	handler := HandleCreateResolution(service, sdkClients, helperService)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -160,9 +161,9 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 		resolution := generateResolution()
 
 		body, _ := json.Marshal(resolution)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return an empty attachment model, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, resolution.Attachments[0]).Return(models.AttachmentResourceDao{}, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, resolution.Attachments[0]).Return(models.AttachmentResourceDao{}, nil)
 
 		res := serveHandleCreateResolution(body, mockService, helperService, true, rec)
 
@@ -181,11 +182,11 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		body, _ := json.Marshal(resolution)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
 
 		res := serveHandleCreateResolution(body, mockService, mockHelperService, true, rec)
 
@@ -207,11 +208,11 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		body, _ := json.Marshal(resolution)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreateResolution(body, mockService, mockHelperService, true, rec)
 
@@ -234,7 +235,7 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		body, _ := json.Marshal(resolution)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil).AnyTimes()
@@ -257,7 +258,7 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		body, _ := json.Marshal(resolution)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil).AnyTimes()
@@ -283,9 +284,9 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 		attachment.Type = "not-resolution"
 
 		body, _ := json.Marshal(resolution)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, resolution.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, resolution.Attachments[0]).Return(attachment, nil)
 
 		res := serveHandleCreateResolution(body, mockService, helperService, true, rec)
 
@@ -304,11 +305,11 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		resolution := generateResolution()
 		body, _ := json.Marshal(resolution)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, resolution.Attachments[0]).Return(generateAttachment(), nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, resolution.Attachments[0]).Return(generateAttachment(), nil)
 		// Expect CreateResolutionResource to be called once and return an error
-		mockService.EXPECT().CreateResolutionResource(gomock.Any(), transactionID).Return(http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
+		mockService.EXPECT().CreateResolutionResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 
 		res := serveHandleCreateResolution(body, mockService, helperService, true, rec)
 
@@ -327,11 +328,11 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		resolution := generateResolution()
 		body, _ := json.Marshal(resolution)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, resolution.Attachments[0]).Return(generateAttachment(), nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, resolution.Attachments[0]).Return(generateAttachment(), nil)
 		// Expect CreateResolutionResource to be called once and return an error
-		mockService.EXPECT().CreateResolutionResource(gomock.Any(), transactionID).Return(http.StatusNotFound, fmt.Errorf("there was a problem handling your request for transaction %s not found", transactionID)).Times(1)
+		mockService.EXPECT().CreateResolutionResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(apperrors.NotFound("there was a problem handling your request for transaction %s not found", transactionID)).Times(1)
 
 		res := serveHandleCreateResolution(body, mockService, helperService, true, rec)
 
@@ -359,18 +360,18 @@ func TestUnitHandleCreateResolution(t *testing.T) {
 
 		body, _ := json.Marshal(resolution)
 		mockHelperService.EXPECT().HandleTransactionIdExistsValidation(gomock.Any(), gomock.Any(), transactionID).Return(true, transactionID).AnyTimes()
-		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockHelperService.EXPECT().HandleTransactionNotClosedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleBodyDecodedValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleMandatoryFieldValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().GenerateEtag().Return("etag", nil).AnyTimes()
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, resolution.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, resolution.Attachments[0]).Return(attachment, nil)
 		mockHelperService.EXPECT().HandleAttachmentValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 		mockHelperService.EXPECT().HandleAttachmentTypeValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(http.StatusOK).AnyTimes()
 		// Expect CreateResolutionResource to be called once and return an error
-		mockService.EXPECT().CreateResolutionResource(gomock.Any(), transactionID).Return(http.StatusCreated, nil).Times(1)
-		mockHelperService.EXPECT().HandleCreateResourceValidation(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+		mockService.EXPECT().CreateResolutionResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(nil).Times(1)
+		mockHelperService.EXPECT().HandleCreateResourceValidation(gomock.Any(), gomock.Any(), gomock.Any()).Return(true).AnyTimes()
 
 		res := serveHandleCreateResolution(body, mockService, mockHelperService, true, rec)
 
@@ -418,7 +419,7 @@ func TestUnitHandleGetResolution(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		// Expect GetResolutionResource to be called once and return an error
-		mockService.EXPECT().GetResolutionResource(transactionID).Return(models.ResolutionResourceDao{}, fmt.Errorf("failed to get resolution from insolvency resource in db for transaction [%s]: %v", transactionID, err))
+		mockService.EXPECT().GetResolutionResource(gomock.Any(), transactionID).Return(models.ResolutionResourceDao{}, fmt.Errorf("failed to get resolution from insolvency resource in db for transaction [%s]: %v", transactionID, err))
 
 		res := serveHandleGetResolution(mockService, true)
 
@@ -430,7 +431,7 @@ func TestUnitHandleGetResolution(t *testing.T) {
 		defer httpmock.DeactivateAndReset()
 
 		// Expect GetResolutionResource to be called once and return nil
-		mockService.EXPECT().GetResolutionResource(transactionID).Return(models.ResolutionResourceDao{}, nil)
+		mockService.EXPECT().GetResolutionResource(gomock.Any(), transactionID).Return(models.ResolutionResourceDao{}, nil)
 
 		res := serveHandleGetResolution(mockService, true)
 
@@ -448,7 +449,7 @@ func TestUnitHandleGetResolution(t *testing.T) {
 			},
 		}
 		// Expect GetResolutionResource to be called once and return a resolution
-		mockService.EXPECT().GetResolutionResource(transactionID).Return(resolution, nil)
+		mockService.EXPECT().GetResolutionResource(gomock.Any(), transactionID).Return(resolution, nil)
 
 		res := serveHandleGetResolution(mockService, true)
 
@@ -469,7 +470,7 @@ func serveHandleDeleteResolution(service dao.Service, tranIDSet bool) *httptest.
 	}
 	res := httptest.NewRecorder()
 
-	handler := HandleDeleteResolution(service)
+	handler := HandleDeleteResolution(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -527,7 +528,7 @@ func TestUnitHandleDeleteResolution(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		// Expect DeleteResolutionResource to be called once and return an error
-		mockService.EXPECT().DeleteResolutionResource(transactionID).Return(http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction id [%s] - could not delete resolution", transactionID))
+		mockService.EXPECT().DeleteResolutionResource(gomock.Any(), transactionID).Return(fmt.Errorf("there was a problem handling your request for transaction id [%s] - could not delete resolution", transactionID))
 
 		res := serveHandleDeleteResolution(mockService, true)
 
@@ -542,7 +543,7 @@ func TestUnitHandleDeleteResolution(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		// Expect DeleteResolutionResource to be called once and return an error
-		mockService.EXPECT().DeleteResolutionResource(transactionID).Return(http.StatusNotFound, fmt.Errorf("there was a problem handling your request for transaction id [%s] - resolution not found", transactionID))
+		mockService.EXPECT().DeleteResolutionResource(gomock.Any(), transactionID).Return(apperrors.NotFound("there was a problem handling your request for transaction id [%s] - resolution not found", transactionID))
 
 		res := serveHandleDeleteResolution(mockService, true)
 
@@ -557,7 +558,7 @@ func TestUnitHandleDeleteResolution(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		// Expect DeleteResolutionResource to be called once and delete resolution
-		mockService.EXPECT().DeleteResolutionResource(transactionID).Return(http.StatusNoContent, nil)
+		mockService.EXPECT().DeleteResolutionResource(gomock.Any(), transactionID).Return(nil)
 
 		res := serveHandleDeleteResolution(mockService, true)
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	"github.com/companieshouse/insolvency-api/models"
@@ -16,11 +17,11 @@ import (
 )
 
 // HandleCreateStatementOfAffairs receives a statement of affairs to be stored against the Insolvency case
-func HandleCreateStatementOfAffairs(svc dao.Service, helperService utils.HelperService) http.Handler {
+func HandleCreateStatementOfAffairs(svc dao.Service, clients service.Clients, helperService utils.HelperService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 
 		// Check transaction is valid
-		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "statement of affairs", service.CheckIfTransactionClosed)
+		transactionID, isValidTransaction := utils.ValidateTransaction(helperService, req, w, "statement of affairs", clients.CheckIfTransactionClosed)
 		if !isValidTransaction {
 			return
 		}
@@ -43,22 +44,20 @@ func HandleCreateStatementOfAffairs(svc dao.Service, helperService utils.HelperS
 		}
 
 		// Validate the provided statement details are in the correct format
-		validationErrs, err := service.ValidateStatementDetails(svc, statementDao, transactionID, req)
+		validationErrs, err := service.ValidateStatementDetails(svc, clients, statementDao, transactionID, req)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to validate statement of affairs: [%s]", err))
-			m := models.NewMessageResponse(fmt.Sprintf("there was a problem handling your request for transaction ID [%s]", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, fmt.Errorf("there was a problem handling your request for transaction ID [%s]: %w", transactionID, err))
 			return
 		}
-		if validationErrs != "" {
-			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", validationErrs))
-			m := models.NewMessageResponse("invalid request body: " + validationErrs)
-			utils.WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+		if len(validationErrs) > 0 {
+			log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", utils.FieldErrorMessages(validationErrs)))
+			utils.WriteFieldErrors(w, req, "invalid request body", validationErrs)
 			return
 		}
 
 		// Validate if supplied attachment matches attachments associated with supplied transactionID in mongo db
-		attachment, err := svc.GetAttachmentFromInsolvencyResource(transactionID, statementDao.Attachments[0])
+		attachment, err := svc.GetAttachmentFromInsolvencyResource(req.Context(), transactionID, statementDao.Attachments[0])
 		isValidAttachment := helperService.HandleAttachmentValidation(w, req, transactionID, attachment, err)
 		if !isValidAttachment {
 			return
@@ -74,8 +73,8 @@ func HandleCreateStatementOfAffairs(svc dao.Service, helperService utils.HelperS
 		}
 
 		// Creates the statement of affairs resource in mongo if all previous checks pass
-		statusCode, err := svc.CreateStatementOfAffairsResource(statementDao, transactionID)
-		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, statusCode, err)
+		err = svc.CreateStatementOfAffairsResource(req.Context(), statementDao, transactionID, req.Header.Get("If-Match"))
+		isValidCreateResource := helperService.HandleCreateResourceValidation(w, req, err)
 		if !isValidCreateResource {
 			return
 		}
@@ -102,11 +101,10 @@ func HandleGetStatementOfAffairs(svc dao.Service) http.Handler {
 
 		log.InfoR(req, fmt.Sprintf("start GET request for get statement of affairs with transaction id: %s", transactionID))
 
-		statementOfAffairs, err := svc.GetStatementOfAffairsResource(transactionID)
+		statementOfAffairs, err := svc.GetStatementOfAffairsResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, fmt.Errorf("failed to get statement of affairs from insolvency resource in db for transaction [%s]: %v", transactionID, err))
-			m := models.NewMessageResponse("there was a problem handling your request")
-			utils.WriteJSONWithStatus(w, req, m, http.StatusInternalServerError)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if statementOfAffairs.StatementDate == "" {
@@ -122,7 +120,7 @@ func HandleGetStatementOfAffairs(svc dao.Service) http.Handler {
 }
 
 // HandleDeleteStatementOfAffairs deletes a statement of affairs resource from an insolvency case
-func HandleDeleteStatementOfAffairs(svc dao.Service) http.Handler {
+func HandleDeleteStatementOfAffairs(svc dao.Service, clients service.Clients) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		transactionID := utils.GetTransactionIDFromVars(vars)
@@ -136,32 +134,31 @@ func HandleDeleteStatementOfAffairs(svc dao.Service) http.Handler {
 		log.InfoR(req, fmt.Sprintf("start DELETE request for submit statement of affairs with transaction id: %s", transactionID))
 
 		// Check if transaction is closed
-		isTransactionClosed, err, httpStatus := service.CheckIfTransactionClosed(transactionID, req)
+		isTransactionClosed, err := clients.CheckIfTransactionClosed(transactionID, req)
 		if err != nil {
-			log.ErrorR(req, fmt.Errorf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			m := models.NewMessageResponse(fmt.Sprintf("error checking transaction status for [%v]: [%s]", transactionID, err))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 		if isTransactionClosed {
-			log.ErrorR(req, fmt.Errorf("transaction [%v] is already closed and cannot be updated", transactionID))
-			m := models.NewMessageResponse(fmt.Sprintf("transaction [%v] is already closed and cannot be updated", transactionID))
-			utils.WriteJSONWithStatus(w, req, m, httpStatus)
+			err = apperrors.Closed("transaction [%v] is already closed and cannot be updated", transactionID)
+			log.ErrorR(req, err)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		// Delete SOA from DB
-		statusCode, err := svc.DeleteStatementOfAffairsResource(transactionID)
+		err = svc.DeleteStatementOfAffairsResource(req.Context(), transactionID)
 		if err != nil {
 			log.ErrorR(req, err)
-			m := models.NewMessageResponse(err.Error())
-			utils.WriteJSONWithStatus(w, req, m, statusCode)
+			utils.WriteErrorResponse(w, req, err)
 			return
 		}
 
 		log.InfoR(req, fmt.Sprintf("successfully deleted statement of affairs from insolvency case with transaction ID: %s", transactionID))
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusCode)
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
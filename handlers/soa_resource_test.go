@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
@@ -28,7 +30,7 @@ func serveHandleCreateStatementOfAffairs(body []byte, service dao.Service, helpe
 		req = mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
 	}
 
-	handler := HandleCreateStatementOfAffairs(service, helperService)
+	handler := HandleCreateStatementOfAffairs(service, sdkClients, helperService)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -161,9 +163,9 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		statement := generateStatement()
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return an empty attachment model, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, statement.Attachments[0]).Return(models.AttachmentResourceDao{}, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, statement.Attachments[0]).Return(models.AttachmentResourceDao{}, nil)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -183,7 +185,7 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		statement := generateStatement()
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -204,7 +206,7 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		statement.StatementDate = "1999-01-01"
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -229,7 +231,7 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		}
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -250,7 +252,7 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		statement.Attachments = []string{}
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -273,9 +275,9 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		attachment.Type = "not-soa"
 
 		body, _ := json.Marshal(statement)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, statement.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, statement.Attachments[0]).Return(attachment, nil)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -298,11 +300,11 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		attachment := generateAttachment()
 		attachment.Type = "statement-of-affairs-director"
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, statement.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, statement.Attachments[0]).Return(attachment, nil)
 		// Expect CreateStatementOfAffairsResource to be called once and return an error
-		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), transactionID).Return(http.StatusInternalServerError, fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
+		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(fmt.Errorf("there was a problem handling your request for transaction %s", transactionID)).Times(1)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -325,11 +327,11 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 		attachment := generateAttachment()
 		attachment.Type = "statement-of-affairs-director"
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, statement.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, statement.Attachments[0]).Return(attachment, nil)
 		// Expect CreateStatementOfAffairsResource to be called once and return an error
-		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), transactionID).Return(http.StatusNotFound, fmt.Errorf("there was a problem handling your request for transaction %s not found", transactionID)).Times(1)
+		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(apperrors.NotFound("there was a problem handling your request for transaction %s not found", transactionID)).Times(1)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -356,11 +358,11 @@ func TestUnitHandleCreateStatementOfAffairs(t *testing.T) {
 			Links:  models.AttachmentResourceLinksDao{},
 		}
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 		// Expect GetAttachmentFromInsolvencyResource to be called once and return attachment, nil
-		mockService.EXPECT().GetAttachmentFromInsolvencyResource(transactionID, statement.Attachments[0]).Return(attachment, nil)
+		mockService.EXPECT().GetAttachmentFromInsolvencyResource(gomock.Any(), transactionID, statement.Attachments[0]).Return(attachment, nil)
 		// Expect CreateStatementOfAffairsResource to be called once and return an error
-		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), transactionID).Return(http.StatusCreated, nil).Times(1)
+		mockService.EXPECT().CreateStatementOfAffairsResource(gomock.Any(), gomock.Any(), transactionID, gomock.Any()).Return(nil).Times(1)
 
 		res := serveHandleCreateStatementOfAffairs(body, mockService, helperService, true, rec)
 
@@ -410,13 +412,26 @@ func TestUnitHandleGetStatementOfAffairs(t *testing.T) {
 		mockService := mock_dao.NewMockService(mockCtrl)
 
 		// Expect GetStatementOfAffairsResource to be called once and return an error
-		mockService.EXPECT().GetStatementOfAffairsResource(transactionID).Return(models.StatementOfAffairsResourceDao{}, fmt.Errorf("failed to get statement of affairs from insolvency resource in db for transaction [%s]: %v", transactionID, err))
+		mockService.EXPECT().GetStatementOfAffairsResource(gomock.Any(), transactionID).Return(models.StatementOfAffairsResourceDao{}, fmt.Errorf("failed to get statement of affairs from insolvency resource in db for transaction [%s]: %v", transactionID, err))
 
 		res := serveHandleGetStatementOfAffairs(mockService, true)
 
 		So(res.Code, ShouldEqual, http.StatusInternalServerError)
 	})
 
+	Convey("Timed out getting statement of affairs from Insolvency resource", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+
+		// Expect GetStatementOfAffairsResource to be called once and return a timeout
+		mockService.EXPECT().GetStatementOfAffairsResource(gomock.Any(), transactionID).Return(models.StatementOfAffairsResourceDao{}, &apperrors.UpstreamUnavailableError{Message: "error getting statement of affairs", Timeout: true, Err: context.DeadlineExceeded})
+
+		res := serveHandleGetStatementOfAffairs(mockService, true)
+
+		So(res.Code, ShouldEqual, http.StatusGatewayTimeout)
+	})
+
 	Convey("Statment of affairs was not found on supplied transaction", t, func() {
 		httpmock.Activate()
 		mockCtrl := gomock.NewController(t)
@@ -425,7 +440,7 @@ func TestUnitHandleGetStatementOfAffairs(t *testing.T) {
 		mockService := mock_dao.NewMockService(mockCtrl)
 
 		// Expect GetStatementOfAffairsResource to be called once and return nil
-		mockService.EXPECT().GetStatementOfAffairsResource(transactionID).Return(models.StatementOfAffairsResourceDao{}, nil)
+		mockService.EXPECT().GetStatementOfAffairsResource(gomock.Any(), transactionID).Return(models.StatementOfAffairsResourceDao{}, nil)
 
 		res := serveHandleGetStatementOfAffairs(mockService, true)
 
@@ -452,7 +467,7 @@ func TestUnitHandleGetStatementOfAffairs(t *testing.T) {
 		}
 
 		// Expect GetStatementOfAffairsResource to be called once and return statement of affairs
-		mockService.EXPECT().GetStatementOfAffairsResource(transactionID).Return(statementOfAffairs, nil)
+		mockService.EXPECT().GetStatementOfAffairsResource(gomock.Any(), transactionID).Return(statementOfAffairs, nil)
 
 		res := serveHandleGetStatementOfAffairs(mockService, true)
 
@@ -473,7 +488,7 @@ func serveHandleDeleteStatementOfAffairs(service dao.Service, tranIDSet bool) *h
 	}
 	res := httptest.NewRecorder()
 
-	handler := HandleDeleteStatementOfAffairs(service)
+	handler := HandleDeleteStatementOfAffairs(service, sdkClients)
 	handler.ServeHTTP(res, req)
 
 	return res
@@ -532,7 +547,7 @@ func TestUnitHandleDeleteStatementOfAffairs(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().DeleteStatementOfAffairsResource(transactionID).Return(http.StatusInternalServerError, fmt.Errorf("err"))
+		mockService.EXPECT().DeleteStatementOfAffairsResource(gomock.Any(), transactionID).Return(fmt.Errorf("err"))
 
 		res := serveHandleDeleteStatementOfAffairs(mockService, true)
 
@@ -549,7 +564,7 @@ func TestUnitHandleDeleteStatementOfAffairs(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().DeleteStatementOfAffairsResource(transactionID).Return(http.StatusNotFound, fmt.Errorf("err"))
+		mockService.EXPECT().DeleteStatementOfAffairsResource(gomock.Any(), transactionID).Return(apperrors.NotFound("err"))
 
 		res := serveHandleDeleteStatementOfAffairs(mockService, true)
 
@@ -566,7 +581,7 @@ func TestUnitHandleDeleteStatementOfAffairs(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, "https://api.companieshouse.gov.uk/transactions/12345678", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse))
 
 		mockService := mock_dao.NewMockService(mockCtrl)
-		mockService.EXPECT().DeleteStatementOfAffairsResource(transactionID).Return(http.StatusNoContent, nil)
+		mockService.EXPECT().DeleteStatementOfAffairsResource(gomock.Any(), transactionID).Return(nil)
 
 		res := serveHandleDeleteStatementOfAffairs(mockService, true)
 
@@ -0,0 +1,26 @@
+// Package httpstatus provides the response writer that middleware uses to find the status code of a response.
+package httpstatus
+
+import "net/http"
+
+// Recorder keeps the status code written by the wrapped handler, which is 200 if the handler did not write one
+type Recorder struct {
+	http.ResponseWriter
+	Status int
+}
+
+// NewRecorder returns a Recorder that passes the response on to w
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+// WriteHeader keeps the status code and writes it to the wrapped ResponseWriter
+func (r *Recorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the wrapped ResponseWriter, so that http.ResponseController can reach it
+func (r *Recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,32 @@
+package httpstatus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitRecorder(t *testing.T) {
+	Convey("The status code is 200 if the handler does not write one", t, func() {
+		res := httptest.NewRecorder()
+		recorder := NewRecorder(res)
+
+		_, _ = recorder.Write([]byte("{}"))
+
+		So(recorder.Status, ShouldEqual, http.StatusOK)
+		So(res.Code, ShouldEqual, http.StatusOK)
+	})
+
+	Convey("The status code written by the handler is kept and passed on", t, func() {
+		res := httptest.NewRecorder()
+		recorder := NewRecorder(res)
+
+		recorder.WriteHeader(http.StatusNotFound)
+
+		So(recorder.Status, ShouldEqual, http.StatusNotFound)
+		So(res.Code, ShouldEqual, http.StatusNotFound)
+		So(recorder.Unwrap(), ShouldEqual, res)
+	})
+}
@@ -0,0 +1,35 @@
+package interceptors
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
+)
+
+const (
+	// identityTypeHeader is the header that ERIC sets to the type of identity that made the request
+	identityTypeHeader = "ERIC-Identity-Type"
+
+	// apiKeyIdentityType is the identity type of requests made by internal services with an API key
+	apiKeyIdentityType = "key"
+)
+
+// AdminIntercept only allows requests made by internal services with an API key, or by users holding the adminRole.
+// Only API key requests are allowed when adminRole is empty
+func AdminIntercept(adminRole string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(identityTypeHeader) == apiKeyIdentityType || hasAuthorisedRole(r, adminRole) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log.InfoR(r, fmt.Sprintf("AdminIntercept forbidden: request to [%s] is not from an API key or an administrator", r.URL.Path), log.Data{"role": adminRole})
+			m := models.NewMessageResponse("only internal services and administrators can access this resource")
+			utils.WriteJSONWithStatus(w, r, m, http.StatusForbidden)
+		})
+	}
+}
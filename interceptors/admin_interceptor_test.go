@@ -0,0 +1,41 @@
+package interceptors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitAdminIntercept(t *testing.T) {
+	Convey("Admin intercept", t, func() {
+		serve := func(adminRole string, headers map[string]string) int {
+			req, _ := http.NewRequestWithContext(testContext(), "GET", "/private/insolvency/history", nil)
+			for name, value := range headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			AdminIntercept(adminRole)(getTestHandler()).ServeHTTP(w, req)
+			return w.Code
+		}
+
+		Convey("Request made with an API key is allowed", func() {
+			So(serve(adminRole, map[string]string{"ERIC-Identity-Type": "key"}), ShouldEqual, http.StatusOK)
+			So(serve("", map[string]string{"ERIC-Identity-Type": "key"}), ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Administrator is allowed", func() {
+			So(serve(adminRole, map[string]string{"ERIC-Identity-Type": "oauth2", "ERIC-Authorised-Roles": "/admin/search " + adminRole}), ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Ordinary user is forbidden", func() {
+			So(serve(adminRole, map[string]string{"ERIC-Identity-Type": "oauth2"}), ShouldEqual, http.StatusForbidden)
+			So(serve(adminRole, map[string]string{"ERIC-Identity-Type": "oauth2", "ERIC-Authorised-Roles": "/admin/search"}), ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Roles are ignored when no administrator role is configured", func() {
+			So(serve("", map[string]string{"ERIC-Identity-Type": "oauth2", "ERIC-Authorised-Roles": adminRole}), ShouldEqual, http.StatusForbidden)
+		})
+	})
+}
@@ -0,0 +1,218 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AuditIntercept records an audit event for every request that changes an insolvency case, holding the user that
+// made the request, the route and path it was made to, and the fields of the case that it changed. The case is
+// read before and after the request is handled, and an event is recorded if the request succeeded or the case
+// changed. Requests for the same case are handled one at a time, so that the changes between the two reads are
+// those made by the request, and the response is only sent once its event has been recorded. The requests are only
+// serialized within this process, so when more than one instance of the service is running, a request handled by
+// another instance between the two reads is attributed to this one. The service is deployed as a single instance
+// for that reason
+func AuditIntercept(svc dao.Service) func(http.Handler) http.Handler {
+	locks := &caseLocks{locks: make(map[string]*caseLock)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			transactionID := utils.GetTransactionIDFromVars(mux.Vars(r))
+			if transactionID == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			if !ok {
+				log.ErrorR(r, fmt.Errorf("audit interceptor error: invalid AuthUserDetails from context"))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
+
+			unlock := locks.lock(transactionID)
+			defer unlock()
+
+			// Every change must be audited, so a request is not handled if the case cannot be read first
+			before, err := auditedCase(r.Context(), svc, transactionID)
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("audit interceptor error getting insolvency resource: [%v]", err))
+				utils.WriteErrorResponse(w, r, err)
+				return
+			}
+
+			buffer := newBufferedResponseWriter()
+			next.ServeHTTP(buffer, r)
+
+			// The request has been handled, so the event is recorded even if the client has gone away. A change
+			// that cannot be audited fails the request, rather than being reported to the client as a success
+			ctx := context.WithoutCancel(r.Context())
+			after, err := auditedCase(ctx, svc, transactionID)
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("audit interceptor error getting changed insolvency resource for transaction [%s]: [%v]", transactionID, err))
+				writeAuditFailure(w, r)
+				return
+			}
+
+			changes, err := service.DiffInsolvencyResource(before, after)
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("audit interceptor error comparing insolvency resource for transaction [%s]: [%v]", transactionID, err))
+				writeAuditFailure(w, r)
+				return
+			}
+			if buffer.status >= http.StatusBadRequest && len(changes) == 0 {
+				buffer.flush(w)
+				return
+			}
+
+			event := &models.AuditEventDao{
+				ID:            uuid.NewString(),
+				TransactionID: transactionID,
+				CompanyNumber: caseCompanyNumber(before, after),
+				UserID:        userDetails.ID,
+				Email:         userDetails.Email,
+				Method:        r.Method,
+				Path:          r.URL.Path,
+				StatusCode:    buffer.status,
+				CreatedAt:     time.Now().UTC().Truncate(time.Millisecond),
+				Changes:       changes,
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				event.Route = route.GetName()
+			}
+
+			if err := svc.CreateAuditEvent(ctx, event); err != nil {
+				log.ErrorR(r, fmt.Errorf("audit interceptor error recording audit event for transaction [%s]: [%v]", transactionID, err), log.Data{"route": event.Route, "user_id": event.UserID})
+				writeAuditFailure(w, r)
+				return
+			}
+
+			buffer.flush(w)
+		})
+	}
+}
+
+// writeAuditFailure responds to a request whose changes could not be audited
+func writeAuditFailure(w http.ResponseWriter, r *http.Request) {
+	m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+	utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+}
+
+// caseLocks holds a lock for each insolvency case that has an audited request in progress in this process. It does
+// not serialize requests handled by other instances of the service
+type caseLocks struct {
+	mtx   sync.Mutex
+	locks map[string]*caseLock
+}
+
+// caseLock is held by the audited request being handled for a case, and counts the requests using it so that it
+// can be removed once none are
+type caseLock struct {
+	mtx  sync.Mutex
+	refs int
+}
+
+// lock waits until no other audited request for the case with the specified transactionID is being handled, and
+// returns the function that lets the next one be handled
+func (c *caseLocks) lock(transactionID string) func() {
+	c.mtx.Lock()
+	l, ok := c.locks[transactionID]
+	if !ok {
+		l = &caseLock{}
+		c.locks[transactionID] = l
+	}
+	l.refs++
+	c.mtx.Unlock()
+
+	l.mtx.Lock()
+
+	return func() {
+		l.mtx.Unlock()
+
+		c.mtx.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(c.locks, transactionID)
+		}
+		c.mtx.Unlock()
+	}
+}
+
+// bufferedResponseWriter holds a response back from the client until the request has been audited
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (rw *bufferedResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *bufferedResponseWriter) WriteHeader(status int) {
+	if !rw.wrote {
+		rw.status = status
+		rw.wrote = true
+	}
+}
+
+func (rw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	rw.wrote = true
+	return rw.body.Write(b)
+}
+
+// flush sends the buffered response to the client
+func (rw *bufferedResponseWriter) flush(w http.ResponseWriter) {
+	for name, values := range rw.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(rw.status)
+	w.Write(rw.body.Bytes())
+}
+
+// auditedCase returns the insolvency case with the specified transactionID, or nil if it does not exist
+func auditedCase(ctx context.Context, svc dao.Service, transactionID string) (*models.InsolvencyResourceDao, error) {
+	insolvencyResource, err := svc.GetInsolvencyResource(ctx, transactionID)
+	if err != nil {
+		var notFoundErr *apperrors.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &insolvencyResource, nil
+}
+
+// caseCompanyNumber returns the company number of the case, so that events can be found across cases
+func caseCompanyNumber(before, after *models.InsolvencyResourceDao) string {
+	if after != nil {
+		return after.Data.CompanyNumber
+	}
+	if before != nil {
+		return before.Data.CompanyNumber
+	}
+	return ""
+}
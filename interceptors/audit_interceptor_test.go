@@ -0,0 +1,176 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/dao"
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// auditedRouter routes requests to the handler through the audit interceptor, so that route names are recorded
+func auditedRouter(svc dao.Service, method, name string, handler http.HandlerFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(AuditIntercept(svc))
+	router.Handle("/transactions/{transaction_id}/insolvency/practitioners/{practitioner_id}", handler).Methods(method).Name(name)
+	return router
+}
+
+func auditedRequest(ctx context.Context, method string) *http.Request {
+	return auditedPractitionerRequest(ctx, method, "1")
+}
+
+func auditedPractitionerRequest(ctx context.Context, method, practitionerID string) *http.Request {
+	req, _ := http.NewRequestWithContext(ctx, method, "/transactions/"+transactionID+"/insolvency/practitioners/"+practitionerID, nil)
+	return req
+}
+
+// failingAuditService stores cases but fails to record audit events
+type failingAuditService struct {
+	dao.Service
+}
+
+func (s *failingAuditService) CreateAuditEvent(ctx context.Context, event *models.AuditEventDao) error {
+	return fmt.Errorf("database error")
+}
+
+func TestUnitAuditIntercept(t *testing.T) {
+	Convey("Audit intercept", t, func() {
+		ctx := context.Background()
+		svc := dao.NewMemoryService()
+		So(svc.CreateInsolvencyResource(ctx, &models.InsolvencyResourceDao{
+			TransactionID: transactionID,
+			Data:          models.InsolvencyResourceDaoData{CompanyNumber: "01234567"},
+		}), ShouldBeNil)
+		So(svc.CreatePractitionersResource(ctx, &models.PractitionerResourceDao{ID: "1", IPCode: "1111"}, transactionID), ShouldBeNil)
+
+		deletePractitioner := func(w http.ResponseWriter, r *http.Request) {
+			if err := svc.DeletePractitioner(r.Context(), "1", transactionID); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		auditEvents := func() []models.AuditEventDao {
+			events, err := svc.GetAuditEvents(ctx, models.AuditEventFilter{})
+			So(err, ShouldBeNil)
+			return events
+		}
+
+		Convey("A change to a case is recorded with the user, route and changed fields", func() {
+			w := httptest.NewRecorder()
+			auditedRouter(svc, http.MethodDelete, "deletePractitioner", deletePractitioner).ServeHTTP(w, auditedRequest(userContext("user1"), http.MethodDelete))
+			So(w.Code, ShouldEqual, http.StatusNoContent)
+
+			events := auditEvents()
+			So(events, ShouldHaveLength, 1)
+			So(events[0].ID, ShouldNotBeBlank)
+			So(events[0].TransactionID, ShouldEqual, transactionID)
+			So(events[0].CompanyNumber, ShouldEqual, "01234567")
+			So(events[0].UserID, ShouldEqual, "user1")
+			So(events[0].Email, ShouldEqual, "demo@companieshouse.gov.uk")
+			So(events[0].Route, ShouldEqual, "deletePractitioner")
+			So(events[0].Method, ShouldEqual, http.MethodDelete)
+			So(events[0].Path, ShouldEqual, "/transactions/"+transactionID+"/insolvency/practitioners/1")
+			So(events[0].StatusCode, ShouldEqual, http.StatusNoContent)
+			So(events[0].CreatedAt.IsZero(), ShouldBeFalse)
+			So(events[0].Changes, ShouldHaveLength, 1)
+			So(events[0].Changes[0].Path, ShouldEqual, "data.practitioners[id=1]")
+			So(events[0].Changes[0].Before, ShouldContainSubstring, `"ip_code":"1111"`)
+			So(events[0].Changes[0].After, ShouldBeEmpty)
+		})
+
+		Convey("A failed request that changed nothing is not recorded", func() {
+			So(svc.DeletePractitioner(ctx, "1", transactionID), ShouldBeNil)
+
+			w := httptest.NewRecorder()
+			auditedRouter(svc, http.MethodDelete, "deletePractitioner", deletePractitioner).ServeHTTP(w, auditedRequest(userContext("user1"), http.MethodDelete))
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(auditEvents(), ShouldBeEmpty)
+		})
+
+		Convey("Requests that read a case are not recorded", func() {
+			w := httptest.NewRecorder()
+			auditedRouter(svc, http.MethodGet, "getPractitionerResource", getTestHandler()).ServeHTTP(w, auditedRequest(userContext("user1"), http.MethodGet))
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(auditEvents(), ShouldBeEmpty)
+		})
+
+		Convey("Concurrent requests for a case are each recorded with only their own changes", func() {
+			So(svc.CreatePractitionersResource(ctx, &models.PractitionerResourceDao{ID: "2", IPCode: "2222"}, transactionID), ShouldBeNil)
+
+			// The first request waits for a while in case the second is handled at the same time
+			entered := make(chan struct{}, 2)
+			deleteOverlapping := func(w http.ResponseWriter, r *http.Request) {
+				entered <- struct{}{}
+				if mux.Vars(r)["practitioner_id"] == "1" {
+					time.Sleep(50 * time.Millisecond)
+				}
+				if err := svc.DeletePractitioner(r.Context(), mux.Vars(r)["practitioner_id"], transactionID); err != nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}
+			router := auditedRouter(svc, http.MethodDelete, "deletePractitioner", deleteOverlapping)
+
+			var wg sync.WaitGroup
+			for _, practitionerID := range []string{"1", "2"} {
+				wg.Add(1)
+				go func(practitionerID string) {
+					defer wg.Done()
+					router.ServeHTTP(httptest.NewRecorder(), auditedPractitionerRequest(userContext("user"+practitionerID), http.MethodDelete, practitionerID))
+				}(practitionerID)
+				<-entered
+			}
+			wg.Wait()
+
+			events := auditEvents()
+			So(events, ShouldHaveLength, 2)
+			for _, event := range events {
+				So(event.Changes, ShouldHaveLength, 1)
+				So(event.Changes[0].Path, ShouldEqual, "data.practitioners[id="+event.UserID[len("user"):]+"]")
+			}
+		})
+
+		Convey("A change that cannot be audited fails the request", func() {
+			w := httptest.NewRecorder()
+			auditedRouter(&failingAuditService{Service: svc}, http.MethodDelete, "deletePractitioner", deletePractitioner).ServeHTTP(w, auditedRequest(userContext("user1"), http.MethodDelete))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(auditEvents(), ShouldBeEmpty)
+		})
+
+		Convey("Invalid user details in context", func() {
+			w := httptest.NewRecorder()
+			auditedRouter(svc, http.MethodDelete, "deletePractitioner", deletePractitioner).ServeHTTP(w, auditedRequest(invalidTestContext(), http.MethodDelete))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(auditEvents(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("A change is not made if the case cannot be read first", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("database error"))
+
+		called := false
+		handler := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+		w := httptest.NewRecorder()
+		auditedRouter(mockService, http.MethodDelete, "deletePractitioner", handler).ServeHTTP(w, auditedRequest(userContext("user1"), http.MethodDelete))
+		So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		So(called, ShouldBeFalse)
+	})
+}
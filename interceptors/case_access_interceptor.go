@@ -0,0 +1,96 @@
+// Package interceptors contains the interceptor middleware that checks for authorisation.
+package interceptors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/gorilla/mux"
+)
+
+// authorisedRolesHeader is the header that ERIC sets to the space separated roles held by the user
+const authorisedRolesHeader = "ERIC-Authorised-Roles"
+
+// CaseAccessIntercept checks that the user is the owner of the insolvency case being requested, or a member of
+// the same firm as the owner. Users holding the adminRole can access every case, unless adminRole is empty.
+// Cases with no recorded owner are only restricted to administrators when denyUnowned is set. Requests for a case
+// that does not exist yet are passed on to the handler
+func CaseAccessIntercept(svc dao.Service, firms service.FirmMembership, adminRole string, denyUnowned bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			transactionID := utils.GetTransactionIDFromVars(mux.Vars(r))
+			if transactionID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if hasAuthorisedRole(r, adminRole) {
+				log.InfoR(r, fmt.Sprintf("CaseAccessIntercept: administrator granted access to insolvency case for transaction [%s]", transactionID), log.Data{"role": adminRole})
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Get user details from context
+			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			if !ok {
+				log.ErrorR(r, fmt.Errorf("case access interceptor error: invalid AuthUserDetails from context"))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
+
+			insolvencyResource, err := svc.GetInsolvencyResource(r.Context(), transactionID)
+			if err != nil {
+				// Leave the handler to deal with a case that has not been created yet
+				var notFoundErr *apperrors.NotFoundError
+				if errors.As(err, &notFoundErr) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				log.ErrorR(r, fmt.Errorf("case access interceptor error getting insolvency resource: [%v]", err))
+				utils.WriteErrorResponse(w, r, err)
+				return
+			}
+
+			canAccess, err := service.CanUserAccessCase(firms, insolvencyResource, userDetails, denyUnowned, r)
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("error checking access to insolvency case: [%v]", err))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
+			if !canAccess {
+				log.InfoR(r, fmt.Sprintf("CaseAccessIntercept forbidden: user [%s] does not have access to insolvency case for transaction [%s]", userDetails.ID, transactionID))
+				m := models.NewMessageResponse(fmt.Sprintf("user does not have access to the insolvency case for transaction [%s]", transactionID))
+				utils.WriteJSONWithStatus(w, r, m, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasAuthorisedRole checks whether the role is one of the roles ERIC has authorised for the request
+func hasAuthorisedRole(r *http.Request, role string) bool {
+	if role == "" {
+		return false
+	}
+
+	for _, authorisedRole := range strings.Fields(r.Header.Get(authorisedRolesHeader)) {
+		if authorisedRole == role {
+			return true
+		}
+	}
+	return false
+}
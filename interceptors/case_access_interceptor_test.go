@@ -0,0 +1,145 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	transactionID = "12345678"
+	adminRole     = "/admin/insolvency"
+)
+
+// stubFirmMembership is a firm membership that returns firm IDs from a map
+type stubFirmMembership map[string]string
+
+func (s stubFirmMembership) GetFirmID(email string) (string, error) {
+	return s[email], nil
+}
+
+func caseAccessRequest(ctx context.Context) *http.Request {
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/transactions/"+transactionID+"/insolvency", nil)
+	return mux.SetURLVars(req, map[string]string{"transaction_id": transactionID})
+}
+
+func TestUnitCaseAccessIntercept(t *testing.T) {
+	Convey("Case access intercept", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+
+		firms := stubFirmMembership{"demo@companieshouse.gov.uk": "firm1"}
+
+		Convey("No transaction ID in the url", func() {
+			req, _ := http.NewRequestWithContext(testContext(), "GET", "", nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Invalid user details in context", func() {
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(invalidTestContext()))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("Case has not been created yet", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, apperrors.NotFound("there was a problem handling your request for transaction [%s] - insolvency case not found", transactionID))
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Error getting the case", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("there was a problem handling your request for transaction [%s]", transactionID))
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("User is not the owner and is in a different firm", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm2"}, nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("User is in the same firm as the owner", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm1"}, nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Case has no recorded owner", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{TransactionID: transactionID}, nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Case has no recorded owner before ownerless cases are denied", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{TransactionID: transactionID}, nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, false)(getTestHandler()).ServeHTTP(w, caseAccessRequest(testContext()))
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Administrator can access a case without reading it", func() {
+			req := caseAccessRequest(testContext())
+			req.Header.Set("ERIC-Authorised-Roles", "/admin/search "+adminRole)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("User without the administrator role is checked as normal", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm2"}, nil)
+			req := caseAccessRequest(testContext())
+			req.Header.Set("ERIC-Authorised-Roles", "/admin/search")
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("Roles are ignored when no administrator role is configured", func() {
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm2"}, nil)
+			req := caseAccessRequest(testContext())
+			req.Header.Set("ERIC-Authorised-Roles", adminRole)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, "", true)(getTestHandler()).ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusForbidden)
+		})
+
+		Convey("User is the owner of the case", func() {
+			ctx := context.WithValue(context.Background(), authentication.ContextKeyUserDetails, authentication.AuthUserDetails{Email: "owner@companieshouse.gov.uk", ID: "user5678"})
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{CreatedBy: "user5678"}, nil)
+
+			w := httptest.NewRecorder()
+			CaseAccessIntercept(mockService, firms, adminRole, true)(getTestHandler()).ServeHTTP(w, caseAccessRequest(ctx))
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+	})
+}
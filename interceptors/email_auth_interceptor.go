@@ -7,33 +7,42 @@ import (
 
 	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
 	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
 )
 
-// EmailAuthIntercept checks that the user has a registered Insolvency Practitioner email address in Mongo to perform the request action
-func EmailAuthIntercept(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get user details from context
-		userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
-		if !ok {
-			log.ErrorR(r, fmt.Errorf("email auth interceptor error: invalid AuthUserDetails from context"))
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+// EmailAuthIntercept checks that the user has a registered Insolvency Practitioner email address in Mongo to perform the request action.
+// The EFS allow list is checked with the supplied clients
+func EmailAuthIntercept(clients service.Clients) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Get user details from context
+			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			if !ok {
+				log.ErrorR(r, fmt.Errorf("email auth interceptor error: invalid AuthUserDetails from context"))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
 
-		isUserOnEfsAllowList, err := service.IsUserOnEfsAllowList(userDetails.Email, r)
+			isUserOnEfsAllowList, err := clients.IsUserOnEfsAllowList(userDetails.Email, r)
 
-		if err != nil {
-			log.ErrorR(r, fmt.Errorf("error checking EFS allow list: [%s]", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		if !isUserOnEfsAllowList {
-			log.ErrorR(r, fmt.Errorf("user not on EFS allow list"))
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("error checking EFS allow list: [%s]", err))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
+			if !isUserOnEfsAllowList {
+				log.ErrorR(r, fmt.Errorf("user not on EFS allow list"))
+				m := models.NewMessageResponse(constants.MsgUserNotAuthorised)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusUnauthorized)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
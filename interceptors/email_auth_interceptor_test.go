@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/insolvency-api/service"
 	"github.com/jarcoal/httpmock"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -47,7 +48,7 @@ func TestUnitEmailAuthIntercept(t *testing.T) {
 			req, _ := http.NewRequestWithContext(invalidTestContext(), "GET", "", nil)
 
 			w := httptest.NewRecorder()
-			test := EmailAuthIntercept(getTestHandler())
+			test := EmailAuthIntercept(service.NewSDKClients())(getTestHandler())
 			test.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 		})
@@ -63,7 +64,7 @@ func TestUnitEmailAuthIntercept(t *testing.T) {
 			)
 
 			w := httptest.NewRecorder()
-			test := EmailAuthIntercept(getTestHandler())
+			test := EmailAuthIntercept(service.NewSDKClients())(getTestHandler())
 			test.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 		})
@@ -79,9 +80,29 @@ func TestUnitEmailAuthIntercept(t *testing.T) {
 			)
 
 			w := httptest.NewRecorder()
-			test := EmailAuthIntercept(getTestHandler())
+			test := EmailAuthIntercept(service.NewSDKClients())(getTestHandler())
 			test.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			So(w.Body.String(), ShouldContainSubstring, `"message":"user is not authorised to perform this request"`)
+		})
+
+		Convey("User not allowed with problem json accepted", func() {
+			req, _ := http.NewRequestWithContext(testContext(), "GET", "", nil)
+			req.Header.Set("Accept", "application/problem+json")
+
+			defer httpmock.Reset()
+			httpmock.RegisterResponder(
+				http.MethodGet,
+				"http://localhost:4001/efs-submission-api/company-authentication/allow-list/demo@companieshouse.gov.uk",
+				httpmock.NewStringResponder(http.StatusOK, "false"),
+			)
+
+			w := httptest.NewRecorder()
+			test := EmailAuthIntercept(service.NewSDKClients())(getTestHandler())
+			test.ServeHTTP(w, req)
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
+			So(w.Body.String(), ShouldContainSubstring, `"status":401`)
 		})
 
 		Convey("User allowed", func() {
@@ -95,7 +116,7 @@ func TestUnitEmailAuthIntercept(t *testing.T) {
 			)
 
 			w := httptest.NewRecorder()
-			test := EmailAuthIntercept(getTestHandler())
+			test := EmailAuthIntercept(service.NewSDKClients())(getTestHandler())
 			test.ServeHTTP(w, req)
 			So(w.Code, ShouldEqual, http.StatusOK)
 		})
@@ -0,0 +1,270 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyKeyHeader is the header that clients send with a POST request so that it can be retried safely
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on a response that has been replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyKeyTTL is how long a response is kept for replay when no TTL is configured
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	// DefaultIdempotencyKeyLease is how long a key is reserved for the request that is handling it when no lease is
+	// configured. A key whose request has not finished by then, for example because the instance handling it
+	// stopped, can be reserved again by a retry
+	DefaultIdempotencyKeyLease = time.Minute
+
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentRequestSize is the largest request body that is read to be hashed, which allows for the largest
+	// attachment and the rest of the multipart form it is uploaded in
+	maxIdempotentRequestSize = service.MaxFileSize + 1048576
+)
+
+// replayedHeaders are the response headers that are stored with a response and set again when it is replayed
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyIntercept handles POST requests sent with an Idempotency-Key header at most once for each user and
+// key. The key is reserved for the lease while the first request is handled, then its response is stored for the
+// ttl and replayed to any retry with the same body, while a retry with a different body is rejected. Responses with
+// a server error are not stored, and neither are requests whose handler panics, so that the request can be retried
+func IdempotencyIntercept(svc dao.Service, ttl, lease time.Duration) func(http.Handler) http.Handler {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	if lease <= 0 {
+		lease = DefaultIdempotencyKeyLease
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				m := models.NewMessageResponse(fmt.Sprintf("the %s header must be no more than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+				return
+			}
+
+			// Keys are chosen by clients, so they are only unique to the user that sent them
+			userDetails, ok := r.Context().Value(authentication.ContextKeyUserDetails).(authentication.AuthUserDetails)
+			if !ok {
+				log.ErrorR(r, fmt.Errorf("idempotency interceptor error: invalid AuthUserDetails from context"))
+				m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+				utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestSize))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				m := models.NewMessageResponse(fmt.Sprintf("the request body must be no more than %d bytes", maxBytesErr.Limit))
+				utils.WriteJSONWithStatus(w, r, m, http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("idempotency interceptor error reading request body: [%v]", err))
+				m := models.NewMessageResponse("there was a problem reading the request body")
+				utils.WriteJSONWithStatus(w, r, m, http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			reservation := &models.IdempotencyKeyDao{
+				Key:         userDetails.ID + ":" + idempotencyKey,
+				Token:       uuid.NewString(),
+				RequestHash: requestHash(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(lease),
+			}
+
+			stored, err := svc.ReserveIdempotencyKey(r.Context(), reservation)
+			if err != nil {
+				log.ErrorR(r, fmt.Errorf("idempotency interceptor error reserving key: [%v]", err))
+				utils.WriteErrorResponse(w, r, err)
+				return
+			}
+
+			if stored != nil {
+				replay(w, r, stored, reservation.RequestHash, idempotencyKey)
+				return
+			}
+
+			// The client may have gone away, which is when it is most likely to retry, so the outcome is stored
+			// whether or not the request has been cancelled
+			ctx := context.WithoutCancel(r.Context())
+			release := func() {
+				if err := svc.ReleaseIdempotencyKey(ctx, reservation.Key, reservation.Token); err != nil {
+					log.ErrorR(r, fmt.Errorf("idempotency interceptor error releasing key: [%v]", err))
+				}
+			}
+
+			// A handler that panics has not produced a response to replay, so the key is released for the retry
+			// before the panic carries on to the server
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			recorder := &recordingResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode() >= http.StatusInternalServerError {
+				release()
+				return
+			}
+
+			if err := svc.CompleteIdempotencyKey(ctx, reservation.Key, reservation.Token, recorder.response(), time.Now().Add(ttl)); err != nil {
+				log.ErrorR(r, fmt.Errorf("idempotency interceptor error storing response: [%v]", err))
+			}
+		})
+	}
+}
+
+// replay writes the response stored for a key to a request that has been sent with the key before
+func replay(w http.ResponseWriter, r *http.Request, stored *models.IdempotencyKeyDao, hash, idempotencyKey string) {
+	if stored.RequestHash != hash {
+		log.InfoR(r, "idempotency key reused for a different request", log.Data{"idempotency_key": idempotencyKey})
+		m := models.NewMessageResponse(fmt.Sprintf("the idempotency key [%s] has already been used for a different request", idempotencyKey))
+		utils.WriteJSONWithStatus(w, r, m, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if stored.Response == nil {
+		log.InfoR(r, "idempotency key is still in use", log.Data{"idempotency_key": idempotencyKey})
+		m := models.NewMessageResponse(fmt.Sprintf("a request with the idempotency key [%s] is still being handled", idempotencyKey))
+		utils.WriteJSONWithStatus(w, r, m, http.StatusConflict)
+		return
+	}
+
+	log.InfoR(r, "replaying response for idempotency key", log.Data{"idempotency_key": idempotencyKey})
+	for name, values := range stored.Response.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Response.StatusCode)
+	w.Write(stored.Response.Body)
+}
+
+// requestHash returns a hash of the method, path and body of the request, so that a retry can be told apart from a
+// different request sent with the same key
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.Path)
+
+	// Clients usually choose a new boundary each time they encode a form, so the parts of a form are hashed
+	// rather than the body
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil && mediaType == "multipart/form-data" {
+		if parts, err := formParts(body, params["boundary"]); err == nil {
+			for _, part := range parts {
+				h.Write(part)
+			}
+			return hex.EncodeToString(h.Sum(nil))
+		}
+	}
+
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// formParts returns the name, file name and contents of each part of a multipart form, encoded with their
+// lengths so that they cannot run into each other
+func formParts(body []byte, boundary string) ([][]byte, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	var parts [][]byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, fmt.Appendf(nil, "%d:%s%d:%s%d:", len(part.FormName()), part.FormName(), len(part.FileName()), part.FileName(), len(contents)), contents)
+	}
+}
+
+// recordingResponseWriter passes a response on to the client while keeping a copy of it to store
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingResponseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// statusCode returns the status of the response, which is 200 if the handler wrote nothing
+func (rw *recordingResponseWriter) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+// response returns the recorded response to store for replay
+func (rw *recordingResponseWriter) response() *models.IdempotentResponseDao {
+	header := make(map[string][]string)
+	for _, name := range replayedHeaders {
+		if values := rw.Header().Values(name); len(values) > 0 {
+			header[name] = values
+		}
+	}
+
+	return &models.IdempotentResponseDao{
+		StatusCode: rw.statusCode(),
+		Header:     header,
+		Body:       rw.body.Bytes(),
+	}
+}
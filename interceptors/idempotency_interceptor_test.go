@@ -0,0 +1,231 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/insolvency-api/dao"
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingHandler creates a resource from the request body, counting how many times it is called
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	body, _ := io.ReadAll(r.Body)
+
+	status := h.status
+	if status == 0 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/practitioners/%d", h.calls))
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"call": %d, "body": %q}`, h.calls, body)
+}
+
+func idempotentRequest(ctx context.Context, key, body string) *http.Request {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/transactions/"+transactionID+"/insolvency/practitioners", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), authentication.ContextKeyUserDetails, authentication.AuthUserDetails{ID: userID, Email: "demo@companieshouse.gov.uk"})
+}
+
+// attachmentForm returns a multipart form holding the file, encoded with a new boundary each time
+func attachmentForm(contents string) (string, []byte) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("attachment_type", "resolution")
+	part, _ := writer.CreateFormFile("file", "resolution.pdf")
+	part.Write([]byte(contents))
+	writer.Close()
+	return writer.FormDataContentType(), body.Bytes()
+}
+
+func TestUnitIdempotencyIntercept(t *testing.T) {
+	Convey("Idempotency intercept", t, func() {
+		svc := dao.NewMemoryService()
+		handler := &countingHandler{}
+		intercept := IdempotencyIntercept(svc, 24*time.Hour, time.Hour)(handler)
+
+		serve := func(req *http.Request) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			intercept.ServeHTTP(w, req)
+			return w
+		}
+
+		Convey("A retry with the same body is given the original response without calling the handler again", func() {
+			first := serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`))
+			So(first.Code, ShouldEqual, http.StatusCreated)
+			So(first.Header().Get(IdempotentReplayedHeader), ShouldBeEmpty)
+
+			retry := serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`))
+			So(handler.calls, ShouldEqual, 1)
+			So(retry.Code, ShouldEqual, http.StatusCreated)
+			So(retry.Body.String(), ShouldEqual, first.Body.String())
+			So(retry.Header().Get("Content-Type"), ShouldEqual, "application/json")
+			So(retry.Header().Get("Location"), ShouldEqual, "/practitioners/1")
+			So(retry.Header().Get(IdempotentReplayedHeader), ShouldEqual, "true")
+		})
+
+		Convey("Reusing a key with a different body is rejected", func() {
+			serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`))
+
+			w := serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "5678"}`))
+			So(handler.calls, ShouldEqual, 1)
+			So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+			So(w.Body.String(), ShouldContainSubstring, "the idempotency key [key1] has already been used for a different request")
+		})
+
+		Convey("Keys are only shared between requests from the same user", func() {
+			serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`))
+
+			w := serve(idempotentRequest(userContext("user2"), "key1", `{"ip_code": "5678"}`))
+			So(handler.calls, ShouldEqual, 2)
+			So(w.Code, ShouldEqual, http.StatusCreated)
+		})
+
+		Convey("Requests without a key, and requests other than POST, are always handled", func() {
+			serve(idempotentRequest(userContext("user1"), "", `{"ip_code": "1234"}`))
+			serve(idempotentRequest(userContext("user1"), "", `{"ip_code": "1234"}`))
+
+			req := idempotentRequest(userContext("user1"), "key1", "")
+			req.Method = http.MethodDelete
+			serve(req)
+			serve(req)
+
+			So(handler.calls, ShouldEqual, 4)
+		})
+
+		Convey("A server error releases the key so that the request can be retried", func() {
+			handler.status = http.StatusInternalServerError
+			So(serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`)).Code, ShouldEqual, http.StatusInternalServerError)
+
+			handler.status = http.StatusCreated
+			So(serve(idempotentRequest(userContext("user1"), "key1", `{"ip_code": "1234"}`)).Code, ShouldEqual, http.StatusCreated)
+			So(handler.calls, ShouldEqual, 2)
+		})
+
+		Convey("A client error is replayed", func() {
+			handler.status = http.StatusBadRequest
+			serve(idempotentRequest(userContext("user1"), "key1", `{}`))
+
+			w := serve(idempotentRequest(userContext("user1"), "key1", `{}`))
+			So(handler.calls, ShouldEqual, 1)
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("An attachment form re-encoded with a new boundary is a retry of the same request", func() {
+			contentType, body := attachmentForm("file contents")
+			req := idempotentRequest(userContext("user1"), "key1", string(body))
+			req.Header.Set("Content-Type", contentType)
+			serve(req)
+
+			contentType, body = attachmentForm("file contents")
+			req = idempotentRequest(userContext("user1"), "key1", string(body))
+			req.Header.Set("Content-Type", contentType)
+			So(serve(req).Code, ShouldEqual, http.StatusCreated)
+			So(handler.calls, ShouldEqual, 1)
+
+			contentType, body = attachmentForm("other contents")
+			req = idempotentRequest(userContext("user1"), "key1", string(body))
+			req.Header.Set("Content-Type", contentType)
+			So(serve(req).Code, ShouldEqual, http.StatusUnprocessableEntity)
+		})
+
+		Convey("A request whose key is still being handled is a conflict", func() {
+			blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w2 := httptest.NewRecorder()
+				IdempotencyIntercept(svc, 24*time.Hour, time.Hour)(handler).ServeHTTP(w2, idempotentRequest(userContext("user1"), "key1", `{}`))
+				So(w2.Code, ShouldEqual, http.StatusConflict)
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			w := httptest.NewRecorder()
+			IdempotencyIntercept(svc, 24*time.Hour, time.Hour)(blocking).ServeHTTP(w, idempotentRequest(userContext("user1"), "key1", `{}`))
+			So(w.Code, ShouldEqual, http.StatusCreated)
+			So(handler.calls, ShouldEqual, 0)
+		})
+
+		Convey("A key whose handler panicked is released so that the request can be retried", func() {
+			panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("handler failed")
+			})
+
+			So(func() {
+				IdempotencyIntercept(svc, 24*time.Hour, time.Hour)(panicking).ServeHTTP(httptest.NewRecorder(), idempotentRequest(userContext("user1"), "key1", `{}`))
+			}, ShouldPanicWith, "handler failed")
+
+			So(serve(idempotentRequest(userContext("user1"), "key1", `{}`)).Code, ShouldEqual, http.StatusCreated)
+			So(handler.calls, ShouldEqual, 1)
+		})
+
+		Convey("A key is only reserved for the lease while its request is being handled", func() {
+			abandoned := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// A retry after the lease has expired reserves the key again, as if the first request had been lost
+				w2 := httptest.NewRecorder()
+				IdempotencyIntercept(svc, 24*time.Hour, time.Nanosecond)(handler).ServeHTTP(w2, idempotentRequest(userContext("user1"), "key1", `{}`))
+				So(w2.Code, ShouldEqual, http.StatusCreated)
+				w.WriteHeader(http.StatusCreated)
+			})
+
+			IdempotencyIntercept(svc, 24*time.Hour, time.Nanosecond)(abandoned).ServeHTTP(httptest.NewRecorder(), idempotentRequest(userContext("user1"), "key1", `{}`))
+			So(handler.calls, ShouldEqual, 1)
+		})
+
+		Convey("A body larger than the largest attachment form is rejected", func() {
+			w := serve(idempotentRequest(userContext("user1"), "key1", strings.Repeat("x", maxIdempotentRequestSize+1)))
+			So(w.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+			So(handler.calls, ShouldEqual, 0)
+		})
+
+		Convey("A key that is too long is rejected", func() {
+			w := serve(idempotentRequest(userContext("user1"), strings.Repeat("k", 256), `{}`))
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			So(handler.calls, ShouldEqual, 0)
+		})
+
+		Convey("Invalid user details in context", func() {
+			w := serve(idempotentRequest(invalidTestContext(), "key1", `{}`))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(handler.calls, ShouldEqual, 0)
+		})
+	})
+
+	Convey("Idempotency intercept with a store that fails", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		handler := &countingHandler{}
+
+		Convey("An error reserving the key is returned without calling the handler", func() {
+			mockService.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("database error"))
+
+			w := httptest.NewRecorder()
+			IdempotencyIntercept(mockService, 24*time.Hour, time.Hour)(handler).ServeHTTP(w, idempotentRequest(userContext("user1"), "key1", `{}`))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(handler.calls, ShouldEqual, 0)
+		})
+	})
+}
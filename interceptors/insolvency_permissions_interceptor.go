@@ -7,34 +7,105 @@ import (
 
 	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
+	"github.com/gorilla/mux"
 )
 
-// InsolvencyPermissionsIntercept checks that the user has the necessary token permissions for an insolvency practitioner
-func InsolvencyPermissionsIntercept(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// Permission is a token permission key and value that a user must hold in order to call a route. A user also
+// holds the permission if their token has any of the Implied values for the key, so that tokens issued before
+// a permission was introduced keep the access they had
+type Permission struct {
+	Key     string
+	Value   string
+	Implied []string
+}
 
-		tp := &authentication.TokenPermissions{}
-		err := tp.DecodeAuthorisedTokenPermissions(r)
-		if err != nil {
-			log.ErrorR(r, fmt.Errorf("TokenPermissionsAuthInterceptor error decoding token permissions: [%v]", err))
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+// Permissions that can be required of a user calling the insolvency routes. The permissions for attachments
+// and appointments are implied by the update permission that existing tokens hold
+var (
+	PermissionCaseRead             = Permission{Key: authentication.PermissionKeyInsolvencyCases, Value: authentication.PermissionValueRead}
+	PermissionCaseUpdate           = Permission{Key: authentication.PermissionKeyInsolvencyCases, Value: authentication.PermissionValueUpdate}
+	PermissionManageAttachments    = Permission{Key: authentication.PermissionKeyInsolvencyCases, Value: "manage_attachments", Implied: []string{authentication.PermissionValueUpdate}}
+	PermissionAppointPractitioners = Permission{Key: authentication.PermissionKeyInsolvencyCases, Value: "appoint_practitioners", Implied: []string{authentication.PermissionValueUpdate}}
+)
+
+// heldIn returns whether the permission, or a value that implies it, is in the decoded token permissions
+func (p Permission) heldIn(tp *authentication.TokenPermissions) bool {
+	if tp.HasPermission(p.Key, p.Value) {
+		return true
+	}
+	for _, value := range p.Implied {
+		if tp.HasPermission(p.Key, value) {
+			return true
 		}
+	}
+	return false
+}
+
+// HeldBy returns whether the user making the request holds the permission in their token, for handlers whose
+// requests need more than the single permission checked for the route
+func (p Permission) HeldBy(r *http.Request) (bool, error) {
+	tp := &authentication.TokenPermissions{}
+	if err := tp.DecodeAuthorisedTokenPermissions(r); err != nil {
+		return false, err
+	}
+	return p.heldIn(tp), nil
+}
+
+// RoutePermissions maps the name of a registered route to the permission required to call it
+type RoutePermissions map[string]Permission
 
-		isReadRequest := http.MethodGet == r.Method
-		isUpdateRequest := http.MethodPost == r.Method || http.MethodDelete == r.Method
-		hasPermissionInsolvencyRead := tp.HasPermission(authentication.PermissionKeyInsolvencyCases, authentication.PermissionValueRead)
-		hasPermissionInsolvencyUpdate := tp.HasPermission(authentication.PermissionKeyInsolvencyCases, authentication.PermissionValueUpdate)
-
-		switch {
-		case hasPermissionInsolvencyRead && isReadRequest:
-			next.ServeHTTP(w, r)
-		case hasPermissionInsolvencyUpdate && isUpdateRequest:
-			next.ServeHTTP(w, r)
-		default:
-			log.InfoR(r, "InsolvencyPermissionsIntercept unauthorised")
-			w.WriteHeader(http.StatusUnauthorized)
+// PermissionFor returns the permission required for the request, looked up by the name of the matched route.
+// There is no permission for a route that has no entry, so that it cannot be called until it is given one
+func (p RoutePermissions) PermissionFor(r *http.Request) (Permission, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return Permission{}, false
+	}
+
+	permission, ok := p[route.GetName()]
+	return permission, ok
+}
+
+// Intercept checks that the user has the token permission required for the route being called. Requests for a
+// route with no entry in the table are forbidden
+func (p RoutePermissions) Intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		permission, ok := p.PermissionFor(r)
+		if !ok {
+			var routeName string
+			if route := mux.CurrentRoute(r); route != nil {
+				routeName = route.GetName()
+			}
+			log.ErrorR(r, fmt.Errorf("RoutePermissionsIntercept forbidden: no permission defined for route [%s]", routeName), log.Data{"method": r.Method})
+			m := models.NewMessageResponse(constants.MsgUserNotAuthorised)
+			utils.WriteJSONWithStatus(w, r, m, http.StatusForbidden)
 			return
 		}
+
+		checkPermission(w, r, next, permission)
 	})
 }
+
+// checkPermission passes the request on to next if the user holds the permission in their token
+func checkPermission(w http.ResponseWriter, r *http.Request, next http.Handler, permission Permission) {
+	tp := &authentication.TokenPermissions{}
+	err := tp.DecodeAuthorisedTokenPermissions(r)
+	if err != nil {
+		log.ErrorR(r, fmt.Errorf("TokenPermissionsAuthInterceptor error decoding token permissions: [%v]", err))
+		m := models.NewMessageResponse(constants.MsgHandleReqProblem)
+		utils.WriteJSONWithStatus(w, r, m, http.StatusInternalServerError)
+		return
+	}
+
+	if !permission.heldIn(tp) {
+		log.InfoR(r, "RoutePermissionsIntercept unauthorised", log.Data{"permission_key": permission.Key, "permission_value": permission.Value})
+		m := models.NewMessageResponse(constants.MsgUserNotAuthorised)
+		utils.WriteJSONWithStatus(w, r, m, http.StatusUnauthorized)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
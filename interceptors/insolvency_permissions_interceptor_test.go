@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/chs.go/authentication"
+	"github.com/gorilla/mux"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -14,56 +15,91 @@ func setTokenHeader(req *http.Request, permissions string) {
 	req.Header.Set("ERIC-Authorised-Token-Permissions", permissions)
 }
 
-func TestUnitInsolvencyPermissionsIntercept(t *testing.T) {
-	Convey("Insolvency permissions intercept", t, func() {
+func serveRoutePermissionsRequest(permissions RoutePermissions, method string, tokenPermissions string) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.Use(permissions.Intercept)
+	router.Handle("/attachments", getTestHandler()).Methods(http.MethodPost).Name("submitAttachment")
+	router.Handle("/attachments", getTestHandler()).Methods(http.MethodGet).Name("getAttachments")
 
-		Convey("Invalid token header", func() {
-			req, _ := http.NewRequest("GET", "", nil)
-			setTokenHeader(req, "invalid=invalid=invalid")
+	req, _ := http.NewRequest(method, "/attachments", nil)
+	setTokenHeader(req, tokenPermissions)
 
-			w := httptest.NewRecorder()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-			test := InsolvencyPermissionsIntercept(getTestHandler())
-			test.ServeHTTP(w, req)
+	return w
+}
 
-			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+func TestUnitRoutePermissionsIntercept(t *testing.T) {
+	Convey("Route permissions intercept", t, func() {
+		permissions := RoutePermissions{"submitAttachment": PermissionManageAttachments}
+
+		Convey("Route requires a distinct permission", func() {
+			w := serveRoutePermissionsRequest(permissions, http.MethodPost, authentication.PermissionKeyInsolvencyCases+"=read")
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
 		})
 
-		Convey("Read request", func() {
-			req, _ := http.NewRequest("GET", "", nil)
-			setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=read")
+		Convey("User holds the distinct permission for the route", func() {
+			w := serveRoutePermissionsRequest(permissions, http.MethodPost, authentication.PermissionKeyInsolvencyCases+"=manage_attachments")
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
 
-			w := httptest.NewRecorder()
+		Convey("User holds a permission that implies the distinct permission", func() {
+			w := serveRoutePermissionsRequest(permissions, http.MethodPost, authentication.PermissionKeyInsolvencyCases+"=update")
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
 
-			test := InsolvencyPermissionsIntercept(getTestHandler())
-			test.ServeHTTP(w, req)
+		Convey("Route with no entry is forbidden", func() {
+			w := serveRoutePermissionsRequest(permissions, http.MethodGet, authentication.PermissionKeyInsolvencyCases+"=read,update")
+			So(w.Code, ShouldEqual, http.StatusForbidden)
+		})
 
-			So(w.Code, ShouldEqual, http.StatusOK)
+		Convey("Invalid token header", func() {
+			w := serveRoutePermissionsRequest(permissions, http.MethodPost, "invalid=invalid=invalid")
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 		})
+	})
+}
 
-		Convey("Update request", func() {
-			req, _ := http.NewRequest("POST", "", nil)
-			setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=update")
+func TestUnitPermissionHeldBy(t *testing.T) {
+	Convey("A permission is held when it is in the user's token", t, func() {
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=read,appoint_practitioners")
 
-			w := httptest.NewRecorder()
+		held, err := PermissionAppointPractitioners.HeldBy(req)
+		So(err, ShouldBeNil)
+		So(held, ShouldBeTrue)
 
-			test := InsolvencyPermissionsIntercept(getTestHandler())
-			test.ServeHTTP(w, req)
+		held, err = PermissionManageAttachments.HeldBy(req)
+		So(err, ShouldBeNil)
+		So(held, ShouldBeFalse)
+	})
 
-			So(w.Code, ShouldEqual, http.StatusOK)
-		})
+	Convey("Existing update permissions imply the distinct permissions", t, func() {
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=read,update")
 
-		Convey("Incorrect token permission", func() {
-			req, _ := http.NewRequest("POST", "", nil)
-			setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=read")
+		for _, permission := range []Permission{PermissionManageAttachments, PermissionAppointPractitioners} {
+			held, err := permission.HeldBy(req)
+			So(err, ShouldBeNil)
+			So(held, ShouldBeTrue)
+		}
+	})
 
-			w := httptest.NewRecorder()
+	Convey("Read does not imply the update permissions", t, func() {
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		setTokenHeader(req, authentication.PermissionKeyInsolvencyCases+"=read")
 
-			test := InsolvencyPermissionsIntercept(getTestHandler())
-			test.ServeHTTP(w, req)
+		held, err := PermissionManageAttachments.HeldBy(req)
+		So(err, ShouldBeNil)
+		So(held, ShouldBeFalse)
+	})
 
-			So(w.Code, ShouldEqual, http.StatusUnauthorized)
-		})
+	Convey("An invalid token header is an error", t, func() {
+		req, _ := http.NewRequest(http.MethodPost, "", nil)
+		setTokenHeader(req, "invalid=invalid=invalid")
 
+		_, err := PermissionCaseUpdate.HeldBy(req)
+		So(err, ShouldNotBeNil)
 	})
 }
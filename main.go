@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/outbox"
+	"github.com/companieshouse/insolvency-api/service"
+	"github.com/companieshouse/insolvency-api/tracing"
 	"github.com/companieshouse/insolvency-api/utils"
 
 	"github.com/companieshouse/chs.go/log"
@@ -30,16 +33,84 @@ func main() {
 		return
 	}
 
+	// Set up tracing if it is enabled, exporting spans to the OTLP collector if one is configured
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		log.Error(fmt.Errorf("error configuring tracing: %s. Exiting", err), nil)
+		return
+	}
+
 	// Create router
 	mainRouter := mux.NewRouter()
 
 	// Create DAO service with database information
-	svc := dao.NewDAOService(cfg)
+	svc, err := dao.NewDAOService(cfg)
+	if err != nil {
+		log.Error(fmt.Errorf("error creating DAO service: %s. Exiting", err), nil)
+		return
+	}
+
+	// Call the upstream APIs with the Companies House SDKs, as the user making each request
+	clients := service.NewSDKClients()
 
 	// Create helper service with common log handler
 	helperSvc := utils.NewHelperService()
 
-	handlers.Register(mainRouter, svc, helperSvc)
+	// Load the insolvency practitioner register used to check IP codes, if one is configured
+	var practitionerRegister service.PractitionerRegister
+	if cfg.PractitionerRegisterFile != "" {
+		practitionerRegister, err = service.NewFilePractitionerRegister(cfg.PractitionerRegisterFile)
+		if err != nil {
+			log.Error(fmt.Errorf("error loading practitioner register: %s. Exiting", err), nil)
+			return
+		}
+		log.Info("practitioner register loaded", log.Data{"file": cfg.PractitionerRegisterFile})
+	} else {
+		log.Info("no practitioner register configured - IP codes will not be checked against the register")
+	}
+
+	// Load the firm membership used to share cases between colleagues, if one is configured
+	var firms service.FirmMembership
+	if cfg.FirmMembershipFile != "" {
+		firms, err = service.NewFileFirmMembership(cfg.FirmMembershipFile)
+		if err != nil {
+			log.Error(fmt.Errorf("error loading firm membership: %s. Exiting", err), nil)
+			return
+		}
+		log.Info("firm membership loaded", log.Data{"file": cfg.FirmMembershipFile})
+	} else {
+		log.Info("no firm membership configured - cases will only be accessible to the user that created them")
+	}
+
+	// Create the checks of upstream APIs reported by the readiness endpoint, if any are configured
+	upstreamChecks, err := service.NewUpstreamChecks(cfg.ReadinessUpstreamChecks)
+	if err != nil {
+		log.Error(fmt.Errorf("error configuring readiness checks: %s. Exiting", err), nil)
+		return
+	}
+
+	handlers.Register(mainRouter, svc, clients, helperSvc, practitionerRegister, firms, upstreamChecks)
+
+	// Deliver the domain events written to the outbox to downstream systems, if a sink is configured
+	outboxSink, err := outbox.NewSink(cfg)
+	if err != nil {
+		log.Error(fmt.Errorf("error configuring outbox sink: %s. Exiting", err), nil)
+		return
+	}
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayStopped := make(chan struct{})
+	if outboxSink != nil {
+		relay := outbox.NewRelay(svc, outboxSink, time.Duration(cfg.OutboxRelayInterval)*time.Second, cfg.OutboxRelayBatchSize,
+			time.Duration(cfg.OutboxRelayLease)*time.Second, cfg.OutboxRelayMaxAttempts)
+		go func() {
+			relay.Run(relayCtx)
+			close(relayStopped)
+		}()
+		log.Info("outbox relay started", log.Data{"sink": cfg.OutboxSink})
+	} else {
+		close(relayStopped)
+		log.Info("no outbox sink configured - domain events will be kept in the outbox until one is")
+	}
 
 	log.Info("Starting " + namespace)
 
@@ -76,4 +147,20 @@ func main() {
 	} else {
 		log.Info("server shutdown gracefully")
 	}
+
+	// stop delivering domain events before disconnecting, leaving any that are undelivered in the outbox
+	stopRelay()
+	<-relayStopped
+
+	// close the database once in-flight requests have finished
+	err = svc.Close(ctx)
+	if err != nil {
+		log.Error(err)
+	}
+
+	// flush any spans that have not been exported yet
+	err = shutdownTracing(ctx)
+	if err != nil {
+		log.Error(fmt.Errorf("failed to shutdown tracing: [%v]", err))
+	}
 }
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var caseValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "case_validation_failures_total",
+	Help:      "Number of times an insolvency case failed a validation rule that it passed when its validation status was last checked, by rule",
+}, []string{"rule"})
+
+// CaseValidated counts the rules that an insolvency case failed when its validation status was checked, other than
+// those in previousRules that it failed at the check before. The failures of the previous check are stored with
+// the case, so clients polling the status on any instance do not inflate the counts, and the counts from every
+// instance can be summed
+func CaseValidated(previousRules []string, failedRules []string) {
+	previous := make(map[string]bool, len(previousRules))
+	for _, rule := range previousRules {
+		previous[rule] = true
+	}
+
+	for _, rule := range failedRules {
+		if previous[rule] {
+			continue
+		}
+		// A rule is counted once, however many validation errors the case has for it
+		previous[rule] = true
+		caseValidationFailures.WithLabelValues(rule).Inc()
+	}
+}
@@ -0,0 +1,141 @@
+// Package metrics defines the prometheus metrics recorded by the service and the handler that exposes them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/httpstatus"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "insolvency_api"
+
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+
+	// unnamedRoute labels requests for routes registered without a name
+	unnamedRoute = "unnamed"
+)
+
+// Results of a lookup in the EFS allow list cache. A stale hit is a miss that is answered with an expired result
+// because the EFS api returned an error, so is counted as well as the miss
+const (
+	EfsAllowListCacheHit      = "hit"
+	EfsAllowListCacheMiss     = "miss"
+	EfsAllowListCacheStaleHit = "stale_hit"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, by route name, method and response status code",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by route name and method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	daoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dao_operation_duration_seconds",
+		Help:      "Time taken by DAO operations, by operation and outcome",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	upstreamRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Number of calls to upstream APIs, by upstream, operation and outcome",
+	}, []string{"upstream", "operation", "outcome"})
+
+	upstreamRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Time taken by calls to upstream APIs, by upstream and operation",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"upstream", "operation"})
+
+	validationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validation_failures_total",
+		Help:      "Number of validation failures, by rule",
+	}, []string{"rule"})
+
+	efsAllowListCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "efs_allow_list_cache_lookups_total",
+		Help:      "Number of lookups in the EFS allow list cache, by result: hit, miss or stale_hit",
+	}, []string{"result"})
+
+	efsAllowListCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "efs_allow_list_cache_evictions_total",
+		Help:      "Number of results evicted from the full EFS allow list cache",
+	})
+)
+
+// Handler returns the handler that exposes the metrics in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HTTPMiddleware records the count and duration of requests, labelled with the name of the matched route
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := httpstatus.NewRecorder(w)
+
+		next.ServeHTTP(recorder, req)
+
+		route := unnamedRoute
+		if currentRoute := mux.CurrentRoute(req); currentRoute != nil && currentRoute.GetName() != "" {
+			route = currentRoute.GetName()
+		}
+
+		httpRequests.WithLabelValues(route, req.Method, strconv.Itoa(recorder.Status)).Inc()
+		httpRequestDuration.WithLabelValues(route, req.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveDAOOperation records the duration and outcome of a DAO operation that started at the given time
+func ObserveDAOOperation(operation string, start time.Time, err error) {
+	daoOperationDuration.WithLabelValues(operation, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveUpstreamCall records the duration and outcome of a call to an upstream API that started at the given time
+func ObserveUpstreamCall(upstream, operation string, start time.Time, err error) {
+	upstreamRequests.WithLabelValues(upstream, operation, outcome(err)).Inc()
+	upstreamRequestDuration.WithLabelValues(upstream, operation).Observe(time.Since(start).Seconds())
+}
+
+// ValidationFailed counts a failure of the named validation rule
+func ValidationFailed(rule string) {
+	validationFailures.WithLabelValues(rule).Inc()
+}
+
+// EfsAllowListCacheLookup counts a lookup in the EFS allow list cache with the given result
+func EfsAllowListCacheLookup(result string) {
+	efsAllowListCacheLookups.WithLabelValues(result).Inc()
+}
+
+// EfsAllowListCacheEvicted counts a result evicted from the EFS allow list cache to make room for another
+func EfsAllowListCacheEvicted() {
+	efsAllowListCacheEvictions.Inc()
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeSuccess
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitHTTPMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/cases/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet).Name("getCase")
+	router.HandleFunc("/unnamed", func(w http.ResponseWriter, _ *http.Request) {}).Methods(http.MethodGet)
+	router.Use(HTTPMiddleware)
+
+	Convey("Requests are counted by route name rather than path", t, func() {
+		before := testutil.ToFloat64(httpRequests.WithLabelValues("getCase", http.MethodGet, "404"))
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cases/1", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/cases/2", nil))
+
+		So(testutil.ToFloat64(httpRequests.WithLabelValues("getCase", http.MethodGet, "404")), ShouldEqual, before+2)
+	})
+
+	Convey("Requests to routes without a name are counted together", t, func() {
+		before := testutil.ToFloat64(httpRequests.WithLabelValues(unnamedRoute, http.MethodGet, "200"))
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unnamed", nil))
+
+		So(testutil.ToFloat64(httpRequests.WithLabelValues(unnamedRoute, http.MethodGet, "200")), ShouldEqual, before+1)
+	})
+}
+
+func TestUnitObserveUpstreamCall(t *testing.T) {
+	Convey("Upstream calls are counted by outcome", t, func() {
+		successes := testutil.ToFloat64(upstreamRequests.WithLabelValues("transaction", "Get", outcomeSuccess))
+		errs := testutil.ToFloat64(upstreamRequests.WithLabelValues("transaction", "Get", outcomeError))
+
+		ObserveUpstreamCall("transaction", "Get", time.Now(), nil)
+		ObserveUpstreamCall("transaction", "Get", time.Now(), errors.New("error communicating with the transaction api"))
+
+		So(testutil.ToFloat64(upstreamRequests.WithLabelValues("transaction", "Get", outcomeSuccess)), ShouldEqual, successes+1)
+		So(testutil.ToFloat64(upstreamRequests.WithLabelValues("transaction", "Get", outcomeError)), ShouldEqual, errs+1)
+	})
+}
+
+func TestUnitValidationFailed(t *testing.T) {
+	Convey("Validation failures are counted by rule", t, func() {
+		before := testutil.ToFloat64(validationFailures.WithLabelValues("telephone_number_length"))
+
+		ValidationFailed("telephone_number_length")
+
+		So(testutil.ToFloat64(validationFailures.WithLabelValues("telephone_number_length")), ShouldEqual, before+1)
+	})
+}
+
+func TestUnitCaseValidated(t *testing.T) {
+	Convey("Rules are counted when a case starts failing them", t, func() {
+		resolution := testutil.ToFloat64(caseValidationFailures.WithLabelValues("no resolution"))
+		practitioner := testutil.ToFloat64(caseValidationFailures.WithLabelValues("practitioner"))
+
+		CaseValidated(nil, []string{"no resolution", "practitioner", "practitioner"})
+
+		So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("no resolution")), ShouldEqual, resolution+1)
+		So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("practitioner")), ShouldEqual, practitioner+1)
+
+		Convey("but not again when the case failed them at the check before", func() {
+			CaseValidated([]string{"no resolution", "practitioner"}, []string{"practitioner"})
+
+			So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("no resolution")), ShouldEqual, resolution+1)
+			So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("practitioner")), ShouldEqual, practitioner+1)
+		})
+
+		Convey("and again once the case has passed them in between", func() {
+			CaseValidated([]string{"practitioner"}, []string{"no resolution"})
+
+			So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("no resolution")), ShouldEqual, resolution+2)
+			So(testutil.ToFloat64(caseValidationFailures.WithLabelValues("practitioner")), ShouldEqual, practitioner+1)
+		})
+	})
+}
+
+func TestUnitHandler(t *testing.T) {
+	Convey("Metrics are exposed in the prometheus text format", t, func() {
+		ObserveDAOOperation("GetInsolvencyResource", time.Now(), nil)
+
+		res := httptest.NewRecorder()
+		Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/insolvency-api/metrics", nil))
+
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(strings.Contains(res.Body.String(), `insolvency_api_dao_operation_duration_seconds_count{operation="GetInsolvencyResource",outcome="success"}`), ShouldBeTrue)
+	})
+}
+
+func TestUnitEfsAllowListCacheLookup(t *testing.T) {
+	Convey("EFS allow list cache lookups are counted by result", t, func() {
+		hits := testutil.ToFloat64(efsAllowListCacheLookups.WithLabelValues(EfsAllowListCacheHit))
+		staleHits := testutil.ToFloat64(efsAllowListCacheLookups.WithLabelValues(EfsAllowListCacheStaleHit))
+		evictions := testutil.ToFloat64(efsAllowListCacheEvictions)
+
+		EfsAllowListCacheLookup(EfsAllowListCacheHit)
+		EfsAllowListCacheLookup(EfsAllowListCacheStaleHit)
+		EfsAllowListCacheEvicted()
+
+		So(testutil.ToFloat64(efsAllowListCacheLookups.WithLabelValues(EfsAllowListCacheHit)), ShouldEqual, hits+1)
+		So(testutil.ToFloat64(efsAllowListCacheLookups.WithLabelValues(EfsAllowListCacheStaleHit)), ShouldEqual, staleHits+1)
+		So(testutil.ToFloat64(efsAllowListCacheEvictions), ShouldEqual, evictions+1)
+	})
+}
@@ -64,15 +64,15 @@ func (mr *MockHelperServiceMockRecorder) HandleTransactionIdExistsValidation(w,
 }
 
 // HandleTransactionNotClosedValidation mocks base method
-func (m *MockHelperService) HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, httpStatus int, err error) bool {
-	ret := m.ctrl.Call(m, "HandleTransactionNotClosedValidation", w, req, transactionID, isTransactionClosed, err, httpStatus)
+func (m *MockHelperService) HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, err error) bool {
+	ret := m.ctrl.Call(m, "HandleTransactionNotClosedValidation", w, req, transactionID, isTransactionClosed, err)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
 // HandleTransactionNotClosedValidation indicates an expected call of HandleTransactionNotClosedValidation
-func (mr *MockHelperServiceMockRecorder) HandleTransactionNotClosedValidation(w, req, transactionID, isTransactionClosed, httpStatus, err interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTransactionNotClosedValidation", reflect.TypeOf((*MockHelperService)(nil).HandleTransactionNotClosedValidation), w, req, transactionID, isTransactionClosed, httpStatus, err)
+func (mr *MockHelperServiceMockRecorder) HandleTransactionNotClosedValidation(w, req, transactionID, isTransactionClosed, err interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleTransactionNotClosedValidation", reflect.TypeOf((*MockHelperService)(nil).HandleTransactionNotClosedValidation), w, req, transactionID, isTransactionClosed, err)
 }
 
 // HandleBodyDecodedValidation indicates an expected call of HandleBodyDecodedValidation
@@ -105,7 +105,7 @@ func (mr *MockHelperServiceMockRecorder) HandleMandatoryFieldValidation(http, re
 }
 
 // HandleMandatoryFieldValidation mocks base method
-func (m *MockHelperService) HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, errs string) bool {
+func (m *MockHelperService) HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, errs []models.FieldError) bool {
 	ret := m.ctrl.Call(m, "HandleMandatoryFieldValidation", w, req, errs)
 	ret0, _ := ret[0].(bool)
 	return ret0
@@ -136,13 +136,13 @@ func (mr *MockHelperServiceMockRecorder) HandleEtagGenerationValidation(err inte
 }
 
 // HandleCreateResourceValidation indicates an expected call of HandleCreateResourceValidation
-func (mr *MockHelperServiceMockRecorder) HandleCreateResourceValidation(w, req, httpStatus, err interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleCreateResourceValidation", reflect.TypeOf((*MockHelperService)(nil).HandleCreateResourceValidation), w, req, httpStatus, err)
+func (mr *MockHelperServiceMockRecorder) HandleCreateResourceValidation(w, req, err interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleCreateResourceValidation", reflect.TypeOf((*MockHelperService)(nil).HandleCreateResourceValidation), w, req, err)
 }
 
 // HandleCreateResourceValidation mocks base method
-func (m *MockHelperService) HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, httpStatus int, err error) bool {
-	ret := m.ctrl.Call(m, "HandleCreateResourceValidation", w, req, err, httpStatus)
+func (m *MockHelperService) HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, err error) bool {
+	ret := m.ctrl.Call(m, "HandleCreateResourceValidation", w, req, err)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
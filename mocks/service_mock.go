@@ -4,9 +4,11 @@
 package mocks
 
 import (
+	context "context"
 	models "github.com/companieshouse/insolvency-api/models"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockService is a mock of Service interface
@@ -33,287 +35,448 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // CreateInsolvencyResource mocks base method
-func (m *MockService) CreateInsolvencyResource(dao *models.InsolvencyResourceDao) (error, int) {
-	ret := m.ctrl.Call(m, "CreateInsolvencyResource", dao)
+func (m *MockService) CreateInsolvencyResource(ctx context.Context, dao *models.InsolvencyResourceDao) error {
+	ret := m.ctrl.Call(m, "CreateInsolvencyResource", ctx, dao)
 	ret0, _ := ret[0].(error)
-	ret1, _ := ret[1].(int)
-	return ret0, ret1
+	return ret0
 }
 
 // CreateInsolvencyResource indicates an expected call of CreateInsolvencyResource
-func (mr *MockServiceMockRecorder) CreateInsolvencyResource(dao interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInsolvencyResource", reflect.TypeOf((*MockService)(nil).CreateInsolvencyResource), dao)
+func (mr *MockServiceMockRecorder) CreateInsolvencyResource(ctx, dao interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInsolvencyResource", reflect.TypeOf((*MockService)(nil).CreateInsolvencyResource), ctx, dao)
 }
 
 // GetInsolvencyResource mocks base method
-func (m *MockService) GetInsolvencyResource(transactionID string) (models.InsolvencyResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetInsolvencyResource", transactionID)
+func (m *MockService) GetInsolvencyResource(ctx context.Context, transactionID string) (models.InsolvencyResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetInsolvencyResource", ctx, transactionID)
 	ret0, _ := ret[0].(models.InsolvencyResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInsolvencyResource indicates an expected call of GetInsolvencyResource
-func (mr *MockServiceMockRecorder) GetInsolvencyResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInsolvencyResource", reflect.TypeOf((*MockService)(nil).GetInsolvencyResource), transactionID)
+func (mr *MockServiceMockRecorder) GetInsolvencyResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInsolvencyResource", reflect.TypeOf((*MockService)(nil).GetInsolvencyResource), ctx, transactionID)
 }
 
 // CreatePractitionersResource mocks base method
-func (m *MockService) CreatePractitionersResource(dao *models.PractitionerResourceDao, transactionID string) (error, int) {
-	ret := m.ctrl.Call(m, "CreatePractitionersResource", dao, transactionID)
+func (m *MockService) CreatePractitionersResource(ctx context.Context, dao *models.PractitionerResourceDao, transactionID string) error {
+	ret := m.ctrl.Call(m, "CreatePractitionersResource", ctx, dao, transactionID)
 	ret0, _ := ret[0].(error)
-	ret1, _ := ret[1].(int)
-	return ret0, ret1
+	return ret0
 }
 
 // CreatePractitionersResource indicates an expected call of CreatePractitionersResource
-func (mr *MockServiceMockRecorder) CreatePractitionersResource(dao, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePractitionersResource", reflect.TypeOf((*MockService)(nil).CreatePractitionersResource), dao, transactionID)
+func (mr *MockServiceMockRecorder) CreatePractitionersResource(ctx, dao, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePractitionersResource", reflect.TypeOf((*MockService)(nil).CreatePractitionersResource), ctx, dao, transactionID)
 }
 
 // GetPractitionerResources mocks base method
-func (m *MockService) GetPractitionerResources(transactionID string) ([]models.PractitionerResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetPractitionerResources", transactionID)
+func (m *MockService) GetPractitionerResources(ctx context.Context, transactionID string) ([]models.PractitionerResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetPractitionerResources", ctx, transactionID)
 	ret0, _ := ret[0].([]models.PractitionerResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPractitionerResources indicates an expected call of GetPractitionerResources
-func (mr *MockServiceMockRecorder) GetPractitionerResources(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPractitionerResources", reflect.TypeOf((*MockService)(nil).GetPractitionerResources), transactionID)
+func (mr *MockServiceMockRecorder) GetPractitionerResources(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPractitionerResources", reflect.TypeOf((*MockService)(nil).GetPractitionerResources), ctx, transactionID)
 }
 
 // GetPractitionerResource mocks base method
-func (m *MockService) GetPractitionerResource(practitionerID, transactionID string) (models.PractitionerResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetPractitionerResource", practitionerID, transactionID)
+func (m *MockService) GetPractitionerResource(ctx context.Context, practitionerID, transactionID string) (models.PractitionerResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetPractitionerResource", ctx, practitionerID, transactionID)
 	ret0, _ := ret[0].(models.PractitionerResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPractitionerResource indicates an expected call of GetPractitionerResource
-func (mr *MockServiceMockRecorder) GetPractitionerResource(practitionerID, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPractitionerResource", reflect.TypeOf((*MockService)(nil).GetPractitionerResource), practitionerID, transactionID)
+func (mr *MockServiceMockRecorder) GetPractitionerResource(ctx, practitionerID, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPractitionerResource", reflect.TypeOf((*MockService)(nil).GetPractitionerResource), ctx, practitionerID, transactionID)
 }
 
 // DeletePractitioner mocks base method
-func (m *MockService) DeletePractitioner(practitionerID, transactionID string) (error, int) {
-	ret := m.ctrl.Call(m, "DeletePractitioner", practitionerID, transactionID)
+func (m *MockService) DeletePractitioner(ctx context.Context, practitionerID, transactionID string) error {
+	ret := m.ctrl.Call(m, "DeletePractitioner", ctx, practitionerID, transactionID)
 	ret0, _ := ret[0].(error)
-	ret1, _ := ret[1].(int)
-	return ret0, ret1
+	return ret0
 }
 
 // DeletePractitioner indicates an expected call of DeletePractitioner
-func (mr *MockServiceMockRecorder) DeletePractitioner(practitionerID, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePractitioner", reflect.TypeOf((*MockService)(nil).DeletePractitioner), practitionerID, transactionID)
+func (mr *MockServiceMockRecorder) DeletePractitioner(ctx, practitionerID, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePractitioner", reflect.TypeOf((*MockService)(nil).DeletePractitioner), ctx, practitionerID, transactionID)
 }
 
 // AppointPractitioner mocks base method
-func (m *MockService) AppointPractitioner(dao *models.AppointmentResourceDao, transactionID, practitionerID string) (error, int) {
-	ret := m.ctrl.Call(m, "AppointPractitioner", dao, transactionID, practitionerID)
+func (m *MockService) AppointPractitioner(ctx context.Context, dao *models.AppointmentResourceDao, transactionID, practitionerID string) error {
+	ret := m.ctrl.Call(m, "AppointPractitioner", ctx, dao, transactionID, practitionerID)
 	ret0, _ := ret[0].(error)
-	ret1, _ := ret[1].(int)
-	return ret0, ret1
+	return ret0
 }
 
 // AppointPractitioner indicates an expected call of AppointPractitioner
-func (mr *MockServiceMockRecorder) AppointPractitioner(dao, transactionID, practitionerID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppointPractitioner", reflect.TypeOf((*MockService)(nil).AppointPractitioner), dao, transactionID, practitionerID)
+func (mr *MockServiceMockRecorder) AppointPractitioner(ctx, dao, transactionID, practitionerID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppointPractitioner", reflect.TypeOf((*MockService)(nil).AppointPractitioner), ctx, dao, transactionID, practitionerID)
 }
 
 // DeletePractitionerAppointment mocks base method
-func (m *MockService) DeletePractitionerAppointment(transactionID, practitionerID string) (error, int) {
-	ret := m.ctrl.Call(m, "DeletePractitionerAppointment", transactionID, practitionerID)
+func (m *MockService) DeletePractitionerAppointment(ctx context.Context, transactionID, practitionerID string) error {
+	ret := m.ctrl.Call(m, "DeletePractitionerAppointment", ctx, transactionID, practitionerID)
 	ret0, _ := ret[0].(error)
-	ret1, _ := ret[1].(int)
-	return ret0, ret1
+	return ret0
 }
 
 // DeletePractitionerAppointment indicates an expected call of DeletePractitionerAppointment
-func (mr *MockServiceMockRecorder) DeletePractitionerAppointment(transactionID, practitionerID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePractitionerAppointment", reflect.TypeOf((*MockService)(nil).DeletePractitionerAppointment), transactionID, practitionerID)
+func (mr *MockServiceMockRecorder) DeletePractitionerAppointment(ctx, transactionID, practitionerID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePractitionerAppointment", reflect.TypeOf((*MockService)(nil).DeletePractitionerAppointment), ctx, transactionID, practitionerID)
 }
 
 // AddAttachmentToInsolvencyResource mocks base method
-func (m *MockService) AddAttachmentToInsolvencyResource(transactionID, fileID, attachmentType string) (*models.AttachmentResourceDao, error) {
-	ret := m.ctrl.Call(m, "AddAttachmentToInsolvencyResource", transactionID, fileID, attachmentType)
+func (m *MockService) AddAttachmentToInsolvencyResource(ctx context.Context, transactionID, fileID, attachmentType string) (*models.AttachmentResourceDao, error) {
+	ret := m.ctrl.Call(m, "AddAttachmentToInsolvencyResource", ctx, transactionID, fileID, attachmentType)
 	ret0, _ := ret[0].(*models.AttachmentResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddAttachmentToInsolvencyResource indicates an expected call of AddAttachmentToInsolvencyResource
-func (mr *MockServiceMockRecorder) AddAttachmentToInsolvencyResource(transactionID, fileID, attachmentType interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttachmentToInsolvencyResource", reflect.TypeOf((*MockService)(nil).AddAttachmentToInsolvencyResource), transactionID, fileID, attachmentType)
+func (mr *MockServiceMockRecorder) AddAttachmentToInsolvencyResource(ctx, transactionID, fileID, attachmentType interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttachmentToInsolvencyResource", reflect.TypeOf((*MockService)(nil).AddAttachmentToInsolvencyResource), ctx, transactionID, fileID, attachmentType)
 }
 
 // GetAttachmentFromInsolvencyResource mocks base method
-func (m *MockService) GetAttachmentFromInsolvencyResource(transactionID, attachmentID string) (models.AttachmentResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetAttachmentFromInsolvencyResource", transactionID, attachmentID)
+func (m *MockService) GetAttachmentFromInsolvencyResource(ctx context.Context, transactionID, attachmentID string) (models.AttachmentResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetAttachmentFromInsolvencyResource", ctx, transactionID, attachmentID)
 	ret0, _ := ret[0].(models.AttachmentResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAttachmentFromInsolvencyResource indicates an expected call of GetAttachmentFromInsolvencyResource
-func (mr *MockServiceMockRecorder) GetAttachmentFromInsolvencyResource(transactionID, attachmentID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentFromInsolvencyResource", reflect.TypeOf((*MockService)(nil).GetAttachmentFromInsolvencyResource), transactionID, attachmentID)
+func (mr *MockServiceMockRecorder) GetAttachmentFromInsolvencyResource(ctx, transactionID, attachmentID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentFromInsolvencyResource", reflect.TypeOf((*MockService)(nil).GetAttachmentFromInsolvencyResource), ctx, transactionID, attachmentID)
 }
 
 // GetAttachmentResources mocks base method
-func (m *MockService) GetAttachmentResources(transactionID string) ([]models.AttachmentResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetAttachmentResources", transactionID)
+func (m *MockService) GetAttachmentResources(ctx context.Context, transactionID string) ([]models.AttachmentResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetAttachmentResources", ctx, transactionID)
 	ret0, _ := ret[0].([]models.AttachmentResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAttachmentResources indicates an expected call of GetAttachmentResources
-func (mr *MockServiceMockRecorder) GetAttachmentResources(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentResources", reflect.TypeOf((*MockService)(nil).GetAttachmentResources), transactionID)
+func (mr *MockServiceMockRecorder) GetAttachmentResources(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachmentResources", reflect.TypeOf((*MockService)(nil).GetAttachmentResources), ctx, transactionID)
 }
 
 // GetProgressReportResource mocks base method
-func (m *MockService) GetProgressReportResource(transactionID string) (*models.ProgressReportResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetProgressReportResource", transactionID)
+func (m *MockService) GetProgressReportResource(ctx context.Context, transactionID string) (*models.ProgressReportResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetProgressReportResource", ctx, transactionID)
 	ret0, _ := ret[0].(*models.ProgressReportResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetProgressReportResource indicates an expected call of GetProgressReportResource
-func (mr *MockServiceMockRecorder) GetProgressReportResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgressReportResource", reflect.TypeOf((*MockService)(nil).GetProgressReportResource), transactionID)
+func (mr *MockServiceMockRecorder) GetProgressReportResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgressReportResource", reflect.TypeOf((*MockService)(nil).GetProgressReportResource), ctx, transactionID)
 }
 
 // DeleteAttachmentResource mocks base method
-func (m *MockService) DeleteAttachmentResource(transactionID, attachmentID string) (int, error) {
-	ret := m.ctrl.Call(m, "DeleteAttachmentResource", transactionID, attachmentID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) DeleteAttachmentResource(ctx context.Context, transactionID, attachmentID string) error {
+	ret := m.ctrl.Call(m, "DeleteAttachmentResource", ctx, transactionID, attachmentID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // DeleteAttachmentResource indicates an expected call of DeleteAttachmentResource
-func (mr *MockServiceMockRecorder) DeleteAttachmentResource(transactionID, attachmentID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachmentResource", reflect.TypeOf((*MockService)(nil).DeleteAttachmentResource), transactionID, attachmentID)
+func (mr *MockServiceMockRecorder) DeleteAttachmentResource(ctx, transactionID, attachmentID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachmentResource", reflect.TypeOf((*MockService)(nil).DeleteAttachmentResource), ctx, transactionID, attachmentID)
 }
 
 // UpdateAttachmentStatus mocks base method
-func (m *MockService) UpdateAttachmentStatus(transactionID, attachmentID, avStatus string) (int, error) {
-	ret := m.ctrl.Call(m, "UpdateAttachmentStatus", transactionID, attachmentID, avStatus)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) UpdateAttachmentStatus(ctx context.Context, transactionID, attachmentID, avStatus string) error {
+	ret := m.ctrl.Call(m, "UpdateAttachmentStatus", ctx, transactionID, attachmentID, avStatus)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // UpdateAttachmentStatus indicates an expected call of UpdateAttachmentStatus
-func (mr *MockServiceMockRecorder) UpdateAttachmentStatus(transactionID, attachmentID, avStatus interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAttachmentStatus", reflect.TypeOf((*MockService)(nil).UpdateAttachmentStatus), transactionID, attachmentID, avStatus)
+func (mr *MockServiceMockRecorder) UpdateAttachmentStatus(ctx, transactionID, attachmentID, avStatus interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAttachmentStatus", reflect.TypeOf((*MockService)(nil).UpdateAttachmentStatus), ctx, transactionID, attachmentID, avStatus)
 }
 
 // CreateStatementOfAffairsResource mocks base method
-func (m *MockService) CreateStatementOfAffairsResource(dao *models.StatementOfAffairsResourceDao, transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "CreateStatementOfAffairsResource", dao, transactionID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) CreateStatementOfAffairsResource(ctx context.Context, dao *models.StatementOfAffairsResourceDao, transactionID string, ifMatch string) error {
+	ret := m.ctrl.Call(m, "CreateStatementOfAffairsResource", ctx, dao, transactionID, ifMatch)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // CreateProgressReportResource mocks base method
-func (m *MockService) CreateProgressReportResource(dao *models.ProgressReportResourceDao, transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "CreateProgressReportResource", dao, transactionID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) CreateProgressReportResource(ctx context.Context, dao *models.ProgressReportResourceDao, transactionID string, ifMatch string) error {
+	ret := m.ctrl.Call(m, "CreateProgressReportResource", ctx, dao, transactionID, ifMatch)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // DeleteProgressReportResource
-func (m *MockService) DeleteProgressReportResource(transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "DeleteProgressReportResource", transactionID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) DeleteProgressReportResource(ctx context.Context, transactionID string) error {
+	ret := m.ctrl.Call(m, "DeleteProgressReportResource", ctx, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // DeleteProgressReportResource indicates an expected call of DeleteProgressReportResource
-func (mr *MockServiceMockRecorder) DeleteProgressReportResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProgressReportResource", reflect.TypeOf((*MockService)(nil).DeleteProgressReportResource), transactionID)
+func (mr *MockServiceMockRecorder) DeleteProgressReportResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProgressReportResource", reflect.TypeOf((*MockService)(nil).DeleteProgressReportResource), ctx, transactionID)
 }
 
 // CreateStatementOfAffairsResource indicates an expected call of CreateStatementOfAffairsResource
-func (mr *MockServiceMockRecorder) CreateStatementOfAffairsResource(dao, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).CreateStatementOfAffairsResource), dao, transactionID)
+func (mr *MockServiceMockRecorder) CreateStatementOfAffairsResource(ctx, dao, transactionID, ifMatch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).CreateStatementOfAffairsResource), ctx, dao, transactionID, ifMatch)
 }
 
 // DeleteStatementOfAffairsResource mocks base method
-func (m *MockService) DeleteStatementOfAffairsResource(transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "DeleteStatementOfAffairsResource", transactionID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) DeleteStatementOfAffairsResource(ctx context.Context, transactionID string) error {
+	ret := m.ctrl.Call(m, "DeleteStatementOfAffairsResource", ctx, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // DeleteStatementOfAffairsResource indicates an expected call of DeleteStatementOfAffairsResource
-func (mr *MockServiceMockRecorder) DeleteStatementOfAffairsResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).DeleteStatementOfAffairsResource), transactionID)
+func (mr *MockServiceMockRecorder) DeleteStatementOfAffairsResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).DeleteStatementOfAffairsResource), ctx, transactionID)
 }
 
 // CreateProgressReportResource indicates an expected call of CreateProgressReportResource
-func (mr *MockServiceMockRecorder) CreateProgressReportResource(dao, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProgressReportResource", reflect.TypeOf((*MockService)(nil).CreateProgressReportResource), dao, transactionID)
+func (mr *MockServiceMockRecorder) CreateProgressReportResource(ctx, dao, transactionID, ifMatch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProgressReportResource", reflect.TypeOf((*MockService)(nil).CreateProgressReportResource), ctx, dao, transactionID, ifMatch)
 }
 
 // CreateResolutionResource mocks base method
-func (m *MockService) CreateResolutionResource(dao *models.ResolutionResourceDao, transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "CreateResolutionResource", dao, transactionID)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+func (m *MockService) CreateResolutionResource(ctx context.Context, dao *models.ResolutionResourceDao, transactionID string, ifMatch string) error {
+	ret := m.ctrl.Call(m, "CreateResolutionResource", ctx, dao, transactionID, ifMatch)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // CreateResolutionResource indicates an expected call of CreateResolutionResource
-func (mr *MockServiceMockRecorder) CreateResolutionResource(dao, transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResolutionResource", reflect.TypeOf((*MockService)(nil).CreateResolutionResource), dao, transactionID)
+func (mr *MockServiceMockRecorder) CreateResolutionResource(ctx, dao, transactionID, ifMatch interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResolutionResource", reflect.TypeOf((*MockService)(nil).CreateResolutionResource), ctx, dao, transactionID, ifMatch)
 }
 
 // GetStatementOfAffairsResource mocks base method
-func (m *MockService) GetStatementOfAffairsResource(transactionID string) (models.StatementOfAffairsResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetStatementOfAffairsResource", transactionID)
+func (m *MockService) GetStatementOfAffairsResource(ctx context.Context, transactionID string) (models.StatementOfAffairsResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetStatementOfAffairsResource", ctx, transactionID)
 	ret0, _ := ret[0].(models.StatementOfAffairsResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetStatementOfAffairsResource indicates an expected call of GetStatementOfAffairsResource
-func (mr *MockServiceMockRecorder) GetStatementOfAffairsResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).GetStatementOfAffairsResource), transactionID)
+func (mr *MockServiceMockRecorder) GetStatementOfAffairsResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatementOfAffairsResource", reflect.TypeOf((*MockService)(nil).GetStatementOfAffairsResource), ctx, transactionID)
 }
 
 // GetResolutionResource mocks base method
-func (m *MockService) GetResolutionResource(transactionID string) (models.ResolutionResourceDao, error) {
-	ret := m.ctrl.Call(m, "GetResolutionResource", transactionID)
+func (m *MockService) GetResolutionResource(ctx context.Context, transactionID string) (models.ResolutionResourceDao, error) {
+	ret := m.ctrl.Call(m, "GetResolutionResource", ctx, transactionID)
 	ret0, _ := ret[0].(models.ResolutionResourceDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetResolutionResource indicates an expected call of GetResolutionResource
-func (mr *MockServiceMockRecorder) GetResolutionResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResolutionResource", reflect.TypeOf((*MockService)(nil).GetResolutionResource), transactionID)
+func (mr *MockServiceMockRecorder) GetResolutionResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResolutionResource", reflect.TypeOf((*MockService)(nil).GetResolutionResource), ctx, transactionID)
 }
 
 // DeleteResolutionResource mocks base method
-func (m *MockService) DeleteResolutionResource(transactionID string) (int, error) {
-	ret := m.ctrl.Call(m, "DeleteResolutionResource", transactionID)
-	ret0, _ := ret[0].(int)
+func (m *MockService) DeleteResolutionResource(ctx context.Context, transactionID string) error {
+	ret := m.ctrl.Call(m, "DeleteResolutionResource", ctx, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteResolutionResource indicates an expected call of DeleteResolutionResource
+func (mr *MockServiceMockRecorder) DeleteResolutionResource(ctx, transactionID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResolutionResource", reflect.TypeOf((*MockService)(nil).DeleteResolutionResource), ctx, transactionID)
+}
+
+// ReserveIdempotencyKey mocks base method
+func (m *MockService) ReserveIdempotencyKey(ctx context.Context, dao *models.IdempotencyKeyDao) (*models.IdempotencyKeyDao, error) {
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", ctx, dao)
+	ret0, _ := ret[0].(*models.IdempotencyKeyDao)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeleteResolutionResource indicates an expected call of DeleteResolutionResource
-func (mr *MockServiceMockRecorder) DeleteResolutionResource(transactionID interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteResolutionResource", reflect.TypeOf((*MockService)(nil).DeleteResolutionResource), transactionID)
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey
+func (mr *MockServiceMockRecorder) ReserveIdempotencyKey(ctx, dao interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockService)(nil).ReserveIdempotencyKey), ctx, dao)
+}
+
+// CompleteIdempotencyKey mocks base method
+func (m *MockService) CompleteIdempotencyKey(ctx context.Context, key, token string, response *models.IdempotentResponseDao, expiresAt time.Time) error {
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", ctx, key, token, response, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey
+func (mr *MockServiceMockRecorder) CompleteIdempotencyKey(ctx, key, token, response, expiresAt interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockService)(nil).CompleteIdempotencyKey), ctx, key, token, response, expiresAt)
+}
+
+// ReleaseIdempotencyKey mocks base method
+func (m *MockService) ReleaseIdempotencyKey(ctx context.Context, key, token string) error {
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", ctx, key, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey
+func (mr *MockServiceMockRecorder) ReleaseIdempotencyKey(ctx, key, token interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockService)(nil).ReleaseIdempotencyKey), ctx, key, token)
+}
+
+// CreateAuditEvent mocks base method
+func (m *MockService) CreateAuditEvent(ctx context.Context, dao *models.AuditEventDao) error {
+	ret := m.ctrl.Call(m, "CreateAuditEvent", ctx, dao)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent
+func (mr *MockServiceMockRecorder) CreateAuditEvent(ctx, dao interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockService)(nil).CreateAuditEvent), ctx, dao)
+}
+
+// GetAuditEvents mocks base method
+func (m *MockService) GetAuditEvents(ctx context.Context, filter models.AuditEventFilter) ([]models.AuditEventDao, error) {
+	ret := m.ctrl.Call(m, "GetAuditEvents", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEventDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents
+func (mr *MockServiceMockRecorder) GetAuditEvents(ctx, filter interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockService)(nil).GetAuditEvents), ctx, filter)
+}
+
+// CreateOutboxEvent mocks base method
+func (m *MockService) CreateOutboxEvent(ctx context.Context, dao *models.OutboxEventDao) error {
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", ctx, dao)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent
+func (mr *MockServiceMockRecorder) CreateOutboxEvent(ctx, dao interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockService)(nil).CreateOutboxEvent), ctx, dao)
+}
+
+// GetOutboxEvents mocks base method
+func (m *MockService) GetOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEventDao, error) {
+	ret := m.ctrl.Call(m, "GetOutboxEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.OutboxEventDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEvents indicates an expected call of GetOutboxEvents
+func (mr *MockServiceMockRecorder) GetOutboxEvents(ctx, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEvents", reflect.TypeOf((*MockService)(nil).GetOutboxEvents), ctx, limit)
+}
+
+// SetValidationFailures mocks base method
+func (m *MockService) SetValidationFailures(ctx context.Context, transactionID string, failedRules []string) ([]string, error) {
+	ret := m.ctrl.Call(m, "SetValidationFailures", ctx, transactionID, failedRules)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetValidationFailures indicates an expected call of SetValidationFailures
+func (mr *MockServiceMockRecorder) SetValidationFailures(ctx, transactionID, failedRules interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetValidationFailures", reflect.TypeOf((*MockService)(nil).SetValidationFailures), ctx, transactionID, failedRules)
+}
+
+// ClaimOutboxEvents mocks base method
+func (m *MockService) ClaimOutboxEvents(ctx context.Context, claimant string, now time.Time, lease time.Duration, limit int) ([]models.OutboxEventDao, error) {
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", ctx, claimant, now, lease, limit)
+	ret0, _ := ret[0].([]models.OutboxEventDao)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents
+func (mr *MockServiceMockRecorder) ClaimOutboxEvents(ctx, claimant, now, lease, limit interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*MockService)(nil).ClaimOutboxEvents), ctx, claimant, now, lease, limit)
+}
+
+// ReleaseOutboxClaim mocks base method
+func (m *MockService) ReleaseOutboxClaim(ctx context.Context, transactionID string, claimant string) error {
+	ret := m.ctrl.Call(m, "ReleaseOutboxClaim", ctx, transactionID, claimant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseOutboxClaim indicates an expected call of ReleaseOutboxClaim
+func (mr *MockServiceMockRecorder) ReleaseOutboxClaim(ctx, transactionID, claimant interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseOutboxClaim", reflect.TypeOf((*MockService)(nil).ReleaseOutboxClaim), ctx, transactionID, claimant)
+}
+
+// FailOutboxEvent mocks base method
+func (m *MockService) FailOutboxEvent(ctx context.Context, transactionID string, eventID string, maxAttempts int) (bool, error) {
+	ret := m.ctrl.Call(m, "FailOutboxEvent", ctx, transactionID, eventID, maxAttempts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailOutboxEvent indicates an expected call of FailOutboxEvent
+func (mr *MockServiceMockRecorder) FailOutboxEvent(ctx, transactionID, eventID, maxAttempts interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailOutboxEvent", reflect.TypeOf((*MockService)(nil).FailOutboxEvent), ctx, transactionID, eventID, maxAttempts)
+}
+
+// DeleteOutboxEvent mocks base method
+func (m *MockService) DeleteOutboxEvent(ctx context.Context, transactionID, eventID string) error {
+	ret := m.ctrl.Call(m, "DeleteOutboxEvent", ctx, transactionID, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEvent indicates an expected call of DeleteOutboxEvent
+func (mr *MockServiceMockRecorder) DeleteOutboxEvent(ctx, transactionID, eventID interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEvent", reflect.TypeOf((*MockService)(nil).DeleteOutboxEvent), ctx, transactionID, eventID)
+}
+
+// Ping mocks base method
+func (m *MockService) Ping(ctx context.Context) error {
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping
+func (mr *MockServiceMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
+}
+
+// Close mocks base method
+func (m *MockService) Close(ctx context.Context) error {
+	ret := m.ctrl.Call(m, "Close", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockServiceMockRecorder) Close(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockService)(nil).Close), ctx)
 }
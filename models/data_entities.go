@@ -1,6 +1,10 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // InsolvencyResourceDao contains the meta-data for the insolvency resource in Mongo
 type InsolvencyResourceDao struct {
@@ -10,6 +14,8 @@ type InsolvencyResourceDao struct {
 	Kind          string                     `bson:"kind"`
 	Data          InsolvencyResourceDaoData  `bson:"data"`
 	Links         InsolvencyResourceLinksDao `bson:"links"`
+	CreatedBy     string                     `bson:"created_by,omitempty"`
+	FirmID        string                     `bson:"firm_id,omitempty"`
 }
 
 // InsolvencyResourceDaoData contains the data for the insolvency resource in Mongo
@@ -43,6 +49,7 @@ type PractitionerResourceDao struct {
 	Role            string                       `bson:"role"`
 	Links           PractitionerResourceLinksDao `bson:"links"`
 	Appointment     *AppointmentResourceDao      `bson:"appointment,omitempty"`
+	UserID          string                       `bson:"user_id,omitempty"`
 }
 
 // AppointmentResourceDao contains the appointment data for a practitioner
@@ -128,3 +135,72 @@ type ProgressReportResourceDao struct {
 type ProgressReportResourceLinksDao struct {
 	Self string `bson:"self,omitempty"`
 }
+
+// IdempotencyKeyDao contains a request made with an Idempotency-Key header and, once the request has been handled,
+// the response that is returned when it is replayed. Token identifies the request that reserved the key, so that a
+// request whose reservation has expired cannot complete or release the reservation of a retry
+type IdempotencyKeyDao struct {
+	Key         string                 `bson:"_id"`
+	Token       string                 `bson:"token"`
+	RequestHash string                 `bson:"request_hash"`
+	Response    *IdempotentResponseDao `bson:"response,omitempty"`
+	CreatedAt   time.Time              `bson:"created_at"`
+	ExpiresAt   time.Time              `bson:"expires_at"`
+}
+
+// IdempotentResponseDao contains the response stored for a request made with an Idempotency-Key header
+type IdempotentResponseDao struct {
+	StatusCode int                 `bson:"status_code"`
+	Header     map[string][]string `bson:"header"`
+	Body       []byte              `bson:"body"`
+}
+
+// AuditEventDao is an append-only record of a request that changed an insolvency case, holding who made the
+// request and the changes it made to the case
+type AuditEventDao struct {
+	ID            string           `bson:"_id"`
+	TransactionID string           `bson:"transaction_id"`
+	CompanyNumber string           `bson:"company_number,omitempty"`
+	UserID        string           `bson:"user_id"`
+	Email         string           `bson:"email"`
+	Route         string           `bson:"route"`
+	Method        string           `bson:"method"`
+	Path          string           `bson:"path"`
+	StatusCode    int              `bson:"status_code"`
+	CreatedAt     time.Time        `bson:"created_at"`
+	Changes       []AuditChangeDao `bson:"changes"`
+}
+
+// AuditChangeDao is a single field of an insolvency case changed by a request, with its JSON encoded value before
+// and after the request. A value is empty when the field did not exist
+type AuditChangeDao struct {
+	Path   string `bson:"path"`
+	Before string `bson:"before,omitempty"`
+	After  string `bson:"after,omitempty"`
+}
+
+// AuditEventFilter selects the audit events to retrieve. Empty fields match every event, and at most Limit events
+// are returned unless it is 0
+type AuditEventFilter struct {
+	TransactionID string
+	CompanyNumber string
+	UserID        string
+	Route         string
+	From          time.Time
+	To            time.Time
+	Limit         int
+}
+
+// OutboxEventDao is a domain event waiting to be delivered to downstream systems. Events are written in the same
+// update as the change to the case they describe, and removed once they have been delivered. The data of the
+// event is JSON encoded. Attempts counts the deliveries of the event that failed, and an event that has failed
+// too many times is parked, so that it is kept in the outbox but no longer delivered
+type OutboxEventDao struct {
+	ID            string    `bson:"id"`
+	Type          string    `bson:"type"`
+	TransactionID string    `bson:"transaction_id"`
+	CreatedAt     time.Time `bson:"created_at"`
+	Data          string    `bson:"data"`
+	Attempts      int       `bson:"attempts,omitempty"`
+	Parked        bool      `bson:"parked,omitempty"`
+}
@@ -60,3 +60,26 @@ type ProgressReport struct {
 	ToDate      string   `json:"to_date" validate:"required,datetime=2006-01-02"`
 	Attachments []string `json:"attachments" validate:"required"`
 }
+
+// CaseImportRequest is the model that should be sent to import a complete insolvency case in a single request.
+// Attachments must already have been uploaded to the File Transfer API, and are referred to by their file IDs
+type CaseImportRequest struct {
+	InsolvencyRequest
+	Practitioners      []CaseImportPractitioner `json:"practitioners"`
+	Attachments        []CaseImportAttachment   `json:"attachments"`
+	Resolution         *Resolution              `json:"resolution"`
+	StatementOfAffairs *StatementOfAffairs      `json:"statement_of_affairs"`
+	ProgressReport     *ProgressReport          `json:"progress_report"`
+}
+
+// CaseImportPractitioner is a practitioner on an imported case, with their appointment if they have been appointed
+type CaseImportPractitioner struct {
+	PractitionerRequest
+	Appointment *PractitionerAppointment `json:"appointment"`
+}
+
+// CaseImportAttachment is an attachment on an imported case that has already been uploaded to the File Transfer API
+type CaseImportAttachment struct {
+	ID             string `json:"id" validate:"required"`
+	AttachmentType string `json:"attachment_type" validate:"required"`
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // CreatedInsolvencyResource is the entity returned in a successful creation of an insolvency resource
 type CreatedInsolvencyResource struct {
 	CompanyNumber string                         `json:"company_number"`
@@ -184,3 +189,78 @@ type ResponseResource struct {
 func NewMessageResponse(message string) *ResponseResource {
 	return &ResponseResource{Message: message}
 }
+
+// ProblemResource is the object returned in an error case when the client accepts application/problem+json, in
+// the format described by RFC 7807
+type ProblemResource struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a validation failure against a single field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// CaseImportReport is the entity returned when an imported case is rejected, listing the errors found in each
+// section of the case
+type CaseImportReport struct {
+	Message  string                    `json:"message"`
+	Sections []CaseImportSectionReport `json:"sections"`
+}
+
+// CaseImportSectionReport contains the errors found in a single section of an imported case, such as
+// practitioners[0] or resolution
+type CaseImportSectionReport struct {
+	Section string   `json:"section"`
+	Errors  []string `json:"errors"`
+}
+
+// ReadinessResource is the entity returned by the readiness check, with the status of each dependency
+type ReadinessResource struct {
+	Status string                         `json:"status"`
+	Checks map[string]DependencyReadiness `json:"checks"`
+}
+
+// DependencyReadiness contains the result of checking a single dependency
+type DependencyReadiness struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// AuditEvent is the entity returned for a request that changed an insolvency case
+type AuditEvent struct {
+	ID            string        `json:"id"`
+	TransactionID string        `json:"transaction_id"`
+	CompanyNumber string        `json:"company_number,omitempty"`
+	UserID        string        `json:"user_id"`
+	Email         string        `json:"email"`
+	Route         string        `json:"route"`
+	Method        string        `json:"method"`
+	Path          string        `json:"path"`
+	Status        int           `json:"status"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Changes       []AuditChange `json:"changes"`
+}
+
+// AuditChange contains the value of a field of an insolvency case before and after a request changed it
+type AuditChange struct {
+	Path   string          `json:"path"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// DomainEvent is the entity delivered to downstream systems for a change to an insolvency case. Events are
+// delivered at least once, so receivers should ignore an event whose ID they have already seen
+type DomainEvent struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	TransactionID string          `json:"transaction_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Data          json.RawMessage `json:"data"`
+}
@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/transformers"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultRelayInterval is the time between each check of the outbox when none is configured
+	DefaultRelayInterval = 5 * time.Second
+
+	// DefaultRelayBatchSize is the number of events delivered on each check of the outbox when none is configured
+	DefaultRelayBatchSize = 100
+
+	// DefaultRelayLease is how long a relay holds its claim on the cases it is delivering when none is configured
+	DefaultRelayLease = 5 * time.Minute
+
+	// DefaultRelayMaxAttempts is the number of failed deliveries after which an event is parked when none is
+	// configured
+	DefaultRelayMaxAttempts = 10
+)
+
+// Relay delivers the events in the outbox to a sink, removing each event once it has been delivered. The events of
+// each case are delivered in the order they were written, and a relay claims the cases it delivers for a lease so
+// that the relays of other instances do not deliver them at the same time. Events are delivered at least once, as
+// an event that is delivered but cannot be removed is delivered again, so receivers should ignore an event whose
+// ID they have already seen
+type Relay struct {
+	svc         dao.Service
+	sink        Sink
+	interval    time.Duration
+	batchSize   int
+	lease       time.Duration
+	maxAttempts int
+	claimant    string
+}
+
+// NewRelay returns a Relay that checks the outbox every interval and delivers up to batchSize events at a time to
+// the sink, claiming their cases for the lease. An event is parked once its delivery has failed maxAttempts times.
+// The defaults are used for an interval, batchSize, lease or maxAttempts that is not positive
+func NewRelay(svc dao.Service, sink Sink, interval time.Duration, batchSize int, lease time.Duration, maxAttempts int) *Relay {
+	if interval <= 0 {
+		interval = DefaultRelayInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRelayBatchSize
+	}
+	if lease <= 0 {
+		lease = DefaultRelayLease
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultRelayMaxAttempts
+	}
+	return &Relay{svc: svc, sink: sink, interval: interval, batchSize: batchSize, lease: lease, maxAttempts: maxAttempts, claimant: uuid.NewString()}
+}
+
+// Run delivers the events in the outbox until ctx is cancelled. A full batch is followed straight away by the
+// next, so that a backlog is cleared without waiting for the interval
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		delivered, err := r.DeliverPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error(fmt.Errorf("outbox relay error: [%v]", err), log.Data{"delivered": delivered})
+		}
+		if err == nil && delivered == r.batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// DeliverPending claims a batch of events from the outbox and delivers them, returning the number delivered. When
+// an event cannot be delivered the later events of its case are held back until the next batch, so that they are
+// not delivered before it, while the events of other cases are still delivered. Delivery stops once the lease on
+// the batch has expired, as the cases may then have been claimed by another relay
+func (r *Relay) DeliverPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	events, err := r.svc.ClaimOutboxEvents(ctx, r.claimant, now, r.lease, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	leaseCtx, cancel := context.WithDeadline(ctx, now.Add(r.lease))
+	defer cancel()
+
+	delivered := 0
+	var errs []error
+	claimed := []string{}
+	heldBack := make(map[string]bool)
+	for i := range events {
+		event := &events[i]
+		if _, ok := heldBack[event.TransactionID]; !ok {
+			claimed = append(claimed, event.TransactionID)
+			heldBack[event.TransactionID] = false
+		}
+		if heldBack[event.TransactionID] {
+			continue
+		}
+		if leaseCtx.Err() != nil {
+			errs = append(errs, fmt.Errorf("the lease on the outbox events expired after delivering %d of %d", delivered, len(events)))
+			break
+		}
+
+		if err := r.deliver(leaseCtx, event); err != nil {
+			heldBack[event.TransactionID] = true
+			errs = append(errs, err)
+			continue
+		}
+		delivered++
+	}
+
+	for _, transactionID := range claimed {
+		if err := r.svc.ReleaseOutboxClaim(ctx, transactionID, r.claimant); err != nil {
+			errs = append(errs, fmt.Errorf("error releasing the outbox claim for transaction [%s]: [%w]", transactionID, err))
+		}
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// deliver sends the event to the sink and removes it from the outbox. A failed delivery is recorded against the
+// event, which is parked once its deliveries have failed maxAttempts times
+func (r *Relay) deliver(ctx context.Context, event *models.OutboxEventDao) error {
+	if err := r.sink.Deliver(ctx, transformers.OutboxEventDaoToDomainEvent(event)); err != nil {
+		err = fmt.Errorf("error delivering %s event [%s] for transaction [%s]: [%w]", event.Type, event.ID, event.TransactionID, err)
+
+		parked, failErr := r.svc.FailOutboxEvent(ctx, event.TransactionID, event.ID, r.maxAttempts)
+		if failErr != nil {
+			return errors.Join(err, fmt.Errorf("error recording the failed delivery of event [%s]: [%w]", event.ID, failErr))
+		}
+		if parked {
+			log.Error(fmt.Errorf("outbox relay parked %s event [%s] for transaction [%s] after %d failed deliveries", event.Type, event.ID, event.TransactionID, r.maxAttempts))
+		}
+		return err
+	}
+
+	if err := r.svc.DeleteOutboxEvent(ctx, event.TransactionID, event.ID); err != nil {
+		return fmt.Errorf("error removing delivered %s event [%s] for transaction [%s]: [%w]", event.Type, event.ID, event.TransactionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/dao"
+	mock_dao "github.com/companieshouse/insolvency-api/mocks"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/golang/mock/gomock"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordingSink records the events delivered to it, failing the deliveries of the events for the transactions in
+// failing
+type recordingSink struct {
+	events  []models.DomainEvent
+	failing map[string]bool
+}
+
+func (s *recordingSink) Deliver(ctx context.Context, event models.DomainEvent) error {
+	if s.failing[event.TransactionID] {
+		return fmt.Errorf("sink unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+// createCase creates a case with the specified transactionID, writing its case created event to the outbox
+func createCase(svc dao.Service, transactionID string) {
+	err := svc.CreateInsolvencyResource(context.Background(), &models.InsolvencyResourceDao{
+		TransactionID: transactionID,
+		Data:          models.InsolvencyResourceDaoData{CompanyNumber: "01234567"},
+	})
+	So(err, ShouldBeNil)
+}
+
+func TestUnitRelayDeliverPending(t *testing.T) {
+	Convey("Relay delivering pending events", t, func() {
+		ctx := context.Background()
+		svc := dao.NewMemoryService()
+		sink := &recordingSink{failing: map[string]bool{}}
+
+		createCase(svc, "1")
+		createCase(svc, "2")
+		createCase(svc, "3")
+
+		Convey("Delivered events are removed from the outbox, in batches", func() {
+			relay := NewRelay(svc, sink, time.Hour, 2, time.Minute, 3)
+
+			delivered, err := relay.DeliverPending(ctx)
+			So(err, ShouldBeNil)
+			So(delivered, ShouldEqual, 2)
+			So(sink.events, ShouldHaveLength, 2)
+			So(sink.events[0].Type, ShouldEqual, dao.EventCaseCreated)
+			So(sink.events[0].TransactionID, ShouldEqual, "1")
+			So(string(sink.events[0].Data), ShouldContainSubstring, `"company_number":"01234567"`)
+
+			delivered, err = relay.DeliverPending(ctx)
+			So(err, ShouldBeNil)
+			So(delivered, ShouldEqual, 1)
+			So(sink.events[2].TransactionID, ShouldEqual, "3")
+
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(events, ShouldBeEmpty)
+		})
+
+		Convey("An event that cannot be delivered only holds back the later events of its case", func() {
+			filings, err := dao.FilingsGeneratedEvent("2", []string{"insolvency#600"})
+			So(err, ShouldBeNil)
+			So(svc.CreateOutboxEvent(ctx, filings), ShouldBeNil)
+			sink.failing["2"] = true
+			relay := NewRelay(svc, sink, time.Hour, 10, time.Minute, 3)
+
+			delivered, err := relay.DeliverPending(ctx)
+			So(err.Error(), ShouldContainSubstring, "sink unavailable")
+			So(delivered, ShouldEqual, 2)
+			So(sink.events, ShouldHaveLength, 2)
+			So(sink.events[1].TransactionID, ShouldEqual, "3")
+
+			events, err := svc.GetOutboxEvents(ctx, 0)
+			So(err, ShouldBeNil)
+			So(events, ShouldHaveLength, 2)
+			So(events[0].Type, ShouldEqual, dao.EventCaseCreated)
+			So(events[0].Attempts, ShouldEqual, 1)
+			So(events[1].ID, ShouldEqual, filings.ID)
+			So(events[1].Attempts, ShouldEqual, 0)
+
+			Convey("and is parked after the maximum attempts, so that the later events are delivered", func() {
+				_, err := relay.DeliverPending(ctx)
+				So(err, ShouldNotBeNil)
+				_, err = relay.DeliverPending(ctx)
+				So(err, ShouldNotBeNil)
+
+				sink.failing["2"] = false
+				delivered, err := relay.DeliverPending(ctx)
+				So(err, ShouldBeNil)
+				So(delivered, ShouldEqual, 1)
+				So(sink.events[2].ID, ShouldEqual, filings.ID)
+
+				events, err := svc.GetOutboxEvents(ctx, 0)
+				So(err, ShouldBeNil)
+				So(events, ShouldHaveLength, 1)
+				So(events[0].Parked, ShouldBeTrue)
+				So(events[0].Attempts, ShouldEqual, 3)
+			})
+		})
+
+		Convey("Cases claimed by another relay are not delivered until the claim is released", func() {
+			claimed, err := svc.ClaimOutboxEvents(ctx, "other", time.Now(), time.Hour, 1)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldHaveLength, 1)
+			relay := NewRelay(svc, sink, time.Hour, 10, time.Minute, 3)
+
+			delivered, err := relay.DeliverPending(ctx)
+			So(err, ShouldBeNil)
+			So(delivered, ShouldEqual, 2)
+			So(sink.events[0].TransactionID, ShouldEqual, "2")
+
+			So(svc.ReleaseOutboxClaim(ctx, "1", "other"), ShouldBeNil)
+			delivered, err = relay.DeliverPending(ctx)
+			So(err, ShouldBeNil)
+			So(delivered, ShouldEqual, 1)
+			So(sink.events[2].TransactionID, ShouldEqual, "1")
+		})
+
+		Convey("The claims on the cases in a batch are released once it has been delivered", func() {
+			sink.failing["2"] = true
+			relay := NewRelay(svc, sink, time.Hour, 10, time.Minute, 3)
+
+			_, err := relay.DeliverPending(ctx)
+			So(err, ShouldNotBeNil)
+
+			claimed, err := svc.ClaimOutboxEvents(ctx, "other", time.Now(), time.Hour, 10)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldHaveLength, 1)
+			So(claimed[0].TransactionID, ShouldEqual, "2")
+		})
+	})
+
+	Convey("Relay with a store that fails", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		mockService := mock_dao.NewMockService(mockCtrl)
+		sink := &recordingSink{failing: map[string]bool{}}
+		relay := NewRelay(mockService, sink, time.Hour, 10, time.Minute, 3)
+
+		event := models.OutboxEventDao{ID: "event1", Type: dao.EventCaseCreated, TransactionID: "1", Data: "{}"}
+		later := models.OutboxEventDao{ID: "event2", Type: dao.EventFilingsGenerated, TransactionID: "1", Data: "{}"}
+
+		Convey("An error claiming the outbox delivers nothing", func() {
+			mockService.EXPECT().ClaimOutboxEvents(gomock.Any(), relay.claimant, gomock.Any(), time.Minute, 10).Return(nil, fmt.Errorf("database error"))
+
+			delivered, err := relay.DeliverPending(context.Background())
+			So(err, ShouldNotBeNil)
+			So(delivered, ShouldEqual, 0)
+			So(sink.events, ShouldBeEmpty)
+		})
+
+		Convey("An event that cannot be removed holds back its case, and is delivered again next time", func() {
+			mockService.EXPECT().ClaimOutboxEvents(gomock.Any(), relay.claimant, gomock.Any(), time.Minute, 10).Return([]models.OutboxEventDao{event, later}, nil)
+			mockService.EXPECT().DeleteOutboxEvent(gomock.Any(), "1", "event1").Return(fmt.Errorf("database error"))
+			mockService.EXPECT().ReleaseOutboxClaim(gomock.Any(), "1", relay.claimant).Return(nil)
+
+			delivered, err := relay.DeliverPending(context.Background())
+			So(err.Error(), ShouldContainSubstring, "error removing delivered insolvency-case.created event [event1]")
+			So(delivered, ShouldEqual, 0)
+			So(sink.events, ShouldHaveLength, 1)
+		})
+
+		Convey("An error recording a failed delivery is returned with the delivery error", func() {
+			sink.failing["1"] = true
+			mockService.EXPECT().ClaimOutboxEvents(gomock.Any(), relay.claimant, gomock.Any(), time.Minute, 10).Return([]models.OutboxEventDao{event, later}, nil)
+			mockService.EXPECT().FailOutboxEvent(gomock.Any(), "1", "event1", 3).Return(false, fmt.Errorf("database error"))
+			mockService.EXPECT().ReleaseOutboxClaim(gomock.Any(), "1", relay.claimant).Return(nil)
+
+			_, err := relay.DeliverPending(context.Background())
+			So(err.Error(), ShouldContainSubstring, "sink unavailable")
+			So(err.Error(), ShouldContainSubstring, "error recording the failed delivery of event [event1]")
+		})
+
+		Convey("An error releasing a claim is returned after the batch is delivered", func() {
+			mockService.EXPECT().ClaimOutboxEvents(gomock.Any(), relay.claimant, gomock.Any(), time.Minute, 10).Return([]models.OutboxEventDao{event}, nil)
+			mockService.EXPECT().DeleteOutboxEvent(gomock.Any(), "1", "event1").Return(nil)
+			mockService.EXPECT().ReleaseOutboxClaim(gomock.Any(), "1", relay.claimant).Return(fmt.Errorf("database error"))
+
+			delivered, err := relay.DeliverPending(context.Background())
+			So(err.Error(), ShouldContainSubstring, "error releasing the outbox claim for transaction [1]")
+			So(delivered, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestUnitRelayRun(t *testing.T) {
+	Convey("A running relay delivers events written after it starts, until it is stopped", t, func() {
+		svc := dao.NewMemoryService()
+		sink := NewWriterSink(&syncBuffer{})
+		relay := NewRelay(svc, sink, 10*time.Millisecond, 1, time.Minute, 3)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			relay.Run(ctx)
+			close(stopped)
+		}()
+
+		createCase(svc, "1")
+		createCase(svc, "2")
+
+		So(waitFor(func() bool {
+			events, err := svc.GetOutboxEvents(context.Background(), 0)
+			return err == nil && len(events) == 0
+		}), ShouldBeTrue)
+		So(sink.w.(*syncBuffer).lines(), ShouldHaveLength, 2)
+
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("relay did not stop")
+		}
+	})
+}
+
+// waitFor returns whether the condition becomes true within a second
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
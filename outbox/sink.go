@@ -0,0 +1,128 @@
+// Package outbox delivers the domain events written to the outbox of each insolvency case to downstream systems.
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// Sinks that domain events can be delivered to, selected with the OUTBOX_SINK config
+const (
+	SinkWebhook = "webhook"
+	SinkFile    = "file"
+	SinkStdout  = "stdout"
+)
+
+// defaultWebhookTimeout is the time allowed for a webhook to respond to each event
+const defaultWebhookTimeout = 10 * time.Second
+
+// Sink delivers domain events to a downstream system
+type Sink interface {
+	// Deliver sends the event, returning an error if it may not have been received
+	Deliver(ctx context.Context, event models.DomainEvent) error
+}
+
+// NewSink returns the sink selected by the OUTBOX_SINK config, or nil if none is configured
+func NewSink(cfg *config.Config) (Sink, error) {
+	switch cfg.OutboxSink {
+	case "":
+		return nil, nil
+	case SinkWebhook:
+		if cfg.OutboxWebhookURL == "" {
+			return nil, fmt.Errorf("the %s outbox sink needs a webhook url", SinkWebhook)
+		}
+		return NewWebhookSink(cfg.OutboxWebhookURL), nil
+	case SinkFile:
+		if cfg.OutboxFile == "" {
+			return nil, fmt.Errorf("the %s outbox sink needs a file", SinkFile)
+		}
+		return NewFileSink(cfg.OutboxFile)
+	case SinkStdout:
+		return NewWriterSink(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink [%s]", cfg.OutboxSink)
+	}
+}
+
+// WebhookSink delivers each event by POSTing it as JSON to a URL. Any response other than a 2xx status is a failure
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink returns a WebhookSink that POSTs events to the url
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: defaultWebhookTimeout}}
+}
+
+// Deliver POSTs the event to the webhook
+func (s *WebhookSink) Deliver(ctx context.Context, event models.DomainEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding event: [%w]", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: [%w]", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling webhook: [%w]", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status [%d]", resp.StatusCode)
+	}
+	return nil
+}
+
+// WriterSink delivers each event by writing it as a line of JSON, so that events can be followed locally
+type WriterSink struct {
+	mtx sync.Mutex
+	w   io.Writer
+}
+
+// NewWriterSink returns a WriterSink that writes events to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink returns a WriterSink that appends events to the file at path, creating it if it does not exist
+func NewFileSink(path string) (*WriterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening outbox file: [%w]", err)
+	}
+	return NewWriterSink(file), nil
+}
+
+// Deliver writes the event as a line of JSON
+func (s *WriterSink) Deliver(ctx context.Context, event models.DomainEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding event: [%w]", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing event: [%w]", err)
+	}
+	return nil
+}
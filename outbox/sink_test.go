@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// syncBuffer is a buffer that can be written and read from different goroutines
+type syncBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns the lines written to the buffer
+func (b *syncBuffer) lines() []string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return strings.Split(strings.TrimSuffix(b.buf.String(), "\n"), "\n")
+}
+
+func domainEvent() models.DomainEvent {
+	return models.DomainEvent{
+		ID:            "event1",
+		Type:          "attachment.scanned",
+		TransactionID: "1234",
+		CreatedAt:     time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC),
+		Data:          json.RawMessage(`{"attachment_id":"file","status":"processed"}`),
+	}
+}
+
+const domainEventJSON = `{"id":"event1","type":"attachment.scanned","transaction_id":"1234","created_at":"2021-06-28T10:00:00Z","data":{"attachment_id":"file","status":"processed"}}`
+
+func TestUnitWebhookSink(t *testing.T) {
+	Convey("Webhook sink", t, func() {
+		var received []byte
+		var contentType string
+		status := http.StatusNoContent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, _ = io.ReadAll(r.Body)
+			contentType = r.Header.Get("Content-Type")
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		sink := NewWebhookSink(server.URL)
+
+		Convey("The event is POSTed as JSON", func() {
+			So(sink.Deliver(context.Background(), domainEvent()), ShouldBeNil)
+			So(string(received), ShouldEqual, domainEventJSON)
+			So(contentType, ShouldEqual, "application/json")
+		})
+
+		Convey("A response other than success is a failed delivery", func() {
+			status = http.StatusServiceUnavailable
+
+			err := sink.Deliver(context.Background(), domainEvent())
+			So(err.Error(), ShouldEqual, "webhook responded with status [503]")
+		})
+
+		Convey("A webhook that cannot be reached is a failed delivery", func() {
+			server.Close()
+
+			So(sink.Deliver(context.Background(), domainEvent()), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestUnitWriterSink(t *testing.T) {
+	Convey("Each event is written as a line of JSON", t, func() {
+		buf := &syncBuffer{}
+		sink := NewWriterSink(buf)
+
+		So(sink.Deliver(context.Background(), domainEvent()), ShouldBeNil)
+		So(sink.Deliver(context.Background(), domainEvent()), ShouldBeNil)
+		So(buf.lines(), ShouldResemble, []string{domainEventJSON, domainEventJSON})
+	})
+
+	Convey("The file sink appends to the file", t, func() {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		So(os.WriteFile(path, []byte("existing\n"), 0o644), ShouldBeNil)
+
+		sink, err := NewFileSink(path)
+		So(err, ShouldBeNil)
+		So(sink.Deliver(context.Background(), domainEvent()), ShouldBeNil)
+
+		contents, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(contents), ShouldEqual, "existing\n"+domainEventJSON+"\n")
+	})
+}
+
+func TestUnitNewSink(t *testing.T) {
+	Convey("The sink is selected by the config", t, func() {
+		sink, err := NewSink(&config.Config{})
+		So(err, ShouldBeNil)
+		So(sink, ShouldBeNil)
+
+		sink, err = NewSink(&config.Config{OutboxSink: SinkWebhook, OutboxWebhookURL: "http://localhost/events"})
+		So(err, ShouldBeNil)
+		So(sink, ShouldHaveSameTypeAs, &WebhookSink{})
+
+		sink, err = NewSink(&config.Config{OutboxSink: SinkFile, OutboxFile: filepath.Join(t.TempDir(), "events.jsonl")})
+		So(err, ShouldBeNil)
+		So(sink, ShouldHaveSameTypeAs, &WriterSink{})
+
+		sink, err = NewSink(&config.Config{OutboxSink: SinkStdout})
+		So(err, ShouldBeNil)
+		So(sink, ShouldHaveSameTypeAs, &WriterSink{})
+	})
+
+	Convey("A sink without its settings, or an unknown sink, is an error", t, func() {
+		_, err := NewSink(&config.Config{OutboxSink: SinkWebhook})
+		So(err, ShouldNotBeNil)
+
+		_, err = NewSink(&config.Config{OutboxSink: SinkFile})
+		So(err, ShouldNotBeNil)
+
+		_, err = NewSink(&config.Config{OutboxSink: "kafka"})
+		So(err.Error(), ShouldEqual, "unknown outbox sink [kafka]")
+	})
+}
@@ -1,40 +1,31 @@
 package service
 
 import (
-	"fmt"
 	"net/http"
 
-	"github.com/companieshouse/chs.go/log"
-	"github.com/companieshouse/go-sdk-manager/manager"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/models"
 )
 
-func CheckCompanyNameAlphaKey(companyProfileCompanyName string, insolvencyRequest *models.InsolvencyRequest, req *http.Request) (error, int) {
+// CheckCompanyNameAlphaKey checks that the company name in the request is the same as the name on the company
+// profile, as compared by their alpha keys
+func (c Clients) CheckCompanyNameAlphaKey(companyProfileCompanyName string, insolvencyRequest *models.InsolvencyRequest, req *http.Request) error {
 
-	api, err := manager.GetPrivateSDK(req)
-	if err != nil {
-		return fmt.Errorf("error creating private SDK to call alphakeyservice: [%v]", err.Error()), http.StatusInternalServerError
-	}
+	alphaKeyClient := c.AlphaKey
 
-	requestAlphaKeyResponse, err := api.AlphaKey.Get(insolvencyRequest.CompanyName).Do()
+	insolvencyRequestAlphaKey, err := alphaKeyClient.GetSameAsAlphaKey(req, insolvencyRequest.CompanyName)
 	if err != nil {
-		log.ErrorR(req, fmt.Errorf("error communicating with alphakey service [%v]", err))
-		return fmt.Errorf("error communicating with alphakey service"), http.StatusInternalServerError
+		return err
 	}
 
-	insolvencyRequestAlphaKey := requestAlphaKeyResponse.SameAsAlphaKey
-
-	profileAlphaKeyResponse, err := api.AlphaKey.Get(companyProfileCompanyName).Do()
+	profileAlphaKey, err := alphaKeyClient.GetSameAsAlphaKey(req, companyProfileCompanyName)
 	if err != nil {
-		log.ErrorR(req, fmt.Errorf("error communicating with alphakey service [%v]", err))
-		return fmt.Errorf("error communicating with alphakey service"), http.StatusInternalServerError
+		return err
 	}
 
-	profileAlphaKey := profileAlphaKeyResponse.SameAsAlphaKey
-
 	if insolvencyRequestAlphaKey != profileAlphaKey {
-		return fmt.Errorf("company names do not match - provided: [%s], expected: [%s]", insolvencyRequest.CompanyName, companyProfileCompanyName), http.StatusBadRequest
+		return apperrors.Validation("company names do not match - provided: [%s], expected: [%s]", insolvencyRequest.CompanyName, companyProfileCompanyName)
 	}
 
-	return nil, requestAlphaKeyResponse.HTTPStatusCode
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/go-sdk-manager/config"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/jarcoal/httpmock"
 	. "github.com/smartystreets/goconvey/convey"
@@ -29,9 +30,8 @@ func TestUnitCheckCompanyNameValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, "http://localhost:18103/alphakey?name=COMPANYNAME", httpmock.NewStringResponder(http.StatusOK, alphaKeyResponse("COMPANYNAME")))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode := CheckCompanyNameAlphaKey("COMPANYNAME", request, &http.Request{})
+			err := sdkClients.CheckCompanyNameAlphaKey("COMPANYNAME", request, &http.Request{})
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusOK)
 
 		})
 
@@ -41,10 +41,10 @@ func TestUnitCheckCompanyNameValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, "http://localhost:18103/alphakey?name=ANOTHERNAME", httpmock.NewStringResponder(http.StatusOK, alphaKeyResponse("ANOTHERNAME")))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode := CheckCompanyNameAlphaKey("ANOTHERNAME", request, &http.Request{})
+			err := sdkClients.CheckCompanyNameAlphaKey("ANOTHERNAME", request, &http.Request{})
 			So(err, ShouldNotBeNil)
 			So(err.Error(), ShouldContainSubstring, "company names do not match")
-			So(statusCode, ShouldEqual, http.StatusBadRequest)
+			So(err, ShouldHaveSameTypeAs, &apperrors.ValidationError{})
 
 		})
 
@@ -53,9 +53,8 @@ func TestUnitCheckCompanyNameValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, "http://localhost:18103/alphakey?name=companyName", httpmock.NewStringResponder(http.StatusInternalServerError, ""))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode := CheckCompanyNameAlphaKey("companyName", request, &http.Request{})
+			err := sdkClients.CheckCompanyNameAlphaKey("companyName", request, &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusInternalServerError)
 			So(err.Error(), ShouldContainSubstring, `error communicating with alphakey service`)
 		})
 
@@ -66,9 +65,8 @@ func TestUnitCheckCompanyNameValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, "http://localhost:8003/alphakey?name=companyName", httpmock.NewStringResponder(http.StatusOK, alphaKeyResponse("COMPANYNAME")))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode := CheckCompanyNameAlphaKey("companyName", request, &http.Request{})
+			err := sdkClients.CheckCompanyNameAlphaKey("companyName", request, &http.Request{})
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusOK)
 		})
 	})
 
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -23,10 +24,10 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "invalid", createHeader())
-		So(validationErrs, ShouldEqual, "attachment_type is invalid")
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "invalid", createHeader())
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "attachment_type", Message: "attachment_type is invalid"}})
 		So(err, ShouldBeNil)
 	})
 
@@ -35,9 +36,9 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(nil, fmt.Errorf("error getting attachments for transaction ID [%s]", transactionID))
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(nil, fmt.Errorf("error getting attachments for transaction ID [%s]", transactionID))
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", createHeader())
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", createHeader())
 		So(validationErrs, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error getting attachments for transaction ID")
 	})
@@ -47,10 +48,10 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(generateAttachment(), nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(generateAttachment(), nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", createHeader())
-		So(validationErrs, ShouldEqual, fmt.Sprintf("attachment of type [%s] has already been filed for insolvency case with transaction ID [%s]", "resolution", transactionID))
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", createHeader())
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "attachment_type", Message: fmt.Sprintf("attachment of type [%s] has already been filed for insolvency case with transaction ID [%s]", "resolution", transactionID)}})
 		So(err, ShouldBeNil)
 	})
 
@@ -62,10 +63,10 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		attachmentResources[0].Type = constants.StatementOfAffairsLiquidator.String()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(attachmentResources, nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(attachmentResources, nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", createHeader())
-		So(validationErrs, ShouldEqual, fmt.Sprintf("attachment of type [%s] has been filed for insolvency case with transaction ID [%s] - no other attachments can be filed for this case", constants.StatementOfAffairsLiquidator.String(), transactionID))
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", createHeader())
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "attachment_type", Message: fmt.Sprintf("attachment of type [%s] has been filed for insolvency case with transaction ID [%s] - no other attachments can be filed for this case", constants.StatementOfAffairsLiquidator.String(), transactionID)}})
 		So(err, ShouldBeNil)
 	})
 
@@ -77,10 +78,10 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		attachmentResources[0].Type = constants.Resolution.String()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(attachmentResources, nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(attachmentResources, nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, constants.StatementOfAffairsLiquidator.String(), createHeader())
-		So(validationErrs, ShouldEqual, fmt.Sprintf("attachment of type [%s] cannot be filed for insolvency case with transaction ID [%s] - other attachments have already been filed for this case", constants.StatementOfAffairsLiquidator.String(), transactionID))
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, constants.StatementOfAffairsLiquidator.String(), createHeader())
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "attachment_type", Message: fmt.Sprintf("attachment of type [%s] cannot be filed for insolvency case with transaction ID [%s] - other attachments have already been filed for this case", constants.StatementOfAffairsLiquidator.String(), transactionID)}})
 		So(err, ShouldBeNil)
 	})
 
@@ -89,14 +90,14 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		header := createHeader()
 		header.Header.Set("Content-Type", "invalid")
 		header.Filename = "test.txt"
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", header)
-		So(validationErrs, ShouldEqual, "attachment file format should be pdf")
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", header)
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "file", Message: "attachment file format should be pdf"}})
 		So(err, ShouldBeNil)
 	})
 
@@ -105,13 +106,13 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
 		header := createHeader()
 		header.Size = 10000000000
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", header)
-		So(validationErrs, ShouldEqual, "attachment file size is too large to be processed")
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", header)
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "file", Message: "attachment file size is too large to be processed"}})
 		So(err, ShouldBeNil)
 	})
 
@@ -123,9 +124,9 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		attachmentResources[0].Type = constants.Resolution.String()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(attachmentResources, nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(attachmentResources, nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, constants.StatementOfAffairsDirector.String(), createHeader())
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, constants.StatementOfAffairsDirector.String(), createHeader())
 		So(validationErrs, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
@@ -135,9 +136,9 @@ func TestUnitValidateAttachmentDetails(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetAttachmentResources(transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
+		mockService.EXPECT().GetAttachmentResources(gomock.Any(), transactionID).Return(make([]models.AttachmentResourceDao, 0), nil)
 
-		validationErrs, err := ValidateAttachmentDetails(mockService, transactionID, "resolution", createHeader())
+		validationErrs, err := ValidateAttachmentDetails(context.Background(), mockService, transactionID, "resolution", createHeader())
 		So(validationErrs, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
@@ -175,7 +176,7 @@ func TestUnitDownloadAttachment(t *testing.T) {
 	Convey("Download attachment - no response", t, func() {
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		w := httptest.NewRecorder()
-		responseType, err := DownloadAttachment(attachmentID, req, w)
+		responseType, err := sdkClients.DownloadAttachment(attachmentID, req, w)
 		So(responseType, ShouldEqual, Error)
 		So(err.Error(), ShouldEqual, "error communicating with the File Transfer API: [error downloading file, no response]")
 	})
@@ -188,7 +189,7 @@ func TestUnitDownloadAttachment(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		w := httptest.NewRecorder()
 
-		responseType, err := DownloadAttachment(attachmentID, req, w)
+		responseType, err := sdkClients.DownloadAttachment(attachmentID, req, w)
 		So(responseType, ShouldEqual, Success)
 		So(err, ShouldBeNil)
 	})
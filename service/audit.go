@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiffInsolvencyResource returns the fields that differ between two versions of an insolvency case, named by their
+// path in the stored case such as data.practitioners[id=1].appointment.appointed_on. Either version may be nil,
+// when the case did not exist before or after a request, in which case each top level field is recorded.
+// Practitioners and attachments are matched by their ID, so that removing one is not recorded as a change to
+// every one after it
+func DiffInsolvencyResource(before, after *models.InsolvencyResourceDao) ([]models.AuditChangeDao, error) {
+	beforeValue, err := plainDocument(before)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := plainDocument(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.AuditChangeDao{}
+	if err := diffValues("", beforeValue, afterValue, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// plainDocument returns the insolvency case as it is stored, made up of maps, slices and plain values
+func plainDocument(insolvencyResource *models.InsolvencyResourceDao) (interface{}, error) {
+	if insolvencyResource == nil {
+		return nil, nil
+	}
+
+	encoded, err := bson.Marshal(insolvencyResource)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding insolvency case: [%w]", err)
+	}
+
+	var document bson.M
+	if err := bson.Unmarshal(encoded, &document); err != nil {
+		return nil, fmt.Errorf("error decoding insolvency case: [%w]", err)
+	}
+
+	return plainValue(document), nil
+}
+
+// plainValue replaces the BSON types in a decoded value with maps, slices and values that encode to plain JSON
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		m := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			m[key] = plainValue(element)
+		}
+		return m
+	case bson.D:
+		m := make(map[string]interface{}, len(v))
+		for _, element := range v {
+			m[element.Key] = plainValue(element.Value)
+		}
+		return m
+	case bson.A:
+		s := make([]interface{}, len(v))
+		for i, element := range v {
+			s[i] = plainValue(element)
+		}
+		return s
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// diffValues appends the changes between the before and after values at the path to changes. A nil value is a
+// field that does not exist
+func diffValues(path string, before, after interface{}, changes *[]models.AuditChangeDao) error {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	// A field that was added or removed is recorded whole, apart from the case itself
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if (beforeIsMap && afterIsMap) || (path == "" && (beforeIsMap || afterIsMap)) {
+		for _, key := range unionKeys(beforeMap, afterMap) {
+			if err := diffValues(joinPath(path, key), beforeMap[key], afterMap[key], changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// A list that is no longer stored once its last element is removed is compared as an empty list
+	beforeSlice, beforeIsSlice := before.([]interface{})
+	afterSlice, afterIsSlice := after.([]interface{})
+	if (beforeIsSlice || before == nil) && (afterIsSlice || after == nil) && path != "" {
+		if beforeIDs, ok := elementIDs(beforeSlice); ok {
+			if afterIDs, ok := elementIDs(afterSlice); ok {
+				return diffByID(path, beforeSlice, beforeIDs, afterSlice, afterIDs, changes)
+			}
+		}
+		if len(beforeSlice) == len(afterSlice) {
+			for i := range beforeSlice {
+				if err := diffValues(path+"["+strconv.Itoa(i)+"]", beforeSlice[i], afterSlice[i], changes); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	change := models.AuditChangeDao{Path: path}
+	var err error
+	if change.Before, err = encodeValue(before); err != nil {
+		return err
+	}
+	if change.After, err = encodeValue(after); err != nil {
+		return err
+	}
+	*changes = append(*changes, change)
+	return nil
+}
+
+// diffByID compares the elements of two slices that have the same ID, in the order they first appear
+func diffByID(path string, before []interface{}, beforeIDs []string, after []interface{}, afterIDs []string, changes *[]models.AuditChangeDao) error {
+	beforeByID := make(map[string]interface{}, len(before))
+	for i, id := range beforeIDs {
+		beforeByID[id] = before[i]
+	}
+	afterByID := make(map[string]interface{}, len(after))
+	for i, id := range afterIDs {
+		afterByID[id] = after[i]
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range append(beforeIDs, afterIDs...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if err := diffValues(path+"[id="+id+"]", beforeByID[id], afterByID[id], changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elementIDs returns the id field of each element of the slice, and whether every element has a distinct ID
+func elementIDs(s []interface{}) ([]string, bool) {
+	ids := make([]string, 0, len(s))
+	seen := make(map[string]bool, len(s))
+	for _, element := range s {
+		m, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, ok := m["id"].(string)
+		if !ok || id == "" || seen[id] {
+			return nil, false
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// unionKeys returns the keys that are in either map, sorted
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// encodeValue returns the value encoded as JSON, or an empty string for a field that does not exist
+func encodeValue(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("error encoding audit change: [%w]", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// auditedCase returns an insolvency case with two practitioners, the first of them appointed
+func auditedCase() *models.InsolvencyResourceDao {
+	return &models.InsolvencyResourceDao{
+		TransactionID: "12345678",
+		Data: models.InsolvencyResourceDaoData{
+			CompanyNumber: "01234567",
+			Practitioners: []models.PractitionerResourceDao{
+				{ID: "1", IPCode: "1111", FirstName: "Joe", Appointment: &models.AppointmentResourceDao{AppointedOn: "2021-06-28", MadeBy: "creditors"}},
+				{ID: "2", IPCode: "2222", FirstName: "Jane"},
+			},
+		},
+	}
+}
+
+// changeFor returns the change to the field at the path, failing the test if there is none
+func changeFor(changes []models.AuditChangeDao, path string) models.AuditChangeDao {
+	for _, change := range changes {
+		if change.Path == path {
+			return change
+		}
+	}
+	So(changes, ShouldContain, path)
+	return models.AuditChangeDao{}
+}
+
+func TestUnitDiffInsolvencyResource(t *testing.T) {
+	Convey("Only the changed fields are recorded, with their JSON values", t, func() {
+		before := auditedCase()
+		after := auditedCase()
+		after.Data.Practitioners[0].Appointment.AppointedOn = "2021-07-01"
+
+		changes, err := DiffInsolvencyResource(before, after)
+		So(err, ShouldBeNil)
+		So(changes, ShouldResemble, []models.AuditChangeDao{
+			{Path: "data.practitioners[id=1].appointment.appointed_on", Before: `"2021-06-28"`, After: `"2021-07-01"`},
+		})
+	})
+
+	Convey("Removing a practitioner is recorded against that practitioner only", t, func() {
+		before := auditedCase()
+		after := auditedCase()
+		after.Data.Practitioners = after.Data.Practitioners[1:]
+
+		changes, err := DiffInsolvencyResource(before, after)
+		So(err, ShouldBeNil)
+		So(changes, ShouldHaveLength, 1)
+		So(changes[0].Path, ShouldEqual, "data.practitioners[id=1]")
+		So(changes[0].Before, ShouldContainSubstring, `"ip_code":"1111"`)
+		So(changes[0].After, ShouldBeEmpty)
+	})
+
+	Convey("Removing the last practitioner is recorded against that practitioner", t, func() {
+		before := auditedCase()
+		before.Data.Practitioners = before.Data.Practitioners[:1]
+		after := auditedCase()
+		after.Data.Practitioners = nil
+
+		changes, err := DiffInsolvencyResource(before, after)
+		So(err, ShouldBeNil)
+		So(changes, ShouldHaveLength, 1)
+		So(changes[0].Path, ShouldEqual, "data.practitioners[id=1]")
+	})
+
+	Convey("Each top level field of a new case is recorded as added", t, func() {
+		changes, err := DiffInsolvencyResource(nil, auditedCase())
+		So(err, ShouldBeNil)
+		So(changeFor(changes, "transaction_id"), ShouldResemble, models.AuditChangeDao{Path: "transaction_id", After: `"12345678"`})
+		So(changeFor(changes, "_id").After, ShouldEqual, `"000000000000000000000000"`)
+		So(changeFor(changes, "data").After, ShouldContainSubstring, `"first_name":"Jane"`)
+		So(changeFor(changes, "data").Before, ShouldBeEmpty)
+	})
+
+	Convey("An unchanged case has no changes", t, func() {
+		changes, err := DiffInsolvencyResource(auditedCase(), auditedCase())
+		So(err, ShouldBeNil)
+		So(changes, ShouldBeEmpty)
+	})
+}
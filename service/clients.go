@@ -0,0 +1,62 @@
+package service
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// CompanyProfileClient gets companies from the company profile api
+type CompanyProfileClient interface {
+	// GetCompanyProfile returns the profile of the company with the status code of the response
+	GetCompanyProfile(req *http.Request, companyNumber string) (*companieshouseapi.CompanyProfile, int, error)
+}
+
+// TransactionClient gets and updates transactions in the transaction api
+type TransactionClient interface {
+	// GetTransactionStatus returns the status of the transaction, such as open or closed, with the status code of
+	// the response
+	GetTransactionStatus(req *http.Request, transactionID string) (string, int, error)
+
+	// PatchTransaction adds the insolvency case to the transaction, returning the status code of the response
+	PatchTransaction(req *http.Request, transactionID string, insolvencyResource *models.InsolvencyResourceDao) (int, error)
+}
+
+// AlphaKeyClient gets the alpha keys used to compare company names from the alpha key service
+type AlphaKeyClient interface {
+	// GetSameAsAlphaKey returns the key that company names considered the same as companyName share
+	GetSameAsAlphaKey(req *http.Request, companyName string) (string, error)
+}
+
+// EfsClient checks users against the EFS submission api allow list
+type EfsClient interface {
+	// IsUserOnAllowList returns whether the email address is on the EFS allow list
+	IsUserOnAllowList(req *http.Request, emailAddress string) (bool, error)
+}
+
+// FileTransferClient stores attachments with the File Transfer API
+type FileTransferClient interface {
+	// UploadFile uploads the file and returns its ID
+	UploadFile(req *http.Request, file multipart.File, header *multipart.FileHeader) (string, error)
+
+	// GetFile returns the details of the file, including its anti-virus status
+	GetFile(req *http.Request, fileID string) (models.AttachmentFile, error)
+
+	// DownloadFile writes the file to the ResponseWriter
+	DownloadFile(req *http.Request, fileID string, w http.ResponseWriter) error
+
+	// DeleteFile deletes the file
+	DeleteFile(req *http.Request, fileID string) error
+}
+
+// Clients holds the clients of the upstream APIs used by the service. It is created once in main and passed to the
+// handlers and interceptors that call the upstream APIs, so that tests can give each handler its own fakes
+type Clients struct {
+	CompanyProfile CompanyProfileClient
+	Transaction    TransactionClient
+	AlphaKey       AlphaKeyClient
+	Efs            EfsClient
+	FileTransfer   FileTransferClient
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// sdkClients are the clients that call the upstream APIs with the SDKs, which the tests mock with httpmock
+var sdkClients = NewSDKClients()
+
+// fakeUpload returns a pdf file and its header as parsed from a multipart request
+func fakeUpload(t *testing.T, contents string) (multipart.File, *multipart.FileHeader) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "resolution.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(contents))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	file, header, err := req.FormFile("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, header
+}
+
+func TestUnitFakeTransactionClient(t *testing.T) {
+	Convey("Transactions are checked with the transaction client", t, func() {
+		transactions := NewFakeTransactionClient("87654321")
+		transactions.SetTransactionStatus("12345678", "closed")
+		clients := Clients{Transaction: transactions}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		So(clients.CheckTransactionID("87654321", req), ShouldBeNil)
+		So(clients.CheckTransactionID("11111111", req), ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+
+		closed, err := clients.CheckIfTransactionClosed("87654321", req)
+		So(err, ShouldBeNil)
+		So(closed, ShouldBeFalse)
+
+		closed, err = clients.CheckIfTransactionClosed("12345678", req)
+		So(err, ShouldBeNil)
+		So(closed, ShouldBeTrue)
+
+		insolvencyResource := &models.InsolvencyResourceDao{TransactionID: "87654321", Kind: "insolvency-resource"}
+		So(clients.PatchTransactionWithInsolvencyResource("87654321", insolvencyResource, req), ShouldBeNil)
+		patched, ok := transactions.PatchedInsolvencyResource("87654321")
+		So(ok, ShouldBeTrue)
+		So(patched.Kind, ShouldEqual, "insolvency-resource")
+
+		transactions.Err = errors.New("connection refused")
+		_, err = clients.CheckIfTransactionClosed("87654321", req)
+		So(err.Error(), ShouldEqual, "error getting transaction from transaction api")
+	})
+}
+
+func TestUnitFakeCompanyProfileClient(t *testing.T) {
+	Convey("Companies are checked with the company profile client", t, func() {
+		clients := Clients{
+			CompanyProfile: NewFakeCompanyProfileClient(companieshouseapi.CompanyProfile{
+				CompanyNumber:  "01234567",
+				CompanyName:    "COMPANY LIMITED",
+				DateOfCreation: "2000-06-26",
+			}),
+			AlphaKey: &FakeAlphaKeyClient{},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		request := incomingInsolvencyRequest("01234567", "Company Limited.", constants.CVL.String())
+		companyProfile, err := clients.CheckCompanyExists(request, req)
+		So(err, ShouldBeNil)
+		So(companyProfile.CompanyName, ShouldEqual, "COMPANY LIMITED")
+
+		So(clients.CheckCompanyNameAlphaKey(companyProfile.CompanyName, request, req), ShouldBeNil)
+		So(clients.CheckCompanyNameAlphaKey("OTHER COMPANY LIMITED", request, req), ShouldHaveSameTypeAs, &apperrors.ValidationError{})
+
+		incorporatedOn, err := clients.GetCompanyIncorporatedOn("01234567", req)
+		So(err, ShouldBeNil)
+		So(incorporatedOn, ShouldEqual, "2000-06-26")
+
+		_, err = clients.GetCompanyIncorporatedOn("76543210", req)
+		So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
+	})
+}
+
+func TestUnitFakeFileTransferClient(t *testing.T) {
+	Convey("Attachments are stored with the file transfer client", t, func() {
+		files := NewFakeFileTransferClient()
+		clients := Clients{FileTransfer: files}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		file, header := fakeUpload(t, "resolution")
+		fileID, responseType, err := clients.UploadAttachment(file, header, req)
+		So(err, ShouldBeNil)
+		So(responseType, ShouldEqual, Success)
+
+		details, _, err := clients.GetAttachmentDetails(fileID, req)
+		So(err, ShouldBeNil)
+		So(details.Name, ShouldEqual, "resolution.pdf")
+		So(details.AVStatus, ShouldEqual, "clean")
+
+		So(files.SetAVStatus(fileID, "infected"), ShouldBeTrue)
+		details, _, err = clients.GetAttachmentDetails(fileID, req)
+		So(err, ShouldBeNil)
+		So(details.AVStatus, ShouldEqual, "infected")
+
+		w := httptest.NewRecorder()
+		_, err = clients.DownloadAttachment(fileID, req, w)
+		So(err, ShouldBeNil)
+		So(w.Body.String(), ShouldEqual, "resolution")
+
+		_, err = clients.DeleteAttachment(fileID, req)
+		So(err, ShouldBeNil)
+		_, responseType, err = clients.GetAttachmentDetails(fileID, req)
+		So(err, ShouldNotBeNil)
+		So(responseType, ShouldEqual, Error)
+	})
+}
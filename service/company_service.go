@@ -1,57 +1,44 @@
 package service
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/companieshouse/api-sdk-go/companieshouseapi"
-	"github.com/companieshouse/go-sdk-manager/manager"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/models"
 )
 
 // CheckCompanyExists will check that the company exists against the company profile api to make a valid insolvency
-func CheckCompanyExists(insolvencyRequest *models.InsolvencyRequest, req *http.Request) (error, int, *companieshouseapi.CompanyProfile) {
-
-	// Create SDK session
-	api, err := manager.GetSDK(req)
-	if err != nil {
-		return fmt.Errorf("error creating SDK to call company profile: [%v]", err.Error()), http.StatusInternalServerError, nil
-	}
+func (c Clients) CheckCompanyExists(insolvencyRequest *models.InsolvencyRequest, req *http.Request) (*companieshouseapi.CompanyProfile, error) {
 
 	// Call company profile api to retrieve company details
-	companyProfile, err := api.Profile.Get(insolvencyRequest.CompanyNumber).Do()
+	companyProfile, statusCode, err := c.CompanyProfile.GetCompanyProfile(req, insolvencyRequest.CompanyNumber)
 	if err != nil {
 		// If 404 then return that company not found
-		if companyProfile.HTTPStatusCode == http.StatusNotFound {
-			return fmt.Errorf("company not found"), http.StatusNotFound, nil
+		if statusCode == http.StatusNotFound {
+			return nil, apperrors.NotFound("company not found")
 		}
 		// Else there has been an error contacting the company profile api
-		return fmt.Errorf("error communicating with the company profile api"), companyProfile.HTTPStatusCode, nil
+		return nil, upstreamError(statusCode, err, "error communicating with the company profile api")
 	}
 
 	// If no errors then the company exists
-	return nil, companyProfile.HTTPStatusCode, companyProfile
+	return companyProfile, nil
 
 }
 
 // GetCompanyIncorporatedOn retrieves the date that the company was created
-func GetCompanyIncorporatedOn(companyNumber string, req *http.Request) (string, error) {
-	// Create SDK session
-	api, err := manager.GetSDK(req)
-	if err != nil {
-		return "", fmt.Errorf("error creating SDK to call company profile: [%v]", err.Error())
-	}
-
+func (c Clients) GetCompanyIncorporatedOn(companyNumber string, req *http.Request) (string, error) {
 	// Call company profile api to retrieve company details
-	companyProfile, err := api.Profile.Get(companyNumber).Do()
+	companyProfile, statusCode, err := c.CompanyProfile.GetCompanyProfile(req, companyNumber)
 	if err != nil {
 		// If 404 then return that company not found
-		if companyProfile.HTTPStatusCode == http.StatusNotFound {
-			return "", fmt.Errorf("company not found")
+		if statusCode == http.StatusNotFound {
+			return "", apperrors.NotFound("company not found")
 		}
 		// Else there has been an error contacting the company profile api
-		return "", fmt.Errorf("error communicating with the company profile api")
+		return "", upstreamError(statusCode, err, "error communicating with the company profile api")
 	}
 
 	return companyProfile.DateOfCreation, nil
@@ -62,17 +49,17 @@ func CheckCompanyDetailsAreValid(companyProfile *companieshouseapi.CompanyProfil
 
 	// Check if company jurisdiction is allowed
 	if !checkJurisdictionIsAllowed(companyProfile.Jurisdiction) {
-		return fmt.Errorf("jurisdiction [%s] not permitted", companyProfile.Jurisdiction)
+		return apperrors.Validation("jurisdiction [%s] not permitted", companyProfile.Jurisdiction)
 	}
 
 	// Check if company status is allowed
 	if !checkCompanyStatusIsAllowed(companyProfile.CompanyStatus) {
-		return fmt.Errorf("company status [%s] not permitted", companyProfile.CompanyStatus)
+		return apperrors.Validation("company status [%s] not permitted", companyProfile.CompanyStatus)
 	}
 
 	// Check is company type is allowed
 	if !checkCompanyTypeIsAllowed(companyProfile.Type) {
-		return fmt.Errorf("company type [%s] not permitted", companyProfile.Type)
+		return apperrors.Validation("company type [%s] not permitted", companyProfile.Type)
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/models"
 	"github.com/jarcoal/httpmock"
@@ -52,9 +53,8 @@ func TestUnitCheckCompanyInsolvencyValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/01234567", httpmock.NewStringResponder(http.StatusNotFound, "Message: Company not found"))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode, companyProfile := CheckCompanyExists(request, &http.Request{})
-			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusNotFound)
+			companyProfile, err := sdkClients.CheckCompanyExists(request, &http.Request{})
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
 			So(err.Error(), ShouldEqual, `company not found`)
 			So(companyProfile, ShouldBeNil)
 		})
@@ -64,9 +64,8 @@ func TestUnitCheckCompanyInsolvencyValid(t *testing.T) {
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/01234567", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
 			request := incomingInsolvencyRequest("01234567", "companyName", constants.CVL.String())
-			err, statusCode, companyProfile := CheckCompanyExists(request, &http.Request{})
+			companyProfile, err := sdkClients.CheckCompanyExists(request, &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusTeapot)
 			So(err.Error(), ShouldEqual, `error communicating with the company profile api`)
 			So(companyProfile, ShouldBeNil)
 		})
@@ -117,7 +116,7 @@ func TestUnitGetCompanyIncorporatedOn(t *testing.T) {
 			defer httpmock.Reset()
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/01234567", httpmock.NewStringResponder(http.StatusNotFound, "Message: Company not found"))
 
-			date, err := GetCompanyIncorporatedOn(companyNumber, &http.Request{})
+			date, err := sdkClients.GetCompanyIncorporatedOn(companyNumber, &http.Request{})
 			So(date, ShouldBeEmpty)
 			So(err.Error(), ShouldEqual, `company not found`)
 		})
@@ -126,7 +125,7 @@ func TestUnitGetCompanyIncorporatedOn(t *testing.T) {
 			defer httpmock.Reset()
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/01234567", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
-			date, err := GetCompanyIncorporatedOn(companyNumber, &http.Request{})
+			date, err := sdkClients.GetCompanyIncorporatedOn(companyNumber, &http.Request{})
 			So(date, ShouldBeEmpty)
 			So(err.Error(), ShouldEqual, `error communicating with the company profile api`)
 		})
@@ -135,7 +134,7 @@ func TestUnitGetCompanyIncorporatedOn(t *testing.T) {
 			defer httpmock.Reset()
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/01234567", httpmock.NewStringResponder(http.StatusOK, companyProfileResponse("england-wales", "active", "private-shares-exemption-30")))
 
-			date, err := GetCompanyIncorporatedOn(companyNumber, &http.Request{})
+			date, err := sdkClients.GetCompanyIncorporatedOn(companyNumber, &http.Request{})
 			So(date, ShouldEqual, "2000-06-26 00:00:00.000Z")
 			So(err, ShouldBeNil)
 		})
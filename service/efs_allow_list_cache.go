@@ -0,0 +1,180 @@
+package service
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/metrics"
+)
+
+const (
+	defaultEfsAllowListPositiveTTL = 5 * time.Minute
+	defaultEfsAllowListNegativeTTL = time.Minute
+	defaultEfsAllowListMaxStaleAge = time.Hour
+)
+
+var (
+	efsCache     atomic.Pointer[efsAllowListCache]
+	efsCacheOnce sync.Once
+)
+
+// efsAllowListCacheStats contains counts of the lookups made against the EFS allow list cache
+type efsAllowListCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	StaleHits uint64
+	Evictions uint64
+	Size      int
+}
+
+// efsAllowListEntry is a cached EFS allow list result for an email address
+type efsAllowListEntry struct {
+	email     string
+	allowed   bool
+	expiresAt time.Time
+}
+
+// efsAllowListCache is a bounded, least recently used cache of EFS allow list results, keyed by email address
+type efsAllowListCache struct {
+	mtx         sync.Mutex
+	entries     map[string]*list.Element
+	recent      *list.List
+	maxSize     int
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	maxStaleAge time.Duration
+	now         func() time.Time
+	stats       efsAllowListCacheStats
+}
+
+// newEfsAllowListCache creates a cache holding at most maxSize results, with separate TTLs for users who are
+// and are not on the allow list. Results that expired no more than maxStaleAge ago are used when the EFS api
+// cannot be called
+func newEfsAllowListCache(maxSize int, positiveTTL, negativeTTL, maxStaleAge time.Duration) *efsAllowListCache {
+	return &efsAllowListCache{
+		entries:     make(map[string]*list.Element),
+		recent:      list.New(),
+		maxSize:     maxSize,
+		positiveTTL: positiveTTL,
+		negativeTTL: negativeTTL,
+		maxStaleAge: maxStaleAge,
+		now:         time.Now,
+	}
+}
+
+// getEfsAllowListCache returns the cache configured for the service, or nil if caching is disabled
+func getEfsAllowListCache(cfg *config.Config) *efsAllowListCache {
+	efsCacheOnce.Do(func() {
+		if cfg.EfsAllowListCacheSize <= 0 {
+			return
+		}
+
+		positiveTTL := time.Duration(cfg.EfsAllowListCachePositiveTTL) * time.Second
+		if positiveTTL <= 0 {
+			positiveTTL = defaultEfsAllowListPositiveTTL
+		}
+		negativeTTL := time.Duration(cfg.EfsAllowListCacheNegativeTTL) * time.Second
+		if negativeTTL <= 0 {
+			negativeTTL = defaultEfsAllowListNegativeTTL
+		}
+		maxStaleAge := time.Duration(cfg.EfsAllowListCacheMaxStaleAge) * time.Second
+		if maxStaleAge <= 0 {
+			maxStaleAge = defaultEfsAllowListMaxStaleAge
+		}
+
+		efsCache.Store(newEfsAllowListCache(cfg.EfsAllowListCacheSize, positiveTTL, negativeTTL, maxStaleAge))
+	})
+
+	return efsCache.Load()
+}
+
+// Stats returns a copy of the current cache statistics
+func (c *efsAllowListCache) Stats() efsAllowListCacheStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stats := c.stats
+	stats.Size = c.recent.Len()
+	return stats
+}
+
+// lookup returns the allow list result for the email address, calling fetch if there is no unexpired result
+// cached. If fetch fails and a result that expired no more than the maximum stale age ago is still held, the
+// expired result is returned instead of the error
+func (c *efsAllowListCache) lookup(emailAddress string, fetch func() (bool, error)) (bool, error) {
+	key := strings.ToLower(emailAddress)
+
+	c.mtx.Lock()
+	entry, found := c.get(key)
+	now := c.now()
+	if found && now.Before(entry.expiresAt) {
+		c.stats.Hits++
+		c.mtx.Unlock()
+		metrics.EfsAllowListCacheLookup(metrics.EfsAllowListCacheHit)
+		return entry.allowed, nil
+	}
+	c.stats.Misses++
+	c.mtx.Unlock()
+	metrics.EfsAllowListCacheLookup(metrics.EfsAllowListCacheMiss)
+
+	// Call the EFS api without holding the lock so that slow lookups do not block other users
+	allowed, err := fetch()
+	if err != nil {
+		if found && now.Sub(entry.expiresAt) <= c.maxStaleAge {
+			c.mtx.Lock()
+			c.stats.StaleHits++
+			c.mtx.Unlock()
+			metrics.EfsAllowListCacheLookup(metrics.EfsAllowListCacheStaleHit)
+			log.Info("using expired EFS allow list result after error calling EFS api", log.Data{"error": err.Error(), "expired_at": entry.expiresAt})
+			return entry.allowed, nil
+		}
+		return false, err
+	}
+
+	c.mtx.Lock()
+	c.set(key, allowed)
+	c.mtx.Unlock()
+
+	return allowed, nil
+}
+
+// get returns the cached entry for the key and marks it as recently used. The caller must hold the lock
+func (c *efsAllowListCache) get(key string) (efsAllowListEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return efsAllowListEntry{}, false
+	}
+	c.recent.MoveToFront(element)
+	return *element.Value.(*efsAllowListEntry), true
+}
+
+// set stores the result for the key, evicting the least recently used entry if the cache is full. The caller
+// must hold the lock
+func (c *efsAllowListCache) set(key string, allowed bool) {
+	ttl := c.negativeTTL
+	if allowed {
+		ttl = c.positiveTTL
+	}
+	entry := &efsAllowListEntry{email: key, allowed: allowed, expiresAt: c.now().Add(ttl)}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+		return
+	}
+
+	if c.recent.Len() >= c.maxSize {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.entries, oldest.Value.(*efsAllowListEntry).email)
+		c.stats.Evictions++
+		metrics.EfsAllowListCacheEvicted()
+	}
+
+	c.entries[key] = c.recent.PushFront(entry)
+}
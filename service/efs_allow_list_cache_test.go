@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingFetch returns an EFS allow list fetch function that returns a fixed result and counts its calls
+func countingFetch(allowed bool, err error, calls *int) func() (bool, error) {
+	return func() (bool, error) {
+		*calls++
+		return allowed, err
+	}
+}
+
+func newTestEfsAllowListCache(maxSize int) (*efsAllowListCache, *time.Time) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newEfsAllowListCache(maxSize, 5*time.Minute, time.Minute, time.Hour)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestUnitEfsAllowListCache(t *testing.T) {
+	Convey("Result is cached until the TTL expires", t, func() {
+		cache, now := newTestEfsAllowListCache(10)
+		calls := 0
+
+		allowed, err := cache.lookup("demo@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(err, ShouldBeNil)
+		So(allowed, ShouldBeTrue)
+
+		allowed, err = cache.lookup("DEMO@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(err, ShouldBeNil)
+		So(allowed, ShouldBeTrue)
+		So(calls, ShouldEqual, 1)
+
+		*now = now.Add(5 * time.Minute)
+		_, _ = cache.lookup("demo@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(calls, ShouldEqual, 2)
+
+		stats := cache.Stats()
+		So(stats.Hits, ShouldEqual, 1)
+		So(stats.Misses, ShouldEqual, 2)
+	})
+
+	Convey("Negative results use a shorter TTL", t, func() {
+		cache, now := newTestEfsAllowListCache(10)
+		calls := 0
+
+		allowed, err := cache.lookup("demo@ch.gov.uk", countingFetch(false, nil, &calls))
+		So(err, ShouldBeNil)
+		So(allowed, ShouldBeFalse)
+
+		*now = now.Add(59 * time.Second)
+		_, _ = cache.lookup("demo@ch.gov.uk", countingFetch(false, nil, &calls))
+		So(calls, ShouldEqual, 1)
+
+		*now = now.Add(time.Second)
+		_, _ = cache.lookup("demo@ch.gov.uk", countingFetch(false, nil, &calls))
+		So(calls, ShouldEqual, 2)
+	})
+
+	Convey("Errors are not cached", t, func() {
+		cache, _ := newTestEfsAllowListCache(10)
+		calls := 0
+
+		allowed, err := cache.lookup("demo@ch.gov.uk", countingFetch(false, fmt.Errorf("efs unavailable"), &calls))
+		So(allowed, ShouldBeFalse)
+		So(err.Error(), ShouldEqual, "efs unavailable")
+
+		allowed, err = cache.lookup("demo@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(err, ShouldBeNil)
+		So(allowed, ShouldBeTrue)
+		So(calls, ShouldEqual, 2)
+	})
+
+	Convey("Expired result is used when the EFS api returns an error", t, func() {
+		cache, now := newTestEfsAllowListCache(10)
+		calls := 0
+
+		_, _ = cache.lookup("demo@ch.gov.uk", countingFetch(true, nil, &calls))
+		*now = now.Add(time.Hour)
+
+		allowed, err := cache.lookup("demo@ch.gov.uk", countingFetch(false, fmt.Errorf("efs unavailable"), &calls))
+		So(err, ShouldBeNil)
+		So(allowed, ShouldBeTrue)
+		So(cache.Stats().StaleHits, ShouldEqual, 1)
+	})
+
+	Convey("Result that expired more than the maximum stale age ago is not used", t, func() {
+		cache, now := newTestEfsAllowListCache(10)
+		calls := 0
+
+		_, _ = cache.lookup("demo@ch.gov.uk", countingFetch(true, nil, &calls))
+		*now = now.Add(5*time.Minute + time.Hour + time.Second)
+
+		allowed, err := cache.lookup("demo@ch.gov.uk", countingFetch(false, fmt.Errorf("efs unavailable"), &calls))
+		So(allowed, ShouldBeFalse)
+		So(err.Error(), ShouldEqual, "efs unavailable")
+		So(cache.Stats().StaleHits, ShouldEqual, 0)
+	})
+
+	Convey("Least recently used result is evicted when the cache is full", t, func() {
+		cache, _ := newTestEfsAllowListCache(2)
+		calls := 0
+
+		_, _ = cache.lookup("one@ch.gov.uk", countingFetch(true, nil, &calls))
+		_, _ = cache.lookup("two@ch.gov.uk", countingFetch(true, nil, &calls))
+		_, _ = cache.lookup("one@ch.gov.uk", countingFetch(true, nil, &calls))
+		_, _ = cache.lookup("three@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(calls, ShouldEqual, 3)
+
+		stats := cache.Stats()
+		So(stats.Size, ShouldEqual, 2)
+		So(stats.Evictions, ShouldEqual, 1)
+
+		_, _ = cache.lookup("one@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(calls, ShouldEqual, 3)
+
+		_, _ = cache.lookup("two@ch.gov.uk", countingFetch(true, nil, &calls))
+		So(calls, ShouldEqual, 4)
+	})
+}
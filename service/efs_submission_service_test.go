@@ -28,7 +28,7 @@ func TestUnitIsUserOnEfsAllowList(t *testing.T) {
 				httpmock.NewStringResponder(http.StatusInternalServerError, ""),
 			)
 
-			userAllowed, err := IsUserOnEfsAllowList("demo@ch.gov.uk", req)
+			userAllowed, err := sdkClients.IsUserOnEfsAllowList("demo@ch.gov.uk", req)
 			So(userAllowed, ShouldBeFalse)
 			So(err.Error(), ShouldEqual, "error communicating with the EFS submission api: [ch-api: got HTTP response code 500 with body: ]")
 		})
@@ -43,7 +43,7 @@ func TestUnitIsUserOnEfsAllowList(t *testing.T) {
 				httpmock.NewStringResponder(http.StatusOK, "true"),
 			)
 
-			userAllowed, err := IsUserOnEfsAllowList("demo@ch.gov.uk", req)
+			userAllowed, err := sdkClients.IsUserOnEfsAllowList("demo@ch.gov.uk", req)
 			So(userAllowed, ShouldBeTrue)
 			So(err, ShouldBeNil)
 		})
@@ -58,7 +58,7 @@ func TestUnitIsUserOnEfsAllowList(t *testing.T) {
 				httpmock.NewStringResponder(http.StatusOK, "false"),
 			)
 
-			userAllowed, err := IsUserOnEfsAllowList("demo@ch.gov.uk", req)
+			userAllowed, err := sdkClients.IsUserOnEfsAllowList("demo@ch.gov.uk", req)
 			So(userAllowed, ShouldBeFalse)
 			So(err, ShouldBeNil)
 		})
@@ -77,7 +77,7 @@ func TestUnitIsUserOnEfsAllowList(t *testing.T) {
 
 			defer httpmock.Reset()
 
-			userAllowed, err := IsUserOnEfsAllowList("demo-ip-test@ch.gov.uk", req)
+			userAllowed, err := sdkClients.IsUserOnEfsAllowList("demo-ip-test@ch.gov.uk", req)
 			So(userAllowed, ShouldBeTrue)
 			So(err, ShouldBeNil)
 		})
@@ -92,7 +92,7 @@ func TestUnitIsUserOnEfsAllowList(t *testing.T) {
 				httpmock.NewStringResponder(http.StatusOK, "true"),
 			)
 
-			userAllowed, err := IsUserOnEfsAllowList("demo-test@ch.gov.uk", req)
+			userAllowed, err := sdkClients.IsUserOnEfsAllowList("demo-test@ch.gov.uk", req)
 			So(userAllowed, ShouldBeFalse)
 			So(err, ShouldBeNil)
 		})
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/google/uuid"
+)
+
+// FakeCompanyProfileClient is a CompanyProfileClient that returns the companies it holds, by company number. A
+// company that it does not hold is not found, and every call fails with Err when it is set
+type FakeCompanyProfileClient struct {
+	mtx       sync.RWMutex
+	companies map[string]companieshouseapi.CompanyProfile
+	Err       error
+}
+
+// NewFakeCompanyProfileClient returns a FakeCompanyProfileClient holding the companies
+func NewFakeCompanyProfileClient(companies ...companieshouseapi.CompanyProfile) *FakeCompanyProfileClient {
+	c := &FakeCompanyProfileClient{companies: make(map[string]companieshouseapi.CompanyProfile)}
+	for _, company := range companies {
+		c.AddCompany(company)
+	}
+	return c
+}
+
+// AddCompany adds the company, replacing any company with the same company number
+func (c *FakeCompanyProfileClient) AddCompany(company companieshouseapi.CompanyProfile) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.companies[company.CompanyNumber] = company
+}
+
+// GetCompanyProfile returns the company with the company number
+func (c *FakeCompanyProfileClient) GetCompanyProfile(req *http.Request, companyNumber string) (*companieshouseapi.CompanyProfile, int, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.Err != nil {
+		return nil, http.StatusInternalServerError, c.Err
+	}
+
+	company, ok := c.companies[companyNumber]
+	if !ok {
+		return nil, http.StatusNotFound, fmt.Errorf("company [%s] not found", companyNumber)
+	}
+
+	company.HTTPStatusCode = http.StatusOK
+	return &company, http.StatusOK, nil
+}
+
+// FakeTransactionClient is a TransactionClient that holds the status of each transaction and the insolvency cases
+// they have been patched with. A transaction that it does not hold is not found, and every call fails with Err
+// when it is set
+type FakeTransactionClient struct {
+	mtx          sync.RWMutex
+	transactions map[string]string
+	patched      map[string]models.InsolvencyResourceDao
+	Err          error
+}
+
+// NewFakeTransactionClient returns a FakeTransactionClient holding open transactions with the IDs
+func NewFakeTransactionClient(transactionIDs ...string) *FakeTransactionClient {
+	c := &FakeTransactionClient{
+		transactions: make(map[string]string),
+		patched:      make(map[string]models.InsolvencyResourceDao),
+	}
+	for _, transactionID := range transactionIDs {
+		c.SetTransactionStatus(transactionID, "open")
+	}
+	return c
+}
+
+// SetTransactionStatus adds the transaction or changes its status
+func (c *FakeTransactionClient) SetTransactionStatus(transactionID string, status string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.transactions[transactionID] = status
+}
+
+// GetTransactionStatus returns the status of the transaction
+func (c *FakeTransactionClient) GetTransactionStatus(req *http.Request, transactionID string) (string, int, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.Err != nil {
+		return "", http.StatusInternalServerError, c.Err
+	}
+
+	status, ok := c.transactions[transactionID]
+	if !ok {
+		return "", http.StatusNotFound, fmt.Errorf("transaction [%s] not found", transactionID)
+	}
+
+	return status, http.StatusOK, nil
+}
+
+// PatchTransaction records the insolvency case against the transaction
+func (c *FakeTransactionClient) PatchTransaction(req *http.Request, transactionID string, insolvencyResource *models.InsolvencyResourceDao) (int, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.Err != nil {
+		return http.StatusInternalServerError, c.Err
+	}
+
+	if _, ok := c.transactions[transactionID]; !ok {
+		return http.StatusNotFound, fmt.Errorf("transaction [%s] not found", transactionID)
+	}
+
+	c.patched[transactionID] = *insolvencyResource
+	return http.StatusOK, nil
+}
+
+// PatchedInsolvencyResource returns the insolvency case that the transaction was patched with, if it has been
+func (c *FakeTransactionClient) PatchedInsolvencyResource(transactionID string) (models.InsolvencyResourceDao, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	insolvencyResource, ok := c.patched[transactionID]
+	return insolvencyResource, ok
+}
+
+// FakeAlphaKeyClient is an AlphaKeyClient that treats company names as the same when they have the same letters
+// and digits, ignoring case. Every call fails with Err when it is set
+type FakeAlphaKeyClient struct {
+	Err error
+}
+
+// GetSameAsAlphaKey returns the letters and digits of the company name in upper case
+func (c *FakeAlphaKeyClient) GetSameAsAlphaKey(req *http.Request, companyName string) (string, error) {
+	if c.Err != nil {
+		return "", c.Err
+	}
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, companyName), nil
+}
+
+// FakeEfsClient is an EfsClient that allows the email addresses it holds. Every call fails with Err when it is set
+type FakeEfsClient struct {
+	mtx     sync.RWMutex
+	allowed map[string]bool
+	Err     error
+}
+
+// NewFakeEfsClient returns a FakeEfsClient that allows the email addresses
+func NewFakeEfsClient(emailAddresses ...string) *FakeEfsClient {
+	c := &FakeEfsClient{allowed: make(map[string]bool)}
+	for _, emailAddress := range emailAddresses {
+		c.Allow(emailAddress)
+	}
+	return c
+}
+
+// Allow adds the email address to the allow list
+func (c *FakeEfsClient) Allow(emailAddress string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.allowed[emailAddress] = true
+}
+
+// IsUserOnAllowList returns whether the email address has been allowed
+func (c *FakeEfsClient) IsUserOnAllowList(req *http.Request, emailAddress string) (bool, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.Err != nil {
+		return false, c.Err
+	}
+
+	return c.allowed[emailAddress], nil
+}
+
+// fakeFile is a file held by the FakeFileTransferClient
+type fakeFile struct {
+	details  models.AttachmentFile
+	contents []byte
+}
+
+// FakeFileTransferClient is a FileTransferClient that holds uploaded files in memory. Uploaded files are given
+// the AVStatus, which is clean when it is not set. Every call fails with Err when it is set
+type FakeFileTransferClient struct {
+	mtx      sync.RWMutex
+	files    map[string]fakeFile
+	AVStatus string
+	Err      error
+}
+
+// NewFakeFileTransferClient returns a FakeFileTransferClient holding no files
+func NewFakeFileTransferClient() *FakeFileTransferClient {
+	return &FakeFileTransferClient{files: make(map[string]fakeFile)}
+}
+
+// SetAVStatus changes the anti-virus status of the file, returning false if the file does not exist
+func (c *FakeFileTransferClient) SetAVStatus(fileID string, avStatus string) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	file, ok := c.files[fileID]
+	if !ok {
+		return false
+	}
+	file.details.AVStatus = avStatus
+	c.files[fileID] = file
+	return true
+}
+
+// UploadFile stores the file under a new ID
+func (c *FakeFileTransferClient) UploadFile(req *http.Request, file multipart.File, header *multipart.FileHeader) (string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.Err != nil {
+		return "", c.Err
+	}
+
+	contents, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: [%v]", err)
+	}
+
+	avStatus := c.AVStatus
+	if avStatus == "" {
+		avStatus = "clean"
+	}
+
+	fileID := uuid.NewString()
+	c.files[fileID] = fakeFile{
+		details: models.AttachmentFile{
+			Name:        header.Filename,
+			Size:        int64(len(contents)),
+			ContentType: header.Header.Get("Content-Type"),
+			AVStatus:    avStatus,
+		},
+		contents: contents,
+	}
+
+	return fileID, nil
+}
+
+// GetFile returns the details of the file, or empty details if it does not exist
+func (c *FakeFileTransferClient) GetFile(req *http.Request, fileID string) (models.AttachmentFile, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.Err != nil {
+		return models.AttachmentFile{}, c.Err
+	}
+
+	return c.files[fileID].details, nil
+}
+
+// DownloadFile writes the contents of the file
+func (c *FakeFileTransferClient) DownloadFile(req *http.Request, fileID string, w http.ResponseWriter) error {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	if c.Err != nil {
+		return c.Err
+	}
+
+	file, ok := c.files[fileID]
+	if !ok {
+		return fmt.Errorf("error downloading file: file [%s] not found", fileID)
+	}
+
+	w.Header().Set("Content-Type", file.details.ContentType)
+	_, err := w.Write(file.contents)
+	return err
+}
+
+// DeleteFile deletes the file
+func (c *FakeFileTransferClient) DeleteFile(req *http.Request, fileID string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.Err != nil {
+		return c.Err
+	}
+
+	if _, ok := c.files[fileID]; !ok {
+		return fmt.Errorf("error deleting file: file [%s] not found", fileID)
+	}
+
+	delete(c.files, fileID)
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// FirmMembership describes a lookup of the insolvency firm or organisation that a user belongs to
+type FirmMembership interface {
+	// GetFirmID returns the ID of the firm the user with the supplied email address belongs to, or an empty
+	// string if they are not a member of any firm
+	GetFirmID(email string) (string, error)
+}
+
+// FirmMember contains the details of a user held on the firm membership file
+type FirmMember struct {
+	Email  string `json:"email"`
+	FirmID string `json:"firm_id"`
+}
+
+// FileFirmMembership is an implementation of the FirmMembership interface backed by a CSV or JSON
+// file which is loaded once when the membership is created
+type FileFirmMembership struct {
+	firms map[string]string
+}
+
+// NewFileFirmMembership loads firm membership from the file at the supplied path.
+// Files with a .json extension must contain an array of members, any other file is read as
+// CSV with the header email,firm_id
+func NewFileFirmMembership(path string) (*FileFirmMembership, error) {
+	members, err := loadRecordFile(path, "firm membership", []string{"email", "firm_id"}, parseFirmMember)
+	if err != nil {
+		return nil, err
+	}
+
+	membership := &FileFirmMembership{
+		firms: make(map[string]string, len(members)),
+	}
+	for _, member := range members {
+		membership.firms[strings.ToLower(strings.TrimSpace(member.Email))] = strings.TrimSpace(member.FirmID)
+	}
+
+	return membership, nil
+}
+
+// GetFirmID returns the firm ID of the member with the supplied email address, ignoring case
+func (f *FileFirmMembership) GetFirmID(email string) (string, error) {
+	return f.firms[strings.ToLower(strings.TrimSpace(email))], nil
+}
+
+// parseFirmMember reads a member from a row of the firm membership CSV file
+func parseFirmMember(row csvRow, line int) (FirmMember, error) {
+	return FirmMember{Email: row("email"), FirmID: row("firm_id")}, nil
+}
+
+// GetUserFirmID returns the firm ID of the supplied user, or an empty string if there is no firm
+// membership configured or the user is not a member of a firm
+func GetUserFirmID(firms FirmMembership, userDetails authentication.AuthUserDetails) (string, error) {
+	if firms == nil || userDetails.Email == "" {
+		return "", nil
+	}
+
+	firmID, err := firms.GetFirmID(userDetails.Email)
+	if err != nil {
+		return "", fmt.Errorf("error checking firm membership for user [%s]: [%v]", userDetails.ID, err)
+	}
+
+	return firmID, nil
+}
+
+// CanUserAccessCase checks whether the supplied user may read or modify the insolvency case. Access is granted
+// to the user that created the case and to members of the same firm. Cases created before ownership was
+// recorded have no owner, so are accessible to every user until denyUnowned is set, after which only
+// administrators can access them
+func CanUserAccessCase(firms FirmMembership, insolvencyResource models.InsolvencyResourceDao, userDetails authentication.AuthUserDetails, denyUnowned bool, req *http.Request) (bool, error) {
+	if insolvencyResource.CreatedBy == "" {
+		log.InfoR(req, fmt.Sprintf("insolvency case for transaction [%s] has no recorded owner", insolvencyResource.TransactionID), log.Data{"deny_unowned": denyUnowned})
+		return !denyUnowned, nil
+	}
+
+	if userDetails.ID != "" && insolvencyResource.CreatedBy == userDetails.ID {
+		return true, nil
+	}
+
+	if insolvencyResource.FirmID == "" {
+		return false, nil
+	}
+
+	firmID, err := GetUserFirmID(firms, userDetails)
+	if err != nil {
+		return false, err
+	}
+
+	if firmID == insolvencyResource.FirmID {
+		log.InfoR(req, fmt.Sprintf("user [%s] granted access to insolvency case for transaction [%s] as a member of firm [%s]", userDetails.ID, insolvencyResource.TransactionID, firmID))
+		return true, nil
+	}
+
+	return false, nil
+}
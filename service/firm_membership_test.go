@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/companieshouse/chs.go/authentication"
+	"github.com/companieshouse/insolvency-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const firmMembershipCSV = `email,firm_id
+joe@bloggs.com,firm1
+jane@doe.com,firm2
+`
+
+const firmMembershipJSON = `[
+	{"email": "joe@bloggs.com", "firm_id": "firm1"},
+	{"email": "jane@doe.com", "firm_id": "firm2"}
+]`
+
+// stubFirmMembership is a FirmMembership that returns firm IDs from a map, or a fixed error
+type stubFirmMembership struct {
+	firms map[string]string
+	err   error
+}
+
+func (s *stubFirmMembership) GetFirmID(email string) (string, error) {
+	return s.firms[email], s.err
+}
+
+func TestUnitNewFileFirmMembership(t *testing.T) {
+	Convey("Load firm membership from a CSV file", t, func() {
+		firms, err := NewFileFirmMembership(writeRegisterFile(t, "firms.csv", firmMembershipCSV))
+		So(err, ShouldBeNil)
+
+		firmID, err := firms.GetFirmID("JOE@bloggs.com")
+		So(err, ShouldBeNil)
+		So(firmID, ShouldEqual, "firm1")
+	})
+
+	Convey("Load firm membership from a JSON file", t, func() {
+		firms, err := NewFileFirmMembership(writeRegisterFile(t, "firms.json", firmMembershipJSON))
+		So(err, ShouldBeNil)
+
+		firmID, err := firms.GetFirmID("jane@doe.com")
+		So(err, ShouldBeNil)
+		So(firmID, ShouldEqual, "firm2")
+	})
+
+	Convey("User is not a member of a firm", t, func() {
+		firms, err := NewFileFirmMembership(writeRegisterFile(t, "firms.csv", firmMembershipCSV))
+		So(err, ShouldBeNil)
+
+		firmID, err := firms.GetFirmID("someone@else.com")
+		So(err, ShouldBeNil)
+		So(firmID, ShouldBeBlank)
+	})
+
+	Convey("Firm membership file does not exist", t, func() {
+		firms, err := NewFileFirmMembership(filepath.Join(t.TempDir(), "missing.csv"))
+		So(firms, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "error opening firm membership file")
+	})
+
+	Convey("Firm membership CSV file is missing a column", t, func() {
+		firms, err := NewFileFirmMembership(writeRegisterFile(t, "firms.csv", "email\njoe@bloggs.com\n"))
+		So(firms, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "column [firm_id] not found in header row")
+	})
+}
+
+func TestUnitCanUserAccessCase(t *testing.T) {
+	user := authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"}
+
+	Convey("Case has no recorded owner", t, func() {
+		canAccess, err := CanUserAccessCase(nil, models.InsolvencyResourceDao{}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeFalse)
+	})
+
+	Convey("Case has no recorded owner before ownerless cases are denied", t, func() {
+		canAccess, err := CanUserAccessCase(nil, models.InsolvencyResourceDao{}, user, false, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeTrue)
+	})
+
+	Convey("User created the case", t, func() {
+		canAccess, err := CanUserAccessCase(nil, models.InsolvencyResourceDao{CreatedBy: "user1234"}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeTrue)
+	})
+
+	Convey("Case created by another user with no firm", t, func() {
+		firms := &stubFirmMembership{firms: map[string]string{"joe@bloggs.com": "firm1"}}
+
+		canAccess, err := CanUserAccessCase(firms, models.InsolvencyResourceDao{CreatedBy: "user5678"}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeFalse)
+	})
+
+	Convey("Case created by a member of the same firm", t, func() {
+		firms := &stubFirmMembership{firms: map[string]string{"joe@bloggs.com": "firm1"}}
+
+		canAccess, err := CanUserAccessCase(firms, models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm1"}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeTrue)
+	})
+
+	Convey("Case created by a member of a different firm", t, func() {
+		firms := &stubFirmMembership{firms: map[string]string{"joe@bloggs.com": "firm1"}}
+
+		canAccess, err := CanUserAccessCase(firms, models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm2"}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeFalse)
+	})
+
+	Convey("No firm membership configured", t, func() {
+		canAccess, err := CanUserAccessCase(nil, models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm1"}, user, true, req)
+		So(err, ShouldBeNil)
+		So(canAccess, ShouldBeFalse)
+	})
+
+	Convey("Error checking firm membership", t, func() {
+		firms := &stubFirmMembership{err: fmt.Errorf("membership unavailable")}
+
+		canAccess, err := CanUserAccessCase(firms, models.InsolvencyResourceDao{CreatedBy: "user5678", FirmID: "firm1"}, user, true, req)
+		So(canAccess, ShouldBeFalse)
+		So(err.Error(), ShouldEqual, "error checking firm membership for user [user1234]: [membership unavailable]")
+	})
+}
@@ -1,13 +1,17 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/dao"
+	"github.com/companieshouse/insolvency-api/metrics"
 	"github.com/companieshouse/insolvency-api/models"
 )
 
@@ -229,14 +233,44 @@ func checkValidStatementOfAffairsDate(statementOfAffairsDate string, resolutionD
 	return true, "", "", nil
 }
 
-// addValidationError adds any validation errors to an array of existing errors
+// addValidationError adds any validation errors to an array of existing errors. The failures are counted once per case
+// by the handler, as the validation status of a case can be checked any number of times
 func addValidationError(validationErrors []models.ValidationErrorResponseResource, validationError, errorLocation string) []models.ValidationErrorResponseResource {
 	return append(validationErrors, *models.NewValidationErrorResponse(validationError, errorLocation))
 }
 
+// validationFailure counts a failure of the validation rule and returns the error to report against the field of the
+// request body that failed it
+func validationFailure(rule, field, message string) models.FieldError {
+	metrics.ValidationFailed(rule)
+	return models.FieldError{Field: field, Message: message}
+}
+
+// ValidateUserIsPractitioner checks that the authenticated user is one of the practitioners on an insolvency case,
+// either by their email address or by the user ID that the practitioner register links to their IP code
+func ValidateUserIsPractitioner(insolvencyResource models.InsolvencyResourceDao, userDetails authentication.AuthUserDetails) *[]models.ValidationErrorResponseResource {
+
+	validationErrors := make([]models.ValidationErrorResponseResource, 0)
+
+	for _, practitioner := range insolvencyResource.Data.Practitioners {
+		if userDetails.Email != "" && strings.EqualFold(practitioner.Email, userDetails.Email) {
+			return &validationErrors
+		}
+		if userDetails.ID != "" && practitioner.UserID == userDetails.ID {
+			return &validationErrors
+		}
+	}
+
+	validationError := fmt.Sprintf("error - the authenticated user must be a practitioner on insolvency case with transaction id [%s]", insolvencyResource.TransactionID)
+	log.Info(fmt.Sprintf(validationMessageFormat, insolvencyResource.ID, validationError))
+	validationErrors = addValidationError(validationErrors, validationError, "practitioners")
+
+	return &validationErrors
+}
+
 // ValidateAntivirus checks that attachments on an insolvency case pass the antivirus check and are ready for submission
 // Any validation errors found are added to an array to be returned
-func ValidateAntivirus(svc dao.Service, insolvencyResource models.InsolvencyResourceDao, req *http.Request) *[]models.ValidationErrorResponseResource {
+func ValidateAntivirus(svc dao.Service, clients Clients, insolvencyResource models.InsolvencyResourceDao, req *http.Request) *[]models.ValidationErrorResponseResource {
 
 	validationErrors := make([]models.ValidationErrorResponseResource, 0)
 
@@ -247,19 +281,19 @@ func ValidateAntivirus(svc dao.Service, insolvencyResource models.InsolvencyReso
 		// Check the antivirus status of each attachment type and update with the appropriate status in mongodb
 		for _, attachment := range insolvencyResource.Data.Attachments {
 			// Calls File Transfer API to get attachment details
-			attachmentDetailsResponse, responseType, err := GetAttachmentDetails(attachment.ID, req)
+			attachmentDetailsResponse, responseType, err := clients.GetAttachmentDetails(attachment.ID, req)
 			if err != nil {
 				log.ErrorR(req, fmt.Errorf("error getting attachment details for attachment ID [%s]: [%v]", attachment.ID, err), log.Data{"service_response_type": responseType.String()})
 			}
 
 			// If antivirus check has not passed, update insolvency resource with "integrity_failed" status
 			if attachmentDetailsResponse.AVStatus != "clean" {
-				svc.UpdateAttachmentStatus(insolvencyResource.TransactionID, attachment.ID, "integrity_failed")
+				svc.UpdateAttachmentStatus(req.Context(), insolvencyResource.TransactionID, attachment.ID, "integrity_failed")
 				avStatuses[attachmentDetailsResponse.AVStatus] = struct{}{}
 				continue
 			}
 			// If antivirus has passed, update insolvency resource with "processed" status
-			svc.UpdateAttachmentStatus(insolvencyResource.TransactionID, attachment.ID, "processed")
+			svc.UpdateAttachmentStatus(req.Context(), insolvencyResource.TransactionID, attachment.ID, "processed")
 			avStatuses[attachmentDetailsResponse.AVStatus] = struct{}{}
 		}
 		// Check avStatuses map to see if status "not-scanned" exists
@@ -282,12 +316,12 @@ func ValidateAntivirus(svc dao.Service, insolvencyResource models.InsolvencyReso
 }
 
 // GenerateFilings generates an array of filings for this insolvency resource to be used by the filing resource handler
-func GenerateFilings(svc dao.Service, transactionID string) ([]models.Filing, error) {
+func GenerateFilings(ctx context.Context, svc dao.Service, transactionID string) ([]models.Filing, error) {
 
 	// Retrieve details for the insolvency resource from DB
-	insolvencyResource, err := svc.GetInsolvencyResource(transactionID)
+	insolvencyResource, err := svc.GetInsolvencyResource(ctx, transactionID)
 	if err != nil {
-		message := fmt.Errorf("error getting insolvency resource from DB [%s]", err)
+		message := fmt.Errorf("error getting insolvency resource from DB [%w]", err)
 		return nil, message
 	}
 
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/companieshouse/chs.go/authentication"
 	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
@@ -748,9 +750,9 @@ func TestUnitValidateAntivirus(t *testing.T) {
 		// Expect GetAttachmentDetails to be called once and return the attachment
 		httpmock.RegisterResponder(http.MethodGet, `=~.*`, httpmock.NewStringResponder(http.StatusOK, attachment))
 
-		mockService.EXPECT().UpdateAttachmentStatus(transactionID, insolvencyCase.Data.Attachments[0].ID, "integrity_failed").Return(http.StatusNoContent, nil).Times(3)
+		mockService.EXPECT().UpdateAttachmentStatus(gomock.Any(), transactionID, insolvencyCase.Data.Attachments[0].ID, "integrity_failed").Return(nil).Times(3)
 
-		validationErrors := ValidateAntivirus(mockService, insolvencyCase, req)
+		validationErrors := ValidateAntivirus(mockService, sdkClients, insolvencyCase, req)
 
 		So(validationErrors, ShouldHaveLength, 1)
 		So((*validationErrors)[0].Error, ShouldContainSubstring, fmt.Sprintf("error - antivirus check has failed on insolvency case with transaction id [%s], attachments have not been scanned", insolvencyCase.TransactionID))
@@ -774,9 +776,9 @@ func TestUnitValidateAntivirus(t *testing.T) {
 		// Expect GetAttachmentDetails to be called once and return the attachment
 		httpmock.RegisterResponder(http.MethodGet, `=~.*`, httpmock.NewStringResponder(http.StatusOK, attachment))
 
-		mockService.EXPECT().UpdateAttachmentStatus(transactionID, insolvencyCase.Data.Attachments[0].ID, "integrity_failed").Return(http.StatusNoContent, nil).Times(3)
+		mockService.EXPECT().UpdateAttachmentStatus(gomock.Any(), transactionID, insolvencyCase.Data.Attachments[0].ID, "integrity_failed").Return(nil).Times(3)
 
-		validationErrors := ValidateAntivirus(mockService, insolvencyCase, req)
+		validationErrors := ValidateAntivirus(mockService, sdkClients, insolvencyCase, req)
 
 		So(validationErrors, ShouldHaveLength, 1)
 		So((*validationErrors)[0].Error, ShouldContainSubstring, fmt.Sprintf("error - antivirus check has failed on insolvency case with transaction id [%s], virus detected", insolvencyCase.TransactionID))
@@ -800,9 +802,53 @@ func TestUnitValidateAntivirus(t *testing.T) {
 		// Expect GetAttachmentDetails to be called once and return the attachment
 		httpmock.RegisterResponder(http.MethodGet, `=~.*`, httpmock.NewStringResponder(http.StatusOK, attachment))
 
-		mockService.EXPECT().UpdateAttachmentStatus(transactionID, insolvencyCase.Data.Attachments[0].ID, "processed").Return(http.StatusNoContent, nil).Times(3)
+		mockService.EXPECT().UpdateAttachmentStatus(gomock.Any(), transactionID, insolvencyCase.Data.Attachments[0].ID, "processed").Return(nil).Times(3)
 
-		validationErrors := ValidateAntivirus(mockService, insolvencyCase, req)
+		validationErrors := ValidateAntivirus(mockService, sdkClients, insolvencyCase, req)
+		So(validationErrors, ShouldHaveLength, 0)
+	})
+}
+
+func TestUnitValidateUserIsPractitioner(t *testing.T) {
+	Convey("Case has no practitioners", t, func() {
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners = nil
+
+		validationErrors := ValidateUserIsPractitioner(insolvencyCase, authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"})
+		So(validationErrors, ShouldHaveLength, 1)
+		So((*validationErrors)[0].Error, ShouldContainSubstring, fmt.Sprintf("error - the authenticated user must be a practitioner on insolvency case with transaction id [%s]", transactionID))
+		So((*validationErrors)[0].Location, ShouldContainSubstring, "practitioners")
+	})
+
+	Convey("Authenticated user is not a practitioner on the case", t, func() {
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners = []models.PractitionerResourceDao{{Email: "jane@doe.com", UserID: "user5678"}}
+
+		validationErrors := ValidateUserIsPractitioner(insolvencyCase, authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"})
+		So(validationErrors, ShouldHaveLength, 1)
+	})
+
+	Convey("No user details are available", t, func() {
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners = []models.PractitionerResourceDao{{}}
+
+		validationErrors := ValidateUserIsPractitioner(insolvencyCase, authentication.AuthUserDetails{})
+		So(validationErrors, ShouldHaveLength, 1)
+	})
+
+	Convey("Practitioner email matches the authenticated user ignoring case", t, func() {
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners = []models.PractitionerResourceDao{{Email: "jane@doe.com"}, {Email: "Joe@Bloggs.com"}}
+
+		validationErrors := ValidateUserIsPractitioner(insolvencyCase, authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"})
+		So(validationErrors, ShouldHaveLength, 0)
+	})
+
+	Convey("Practitioner is linked to the authenticated user ID", t, func() {
+		insolvencyCase := createInsolvencyResource()
+		insolvencyCase.Data.Practitioners = []models.PractitionerResourceDao{{Email: "old@bloggs.com", UserID: "user1234"}}
+
+		validationErrors := ValidateUserIsPractitioner(insolvencyCase, authentication.AuthUserDetails{Email: "joe@bloggs.com", ID: "user1234"})
 		So(validationErrors, ShouldHaveLength, 0)
 	})
 }
@@ -823,9 +869,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		mockService := mocks.NewMockService(mockCtrl)
 
 		// Expect GetInsolvencyResource to be called once and return an error for the insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(createInsolvencyResource(), errors.New("insolvency case does not exist")).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(createInsolvencyResource(), errors.New("insolvency case does not exist")).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(filings, ShouldBeNil)
 		So(err.Error(), ShouldContainSubstring, "insolvency case does not exist")
@@ -843,9 +889,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		insolvencyResource.Data.Attachments = []models.AttachmentResourceDao{}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -880,9 +926,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -919,9 +965,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -958,9 +1004,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -1006,9 +1052,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -1044,9 +1090,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 2)
 
@@ -1104,9 +1150,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 3)
 
@@ -1166,9 +1212,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 1)
 
@@ -1220,9 +1266,9 @@ func TestUnitGenerateFilings(t *testing.T) {
 		}
 
 		// Expect GetInsolvencyResource to be called once and return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(insolvencyResource, nil).Times(1)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(insolvencyResource, nil).Times(1)
 
-		filings, err := GenerateFilings(mockService, transactionID)
+		filings, err := GenerateFilings(context.Background(), mockService, transactionID)
 
 		So(len(filings), ShouldEqual, 2)
 
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// PractitionerRegister describes a register of insolvency practitioners that IP codes can be checked against
+type PractitionerRegister interface {
+	// GetPractitioner returns the registered practitioner with the supplied IP code, or nil if there is none
+	GetPractitioner(ipCode string) (*RegisteredPractitioner, error)
+}
+
+// RegisteredPractitioner contains the details of a practitioner held on the practitioner register. UserID is
+// the optional ID of the Companies House user account held by the practitioner
+type RegisteredPractitioner struct {
+	IPCode     string `json:"ip_code"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Authorised bool   `json:"authorised"`
+	UserID     string `json:"user_id"`
+}
+
+// FilePractitionerRegister is an implementation of the PractitionerRegister interface backed by a
+// CSV or JSON file which is loaded once when the register is created
+type FilePractitionerRegister struct {
+	practitioners map[string]RegisteredPractitioner
+}
+
+// NewFilePractitionerRegister loads the practitioner register from the file at the supplied path.
+// Files with a .json extension must contain an array of practitioners, any other file is read as
+// CSV with the header ip_code,first_name,last_name,authorised and an optional user_id column
+func NewFilePractitionerRegister(path string) (*FilePractitionerRegister, error) {
+	practitioners, err := loadRecordFile(path, "practitioner register", []string{"ip_code", "first_name", "last_name", "authorised"}, parseRegisteredPractitioner)
+	if err != nil {
+		return nil, err
+	}
+
+	register := &FilePractitionerRegister{
+		practitioners: make(map[string]RegisteredPractitioner, len(practitioners)),
+	}
+	for _, practitioner := range practitioners {
+		register.practitioners[practitioner.IPCode] = practitioner
+	}
+
+	return register, nil
+}
+
+// GetPractitioner returns the practitioner with the supplied IP code from the loaded register
+func (r *FilePractitionerRegister) GetPractitioner(ipCode string) (*RegisteredPractitioner, error) {
+	practitioner, ok := r.practitioners[ipCode]
+	if !ok {
+		return nil, nil
+	}
+
+	return &practitioner, nil
+}
+
+// parseRegisteredPractitioner reads a practitioner from a row of the practitioner register CSV file
+func parseRegisteredPractitioner(row csvRow, line int) (RegisteredPractitioner, error) {
+	authorised, err := strconv.ParseBool(row("authorised"))
+	if err != nil {
+		return RegisteredPractitioner{}, fmt.Errorf("invalid authorised value on line %d: [%v]", line, err)
+	}
+
+	return RegisteredPractitioner{
+		IPCode:     row("ip_code"),
+		FirstName:  row("first_name"),
+		LastName:   row("last_name"),
+		Authorised: authorised,
+		UserID:     row("user_id"),
+	}, nil
+}
+
+// ValidatePractitionerAgainstRegister checks that the IP code of the incoming practitioner is on the practitioner
+// register, is currently authorised and belongs to a practitioner with the same name. No checks are made if
+// there is no register configured
+func ValidatePractitionerAgainstRegister(register PractitionerRegister, practitioner models.PractitionerRequest) ([]models.FieldError, error) {
+	if register == nil {
+		return nil, nil
+	}
+
+	registeredPractitioner, err := register.GetPractitioner(practitioner.IPCode)
+	if err != nil {
+		return nil, fmt.Errorf("error checking practitioner register for ip_code [%s]: [%v]", practitioner.IPCode, err)
+	}
+
+	if registeredPractitioner == nil {
+		return []models.FieldError{validationFailure("practitioner_not_on_register", "ip_code", fmt.Sprintf("ip_code [%s] was not found on the insolvency practitioner register", practitioner.IPCode))}, nil
+	}
+
+	var errs []models.FieldError
+
+	if !registeredPractitioner.Authorised {
+		errs = append(errs, validationFailure("practitioner_not_authorised", "ip_code", fmt.Sprintf("the practitioner with ip_code [%s] is not currently authorised", practitioner.IPCode)))
+	}
+
+	if !namesMatch(practitioner.FirstName, registeredPractitioner.FirstName) {
+		errs = append(errs, validationFailure("practitioner_name_mismatch", "first_name", fmt.Sprintf("first_name does not match the insolvency practitioner register for ip_code [%s]", practitioner.IPCode)))
+	}
+
+	if !namesMatch(practitioner.LastName, registeredPractitioner.LastName) {
+		errs = append(errs, validationFailure("practitioner_name_mismatch", "last_name", fmt.Sprintf("last_name does not match the insolvency practitioner register for ip_code [%s]", practitioner.IPCode)))
+	}
+
+	return errs, nil
+}
+
+// GetRegisteredUserID returns the ID of the user account that the practitioner register links to the supplied
+// IP code, or an empty string if there is no register configured or no user linked to the practitioner
+func GetRegisteredUserID(register PractitionerRegister, ipCode string) (string, error) {
+	if register == nil {
+		return "", nil
+	}
+
+	registeredPractitioner, err := register.GetPractitioner(ipCode)
+	if err != nil {
+		return "", fmt.Errorf("error checking practitioner register for ip_code [%s]: [%v]", ipCode, err)
+	}
+	if registeredPractitioner == nil {
+		return "", nil
+	}
+
+	return registeredPractitioner.UserID, nil
+}
+
+// namesMatch compares two names ignoring case and surrounding whitespace
+func namesMatch(supplied string, registered string) bool {
+	return strings.EqualFold(strings.TrimSpace(supplied), strings.TrimSpace(registered))
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const practitionerRegisterCSV = `ip_code,first_name,last_name,authorised
+1234,Joe,Bloggs,true
+5678,Jane,Doe,false
+`
+
+const practitionerRegisterJSON = `[
+	{"ip_code": "1234", "first_name": "Joe", "last_name": "Bloggs", "authorised": true},
+	{"ip_code": "5678", "first_name": "Jane", "last_name": "Doe", "authorised": false}
+]`
+
+// stubPractitionerRegister is a PractitionerRegister that returns a fixed practitioner or error
+type stubPractitionerRegister struct {
+	practitioner *RegisteredPractitioner
+	err          error
+}
+
+func (s *stubPractitionerRegister) GetPractitioner(ipCode string) (*RegisteredPractitioner, error) {
+	return s.practitioner, s.err
+}
+
+func writeRegisterFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("error writing practitioner register file: %v", err)
+	}
+	return path
+}
+
+func TestUnitNewFilePractitionerRegister(t *testing.T) {
+	Convey("Load practitioner register from a CSV file", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.csv", practitionerRegisterCSV))
+		So(err, ShouldBeNil)
+
+		practitioner, err := register.GetPractitioner("1234")
+		So(err, ShouldBeNil)
+		So(*practitioner, ShouldResemble, RegisteredPractitioner{IPCode: "1234", FirstName: "Joe", LastName: "Bloggs", Authorised: true})
+
+		practitioner, err = register.GetPractitioner("5678")
+		So(err, ShouldBeNil)
+		So(practitioner.Authorised, ShouldBeFalse)
+	})
+
+	Convey("Load practitioner register from a JSON file", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.json", practitionerRegisterJSON))
+		So(err, ShouldBeNil)
+
+		practitioner, err := register.GetPractitioner("1234")
+		So(err, ShouldBeNil)
+		So(*practitioner, ShouldResemble, RegisteredPractitioner{IPCode: "1234", FirstName: "Joe", LastName: "Bloggs", Authorised: true})
+	})
+
+	Convey("Load practitioner register with linked user accounts from a CSV file", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.csv", "ip_code,first_name,last_name,authorised,user_id\n1234,Joe,Bloggs,true,user1234\n5678,Jane,Doe,true,\n"))
+		So(err, ShouldBeNil)
+
+		practitioner, err := register.GetPractitioner("1234")
+		So(err, ShouldBeNil)
+		So(practitioner.UserID, ShouldEqual, "user1234")
+
+		practitioner, err = register.GetPractitioner("5678")
+		So(err, ShouldBeNil)
+		So(practitioner.UserID, ShouldBeBlank)
+	})
+
+	Convey("IP code not on the register", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.csv", practitionerRegisterCSV))
+		So(err, ShouldBeNil)
+
+		practitioner, err := register.GetPractitioner("9999")
+		So(err, ShouldBeNil)
+		So(practitioner, ShouldBeNil)
+	})
+
+	Convey("Practitioner register file does not exist", t, func() {
+		register, err := NewFilePractitionerRegister(filepath.Join(t.TempDir(), "missing.csv"))
+		So(register, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "error opening practitioner register file")
+	})
+
+	Convey("Practitioner register CSV file is missing a column", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.csv", "ip_code,first_name,last_name\n1234,Joe,Bloggs\n"))
+		So(register, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "column [authorised] not found in header row")
+	})
+
+	Convey("Practitioner register CSV file has an invalid authorised value", t, func() {
+		register, err := NewFilePractitionerRegister(writeRegisterFile(t, "register.csv", "ip_code,first_name,last_name,authorised\n1234,Joe,Bloggs,maybe\n"))
+		So(register, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "invalid authorised value on line 2")
+	})
+}
+
+func TestUnitValidatePractitionerAgainstRegister(t *testing.T) {
+	Convey("No practitioner register configured", t, func() {
+		validationErrs, err := ValidatePractitionerAgainstRegister(nil, generatePractitioner())
+		So(err, ShouldBeNil)
+		So(validationErrs, ShouldBeEmpty)
+	})
+
+	Convey("Error checking practitioner register", t, func() {
+		register := &stubPractitionerRegister{err: fmt.Errorf("register unavailable")}
+
+		validationErrs, err := ValidatePractitionerAgainstRegister(register, generatePractitioner())
+		So(validationErrs, ShouldBeEmpty)
+		So(err.Error(), ShouldEqual, "error checking practitioner register for ip_code [1234]: [register unavailable]")
+	})
+
+	Convey("IP code not on the practitioner register", t, func() {
+		register := &stubPractitionerRegister{}
+
+		validationErrs, err := ValidatePractitionerAgainstRegister(register, generatePractitioner())
+		So(err, ShouldBeNil)
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "ip_code", Message: "ip_code [1234] was not found on the insolvency practitioner register"}})
+	})
+
+	Convey("Practitioner is not currently authorised", t, func() {
+		register := &stubPractitionerRegister{practitioner: &RegisteredPractitioner{IPCode: "1234", FirstName: "Joe", LastName: "Bloggs"}}
+
+		validationErrs, err := ValidatePractitionerAgainstRegister(register, generatePractitioner())
+		So(err, ShouldBeNil)
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "ip_code", Message: "the practitioner with ip_code [1234] is not currently authorised"}})
+	})
+
+	Convey("Practitioner name does not match the register", t, func() {
+		register := &stubPractitionerRegister{practitioner: &RegisteredPractitioner{IPCode: "1234", FirstName: "Joe", LastName: "Smith", Authorised: true}}
+
+		validationErrs, err := ValidatePractitionerAgainstRegister(register, generatePractitioner())
+		So(err, ShouldBeNil)
+		So(validationErrs, ShouldResemble, []models.FieldError{{Field: "last_name", Message: "last_name does not match the insolvency practitioner register for ip_code [1234]"}})
+	})
+
+	Convey("Practitioner matches the register ignoring case", t, func() {
+		register := &stubPractitionerRegister{practitioner: &RegisteredPractitioner{IPCode: "1234", FirstName: "JOE", LastName: "bloggs", Authorised: true}}
+
+		validationErrs, err := ValidatePractitionerAgainstRegister(register, generatePractitioner())
+		So(err, ShouldBeNil)
+		So(validationErrs, ShouldBeEmpty)
+	})
+}
+
+func TestUnitGetRegisteredUserID(t *testing.T) {
+	Convey("No practitioner register configured", t, func() {
+		userID, err := GetRegisteredUserID(nil, "1234")
+		So(err, ShouldBeNil)
+		So(userID, ShouldBeBlank)
+	})
+
+	Convey("Error checking practitioner register", t, func() {
+		userID, err := GetRegisteredUserID(&stubPractitionerRegister{err: fmt.Errorf("register unavailable")}, "1234")
+		So(userID, ShouldBeBlank)
+		So(err.Error(), ShouldEqual, "error checking practitioner register for ip_code [1234]: [register unavailable]")
+	})
+
+	Convey("IP code not on the practitioner register", t, func() {
+		userID, err := GetRegisteredUserID(&stubPractitionerRegister{}, "1234")
+		So(err, ShouldBeNil)
+		So(userID, ShouldBeBlank)
+	})
+
+	Convey("Practitioner linked to a user account", t, func() {
+		register := &stubPractitionerRegister{practitioner: &RegisteredPractitioner{IPCode: "1234", UserID: "user1234"}}
+
+		userID, err := GetRegisteredUserID(register, "1234")
+		So(err, ShouldBeNil)
+		So(userID, ShouldEqual, "user1234")
+	})
+}
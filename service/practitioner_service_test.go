@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"github.com/companieshouse/insolvency-api/mocks"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
 	. "github.com/smartystreets/goconvey/convey"
@@ -29,12 +31,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "either telephone_number or email are required")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "either telephone_number or email are required")
 	})
 
 	Convey("Practitioner request supplied is valid - email is supplied", t, func() {
@@ -46,11 +48,11 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldBeBlank)
+		So(err, ShouldBeEmpty)
 	})
 
 	Convey("Practitioner request supplied is valid - telephone number is supplied", t, func() {
@@ -62,11 +64,11 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldBeBlank)
+		So(err, ShouldBeEmpty)
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number is less than 10 digits", t, func() {
@@ -78,12 +80,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number is more than 11 digits", t, func() {
@@ -95,12 +97,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number does not consist solely of digits", t, func() {
@@ -112,12 +114,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number does not consist solely of digits", t, func() {
@@ -129,13 +131,13 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
-		So(err, ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must be 10 or 11 digits long")
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number contains spaces", t, func() {
@@ -147,12 +149,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must not contain spaces")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must not contain spaces")
 	})
 
 	Convey("Practitioner request supplied is invalid - telephone number does not begin with 0", t, func() {
@@ -164,12 +166,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "telephone_number must start with 0 and contain only numeric characters")
 	})
 
 	Convey("Practitioner request supplied is invalid - first name does not match regex", t, func() {
@@ -181,12 +183,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "the first name contains a character which is not allowed")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the first name contains a character which is not allowed")
 	})
 
 	Convey("Practitioner request supplied is invalid - last name does not match regex", t, func() {
@@ -198,12 +200,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "the last name contains a character which is not allowed")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the last name contains a character which is not allowed")
 	})
 
 	Convey("Practitioner request supplied is invalid - first and last name does not match regex", t, func() {
@@ -216,13 +218,13 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "the first name contains a character which is not allowed")
-		So(err, ShouldContainSubstring, "the last name contains a character which is not allowed")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the first name contains a character which is not allowed")
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the last name contains a character which is not allowed")
 	})
 
 	Convey("Practitioner request supplied is invalid - first and last name does not match regex and contact details missing", t, func() {
@@ -237,14 +239,14 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "either telephone_number or email are required")
-		So(err, ShouldContainSubstring, "the first name contains a character which is not allowed")
-		So(err, ShouldContainSubstring, "the last name contains a character which is not allowed")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "either telephone_number or email are required")
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the first name contains a character which is not allowed")
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "the last name contains a character which is not allowed")
 	})
 
 	Convey("Practitioner request supplied is invalid - role supplied is incorrect for CVL case", t, func() {
@@ -256,12 +258,12 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, fmt.Sprintf("the practitioner role must be "+constants.FinalLiquidator.String()+" because the insolvency case for transaction ID [%s] is of type "+constants.CVL.String(), transactionID))
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, fmt.Sprintf("the practitioner role must be "+constants.FinalLiquidator.String()+" because the insolvency case for transaction ID [%s] is of type "+constants.CVL.String(), transactionID))
 	})
 
 	Convey("Error retrieving insolvency case when validating practitioner", t, func() {
@@ -273,9 +275,9 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return an error
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error retrieving insolvency case"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error retrieving insolvency case"))
 
-		_, err := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		_, err := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
 		So(err, ShouldNotBeNil)
 	})
@@ -288,11 +290,11 @@ func TestUnitIsValidPractitionerDetails(t *testing.T) {
 
 		mockService := mock_dao.NewMockService(mockCtrl)
 		// Expect GetInsolvencyResource to return a valid insolvency case
-		mockService.EXPECT().GetInsolvencyResource(gomock.Any()).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), gomock.Any()).Return(generateInsolvencyResource(), nil)
 
-		err, _ := ValidatePractitionerDetails(mockService, transactionID, practitioner)
+		err, _ := ValidatePractitionerDetails(context.Background(), mockService, transactionID, practitioner)
 
-		So(err, ShouldBeBlank)
+		So(err, ShouldBeEmpty)
 	})
 }
 
@@ -309,10 +311,10 @@ func TestUnitIsValidAppointment(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(nil, fmt.Errorf("err"))
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("err"))
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, generateAppointment(), transactionID, practitionerID, req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, generateAppointment(), transactionID, practitionerID, req)
 		So(err.Error(), ShouldContainSubstring, "err")
 		So(validationErr, ShouldBeEmpty)
 	})
@@ -333,13 +335,13 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErrs, err := ValidateAppointmentDetails(mockService, generateAppointment(), transactionID, practitionerID, req)
+		validationErrs, err := ValidateAppointmentDetails(mockService, sdkClients, generateAppointment(), transactionID, practitionerID, req)
 		So(err, ShouldBeNil)
-		So(validationErrs, ShouldContainSubstring, "already appointed")
+		So(utils.FieldErrorMessages(validationErrs), ShouldContainSubstring, "already appointed")
 	})
 
 	Convey("error retrieving insolvency resource", t, func() {
@@ -355,11 +357,11 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, generateAppointment(), transactionID, practitionerID, req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, generateAppointment(), transactionID, practitionerID, req)
 		So(err.Error(), ShouldContainSubstring, "err")
 		So(validationErr, ShouldBeEmpty)
 	})
@@ -380,11 +382,11 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, generateAppointment(), transactionID, practitionerID, req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, generateAppointment(), transactionID, practitionerID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error getting company details from DB")
 	})
@@ -405,14 +407,14 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.AppointedOn = "2001/1/2"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, practitionerID, req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, practitionerID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -433,11 +435,11 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, generateAppointment(), transactionID, practitionerID, req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, generateAppointment(), transactionID, practitionerID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -458,15 +460,15 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.AppointedOn = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, "111", req)
-		So(validationErr, ShouldContainSubstring, "should not be in the future")
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, "111", req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "should not be in the future")
 		So(err, ShouldBeNil)
 	})
 
@@ -486,15 +488,15 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.AppointedOn = "1999-01-01"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, "111", req)
-		So(validationErr, ShouldContainSubstring, "before the company was incorporated")
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, "111", req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "before the company was incorporated")
 		So(err, ShouldBeNil)
 	})
 
@@ -514,15 +516,15 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.AppointedOn = "2012-01-24"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, "111", req)
-		So(validationErr, ShouldEqual, fmt.Sprintf("appointed_on [%s] differs from practitioner ID [%s] who was appointed on [%s]", appointment.AppointedOn, practitionerID, practitionersResponse[0].Appointment.AppointedOn))
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, "111", req)
+		So(validationErr, ShouldResemble, []models.FieldError{{Field: "appointed_on", Message: fmt.Sprintf("appointed_on [%s] differs from practitioner ID [%s] who was appointed on [%s]", appointment.AppointedOn, practitionerID, practitionersResponse[0].Appointment.AppointedOn)}})
 		So(err, ShouldBeNil)
 	})
 
@@ -542,15 +544,15 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.MadeBy = "company"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, "111", req)
-		So(validationErr, ShouldEqual, fmt.Sprintf("made_by cannot be [%s] for insolvency case of type CVL", appointment.MadeBy))
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, "111", req)
+		So(validationErr, ShouldResemble, []models.FieldError{{Field: "made_by", Message: fmt.Sprintf("made_by cannot be [%s] for insolvency case of type CVL", appointment.MadeBy)}})
 		So(err, ShouldBeNil)
 	})
 
@@ -570,14 +572,14 @@ func TestUnitIsValidAppointment(t *testing.T) {
 			},
 		}
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetPractitionerResources(gomock.Any()).Return(practitionersResponse, nil)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetPractitionerResources(gomock.Any(), gomock.Any()).Return(practitionersResponse, nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		appointment := generateAppointment()
 		appointment.MadeBy = "creditors"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateAppointmentDetails(mockService, appointment, transactionID, "111", req)
+		validationErr, err := ValidateAppointmentDetails(mockService, sdkClients, appointment, transactionID, "111", req)
 		So(validationErr, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
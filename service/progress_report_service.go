@@ -3,7 +3,6 @@ package service
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/insolvency-api/dao"
@@ -12,61 +11,61 @@ import (
 )
 
 // ValidateProgressReportDetails checks that the incoming statement details are valid
-func ValidateProgressReportDetails(svc dao.Service, progressReportStatementDao *models.ProgressReportResourceDao, transactionID string, req *http.Request) (string, error) {
-	var errs []string
+func ValidateProgressReportDetails(svc dao.Service, clients Clients, progressReportStatementDao *models.ProgressReportResourceDao, transactionID string, req *http.Request) ([]models.FieldError, error) {
+	var errs []models.FieldError
 
 	if progressReportStatementDao == nil {
 		err := fmt.Errorf("nil DAO passed to service for validation")
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	// Check that the attachment has been submitted correctly
 	if len(progressReportStatementDao.Attachments) == 0 || len(progressReportStatementDao.Attachments) > 1 {
-		errs = append(errs, "please supply only one attachment")
+		errs = append(errs, validationFailure("progress_report_attachments", "attachments", "please supply only one attachment"))
 	}
 
 	// Check if statement date supplied is in the future or before company was incorporated
-	insolvencyResource, err := svc.GetInsolvencyResource(transactionID)
+	insolvencyResource, err := svc.GetInsolvencyResource(req.Context(), transactionID)
 	if err != nil {
-		err = fmt.Errorf("error getting insolvency resource from DB: [%s]", err)
+		err = fmt.Errorf("error getting insolvency resource from DB: [%w]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	// Retrieve company incorporation date
-	incorporatedOn, err := GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
+	incorporatedOn, err := clients.GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
 	if err != nil {
 		err = fmt.Errorf("error getting company details from DB: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	ok, err := utils.IsDateBetweenIncorporationAndNow(progressReportStatementDao.FromDate, incorporatedOn)
 	if err != nil {
 		err = fmt.Errorf("error parsing date: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	if !ok {
-		errs = append(errs, fmt.Sprintf("from_date [%s] should not be in the future or before the company was incorporated", progressReportStatementDao.FromDate))
+		errs = append(errs, validationFailure("progress_report_from_date", "from_date", fmt.Sprintf("from_date [%s] should not be in the future or before the company was incorporated", progressReportStatementDao.FromDate)))
 	}
 
 	ok, err = utils.IsDateBetweenIncorporationAndNow(progressReportStatementDao.ToDate, incorporatedOn)
 	if err != nil {
 		err = fmt.Errorf("error parsing date: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	if !ok {
-		errs = append(errs, fmt.Sprintf("to_date [%s] should not be in the future or before the company was incorporated", progressReportStatementDao.ToDate))
+		errs = append(errs, validationFailure("progress_report_to_date", "to_date", fmt.Sprintf("to_date [%s] should not be in the future or before the company was incorporated", progressReportStatementDao.ToDate)))
 	}
 
 	// Check if from date is after to date
 	ok, err = utils.IsDateBeforeDate(progressReportStatementDao.FromDate, progressReportStatementDao.ToDate)
 	if !ok {
-		errs = append(errs, fmt.Sprintf("to_date [%s] should not be before from_date [%s]", progressReportStatementDao.ToDate, progressReportStatementDao.FromDate))
+		errs = append(errs, validationFailure("progress_report_date_order", "to_date", fmt.Sprintf("to_date [%s] should not be before from_date [%s]", progressReportStatementDao.ToDate, progressReportStatementDao.FromDate)))
 	}
 
-	return strings.Join(errs, ", "), nil
+	return errs, nil
 }
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/companieshouse/insolvency-api/mocks"
+	"github.com/golang/mock/gomock"
 
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/jarcoal/httpmock"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -22,14 +24,14 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.Attachments = []string{}
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 
-		So(validationErr, ShouldContainSubstring, "please supply only one attachment")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "please supply only one attachment")
 		So(err, ShouldBeNil)
 	})
 
@@ -39,7 +41,7 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.Attachments = []string{
@@ -47,9 +49,9 @@ func TestUnitValidProgressReport(t *testing.T) {
 			"0987654321",
 		}
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 
-		So(validationErr, ShouldContainSubstring, "please supply only one attachment")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "please supply only one attachment")
 		So(err, ShouldBeNil)
 	})
 
@@ -59,11 +61,11 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("error"))
 
 		progressReport := generateProgressReport()
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error getting insolvency resource from DB")
@@ -75,11 +77,11 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error communicating with the company profile api")
@@ -91,12 +93,12 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.FromDate = "2001/1/2"
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -107,12 +109,12 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.ToDate = "2001/1/2"
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -123,13 +125,13 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.FromDate = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "should not be in the future")
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "should not be in the future")
 		So(err, ShouldBeNil)
 	})
 
@@ -139,13 +141,13 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.ToDate = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "should not be in the future")
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "should not be in the future")
 		So(err, ShouldBeNil)
 	})
 
@@ -155,14 +157,14 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.FromDate = "1999-01-01"
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "from_date")
-		So(validationErr, ShouldContainSubstring, "before the company was incorporated")
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "from_date")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "before the company was incorporated")
 		So(err, ShouldBeNil)
 	})
 
@@ -173,14 +175,14 @@ func TestUnitValidProgressReport(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		//mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 		progressReport.ToDate = "1999-01-01"
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "to_date")
-		So(validationErr, ShouldContainSubstring, "before the company was incorporated")
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "to_date")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "before the company was incorporated")
 		So(err, ShouldBeNil)
 	})
 
@@ -190,11 +192,11 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		progressReport := generateProgressReport()
 
-		validationErr, err := ValidateProgressReportDetails(mockService, &progressReport, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, &progressReport, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
@@ -205,7 +207,7 @@ func TestUnitValidProgressReport(t *testing.T) {
 
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
-		validationErr, err := ValidateProgressReportDetails(mockService, nil, transactionID, req)
+		validationErr, err := ValidateProgressReportDetails(mockService, sdkClients, nil, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "nil DAO passed to service for validation")
 	})
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// csvRow returns the value in the column of a CSV row with the supplied heading, with surrounding whitespace
+// removed, or an empty string if there is no such column
+type csvRow func(heading string) string
+
+// loadRecordFile loads the records held in the file at the supplied path, describing the file by the supplied
+// description in any error. Files with a .json extension must contain an array of records, any other file is read
+// as CSV with a header row that contains at least the required headings, and each row is parsed by parseRow
+func loadRecordFile[T any](path string, description string, required []string, parseRow func(row csvRow, line int) (T, error)) ([]T, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s file [%s]: [%v]", description, path, err)
+	}
+	defer file.Close()
+
+	var records []T
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&records)
+	} else {
+		records, err = readCSVRecords(file, required, parseRow)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s file [%s]: [%v]", description, path, err)
+	}
+
+	return records, nil
+}
+
+// readCSVRecords reads records from CSV data, using the header row to find each column
+func readCSVRecords[T any](reader io.Reader, required []string, parseRow func(row csvRow, line int) (T, error)) ([]T, error) {
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no header row found")
+	}
+
+	columns := make(map[string]int)
+	for i, heading := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(heading))] = i
+	}
+	for _, heading := range required {
+		if _, ok := columns[heading]; !ok {
+			return nil, fmt.Errorf("column [%s] not found in header row", heading)
+		}
+	}
+
+	var records []T
+	for line, values := range rows[1:] {
+		row := func(heading string) string {
+			i, ok := columns[heading]
+			if !ok {
+				return ""
+			}
+			return strings.TrimSpace(values[i])
+		}
+
+		record, err := parseRow(row, line+2)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/insolvency-api/dao"
@@ -12,44 +11,44 @@ import (
 )
 
 // ValidateResolutionRequest checks that the incoming resolution request is valid
-func ValidateResolutionRequest(resolution models.Resolution) string {
-	var errs []string
+func ValidateResolutionRequest(resolution models.Resolution) []models.FieldError {
+	var errs []models.FieldError
 
 	// Check that the attachment has been submitted correctly
 	if len(resolution.Attachments) == 0 || len(resolution.Attachments) > 1 {
-		errs = append(errs, "please supply only one attachment")
+		errs = append(errs, validationFailure("resolution_attachments", "attachments", "please supply only one attachment"))
 	}
-	return strings.Join(errs, ", ")
+	return errs
 }
 
 // ValidateResolutionDate checks that the incoming resolution date is valid
-func ValidateResolutionDate(svc dao.Service, resolution *models.ResolutionResourceDao, transactionID string, req *http.Request) (string, error) {
-	var errs []string
+func ValidateResolutionDate(svc dao.Service, clients Clients, resolution *models.ResolutionResourceDao, transactionID string, req *http.Request) ([]models.FieldError, error) {
+	var errs []models.FieldError
 
 	// Check if resolution date supplied is in the future or before company was incorporated
-	insolvencyResource, err := svc.GetInsolvencyResource(transactionID)
+	insolvencyResource, err := svc.GetInsolvencyResource(req.Context(), transactionID)
 	if err != nil {
-		err = fmt.Errorf("error getting insolvency resource from DB: [%s]", err)
+		err = fmt.Errorf("error getting insolvency resource from DB: [%w]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	// Retrieve company incorporation date
-	incorporatedOn, err := GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
+	incorporatedOn, err := clients.GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
 	if err != nil {
 		err = fmt.Errorf("error getting company details from DB: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	ok, err := utils.IsDateBetweenIncorporationAndNow(resolution.DateOfResolution, incorporatedOn)
 	if err != nil {
 		err = fmt.Errorf("error parsing date: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	if !ok {
-		errs = append(errs, fmt.Sprintf("date_of_resolution [%s] should not be in the future or before the company was incorporated", resolution.DateOfResolution))
+		errs = append(errs, validationFailure("date_of_resolution", "date_of_resolution", fmt.Sprintf("date_of_resolution [%s] should not be in the future or before the company was incorporated", resolution.DateOfResolution)))
 	}
 
-	return strings.Join(errs, ", "), nil
+	return errs, nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
 	. "github.com/smartystreets/goconvey/convey"
@@ -21,8 +22,8 @@ func TestUnitIsValidResolutionRequest(t *testing.T) {
 
 		err := ValidateResolutionRequest(resolution)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "please supply only one attachment")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "please supply only one attachment")
 	})
 
 	Convey("Resolution request supplied is invalid - more than one attachment has been supplied", t, func() {
@@ -34,8 +35,8 @@ func TestUnitIsValidResolutionRequest(t *testing.T) {
 
 		err := ValidateResolutionRequest(resolution)
 
-		So(err, ShouldNotBeBlank)
-		So(err, ShouldContainSubstring, "please supply only one attachment")
+		So(err, ShouldNotBeEmpty)
+		So(utils.FieldErrorMessages(err), ShouldContainSubstring, "please supply only one attachment")
 	})
 }
 
@@ -51,12 +52,12 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
 
 		resolution := generateResolutionDao()
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
 		So(err.Error(), ShouldContainSubstring, "err")
 		So(validationErr, ShouldBeEmpty)
 	})
@@ -69,11 +70,11 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		resolution := generateResolutionDao()
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error getting company details from DB")
 	})
@@ -86,13 +87,13 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		resolution := generateResolutionDao()
 		resolution.DateOfResolution = "2001/1/2"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -106,10 +107,10 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 
 		resolution := generateResolutionDao()
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -122,14 +123,14 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		resolution := generateResolutionDao()
 		resolution.DateOfResolution = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "should not be in the future")
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "should not be in the future")
 		So(err, ShouldBeNil)
 	})
 
@@ -141,14 +142,14 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		resolution := generateResolutionDao()
 		resolution.DateOfResolution = "1999-01-01"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "before the company was incorporated")
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "before the company was incorporated")
 		So(err, ShouldBeNil)
 	})
 
@@ -160,12 +161,12 @@ func TestUnitIsValidResolutionDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		resolution := generateResolutionDao()
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateResolutionDate(mockService, &resolution, transactionID, req)
+		validationErr, err := ValidateResolutionDate(mockService, sdkClients, &resolution, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/config"
+)
+
+const (
+	// defaultSandboxAllowListEntry is used when EFS allow list auth is disabled but no sandbox entries are configured
+	defaultSandboxAllowListEntry = "regex:ip-test"
+
+	sandboxDomainPrefix = "@"
+	sandboxRegexPrefix  = "regex:"
+)
+
+var (
+	sandbox    *SandboxAllowList
+	sandboxMtx sync.Mutex
+)
+
+// SandboxAllowList decides which users may bypass the EFS allow list in test environments. Entries are either an
+// exact email address, a domain prefixed with "@", or a regular expression prefixed with "regex:". Entries can be
+// supplied directly and in a file, which is reloaded whenever it changes
+type SandboxAllowList struct {
+	mtx         sync.RWMutex
+	entries     []string
+	file        string
+	fileModTime time.Time
+	emails      map[string]struct{}
+	domains     map[string]struct{}
+	patterns    []*regexp.Regexp
+}
+
+// NewSandboxAllowList creates a sandbox allow list from the supplied entries and the entries in the file, if a
+// file path is supplied
+func NewSandboxAllowList(entries []string, file string) (*SandboxAllowList, error) {
+	s := &SandboxAllowList{entries: entries, file: file}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the sandbox allow list file and rebuilds the rules. The existing rules are kept if there is an error
+func (s *SandboxAllowList) Reload() error {
+	entries := append([]string{}, s.entries...)
+
+	var modTime time.Time
+	if s.file != "" {
+		info, err := os.Stat(s.file)
+		if err != nil {
+			return fmt.Errorf("error reading sandbox allow list file [%s]: [%v]", s.file, err)
+		}
+		modTime = info.ModTime()
+
+		fileEntries, err := readSandboxAllowListFile(s.file)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	emails := make(map[string]struct{})
+	domains := make(map[string]struct{})
+	var patterns []*regexp.Regexp
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.HasPrefix(entry, sandboxRegexPrefix):
+			pattern, err := regexp.Compile(strings.TrimPrefix(entry, sandboxRegexPrefix))
+			if err != nil {
+				return fmt.Errorf("invalid sandbox allow list pattern [%s]: [%v]", entry, err)
+			}
+			patterns = append(patterns, pattern)
+		case strings.HasPrefix(entry, sandboxDomainPrefix):
+			domains[strings.ToLower(strings.TrimPrefix(entry, sandboxDomainPrefix))] = struct{}{}
+		default:
+			emails[strings.ToLower(entry)] = struct{}{}
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.emails = emails
+	s.domains = domains
+	s.patterns = patterns
+	s.fileModTime = modTime
+
+	return nil
+}
+
+// Match reports whether the email address is on the sandbox allow list, along with the rule that matched it
+func (s *SandboxAllowList) Match(emailAddress string) (bool, string) {
+	s.reloadIfChanged()
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	email := strings.ToLower(strings.TrimSpace(emailAddress))
+	if _, ok := s.emails[email]; ok {
+		return true, email
+	}
+
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		if _, ok := s.domains[email[at+1:]]; ok {
+			return true, sandboxDomainPrefix + email[at+1:]
+		}
+	}
+
+	for _, pattern := range s.patterns {
+		if pattern.MatchString(emailAddress) {
+			return true, sandboxRegexPrefix + pattern.String()
+		}
+	}
+
+	return false, ""
+}
+
+// reloadIfChanged reloads the sandbox allow list if the file has been modified since it was last read
+func (s *SandboxAllowList) reloadIfChanged() {
+	if s.file == "" {
+		return
+	}
+
+	info, err := os.Stat(s.file)
+	if err != nil {
+		log.Error(fmt.Errorf("error checking sandbox allow list file [%s] for changes, using previous entries: [%v]", s.file, err))
+		return
+	}
+
+	s.mtx.RLock()
+	changed := !info.ModTime().Equal(s.fileModTime)
+	s.mtx.RUnlock()
+	if !changed {
+		return
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Error(fmt.Errorf("error reloading sandbox allow list, using previous entries: [%v]", err))
+		return
+	}
+	log.Info("sandbox allow list reloaded", log.Data{"file": s.file})
+}
+
+// readSandboxAllowListFile reads one sandbox allow list entry per line, ignoring blank lines and # comments
+func readSandboxAllowListFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sandbox allow list file [%s]: [%v]", path, err)
+	}
+	defer file.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading sandbox allow list file [%s]: [%v]", path, err)
+	}
+
+	return entries, nil
+}
+
+// getSandboxAllowList returns the sandbox allow list configured for the service, creating it on first use
+func getSandboxAllowList(cfg *config.Config) (*SandboxAllowList, error) {
+	sandboxMtx.Lock()
+	defer sandboxMtx.Unlock()
+
+	if sandbox != nil {
+		return sandbox, nil
+	}
+
+	var entries []string
+	if cfg.EfsSandboxAllowList != "" {
+		entries = strings.Split(cfg.EfsSandboxAllowList, ",")
+	}
+	if len(entries) == 0 && cfg.EfsSandboxAllowListFile == "" {
+		entries = []string{defaultSandboxAllowListEntry}
+	}
+
+	allowList, err := NewSandboxAllowList(entries, cfg.EfsSandboxAllowListFile)
+	if err != nil {
+		return nil, err
+	}
+	sandbox = allowList
+
+	return sandbox, nil
+}
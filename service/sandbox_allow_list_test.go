@@ -0,0 +1,97 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitSandboxAllowList(t *testing.T) {
+	Convey("Match exact email addresses ignoring case", t, func() {
+		allowList, err := NewSandboxAllowList([]string{"Synthetic.User@example.com"}, "")
+		So(err, ShouldBeNil)
+
+		isMatch, rule := allowList.Match("synthetic.user@EXAMPLE.com")
+		So(isMatch, ShouldBeTrue)
+		So(rule, ShouldEqual, "synthetic.user@example.com")
+
+		isMatch, rule = allowList.Match("other.user@example.com")
+		So(isMatch, ShouldBeFalse)
+		So(rule, ShouldBeBlank)
+	})
+
+	Convey("Match every email address in a domain", t, func() {
+		allowList, err := NewSandboxAllowList([]string{"@test.example.com"}, "")
+		So(err, ShouldBeNil)
+
+		isMatch, rule := allowList.Match("anyone@test.example.com")
+		So(isMatch, ShouldBeTrue)
+		So(rule, ShouldEqual, "@test.example.com")
+
+		isMatch, _ = allowList.Match("anyone@example.com")
+		So(isMatch, ShouldBeFalse)
+	})
+
+	Convey("Match email addresses against a pattern", t, func() {
+		allowList, err := NewSandboxAllowList([]string{"regex:ip-test"}, "")
+		So(err, ShouldBeNil)
+
+		isMatch, rule := allowList.Match("demo-ip-test@ch.gov.uk")
+		So(isMatch, ShouldBeTrue)
+		So(rule, ShouldEqual, "regex:ip-test")
+	})
+
+	Convey("Invalid pattern", t, func() {
+		allowList, err := NewSandboxAllowList([]string{"regex:("}, "")
+		So(allowList, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "invalid sandbox allow list pattern [regex:(]")
+	})
+
+	Convey("Sandbox allow list file does not exist", t, func() {
+		allowList, err := NewSandboxAllowList(nil, filepath.Join(t.TempDir(), "missing.txt"))
+		So(allowList, ShouldBeNil)
+		So(err.Error(), ShouldContainSubstring, "error reading sandbox allow list file")
+	})
+
+	Convey("Entries are reloaded when the file changes", t, func() {
+		path := filepath.Join(t.TempDir(), "sandbox.txt")
+		So(os.WriteFile(path, []byte("# synthetic users\none@example.com\n\n"), 0600), ShouldBeNil)
+
+		allowList, err := NewSandboxAllowList([]string{"@config.example.com"}, path)
+		So(err, ShouldBeNil)
+
+		isMatch, _ := allowList.Match("one@example.com")
+		So(isMatch, ShouldBeTrue)
+		isMatch, _ = allowList.Match("two@example.com")
+		So(isMatch, ShouldBeFalse)
+
+		So(os.WriteFile(path, []byte("two@example.com\n"), 0600), ShouldBeNil)
+		modTime := time.Now().Add(time.Minute)
+		So(os.Chtimes(path, modTime, modTime), ShouldBeNil)
+
+		isMatch, _ = allowList.Match("one@example.com")
+		So(isMatch, ShouldBeFalse)
+		isMatch, _ = allowList.Match("two@example.com")
+		So(isMatch, ShouldBeTrue)
+		isMatch, _ = allowList.Match("anyone@config.example.com")
+		So(isMatch, ShouldBeTrue)
+	})
+
+	Convey("Previous entries are kept if the changed file is invalid", t, func() {
+		path := filepath.Join(t.TempDir(), "sandbox.txt")
+		So(os.WriteFile(path, []byte("one@example.com\n"), 0600), ShouldBeNil)
+
+		allowList, err := NewSandboxAllowList(nil, path)
+		So(err, ShouldBeNil)
+
+		So(os.WriteFile(path, []byte("regex:(\n"), 0600), ShouldBeNil)
+		modTime := time.Now().Add(time.Minute)
+		So(os.Chtimes(path, modTime, modTime), ShouldBeNil)
+
+		isMatch, _ := allowList.Match("one@example.com")
+		So(isMatch, ShouldBeTrue)
+	})
+}
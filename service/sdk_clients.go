@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/companieshouse/api-sdk-go/companieshouseapi"
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/go-sdk-manager/manager"
+	"github.com/companieshouse/insolvency-api/constants"
+	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/transformers"
+)
+
+// NewSDKClients returns clients that call the upstream APIs with the Companies House SDKs, authenticated as the
+// user making the request
+func NewSDKClients() Clients {
+	return Clients{
+		CompanyProfile: sdkCompanyProfileClient{},
+		Transaction:    sdkTransactionClient{},
+		AlphaKey:       sdkAlphaKeyClient{},
+		Efs:            sdkEfsClient{},
+		FileTransfer:   sdkFileTransferClient{},
+	}
+}
+
+// sdkCompanyProfileClient is a CompanyProfileClient that uses the SDK
+type sdkCompanyProfileClient struct{}
+
+// GetCompanyProfile calls the company profile api to retrieve the company details
+func (sdkCompanyProfileClient) GetCompanyProfile(req *http.Request, companyNumber string) (*companieshouseapi.CompanyProfile, int, error) {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "company-profile", "Get")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		err = fmt.Errorf("error creating SDK to call company profile: [%v]", err.Error())
+		log.ErrorR(req, err)
+		return nil, http.StatusInternalServerError, err
+	}
+
+	companyProfile, err := api.Profile.Get(companyNumber).Do()
+	done(err)
+
+	return companyProfile, companyProfile.HTTPStatusCode, err
+}
+
+// sdkTransactionClient is a TransactionClient that uses the SDKs
+type sdkTransactionClient struct{}
+
+// GetTransactionStatus calls the transaction api to retrieve the details of the transaction
+func (sdkTransactionClient) GetTransactionStatus(req *http.Request, transactionID string) (string, int, error) {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "transaction", "Get")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		err = fmt.Errorf("error creating SDK to call transaction api: [%v]", err.Error())
+		log.ErrorR(req, err)
+		return "", http.StatusInternalServerError, err
+	}
+
+	transactionProfile, err := api.Transaction.Get(transactionID).Do()
+	done(err)
+
+	return transactionProfile.Status, transactionProfile.HTTPStatusCode, err
+}
+
+// PatchTransaction patches the transaction api with the insolvency resource
+func (sdkTransactionClient) PatchTransaction(req *http.Request, transactionID string, insolvencyResource *models.InsolvencyResourceDao) (int, error) {
+	// Create Private SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "transaction", "Patch")
+	api, err := manager.GetPrivateSDK(req)
+	if err != nil {
+		done(err)
+		err = fmt.Errorf("error creating SDK to call transaction api: [%v]", err.Error())
+		log.ErrorR(req, err)
+		return http.StatusInternalServerError, err
+	}
+
+	transactionProfile, err := api.Transaction.Patch(transactionID, transformers.InsolvencyResourceDaoToTransactionResource(insolvencyResource)).Do()
+	done(err)
+
+	return transactionProfile.HTTPStatusCode, err
+}
+
+// sdkAlphaKeyClient is an AlphaKeyClient that uses the private SDK
+type sdkAlphaKeyClient struct{}
+
+// GetSameAsAlphaKey calls the alpha key service to retrieve the alpha keys for the company name
+func (sdkAlphaKeyClient) GetSameAsAlphaKey(req *http.Request, companyName string) (string, error) {
+	req, done := startUpstreamCall(req, "alpha-key", "Get")
+	api, err := manager.GetPrivateSDK(req)
+	if err != nil {
+		done(err)
+		return "", fmt.Errorf("error creating private SDK to call alphakeyservice: [%v]", err.Error())
+	}
+
+	alphaKeyResponse, err := api.AlphaKey.Get(companyName).Do()
+	done(err)
+	if err != nil {
+		log.ErrorR(req, fmt.Errorf("error communicating with alphakey service [%v]", err))
+		return "", fmt.Errorf("error communicating with alphakey service")
+	}
+
+	return alphaKeyResponse.SameAsAlphaKey, nil
+}
+
+// sdkEfsClient is an EfsClient that uses the private SDK
+type sdkEfsClient struct{}
+
+// IsUserOnAllowList calls the EFS submission api to check the allow list
+func (sdkEfsClient) IsUserOnAllowList(req *http.Request, emailAddress string) (bool, error) {
+	req, done := startUpstreamCall(req, "efs", "IsUserOnAllowList")
+	api, err := manager.GetPrivateSDK(req)
+	if err != nil {
+		done(err)
+		return false, fmt.Errorf("error creating private SDK to call transaction api: [%v]", err.Error())
+	}
+
+	isUserAllowed, err := api.Efs.IsUserOnAllowList(emailAddress).Do()
+	done(err)
+
+	if err != nil {
+		return false, fmt.Errorf("error communicating with the EFS submission api: [%s]", err)
+	}
+
+	if isUserAllowed == nil {
+		return false, fmt.Errorf("error communicating with the EFS submission API: no response received")
+	}
+
+	return isUserAllowed.UserAllowed, nil
+}
+
+// sdkFileTransferClient is a FileTransferClient that uses the SDK
+type sdkFileTransferClient struct{}
+
+// UploadFile sends the file to the File Transfer API
+func (sdkFileTransferClient) UploadFile(req *http.Request, file multipart.File, header *multipart.FileHeader) (string, error) {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "file-transfer", "UploadFile")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		return "", fmt.Errorf("error creating SDK to upload attachment: [%v]", err)
+	}
+
+	uploadedFileResponse, err := api.FileTransfer.UploadFile(file, header).Do()
+	done(err)
+	if err != nil {
+		return "", fmt.Errorf(constants.MsgErrorCommsFileTransferAPI, err)
+	}
+	if uploadedFileResponse == nil {
+		return "", fmt.Errorf("error uploading file: [%v]", err)
+	}
+
+	return uploadedFileResponse.Id, nil
+}
+
+// GetFile gets the file details from the File Transfer API
+func (sdkFileTransferClient) GetFile(req *http.Request, fileID string) (models.AttachmentFile, error) {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "file-transfer", "GetFile")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		return models.AttachmentFile{}, fmt.Errorf("error creating SDK to get attachment details: [%v]", err)
+	}
+
+	response, err := api.FileTransfer.GetFile(fileID).Do()
+	done(err)
+	if err != nil {
+		return models.AttachmentFile{}, fmt.Errorf(constants.MsgErrorCommsFileTransferAPI, err)
+	}
+
+	return models.AttachmentFile{
+		Name:        response.Name,
+		Size:        response.Size,
+		ContentType: response.ContentType,
+		AVStatus:    response.AvStatus,
+	}, nil
+}
+
+// DownloadFile downloads the file from the File Transfer API
+func (sdkFileTransferClient) DownloadFile(req *http.Request, fileID string, w http.ResponseWriter) error {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "file-transfer", "DownloadFile")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		return fmt.Errorf("error creating SDK to upload attachment: [%v]", err)
+	}
+
+	downloadedFileResponse, err := api.FileTransfer.DownloadFile(fileID, w).Do()
+	done(err)
+	if err != nil {
+		return fmt.Errorf(constants.MsgErrorCommsFileTransferAPI, err)
+	}
+	if downloadedFileResponse == nil {
+		return fmt.Errorf("error downloading file: [%v]", err)
+	}
+
+	return nil
+}
+
+// DeleteFile deletes the file with the File Transfer API
+func (sdkFileTransferClient) DeleteFile(req *http.Request, fileID string) error {
+	// Create SDK session from the request holding the span of the call
+	req, done := startUpstreamCall(req, "file-transfer", "DeleteFile")
+	api, err := manager.GetSDK(req)
+	if err != nil {
+		done(err)
+		return fmt.Errorf("error creating SDK to get attachment details: [%v]", err)
+	}
+
+	response, err := api.FileTransfer.DeleteFile(fileID).Do()
+	done(err)
+	if err != nil {
+		return fmt.Errorf(constants.MsgErrorCommsFileTransferAPI, err)
+	}
+	if response == nil {
+		return fmt.Errorf("error deleting file: [%v]", err)
+	}
+
+	return nil
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/companieshouse/chs.go/log"
 	"github.com/companieshouse/insolvency-api/dao"
@@ -12,47 +11,47 @@ import (
 )
 
 // ValidateStatementDetails checks that the incoming statement details are valid
-func ValidateStatementDetails(svc dao.Service, statementDao *models.StatementOfAffairsResourceDao, transactionID string, req *http.Request) (string, error) {
-	var errs []string
+func ValidateStatementDetails(svc dao.Service, clients Clients, statementDao *models.StatementOfAffairsResourceDao, transactionID string, req *http.Request) ([]models.FieldError, error) {
+	var errs []models.FieldError
 
 	if statementDao == nil {
 		err := fmt.Errorf("nil DAO passed to service for validation")
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	// Check that the attachment has been submitted correctly
 	if len(statementDao.Attachments) == 0 {
-		errs = append(errs, "please supply at least one attachment")
+		errs = append(errs, validationFailure("statement_of_affairs_attachments_missing", "attachments", "please supply at least one attachment"))
 	}
 	if len(statementDao.Attachments) > 2 {
-		errs = append(errs, "please supply a maximum of two attachments")
+		errs = append(errs, validationFailure("statement_of_affairs_attachments_too_many", "attachments", "please supply a maximum of two attachments"))
 	}
 
 	// Check if statement date supplied is in the future or before company was incorporated
-	insolvencyResource, err := svc.GetInsolvencyResource(transactionID)
+	insolvencyResource, err := svc.GetInsolvencyResource(req.Context(), transactionID)
 	if err != nil {
-		err = fmt.Errorf("error getting insolvency resource from DB: [%s]", err)
+		err = fmt.Errorf("error getting insolvency resource from DB: [%w]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	// Retrieve company incorporation date
-	incorporatedOn, err := GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
+	incorporatedOn, err := clients.GetCompanyIncorporatedOn(insolvencyResource.Data.CompanyNumber, req)
 	if err != nil {
 		err = fmt.Errorf("error getting company details from DB: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 
 	ok, err := utils.IsDateBetweenIncorporationAndNow(statementDao.StatementDate, incorporatedOn)
 	if err != nil {
 		err = fmt.Errorf("error parsing date: [%s]", err)
 		log.ErrorR(req, err)
-		return "", err
+		return nil, err
 	}
 	if !ok {
-		errs = append(errs, fmt.Sprintf("statement_date [%s] should not be in the future or before the company was incorporated", statementDao.StatementDate))
+		errs = append(errs, validationFailure("statement_date", "statement_date", fmt.Sprintf("statement_date [%s] should not be in the future or before the company was incorporated", statementDao.StatementDate)))
 	}
 
-	return strings.Join(errs, ", "), nil
+	return errs, nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
+	"github.com/companieshouse/insolvency-api/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/jarcoal/httpmock"
 	. "github.com/smartystreets/goconvey/convey"
@@ -29,14 +30,14 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		statement.Attachments = []string{}
 
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 
-		So(validationErr, ShouldContainSubstring, "please supply at least one attachment")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "please supply at least one attachment")
 		So(err, ShouldBeNil)
 	})
 
@@ -48,7 +49,7 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		statement.Attachments = []string{
@@ -57,9 +58,9 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 			"2468097531",
 		}
 
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 
-		So(validationErr, ShouldContainSubstring, "please supply a maximum of two attachments")
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "please supply a maximum of two attachments")
 		So(err, ShouldBeNil)
 	})
 
@@ -74,9 +75,9 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		i := 1
 		for i <= 2 {
 			mockService := mocks.NewMockService(mockCtrl)
-			mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+			mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
-			validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+			validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 			So(validationErr, ShouldBeEmpty)
 			So(err, ShouldBeNil)
 			attachID := fmt.Sprintf("098765432%d", i)
@@ -91,12 +92,12 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		defer mockCtrl.Finish()
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(models.InsolvencyResourceDao{}, fmt.Errorf("err"))
 
 		statement := generateStatement()
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 		So(err.Error(), ShouldContainSubstring, "err")
 		So(validationErr, ShouldBeEmpty)
 	})
@@ -109,11 +110,11 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error getting company details from DB")
 	})
@@ -126,13 +127,13 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		statement.StatementDate = "2001/1/2"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -146,10 +147,10 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 
 		statement := generateStatement()
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "error parsing date")
 	})
@@ -162,14 +163,14 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		statement.StatementDate = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "should not be in the future")
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "should not be in the future")
 		So(err, ShouldBeNil)
 	})
 
@@ -181,14 +182,14 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 		statement.StatementDate = "1999-01-01"
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
-		So(validationErr, ShouldContainSubstring, "before the company was incorporated")
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
+		So(utils.FieldErrorMessages(validationErr), ShouldContainSubstring, "before the company was incorporated")
 		So(err, ShouldBeNil)
 	})
 
@@ -200,12 +201,12 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		httpmock.RegisterResponder(http.MethodGet, apiURL+"/company/1234", httpmock.NewStringResponder(http.StatusOK, companyProfileDateResponse("2000-06-26 00:00:00.000Z")))
 
 		mockService := mocks.NewMockService(mockCtrl)
-		mockService.EXPECT().GetInsolvencyResource(transactionID).Return(generateInsolvencyResource(), nil)
+		mockService.EXPECT().GetInsolvencyResource(gomock.Any(), transactionID).Return(generateInsolvencyResource(), nil)
 
 		statement := generateStatement()
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, &statement, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, &statement, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err, ShouldBeNil)
 	})
@@ -220,7 +221,7 @@ func TestUnitIsValidStatementDate(t *testing.T) {
 		mockService := mocks.NewMockService(mockCtrl)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
-		validationErr, err := ValidateStatementDetails(mockService, nil, transactionID, req)
+		validationErr, err := ValidateStatementDetails(mockService, sdkClients, nil, transactionID, req)
 		So(validationErr, ShouldBeEmpty)
 		So(err.Error(), ShouldContainSubstring, "nil DAO passed to service for validation")
 	})
@@ -1,89 +1,59 @@
 package service
 
 import (
-	"fmt"
 	"net/http"
 
-	"github.com/companieshouse/go-sdk-manager/manager"
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/models"
-	"github.com/companieshouse/insolvency-api/transformers"
 )
 
 // CheckTransactionID will check with the transaction api that the provided transaction id exists
-func CheckTransactionID(transactionID string, req *http.Request) (error, int) {
-
-	// Create SDK session
-	api, err := manager.GetSDK(req)
-
-	if err != nil {
-		return fmt.Errorf("error creating SDK to call transaction api: [%v]", err.Error()), http.StatusInternalServerError
-	}
+func (c Clients) CheckTransactionID(transactionID string, req *http.Request) error {
 
 	// Call transaction api to retrieve details of the transaction
-	transactionProfile, err := api.Transaction.Get(transactionID).Do()
-
+	_, statusCode, err := c.Transaction.GetTransactionStatus(req, transactionID)
 	if err != nil {
 		// If 404 then return the transaction not found
-		if transactionProfile.HTTPStatusCode == http.StatusNotFound {
-			return fmt.Errorf("transaction not found"), http.StatusNotFound
+		if statusCode == http.StatusNotFound {
+			return apperrors.NotFound("transaction not found")
 		}
 		// Else return that there has been an error contacting the transaction api
-		return fmt.Errorf("error communicating with the transaction api"), transactionProfile.HTTPStatusCode
+		return upstreamError(statusCode, err, "error communicating with the transaction api")
 	}
 
-	return nil, transactionProfile.HTTPStatusCode
+	return nil
 }
 
 // PatchTransactionWithInsolvencyResource will patch the provided transaction with the created insolvency resource
-func PatchTransactionWithInsolvencyResource(transactionID string, insolvencyResource *models.InsolvencyResourceDao, req *http.Request) (error, int) {
-
-	// Create Private SDK session
-	api, err := manager.GetPrivateSDK(req)
-
-	if err != nil {
-		return fmt.Errorf("error creating SDK to call transaction api: [%v]", err.Error()), http.StatusInternalServerError
-	}
+func (c Clients) PatchTransactionWithInsolvencyResource(transactionID string, insolvencyResource *models.InsolvencyResourceDao, req *http.Request) error {
 
 	// Patch transaction api with insolvency resource
-	transactionProfile, err := api.Transaction.Patch(transactionID, transformers.InsolvencyResourceDaoToTransactionResource(insolvencyResource)).Do()
-
+	statusCode, err := c.Transaction.PatchTransaction(req, transactionID, insolvencyResource)
 	if err != nil {
 		// If 404 then return the transaction not found
-		if transactionProfile.HTTPStatusCode == http.StatusNotFound {
-			return fmt.Errorf("transaction not found"), http.StatusNotFound
+		if statusCode == http.StatusNotFound {
+			return apperrors.NotFound("transaction not found")
 		}
 		// Else return that there has been an error contacting the transaction api
-		return fmt.Errorf("error communication with the transaction api"), transactionProfile.HTTPStatusCode
+		return upstreamError(statusCode, err, "error communication with the transaction api")
 	}
 
-	return nil, transactionProfile.HTTPStatusCode
+	return nil
 }
 
 // CheckIfTransactionClosed checks against the transaction api if the transaction is closed or not
-func CheckIfTransactionClosed(transactionID string, req *http.Request) (bool, error, int) {
-
-	// Create SDK session
-	api, err := manager.GetSDK(req)
-
-	if err != nil {
-		return false, fmt.Errorf("error creating SDK to call transaction api: [%v]", err.Error()), http.StatusInternalServerError
-	}
+func (c Clients) CheckIfTransactionClosed(transactionID string, req *http.Request) (bool, error) {
 
 	// Call transaction api to retrieve details of the transaction
-	transactionProfile, err := api.Transaction.Get(transactionID).Do()
-
+	status, statusCode, err := c.Transaction.GetTransactionStatus(req, transactionID)
 	if err != nil {
 		// If 404 then return the transaction not found
-		if transactionProfile.HTTPStatusCode == http.StatusNotFound {
-			return false, fmt.Errorf("transaction not found"), http.StatusNotFound
+		if statusCode == http.StatusNotFound {
+			return false, apperrors.NotFound("transaction not found")
 		}
 		// Else return that there has been an error contacting the transaction api
-		return false, fmt.Errorf("error getting transaction from transaction api: [%v]", err.Error()), transactionProfile.HTTPStatusCode
-	}
-
-	if transactionProfile.Status == "closed" {
-		return true, nil, http.StatusForbidden
+		return false, upstreamError(statusCode, err, "error getting transaction from transaction api")
 	}
 
-	return false, nil, http.StatusOK
+	return status == "closed", nil
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/companieshouse/insolvency-api/apperrors"
 	"github.com/companieshouse/insolvency-api/constants"
 	mock_dao "github.com/companieshouse/insolvency-api/mocks"
 	"github.com/companieshouse/insolvency-api/models"
@@ -48,9 +49,9 @@ func TestUnitCheckTransactionID(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusNotFound, "Message: Transaction not found"))
 
-			err, statusCode := CheckTransactionID("87654321", &http.Request{})
+			err := sdkClients.CheckTransactionID("87654321", &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusNotFound)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
 			So(err.Error(), ShouldEqual, `transaction not found`)
 		})
 
@@ -59,9 +60,8 @@ func TestUnitCheckTransactionID(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
-			err, statusCode := CheckTransactionID("87654321", &http.Request{})
+			err := sdkClients.CheckTransactionID("87654321", &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusTeapot)
 			So(err.Error(), ShouldEqual, `error communicating with the transaction api`)
 		})
 
@@ -70,10 +70,9 @@ func TestUnitCheckTransactionID(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse("open")))
 
-			err, statusCode := CheckTransactionID("87654321", &http.Request{})
+			err := sdkClients.CheckTransactionID("87654321", &http.Request{})
 
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusOK)
 		})
 	})
 }
@@ -98,9 +97,9 @@ func TestUnitPatchTransactionWithInsolvencyResource(t *testing.T) {
 
 			mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 
-			err, statusCode := PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
+			err := sdkClients.PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusNotFound)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
 			So(err.Error(), ShouldEqual, `transaction not found`)
 		})
 
@@ -111,9 +110,8 @@ func TestUnitPatchTransactionWithInsolvencyResource(t *testing.T) {
 
 			mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 
-			err, statusCode := PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
+			err := sdkClients.PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusTeapot)
 			So(err.Error(), ShouldEqual, `error communication with the transaction api`)
 		})
 
@@ -124,10 +122,9 @@ func TestUnitPatchTransactionWithInsolvencyResource(t *testing.T) {
 
 			mockHelperService.EXPECT().GenerateEtag().Return("etag", nil)
 
-			err, statusCode := PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
+			err := sdkClients.PatchTransactionWithInsolvencyResource("87654321", incomingInsolvencyResourceDao(mockHelperService), &http.Request{})
 
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusOK)
 		})
 	})
 }
@@ -146,9 +143,9 @@ func TestUnitCheckIfTransactionClosed(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusNotFound, "Message: Transaction not found"))
 
-			_, err, statusCode := CheckIfTransactionClosed("87654321", &http.Request{})
+			_, err := sdkClients.CheckIfTransactionClosed("87654321", &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusNotFound)
+			So(err, ShouldHaveSameTypeAs, &apperrors.NotFoundError{})
 			So(err.Error(), ShouldEqual, `transaction not found`)
 		})
 
@@ -157,9 +154,8 @@ func TestUnitCheckIfTransactionClosed(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusTeapot, ""))
 
-			_, err, statusCode := CheckIfTransactionClosed("87654321", &http.Request{})
+			_, err := sdkClients.CheckIfTransactionClosed("87654321", &http.Request{})
 			So(err, ShouldNotBeNil)
-			So(statusCode, ShouldEqual, http.StatusTeapot)
 			So(err.Error(), ShouldContainSubstring, `error getting transaction from transaction api`)
 		})
 
@@ -168,11 +164,10 @@ func TestUnitCheckIfTransactionClosed(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse("closed")))
 
-			isTransactionClosed, err, statusCode := CheckIfTransactionClosed("87654321", &http.Request{})
+			isTransactionClosed, err := sdkClients.CheckIfTransactionClosed("87654321", &http.Request{})
 
 			So(isTransactionClosed, ShouldBeTrue)
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusForbidden)
 		})
 
 		Convey("Provided transaction successfully returned as an open transaction", func() {
@@ -180,11 +175,10 @@ func TestUnitCheckIfTransactionClosed(t *testing.T) {
 
 			httpmock.RegisterResponder(http.MethodGet, apiURL+"/transactions/87654321", httpmock.NewStringResponder(http.StatusOK, transactionProfileResponse("open")))
 
-			isTransactionClosed, err, statusCode := CheckIfTransactionClosed("87654321", &http.Request{})
+			isTransactionClosed, err := sdkClients.CheckIfTransactionClosed("87654321", &http.Request{})
 
 			So(isTransactionClosed, ShouldBeFalse)
 			So(err, ShouldBeNil)
-			So(statusCode, ShouldEqual, http.StatusOK)
 		})
 	})
 }
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/metrics"
+	"github.com/companieshouse/insolvency-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// startUpstreamCall starts timing and tracing an SDK call to an upstream API. It returns a copy of the request with
+// the span in its context, which the SDK session for the call is created from so that the call is made within the
+// span, and the function that records the outcome of the call
+func startUpstreamCall(req *http.Request, upstream, operation string) (*http.Request, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.StartSpan(req.Context(), upstream+"."+operation, attribute.String("upstream", upstream))
+
+	return req.WithContext(ctx), func(err error) {
+		metrics.ObserveUpstreamCall(upstream, operation, start, err)
+		tracing.EndSpan(span, err)
+	}
+}
+
+// upstreamError returns the error for a failed SDK call to an upstream API that responded with the given status.
+// An upstream that could not be reached or was unavailable returns an UpstreamUnavailableError, flagged as a
+// timeout if it did not respond in time, and any other failure returns a plain error with the message
+func upstreamError(statusCode int, err error, message string) error {
+	switch {
+	case statusCode == http.StatusGatewayTimeout || errors.Is(err, context.DeadlineExceeded):
+		return &apperrors.UpstreamUnavailableError{Message: message, Timeout: true, Err: err}
+	case statusCode == 0 || statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable:
+		return &apperrors.UpstreamUnavailableError{Message: message, Err: err}
+	default:
+		return errors.New(message)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestUnitStartUpstreamCall(t *testing.T) {
+	Convey("The SDK call is made with a request holding the span of the call", t, func() {
+		recorder := tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+		parentCtx, parent := otel.Tracer("test").Start(context.Background(), "request")
+		req := httptest.NewRequest(http.MethodGet, "/transactions/12345678/insolvency", nil).WithContext(parentCtx)
+
+		callReq, done := startUpstreamCall(req, "transaction", "Get")
+		callSpanContext := trace.SpanContextFromContext(callReq.Context())
+		done(errors.New("error communicating with the transaction api"))
+		parent.End()
+
+		So(callSpanContext.IsValid(), ShouldBeTrue)
+		So(callSpanContext.TraceID(), ShouldEqual, parent.SpanContext().TraceID())
+		So(callSpanContext.SpanID(), ShouldNotEqual, parent.SpanContext().SpanID())
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 2)
+		So(spans[0].Name(), ShouldEqual, "transaction.Get")
+		So(spans[0].SpanContext().SpanID(), ShouldEqual, callSpanContext.SpanID())
+		So(spans[0].Parent().SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+		So(spans[0].Status().Code, ShouldEqual, codes.Error)
+
+		// The request passed in is left unchanged
+		So(trace.SpanContextFromContext(req.Context()).SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+	})
+}
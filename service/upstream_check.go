@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// upstreamNames are the APIs that the service depends on and whose reachability can be checked for readiness
+var upstreamNames = map[string]struct{}{
+	"transaction":     {},
+	"company-profile": {},
+	"alpha-key":       {},
+	"efs":             {},
+	"file-transfer":   {},
+}
+
+// UpstreamCheck checks that one of the APIs the service depends on can be reached
+type UpstreamCheck struct {
+	Name   string
+	URL    string
+	Client *http.Client
+}
+
+// NewUpstreamChecks creates the upstream checks from comma separated name=url entries, where the name is one of
+// transaction, company-profile, alpha-key, efs or file-transfer
+func NewUpstreamChecks(entries string) ([]UpstreamCheck, error) {
+	var checks []UpstreamCheck
+	seen := make(map[string]struct{})
+	for _, entry := range strings.Split(entries, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, checkURL, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		checkURL = strings.TrimSpace(checkURL)
+		if !found || checkURL == "" {
+			return nil, fmt.Errorf("invalid upstream check [%s]: expected name=url", entry)
+		}
+		if _, ok := upstreamNames[name]; !ok {
+			return nil, fmt.Errorf("invalid upstream check [%s]: unknown upstream [%s]", entry, name)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("invalid upstream check [%s]: upstream [%s] is already checked", entry, name)
+		}
+		if _, err := url.ParseRequestURI(checkURL); err != nil {
+			return nil, fmt.Errorf("invalid upstream check [%s]: [%v]", entry, err)
+		}
+
+		seen[name] = struct{}{}
+		checks = append(checks, UpstreamCheck{Name: name, URL: checkURL, Client: http.DefaultClient})
+	}
+
+	return checks, nil
+}
+
+// Check requests the upstream URL and returns an error if it cannot be reached or responds with a server error.
+// Client errors such as 401 still show the API is reachable
+func (u UpstreamCheck) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request to %s api: [%v]", u.Name, err)
+	}
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error communicating with %s api: [%v]", u.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s api responded with status [%d]", u.Name, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitNewUpstreamChecks(t *testing.T) {
+	Convey("No upstream checks configured", t, func() {
+		checks, err := NewUpstreamChecks("")
+		So(err, ShouldBeNil)
+		So(checks, ShouldBeEmpty)
+	})
+
+	Convey("Upstream checks are parsed", t, func() {
+		checks, err := NewUpstreamChecks("transaction=http://api.chs.local/healthcheck, efs=http://efs.chs.local/healthcheck")
+		So(err, ShouldBeNil)
+		So(checks, ShouldHaveLength, 2)
+		So(checks[0].Name, ShouldEqual, "transaction")
+		So(checks[0].URL, ShouldEqual, "http://api.chs.local/healthcheck")
+		So(checks[1].Name, ShouldEqual, "efs")
+		So(checks[1].URL, ShouldEqual, "http://efs.chs.local/healthcheck")
+	})
+
+	Convey("Upstream check without a URL", t, func() {
+		_, err := NewUpstreamChecks("transaction")
+		So(err.Error(), ShouldContainSubstring, "expected name=url")
+	})
+
+	Convey("Unknown upstream", t, func() {
+		_, err := NewUpstreamChecks("officers=http://api.chs.local")
+		So(err.Error(), ShouldContainSubstring, "unknown upstream [officers]")
+	})
+
+	Convey("Upstream checked twice", t, func() {
+		_, err := NewUpstreamChecks("efs=http://a.local,efs=http://b.local")
+		So(err.Error(), ShouldContainSubstring, "upstream [efs] is already checked")
+	})
+
+	Convey("Invalid URL", t, func() {
+		_, err := NewUpstreamChecks("efs=not a url")
+		So(err.Error(), ShouldContainSubstring, "invalid upstream check [efs=not a url]")
+	})
+}
+
+func TestUnitUpstreamCheck(t *testing.T) {
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	check := UpstreamCheck{Name: "transaction", URL: server.URL, Client: server.Client()}
+
+	Convey("Upstream is reachable", t, func() {
+		statusCode = http.StatusOK
+		So(check.Check(context.Background()), ShouldBeNil)
+	})
+
+	Convey("Upstream rejecting the unauthenticated request is still reachable", t, func() {
+		statusCode = http.StatusUnauthorized
+		So(check.Check(context.Background()), ShouldBeNil)
+	})
+
+	Convey("Upstream responds with a server error", t, func() {
+		statusCode = http.StatusBadGateway
+		So(check.Check(context.Background()).Error(), ShouldEqual, "transaction api responded with status [502]")
+	})
+
+	Convey("Upstream cannot be reached", t, func() {
+		unreachable := UpstreamCheck{Name: "efs", URL: "http://127.0.0.1:1", Client: http.DefaultClient}
+		So(unreachable.Check(context.Background()).Error(), ShouldContainSubstring, "error communicating with efs api")
+	})
+}
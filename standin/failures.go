@@ -0,0 +1,217 @@
+package standin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Failure makes the requests to one of the stand-in APIs fail, respond slowly, or both
+type Failure struct {
+	// API is the stand-in API that the failure applies to: transaction, company-profile, alpha-key, efs or
+	// file-transfer
+	API string `json:"api"`
+
+	// StatusCode is the status code of the failed responses. Requests are only delayed when it is not set
+	StatusCode int `json:"status_code,omitempty"`
+
+	// Rate is the proportion of requests that fail, from 0 to 1. Every request fails when it is not set
+	Rate float64 `json:"rate,omitempty"`
+
+	// Count is the number of requests that fail before the failure is removed. The failure is never removed when
+	// it is not set
+	Count int `json:"count,omitempty"`
+
+	// Delay is how long every request to the API waits before it is served, such as 2s
+	Delay string `json:"delay,omitempty"`
+
+	delay time.Duration
+}
+
+// validate checks the failure and parses its delay
+func (f *Failure) validate() error {
+	if !isAPI(f.API) {
+		return fmt.Errorf("unknown api [%s]: expected one of %s", f.API, strings.Join(apis, ", "))
+	}
+	if f.StatusCode != 0 && (f.StatusCode < 100 || f.StatusCode > 599) {
+		return fmt.Errorf("invalid status code [%d]", f.StatusCode)
+	}
+	if f.Rate < 0 || f.Rate > 1 {
+		return fmt.Errorf("invalid rate [%v]: expected a value from 0 to 1", f.Rate)
+	}
+	if f.Count < 0 {
+		return fmt.Errorf("invalid count [%d]", f.Count)
+	}
+
+	f.delay = 0
+	if f.Delay != "" {
+		delay, err := time.ParseDuration(f.Delay)
+		if err != nil {
+			return fmt.Errorf("invalid delay [%s]: [%v]", f.Delay, err)
+		}
+		f.delay = delay
+	}
+
+	return nil
+}
+
+// isAPI returns whether the name is the name of a stand-in API
+func isAPI(name string) bool {
+	for _, api := range apis {
+		if api == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFailure parses a failure given as api=status[:rate], such as file-transfer=503:0.5 to fail half of the
+// requests to the File Transfer stand-in
+func ParseFailure(spec string) (Failure, error) {
+	api, value, found := strings.Cut(spec, "=")
+	if !found {
+		return Failure{}, fmt.Errorf("invalid failure [%s]: expected api=status[:rate]", spec)
+	}
+
+	failure := Failure{API: strings.TrimSpace(api)}
+	status, rate, hasRate := strings.Cut(strings.TrimSpace(value), ":")
+
+	var err error
+	if failure.StatusCode, err = strconv.Atoi(status); err != nil {
+		return Failure{}, fmt.Errorf("invalid failure [%s]: invalid status code [%s]", spec, status)
+	}
+	if hasRate {
+		if failure.Rate, err = strconv.ParseFloat(rate, 64); err != nil {
+			return Failure{}, fmt.Errorf("invalid failure [%s]: invalid rate [%s]", spec, rate)
+		}
+	}
+
+	if err = failure.validate(); err != nil {
+		return Failure{}, fmt.Errorf("invalid failure [%s]: %v", spec, err)
+	}
+
+	return failure, nil
+}
+
+// ParseDelay parses a delay given as api=duration, such as efs=2s to delay every request to the EFS stand-in
+func ParseDelay(spec string) (Failure, error) {
+	api, delay, found := strings.Cut(spec, "=")
+	if !found {
+		return Failure{}, fmt.Errorf("invalid delay [%s]: expected api=duration", spec)
+	}
+
+	failure := Failure{API: strings.TrimSpace(api), Delay: strings.TrimSpace(delay)}
+	if err := failure.validate(); err != nil {
+		return Failure{}, fmt.Errorf("invalid delay [%s]: %v", spec, err)
+	}
+
+	return failure, nil
+}
+
+// SetFailure makes requests to the failure's API fail as it describes, replacing any failure of that API
+func (s *Server) SetFailure(failure Failure) error {
+	if err := failure.validate(); err != nil {
+		return err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.failures[failure.API] = &failure
+	return nil
+}
+
+// ClearFailure removes the failure of the API
+func (s *Server) ClearFailure(api string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.failures, api)
+}
+
+// ClearFailures removes the failures of every API
+func (s *Server) ClearFailures() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.failures = make(map[string]*Failure)
+}
+
+// nextFailure returns the delay and, when the request should fail, the status code of the next request to the API
+func (s *Server) nextFailure(api string) (time.Duration, int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	failure, ok := s.failures[api]
+	if !ok {
+		return 0, 0
+	}
+	if failure.StatusCode == 0 || (failure.Rate > 0 && s.random() >= failure.Rate) {
+		return failure.delay, 0
+	}
+
+	if failure.Count > 0 {
+		failure.Count--
+		if failure.Count == 0 {
+			delete(s.failures, api)
+		}
+	}
+
+	return failure.delay, failure.StatusCode
+}
+
+// withFailures delays or fails the requests to the API as its failure describes
+func (s *Server) withFailures(api string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		delay, statusCode := s.nextFailure(api)
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return
+			}
+		}
+
+		if statusCode != 0 {
+			writeError(w, statusCode, fmt.Sprintf("injected failure of the %s stand-in", api))
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// handleSetFailure sets the failure of an API from the request
+func (s *Server) handleSetFailure(w http.ResponseWriter, req *http.Request) {
+	var failure Failure
+	if err := json.NewDecoder(req.Body).Decode(&failure); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid failure: [%v]", err))
+		return
+	}
+	failure.API = mux.Vars(req)["api"]
+
+	if err := s.SetFailure(failure); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid failure: [%v]", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleClearFailure removes the failure of an API
+func (s *Server) handleClearFailure(w http.ResponseWriter, req *http.Request) {
+	s.ClearFailure(mux.Vars(req)["api"])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleClearFailures removes the failures of every API
+func (s *Server) handleClearFailures(w http.ResponseWriter, req *http.Request) {
+	s.ClearFailures()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package standin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// The anti-virus statuses reported by the File Transfer API
+const (
+	AVStatusNotScanned = "not-scanned"
+	AVStatusClean      = "clean"
+	AVStatusInfected   = "infected"
+)
+
+// file is a file uploaded to the File Transfer stand-in
+type file struct {
+	name        string
+	contentType string
+	contents    []byte
+	scannedAt   time.Time
+	infected    bool
+
+	// avStatus overrides the simulated scan result when it has been scripted
+	avStatus string
+}
+
+// status returns the anti-virus status of the file at the time
+func (f *file) status(now time.Time) string {
+	if f.avStatus != "" {
+		return f.avStatus
+	}
+	if now.Before(f.scannedAt) {
+		return AVStatusNotScanned
+	}
+	if f.infected {
+		return AVStatusInfected
+	}
+	return AVStatusClean
+}
+
+// fileDetails is the response of the File Transfer API describing a file
+type fileDetails struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	AVStatus    string `json:"av_status"`
+}
+
+// handleUploadFile stores the file in the multipart request. Its scan completes after the AV scan delay, finding
+// it infected if its name or contents contain the infected marker
+func (s *Server) handleUploadFile(w http.ResponseWriter, req *http.Request) {
+	uploaded, header, err := req.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("error reading file from request: [%v]", err))
+		return
+	}
+	defer uploaded.Close()
+
+	contents, err := io.ReadAll(uploaded)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("error reading file from request: [%v]", err))
+		return
+	}
+
+	marker := s.opts.InfectedMarker
+	f := &file{
+		name:        header.Filename,
+		contentType: header.Header.Get("Content-Type"),
+		contents:    contents,
+		scannedAt:   s.now().Add(s.opts.AVScanDelay),
+		infected:    strings.Contains(header.Filename, marker) || bytes.Contains(contents, []byte(marker)),
+	}
+
+	fileID := uuid.NewString()
+	s.mtx.Lock()
+	s.files[fileID] = f
+	s.mtx.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": fileID})
+}
+
+// handleGetFile returns the details of a file, including its anti-virus status
+func (s *Server) handleGetFile(w http.ResponseWriter, req *http.Request) {
+	fileID := mux.Vars(req)["file_id"]
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("file [%s] not found", fileID))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fileDetails{
+		ID:          fileID,
+		Name:        f.name,
+		Size:        int64(len(f.contents)),
+		ContentType: f.contentType,
+		AVStatus:    f.status(s.now()),
+	})
+}
+
+// handleDownloadFile writes the contents of a file, which can only be downloaded once it has been scanned as clean
+func (s *Server) handleDownloadFile(w http.ResponseWriter, req *http.Request) {
+	fileID := mux.Vars(req)["file_id"]
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("file [%s] not found", fileID))
+		return
+	}
+	if avStatus := f.status(s.now()); avStatus != AVStatusClean {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("file [%s] cannot be downloaded as it is %s", fileID, avStatus))
+		return
+	}
+
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", f.name))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(f.contents)
+}
+
+// handleDeleteFile deletes a file
+func (s *Server) handleDeleteFile(w http.ResponseWriter, req *http.Request) {
+	fileID := mux.Vars(req)["file_id"]
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.files[fileID]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("file [%s] not found", fileID))
+		return
+	}
+
+	delete(s.files, fileID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetAVStatus sets the anti-virus status of a file, overriding the simulated scan
+func (s *Server) handleSetAVStatus(w http.ResponseWriter, req *http.Request) {
+	fileID := mux.Vars(req)["file_id"]
+
+	var body struct {
+		AVStatus string `json:"av_status"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.AVStatus == "" {
+		writeError(w, http.StatusBadRequest, "av_status is required")
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	f, ok := s.files[fileID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("file [%s] not found", fileID))
+		return
+	}
+
+	f.avStatus = body.AVStatus
+	w.WriteHeader(http.StatusNoContent)
+}
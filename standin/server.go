@@ -0,0 +1,345 @@
+// Package standin serves stand-ins for the upstream Companies House APIs that the insolvency API calls, so that the
+// API can be run and tested end to end without them. It fakes the transaction, company profile, alpha key, EFS
+// allow list and File Transfer APIs, and can be seeded and scripted over HTTP.
+package standin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// The names of the stand-in APIs, matching the upstream names used by the insolvency API's metrics and readiness
+// checks
+const (
+	APITransaction    = "transaction"
+	APICompanyProfile = "company-profile"
+	APIAlphaKey       = "alpha-key"
+	APIEfs            = "efs"
+	APIFileTransfer   = "file-transfer"
+)
+
+// apis are the names of the stand-in APIs
+var apis = []string{APITransaction, APICompanyProfile, APIAlphaKey, APIEfs, APIFileTransfer}
+
+// DefaultInfectedMarker is the EICAR anti-virus test string. Files whose name or contents contain it are reported
+// as infected unless another marker is configured
+const DefaultInfectedMarker = "EICAR-STANDARD-ANTIVIRUS-TEST-FILE"
+
+// Address is the registered office address of a company
+type Address struct {
+	Premises     string `json:"premises,omitempty"`
+	AddressLine1 string `json:"address_line_1,omitempty"`
+	AddressLine2 string `json:"address_line_2,omitempty"`
+	Locality     string `json:"locality,omitempty"`
+	Region       string `json:"region,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+	Country      string `json:"country,omitempty"`
+}
+
+// Company is a company served by the company profile stand-in
+type Company struct {
+	CompanyName             string   `json:"company_name"`
+	CompanyNumber           string   `json:"company_number"`
+	CompanyStatus           string   `json:"company_status"`
+	DateOfCreation          string   `json:"date_of_creation"`
+	Jurisdiction            string   `json:"jurisdiction"`
+	Type                    string   `json:"type"`
+	RegisteredOfficeAddress *Address `json:"registered_office_address,omitempty"`
+}
+
+// Transaction is a transaction served by the transaction stand-in. Resources holds the resources that the
+// transaction has been patched with, by their self link
+type Transaction struct {
+	ID            string                     `json:"id"`
+	Status        string                     `json:"status"`
+	CompanyNumber string                     `json:"company_number,omitempty"`
+	CompanyName   string                     `json:"company_name,omitempty"`
+	Resources     map[string]json.RawMessage `json:"resources,omitempty"`
+}
+
+// Seed is data added to the stand-ins, replacing any company or transaction with the same number or ID. A
+// transaction without a status is open
+type Seed struct {
+	Companies    []Company     `json:"companies"`
+	Transactions []Transaction `json:"transactions"`
+	AllowList    []string      `json:"allow_list"`
+}
+
+// Options configures the behaviour of the File Transfer stand-in
+type Options struct {
+	// AVScanDelay is how long uploaded files are reported as not-scanned before their scan completes
+	AVScanDelay time.Duration
+
+	// InfectedMarker is the text that marks a file as infected when it is in its name or contents.
+	// DefaultInfectedMarker is used when it is not set
+	InfectedMarker string
+}
+
+// Server serves the stand-in APIs
+type Server struct {
+	mtx          sync.RWMutex
+	router       *mux.Router
+	opts         Options
+	companies    map[string]Company
+	transactions map[string]Transaction
+	allowList    map[string]bool
+	files        map[string]*file
+	failures     map[string]*Failure
+
+	// now and random are replaced in tests
+	now    func() time.Time
+	random func() float64
+}
+
+// NewServer returns a Server with no data and no failures
+func NewServer(opts Options) *Server {
+	if opts.InfectedMarker == "" {
+		opts.InfectedMarker = DefaultInfectedMarker
+	}
+
+	s := &Server{
+		router: mux.NewRouter(),
+		opts:   opts,
+		now:    time.Now,
+		random: rand.Float64,
+	}
+	s.Reset()
+	s.registerRoutes()
+
+	return s
+}
+
+// registerRoutes adds the routes of the stand-in APIs and the routes that script them
+func (s *Server) registerRoutes() {
+	handle := func(api, path string, handler http.HandlerFunc, methods ...string) {
+		s.router.Handle(path, s.withFailures(api, handler)).Methods(methods...)
+	}
+
+	handle(APICompanyProfile, "/company/{company_number}", s.handleGetCompany, http.MethodGet)
+	handle(APITransaction, "/transactions/{transaction_id}", s.handleGetTransaction, http.MethodGet)
+	handle(APITransaction, "/private/transactions/{transaction_id}", s.handlePatchTransaction, http.MethodPatch)
+	handle(APIAlphaKey, "/alphakey", s.handleGetAlphaKey, http.MethodGet)
+	handle(APIEfs, "/efs-submission-api/company-authentication/allow-list/{email}", s.handleGetAllowList, http.MethodGet)
+	handle(APIFileTransfer, "/files", s.handleUploadFile, http.MethodPost)
+	handle(APIFileTransfer, "/files/{file_id}", s.handleGetFile, http.MethodGet)
+	handle(APIFileTransfer, "/files/{file_id}/download", s.handleDownloadFile, http.MethodGet)
+	handle(APIFileTransfer, "/files/{file_id}", s.handleDeleteFile, http.MethodDelete)
+
+	s.router.HandleFunc("/stand-in/seed", s.handleSeed).Methods(http.MethodPost)
+	s.router.HandleFunc("/stand-in/reset", s.handleReset).Methods(http.MethodPost)
+	s.router.HandleFunc("/stand-in/failures", s.handleClearFailures).Methods(http.MethodDelete)
+	s.router.HandleFunc("/stand-in/failures/{api}", s.handleSetFailure).Methods(http.MethodPut)
+	s.router.HandleFunc("/stand-in/failures/{api}", s.handleClearFailure).Methods(http.MethodDelete)
+	s.router.HandleFunc("/stand-in/files/{file_id}/av-status", s.handleSetAVStatus).Methods(http.MethodPut)
+}
+
+// ServeHTTP serves the stand-in APIs
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.router.ServeHTTP(w, req)
+}
+
+// Seed adds the companies, transactions and allowed email addresses
+func (s *Server) Seed(seed Seed) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, company := range seed.Companies {
+		s.companies[company.CompanyNumber] = company
+	}
+	for _, transaction := range seed.Transactions {
+		if transaction.Status == "" {
+			transaction.Status = "open"
+		}
+		if transaction.Resources == nil {
+			transaction.Resources = make(map[string]json.RawMessage)
+		}
+		s.transactions[transaction.ID] = transaction
+	}
+	for _, emailAddress := range seed.AllowList {
+		s.allowList[emailAddress] = true
+	}
+}
+
+// LoadSeed reads a seed from a JSON file
+func LoadSeed(path string) (Seed, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return Seed{}, fmt.Errorf("error reading seed file: [%v]", err)
+	}
+
+	var seed Seed
+	if err = json.Unmarshal(contents, &seed); err != nil {
+		return Seed{}, fmt.Errorf("error parsing seed file [%s]: [%v]", path, err)
+	}
+
+	return seed, nil
+}
+
+// Reset removes all companies, transactions, allowed email addresses, files and failures
+func (s *Server) Reset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.companies = make(map[string]Company)
+	s.transactions = make(map[string]Transaction)
+	s.allowList = make(map[string]bool)
+	s.files = make(map[string]*file)
+	s.failures = make(map[string]*Failure)
+}
+
+// Transaction returns the transaction with the ID, including the resources it has been patched with
+func (s *Server) Transaction(transactionID string) (Transaction, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	transaction, ok := s.transactions[transactionID]
+	return transaction, ok
+}
+
+// handleGetCompany returns the profile of a company
+func (s *Server) handleGetCompany(w http.ResponseWriter, req *http.Request) {
+	companyNumber := mux.Vars(req)["company_number"]
+
+	s.mtx.RLock()
+	company, ok := s.companies[companyNumber]
+	s.mtx.RUnlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("company [%s] not found", companyNumber))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, company)
+}
+
+// handleGetTransaction returns a transaction
+func (s *Server) handleGetTransaction(w http.ResponseWriter, req *http.Request) {
+	transactionID := mux.Vars(req)["transaction_id"]
+
+	transaction, ok := s.Transaction(transactionID)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("transaction [%s] not found", transactionID))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// handlePatchTransaction adds the resources in the request to a transaction
+func (s *Server) handlePatchTransaction(w http.ResponseWriter, req *http.Request) {
+	transactionID := mux.Vars(req)["transaction_id"]
+
+	var patch struct {
+		Resources map[string]json.RawMessage `json:"resources"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: [%v]", err))
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	transaction, ok := s.transactions[transactionID]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("transaction [%s] not found", transactionID))
+		return
+	}
+	if transaction.Status != "open" {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("transaction [%s] is %s", transactionID, transaction.Status))
+		return
+	}
+
+	for self, resource := range patch.Resources {
+		transaction.Resources[self] = resource
+	}
+	s.transactions[transactionID] = transaction
+
+	writeJSON(w, http.StatusOK, transaction)
+}
+
+// handleGetAlphaKey returns the alpha keys of a company name
+func (s *Server) handleGetAlphaKey(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("name")
+	alphaKey := AlphaKey(name)
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"sameAsAlphaKey":  alphaKey,
+		"orderedAlphaKey": alphaKey,
+		"upperCaseName":   strings.ToUpper(name),
+	})
+}
+
+// companyNameEndings are the endings that do not distinguish one company name from another
+var companyNameEndings = []string{"LIMITED", "LTD", "PLC", "LLP"}
+
+// AlphaKey returns the key that the alpha key stand-in gives a company name: its letters and digits in upper case,
+// without any ending such as LIMITED or LTD
+func AlphaKey(name string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, name)
+
+	for _, ending := range companyNameEndings {
+		if trimmed := strings.TrimSuffix(key, ending); trimmed != "" {
+			key = trimmed
+		}
+	}
+
+	return key
+}
+
+// handleGetAllowList returns whether an email address is on the EFS allow list
+func (s *Server) handleGetAllowList(w http.ResponseWriter, req *http.Request) {
+	emailAddress := mux.Vars(req)["email"]
+
+	s.mtx.RLock()
+	allowed := s.allowList[emailAddress]
+	s.mtx.RUnlock()
+
+	writeJSON(w, http.StatusOK, allowed)
+}
+
+// handleSeed adds the seed in the request
+func (s *Server) handleSeed(w http.ResponseWriter, req *http.Request) {
+	var seed Seed
+	if err := json.NewDecoder(req.Body).Decode(&seed); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid seed: [%v]", err))
+		return
+	}
+
+	s.Seed(seed)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReset removes all data and failures
+func (s *Server) handleReset(w http.ResponseWriter, req *http.Request) {
+	s.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes the body as JSON with the status code
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error response in the style of the Companies House APIs
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]interface{}{
+		"errors": []map[string]string{{"error": message, "type": "ch:service"}},
+	})
+}
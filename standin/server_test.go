@@ -0,0 +1,224 @@
+package standin
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// serve sends the request to the server and returns the response
+func serve(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	return res
+}
+
+// upload uploads a file to the File Transfer stand-in and returns its ID
+func upload(t *testing.T, s *Server, name, contents string) string {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(contents))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, req)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("upload failed with status %d: %s", res.Code, res.Body.String())
+	}
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err = json.Unmarshal(res.Body.Bytes(), &uploaded); err != nil {
+		t.Fatal(err)
+	}
+	return uploaded.ID
+}
+
+// avStatus returns the anti-virus status reported for the file
+func avStatus(t *testing.T, s *Server, fileID string) string {
+	res := serve(s, http.MethodGet, "/files/"+fileID, "")
+	var details fileDetails
+	if err := json.Unmarshal(res.Body.Bytes(), &details); err != nil {
+		t.Fatal(err)
+	}
+	return details.AVStatus
+}
+
+func TestUnitStandInCompaniesAndTransactions(t *testing.T) {
+	Convey("Seeded companies and transactions are served", t, func() {
+		s := NewServer(Options{})
+		res := serve(s, http.MethodPost, "/stand-in/seed", `{
+			"companies": [{"company_number": "01234567", "company_name": "Test Company Ltd", "company_status": "active", "jurisdiction": "england-wales", "type": "ltd"}],
+			"transactions": [{"id": "12345678", "company_number": "01234567"}, {"id": "87654321", "status": "closed"}],
+			"allow_list": ["user@example.com"]
+		}`)
+		So(res.Code, ShouldEqual, http.StatusNoContent)
+
+		res = serve(s, http.MethodGet, "/company/01234567", "")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldContainSubstring, `"jurisdiction":"england-wales"`)
+		So(serve(s, http.MethodGet, "/company/99999999", "").Code, ShouldEqual, http.StatusNotFound)
+
+		res = serve(s, http.MethodGet, "/transactions/12345678", "")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldContainSubstring, `"status":"open"`)
+
+		Convey("Open transactions are patched with the resources in the request", func() {
+			patch := `{"resources": {"/transactions/12345678/insolvency": {"kind": "insolvency-resource"}}}`
+			So(serve(s, http.MethodPatch, "/private/transactions/12345678", patch).Code, ShouldEqual, http.StatusOK)
+
+			transaction, ok := s.Transaction("12345678")
+			So(ok, ShouldBeTrue)
+			So(string(transaction.Resources["/transactions/12345678/insolvency"]), ShouldEqual, `{"kind": "insolvency-resource"}`)
+
+			So(serve(s, http.MethodPatch, "/private/transactions/87654321", patch).Code, ShouldEqual, http.StatusForbidden)
+			So(serve(s, http.MethodPatch, "/private/transactions/11111111", patch).Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("The EFS allow list holds the seeded email addresses", func() {
+			So(serve(s, http.MethodGet, "/efs-submission-api/company-authentication/allow-list/user@example.com", "").Body.String(), ShouldEqual, "true\n")
+			So(serve(s, http.MethodGet, "/efs-submission-api/company-authentication/allow-list/other@example.com", "").Body.String(), ShouldEqual, "false\n")
+		})
+
+		Convey("Resetting removes the seeded data", func() {
+			So(serve(s, http.MethodPost, "/stand-in/reset", "").Code, ShouldEqual, http.StatusNoContent)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
+
+func TestUnitStandInAlphaKey(t *testing.T) {
+	Convey("Company names differing only in case, punctuation and ending share an alpha key", t, func() {
+		So(AlphaKey("Test Company Limited"), ShouldEqual, "TESTCOMPANY")
+		So(AlphaKey("test-company ltd."), ShouldEqual, "TESTCOMPANY")
+		So(AlphaKey("Limited"), ShouldEqual, "LIMITED")
+
+		res := serve(NewServer(Options{}), http.MethodGet, "/alphakey?name=Test+Company+Ltd", "")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldContainSubstring, `"sameAsAlphaKey":"TESTCOMPANY"`)
+		So(res.Body.String(), ShouldContainSubstring, `"upperCaseName":"TEST COMPANY LTD"`)
+	})
+}
+
+func TestUnitStandInFileTransfer(t *testing.T) {
+	Convey("Uploaded files are scanned after the AV scan delay", t, func() {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := NewServer(Options{AVScanDelay: time.Minute})
+		s.now = func() time.Time { return now }
+
+		clean := upload(t, s, "resolution.pdf", "resolution")
+		infected := upload(t, s, "statement.pdf", "X5O!P%@AP "+DefaultInfectedMarker)
+
+		So(avStatus(t, s, clean), ShouldEqual, AVStatusNotScanned)
+		So(avStatus(t, s, infected), ShouldEqual, AVStatusNotScanned)
+		So(serve(s, http.MethodGet, "/files/"+clean+"/download", "").Code, ShouldEqual, http.StatusForbidden)
+
+		now = now.Add(time.Minute)
+		So(avStatus(t, s, clean), ShouldEqual, AVStatusClean)
+		So(avStatus(t, s, infected), ShouldEqual, AVStatusInfected)
+
+		res := serve(s, http.MethodGet, "/files/"+clean+"/download", "")
+		So(res.Code, ShouldEqual, http.StatusOK)
+		So(res.Body.String(), ShouldEqual, "resolution")
+		So(serve(s, http.MethodGet, "/files/"+infected+"/download", "").Code, ShouldEqual, http.StatusForbidden)
+
+		Convey("The scan result can be scripted", func() {
+			So(serve(s, http.MethodPut, "/stand-in/files/"+clean+"/av-status", `{"av_status": "infected"}`).Code, ShouldEqual, http.StatusNoContent)
+			So(avStatus(t, s, clean), ShouldEqual, AVStatusInfected)
+			So(serve(s, http.MethodPut, "/stand-in/files/unknown/av-status", `{"av_status": "clean"}`).Code, ShouldEqual, http.StatusNotFound)
+		})
+
+		Convey("Deleted files are not found", func() {
+			So(serve(s, http.MethodDelete, "/files/"+clean, "").Code, ShouldEqual, http.StatusNoContent)
+			So(serve(s, http.MethodGet, "/files/"+clean, "").Code, ShouldEqual, http.StatusNotFound)
+			So(serve(s, http.MethodDelete, "/files/"+clean, "").Code, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
+
+func TestUnitStandInFailures(t *testing.T) {
+	Convey("Failures are injected into the requests to an API", t, func() {
+		s := NewServer(Options{})
+		s.Seed(Seed{Transactions: []Transaction{{ID: "12345678"}}, Companies: []Company{{CompanyNumber: "01234567"}}})
+
+		Convey("Every request fails until the failure is cleared", func() {
+			So(serve(s, http.MethodPut, "/stand-in/failures/transaction", `{"status_code": 503}`).Code, ShouldEqual, http.StatusNoContent)
+			So(serve(s, http.MethodGet, "/transactions/12345678", "").Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusOK)
+
+			So(serve(s, http.MethodDelete, "/stand-in/failures/transaction", "").Code, ShouldEqual, http.StatusNoContent)
+			So(serve(s, http.MethodGet, "/transactions/12345678", "").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("A counted failure is removed once it has failed that many requests", func() {
+			So(s.SetFailure(Failure{API: APICompanyProfile, StatusCode: 500, Count: 2}), ShouldBeNil)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusInternalServerError)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusInternalServerError)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Only the proportion of requests given by the rate fail", func() {
+			rolls := []float64{0.1, 0.9}
+			s.random = func() float64 {
+				roll := rolls[0]
+				rolls = rolls[1:]
+				return roll
+			}
+			So(s.SetFailure(Failure{API: APICompanyProfile, StatusCode: 500, Rate: 0.5}), ShouldBeNil)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusInternalServerError)
+			So(serve(s, http.MethodGet, "/company/01234567", "").Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("A failure without a status code only delays requests", func() {
+			So(s.SetFailure(Failure{API: APITransaction, Delay: "20ms"}), ShouldBeNil)
+			start := time.Now()
+			So(serve(s, http.MethodGet, "/transactions/12345678", "").Code, ShouldEqual, http.StatusOK)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+		})
+
+		Convey("Invalid failures are rejected", func() {
+			So(serve(s, http.MethodPut, "/stand-in/failures/unknown", `{"status_code": 500}`).Code, ShouldEqual, http.StatusBadRequest)
+			So(serve(s, http.MethodPut, "/stand-in/failures/efs", `{"rate": 2}`).Code, ShouldEqual, http.StatusBadRequest)
+			So(serve(s, http.MethodPut, "/stand-in/failures/efs", `{"delay": "soon"}`).Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestUnitParseFailure(t *testing.T) {
+	Convey("Failures are parsed from flags", t, func() {
+		failure, err := ParseFailure("file-transfer=503:0.5")
+		So(err, ShouldBeNil)
+		So(failure.API, ShouldEqual, APIFileTransfer)
+		So(failure.StatusCode, ShouldEqual, 503)
+		So(failure.Rate, ShouldEqual, 0.5)
+
+		failure, err = ParseDelay("efs=2s")
+		So(err, ShouldBeNil)
+		So(failure.API, ShouldEqual, APIEfs)
+		So(failure.Delay, ShouldEqual, "2s")
+
+		_, err = ParseFailure("transaction")
+		So(err.Error(), ShouldEqual, "invalid failure [transaction]: expected api=status[:rate]")
+		_, err = ParseFailure("transaction=bad")
+		So(err.Error(), ShouldEqual, "invalid failure [transaction=bad]: invalid status code [bad]")
+		_, err = ParseFailure("ledger=500")
+		So(err.Error(), ShouldContainSubstring, "unknown api [ledger]")
+		_, err = ParseDelay("efs=soon")
+		So(err.Error(), ShouldContainSubstring, "invalid delay [soon]")
+	})
+}
@@ -30,12 +30,22 @@ variable "docker_registry" {
 variable "desired_task_count" {
   type        = number
   description = "The desired ECS task count for this service"
-  default     = 1 # defaulted low for dev environments, override for production if required
+  default     = 1 # must not exceed max_task_count, which is limited to a single task
+
+  validation {
+    condition     = var.desired_task_count <= 1
+    error_message = "The audit trail attributes changes correctly only while a single task is running."
+  }
 }
 variable "max_task_count" {
   type        = number
-  description = "The maximum number of tasks for this service."
-  default     = 3
+  description = "The maximum number of tasks for this service. Must stay at 1, as the audit trail only serializes the requests that change a case within one task"
+  default     = 1
+
+  validation {
+    condition     = var.max_task_count == 1
+    error_message = "The audit trail attributes changes correctly only while a single task is running."
+  }
 }
 variable "required_cpus" {
   type        = number
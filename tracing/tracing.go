@@ -0,0 +1,120 @@
+// Package tracing configures OpenTelemetry tracing for the service and provides the helpers used to create spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/companieshouse/insolvency-api/httpstatus"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName = "insolvency-api"
+	tracerName  = "github.com/companieshouse/insolvency-api"
+
+	// otlpTracesPath is appended to the OTLP endpoint to give the URL that traces are sent to
+	otlpTracesPath = "/v1/traces"
+)
+
+// Init sets up W3C trace context propagation and, if tracing is enabled, the global tracer provider. Spans are
+// exported over OTLP when an endpoint is configured, otherwise they are written to stdout for local debugging. The
+// returned function flushes and stops the exporter
+func Init(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.EnableTracing {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating tracing resource: [%v]", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tracerProvider)
+
+	return tracerProvider.Shutdown, nil
+}
+
+// newExporter creates the OTLP exporter if an endpoint is configured, or the stdout exporter if not
+func newExporter(ctx context.Context, cfg *config.Config) (sdktrace.SpanExporter, error) {
+	if cfg.OtlpEndpoint == "" {
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("error creating stdout trace exporter: [%v]", err)
+		}
+		return exporter, nil
+	}
+
+	endpointURL := strings.TrimSuffix(cfg.OtlpEndpoint, "/") + otlpTracesPath
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpointURL))
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP trace exporter for [%s]: [%v]", endpointURL, err)
+	}
+	return exporter, nil
+}
+
+// StartSpan starts a span with the given name as a child of any span in the context
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan records the error on the span, if there is one, and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// HTTPMiddleware continues any trace in the W3C trace context headers of the request and wraps the handler for the
+// matched route in a server span
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		spanName := req.Method
+		attributes := []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(req.Method), semconv.URLPath(req.URL.Path)}
+		if route := mux.CurrentRoute(req); route != nil {
+			if pathTemplate, err := route.GetPathTemplate(); err == nil {
+				spanName = req.Method + " " + pathTemplate
+				attributes = append(attributes, semconv.HTTPRoute(pathTemplate))
+			}
+			if route.GetName() != "" {
+				attributes = append(attributes, attribute.String("route.name", route.GetName()))
+			}
+		}
+
+		ctx, span := otel.Tracer(tracerName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+		defer span.End()
+
+		recorder := httpstatus.NewRecorder(w)
+		next.ServeHTTP(recorder, req.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.Status))
+		if recorder.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.Status))
+		}
+	})
+}
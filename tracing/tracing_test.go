@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/config"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans replaces the global tracer provider with one that records the spans that are ended
+func recordSpans() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestUnitInit(t *testing.T) {
+	Convey("Tracing is disabled unless enabled", t, func() {
+		shutdown, err := Init(context.Background(), &config.Config{})
+		So(err, ShouldBeNil)
+		So(shutdown(context.Background()), ShouldBeNil)
+	})
+
+	Convey("Spans are written to stdout when no OTLP endpoint is configured", t, func() {
+		shutdown, err := Init(context.Background(), &config.Config{EnableTracing: true})
+		So(err, ShouldBeNil)
+		So(shutdown(context.Background()), ShouldBeNil)
+	})
+
+	Convey("Spans are exported to the configured OTLP endpoint", t, func() {
+		shutdown, err := Init(context.Background(), &config.Config{EnableTracing: true, OtlpEndpoint: "http://localhost:4318/"})
+		So(err, ShouldBeNil)
+		So(shutdown(context.Background()), ShouldBeNil)
+	})
+}
+
+func TestUnitHTTPMiddleware(t *testing.T) {
+	Convey("Trace context is continued from the incoming request", t, func() {
+		recorder := recordSpans()
+
+		var handlerSpanContext trace.SpanContext
+		router := mux.NewRouter()
+		router.HandleFunc("/transactions/{transaction_id}/insolvency", func(w http.ResponseWriter, req *http.Request) {
+			handlerSpanContext = trace.SpanContextFromContext(req.Context())
+			w.WriteHeader(http.StatusCreated)
+		}).Methods(http.MethodPost).Name("createInsolvencyResource")
+		router.Use(HTTPMiddleware)
+
+		req := httptest.NewRequest(http.MethodPost, "/transactions/12345678/insolvency", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 1)
+		So(spans[0].Name(), ShouldEqual, "POST /transactions/{transaction_id}/insolvency")
+		So(spans[0].SpanKind(), ShouldEqual, trace.SpanKindServer)
+		So(spans[0].Parent().TraceID().String(), ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+		So(spans[0].Parent().SpanID().String(), ShouldEqual, "00f067aa0ba902b7")
+		So(handlerSpanContext.SpanID(), ShouldEqual, spans[0].SpanContext().SpanID())
+	})
+
+	Convey("Server errors set the span status", t, func() {
+		recorder := recordSpans()
+
+		router := mux.NewRouter()
+		router.HandleFunc("/fail", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}).Name("fail")
+		router.Use(HTTPMiddleware)
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 1)
+		So(spans[0].Status().Code, ShouldEqual, codes.Error)
+	})
+}
+
+func TestUnitEndSpan(t *testing.T) {
+	Convey("Errors are recorded on the span", t, func() {
+		recorder := recordSpans()
+
+		_, span := StartSpan(context.Background(), "transaction.Get")
+		EndSpan(span, errors.New("error communicating with the transaction api"))
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 1)
+		So(spans[0].Status().Code, ShouldEqual, codes.Error)
+		So(spans[0].Status().Description, ShouldEqual, "error communicating with the transaction api")
+		So(spans[0].Events(), ShouldHaveLength, 1)
+	})
+
+	Convey("Successful spans are left unset", t, func() {
+		recorder := recordSpans()
+
+		_, span := StartSpan(context.Background(), "transaction.Get")
+		EndSpan(span, nil)
+
+		spans := recorder.Ended()
+		So(spans, ShouldHaveLength, 1)
+		So(spans[0].Status().Code, ShouldEqual, codes.Unset)
+	})
+}
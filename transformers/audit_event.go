@@ -0,0 +1,50 @@
+package transformers
+
+import (
+	"encoding/json"
+
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// AuditEventDaoToResponse transforms an audit event dao model to the response model
+func AuditEventDaoToResponse(dao *models.AuditEventDao) models.AuditEvent {
+	changes := make([]models.AuditChange, 0, len(dao.Changes))
+	for _, change := range dao.Changes {
+		changes = append(changes, models.AuditChange{
+			Path:   change.Path,
+			Before: rawJSON(change.Before),
+			After:  rawJSON(change.After),
+		})
+	}
+
+	return models.AuditEvent{
+		ID:            dao.ID,
+		TransactionID: dao.TransactionID,
+		CompanyNumber: dao.CompanyNumber,
+		UserID:        dao.UserID,
+		Email:         dao.Email,
+		Route:         dao.Route,
+		Method:        dao.Method,
+		Path:          dao.Path,
+		Status:        dao.StatusCode,
+		CreatedAt:     dao.CreatedAt,
+		Changes:       changes,
+	}
+}
+
+// AuditEventDaoListToResponseList transforms a list of audit event dao models to a list of the response model
+func AuditEventDaoListToResponseList(daos []models.AuditEventDao) []models.AuditEvent {
+	events := make([]models.AuditEvent, 0, len(daos))
+	for i := range daos {
+		events = append(events, AuditEventDaoToResponse(&daos[i]))
+	}
+	return events
+}
+
+// rawJSON returns a JSON encoded value, or nil for an audit change to a field that did not exist
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
@@ -0,0 +1,53 @@
+package transformers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitAuditEventDaoListToResponseList(t *testing.T) {
+	Convey("field mappings are correct", t, func() {
+		createdAt := time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC)
+		daos := []models.AuditEventDao{{
+			ID:            "event1",
+			TransactionID: "1234",
+			CompanyNumber: "01234567",
+			UserID:        "user1",
+			Email:         "user1@companieshouse.gov.uk",
+			Route:         "appointPractitioner",
+			Method:        "POST",
+			Path:          "/transactions/1234/insolvency/practitioners/1/appointment",
+			StatusCode:    201,
+			CreatedAt:     createdAt,
+			Changes:       []models.AuditChangeDao{{Path: "data.practitioners[id=1].appointment", After: `{"made_by":"creditors"}`}},
+		}}
+
+		events := AuditEventDaoListToResponseList(daos)
+
+		So(events, ShouldHaveLength, 1)
+		So(events[0].ID, ShouldEqual, "event1")
+		So(events[0].TransactionID, ShouldEqual, "1234")
+		So(events[0].CompanyNumber, ShouldEqual, "01234567")
+		So(events[0].UserID, ShouldEqual, "user1")
+		So(events[0].Email, ShouldEqual, "user1@companieshouse.gov.uk")
+		So(events[0].Route, ShouldEqual, "appointPractitioner")
+		So(events[0].Method, ShouldEqual, "POST")
+		So(events[0].Path, ShouldEqual, "/transactions/1234/insolvency/practitioners/1/appointment")
+		So(events[0].Status, ShouldEqual, 201)
+		So(events[0].CreatedAt, ShouldEqual, createdAt)
+
+		encoded, err := json.Marshal(events[0].Changes)
+		So(err, ShouldBeNil)
+		So(string(encoded), ShouldEqual, `[{"path":"data.practitioners[id=1].appointment","after":{"made_by":"creditors"}}]`)
+	})
+
+	Convey("no events transform to an empty list", t, func() {
+		So(AuditEventDaoListToResponseList(nil), ShouldBeEmpty)
+		So(AuditEventDaoListToResponseList(nil), ShouldNotBeNil)
+	})
+}
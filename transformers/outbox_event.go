@@ -0,0 +1,16 @@
+package transformers
+
+import (
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// OutboxEventDaoToDomainEvent transforms an outbox event dao model to the domain event delivered downstream
+func OutboxEventDaoToDomainEvent(dao *models.OutboxEventDao) models.DomainEvent {
+	return models.DomainEvent{
+		ID:            dao.ID,
+		Type:          dao.Type,
+		TransactionID: dao.TransactionID,
+		CreatedAt:     dao.CreatedAt,
+		Data:          rawJSON(dao.Data),
+	}
+}
@@ -0,0 +1,35 @@
+package transformers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/companieshouse/insolvency-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitOutboxEventDaoToDomainEvent(t *testing.T) {
+	Convey("field mappings are correct", t, func() {
+		createdAt := time.Date(2021, 6, 28, 10, 0, 0, 0, time.UTC)
+		dao := &models.OutboxEventDao{
+			ID:            "event1",
+			Type:          "practitioner.appointed",
+			TransactionID: "1234",
+			CreatedAt:     createdAt,
+			Data:          `{"practitioner_id":"1"}`,
+		}
+
+		event := OutboxEventDaoToDomainEvent(dao)
+
+		So(event.ID, ShouldEqual, "event1")
+		So(event.Type, ShouldEqual, "practitioner.appointed")
+		So(event.TransactionID, ShouldEqual, "1234")
+		So(event.CreatedAt, ShouldEqual, createdAt)
+
+		encoded, err := json.Marshal(event)
+		So(err, ShouldBeNil)
+		So(string(encoded), ShouldEqual, `{"id":"event1","type":"practitioner.appointed","transaction_id":"1234","created_at":"2021-06-28T10:00:00Z","data":{"practitioner_id":"1"}}`)
+	})
+}
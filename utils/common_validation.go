@@ -5,19 +5,21 @@ import (
 	"net/http"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/constants"
 	"github.com/companieshouse/insolvency-api/models"
 )
 
 // HelperService interface declares
 type HelperService interface {
 	HandleTransactionIdExistsValidation(w http.ResponseWriter, req *http.Request, transactionID string) (bool, string)
-	HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, httpStatus int, err error) bool
+	HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, err error) bool
 	HandleBodyDecodedValidation(w http.ResponseWriter, req *http.Request, transactionID string, err error) bool
-	HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, err string) bool
+	HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, errs []models.FieldError) bool
 	HandleAttachmentValidation(w http.ResponseWriter, req *http.Request, transactionID string, attachment models.AttachmentResourceDao, err error) bool
 	HandleAttachmentTypeValidation(w http.ResponseWriter, req *http.Request, responseMessage string, err error) int
 	HandleEtagGenerationValidation(err error) bool
-	HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, statusCode int, err error) bool
+	HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, err error) bool
 	// GenerateEtag generates a random etag which is generated on every write action
 	GenerateEtag() (string, error)
 }
@@ -37,18 +39,18 @@ func (*helperService) HandleTransactionIdExistsValidation(w http.ResponseWriter,
 }
 
 // HandleTransactionNotClosedValidation implements HelperService
-func (*helperService) HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, httpStatus int, err error) bool {
+func (*helperService) HandleTransactionNotClosedValidation(w http.ResponseWriter, req *http.Request, transactionID string, isTransactionClosed bool, err error) bool {
 	if err != nil {
-		log.ErrorR(req, fmt.Errorf("error checking transaction status for [%v]: [%s]", transactionID, err))
-		m := models.NewMessageResponse(fmt.Sprintf("error checking transaction status for [%v]: [%s]", transactionID, err))
-		WriteJSONWithStatus(w, req, m, httpStatus)
+		err = fmt.Errorf(constants.MsgErrorCheckTransactionStatus, transactionID, err)
+		log.ErrorR(req, err)
+		WriteErrorResponse(w, req, err)
 		return false
 	}
 
 	if isTransactionClosed {
-		log.ErrorR(req, fmt.Errorf("transaction [%v] is already closed and cannot be updated", transactionID))
-		m := models.NewMessageResponse(fmt.Sprintf("transaction [%v] is already closed and cannot be updated", transactionID))
-		WriteJSONWithStatus(w, req, m, httpStatus)
+		err = apperrors.Closed(constants.MsgNoUpdateTransactionClosed, transactionID)
+		log.ErrorR(req, err)
+		WriteErrorResponse(w, req, err)
 		return false
 	}
 	return true
@@ -66,11 +68,10 @@ func (*helperService) HandleBodyDecodedValidation(w http.ResponseWriter, req *ht
 }
 
 // HandleMandatoryFieldValidation implements HelperService
-func (*helperService) HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, errs string) bool {
-	if errs != "" {
-		log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", errs))
-		m := models.NewMessageResponse("invalid request body: " + errs)
-		WriteJSONWithStatus(w, req, m, http.StatusBadRequest)
+func (*helperService) HandleMandatoryFieldValidation(w http.ResponseWriter, req *http.Request, errs []models.FieldError) bool {
+	if len(errs) > 0 {
+		log.ErrorR(req, fmt.Errorf("invalid request - failed validation on the following: %s", FieldErrorMessages(errs)))
+		WriteFieldErrors(w, req, "invalid request body", errs)
 		return false
 	}
 	return true
@@ -96,11 +97,10 @@ func (*helperService) HandleAttachmentTypeValidation(w http.ResponseWriter, req
 }
 
 // HandleCreateResourceValidation implements HelperService
-func (*helperService) HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, statusCode int, err error) bool {
+func (*helperService) HandleCreateResourceValidation(w http.ResponseWriter, req *http.Request, err error) bool {
 	if err != nil {
 		log.ErrorR(req, err)
-		m := models.NewMessageResponse(err.Error())
-		WriteJSONWithStatus(w, req, m, statusCode)
+		WriteErrorResponse(w, req, err)
 		return false
 	}
 	return true
@@ -42,21 +42,21 @@ func TestUnitHandleRequestValidation(t *testing.T) {
 
 	Convey("Fails validation when transaction is closed against transaction api", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", false, http.StatusInternalServerError, errors.New("anything"))
+		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", false, errors.New("anything"))
 
 		So(valid, ShouldBeFalse)
 	})
 
 	Convey("Fails validation when transaction is already closed and cannot be updated", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", true, http.StatusInternalServerError, nil)
+		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", true, nil)
 
 		So(valid, ShouldBeFalse)
 	})
 
 	Convey("Passes validation when transaction is not closed", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", false, http.StatusOK, nil)
+		valid := helperService.HandleTransactionNotClosedValidation(res, req, "anything", false, nil)
 
 		So(valid, ShouldBeTrue)
 	})
@@ -77,7 +77,7 @@ func TestUnitHandleRequestValidation(t *testing.T) {
 
 	Convey("Fails validation when missing mandatory field values check fails", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleMandatoryFieldValidation(res, req, "anything")
+		valid := helperService.HandleMandatoryFieldValidation(res, req, []models.FieldError{{Field: "anything", Message: "anything is a required field"}})
 
 		So(valid, ShouldBeFalse)
 	})
@@ -128,28 +128,28 @@ func TestUnitHandleRequestValidation(t *testing.T) {
 
 	Convey("Fails validation when create resource fails", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleCreateResourceValidation(res, req, http.StatusInternalServerError, errors.New("anything"))
+		valid := helperService.HandleCreateResourceValidation(res, req, errors.New("anything"))
 
 		So(valid, ShouldBeFalse)
 	})
 
 	Convey("Passes validation when create resource succeeds", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleCreateResourceValidation(res, req, http.StatusInternalServerError, nil)
+		valid := helperService.HandleCreateResourceValidation(res, req, nil)
 
 		So(valid, ShouldBeTrue)
 	})
 
 	Convey("Fails validation when missing mandatory field value check fails", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleMandatoryFieldValidation(res, req, "anything")
+		valid := helperService.HandleMandatoryFieldValidation(res, req, []models.FieldError{{Field: "anything", Message: "anything is a required field"}})
 
 		So(valid, ShouldBeFalse)
 	})
 
 	Convey("Passes validation when mandatory field value check succeeds", t, func() {
 		var req, res = prepareForTest()
-		valid := helperService.HandleMandatoryFieldValidation(res, req, "")
+		valid := helperService.HandleMandatoryFieldValidation(res, req, nil)
 
 		So(valid, ShouldBeTrue)
 	})
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// ErrorStatus returns the HTTP status to respond with for an error returned by the dao or service packages. Errors
+// that are not one of the apperrors types return 500
+func ErrorStatus(err error) int {
+	var notFoundErr *apperrors.NotFoundError
+	var conflictErr *apperrors.ConflictError
+	var preconditionErr *apperrors.PreconditionFailedError
+	var closedErr *apperrors.ClosedError
+	var validationErr *apperrors.ValidationError
+	var unavailableErr *apperrors.UpstreamUnavailableError
+
+	switch {
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound
+	case errors.As(err, &conflictErr):
+		return http.StatusConflict
+	case errors.As(err, &preconditionErr):
+		return http.StatusPreconditionFailed
+	case errors.As(err, &closedErr):
+		return http.StatusForbidden
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest
+	case errors.As(err, &unavailableErr):
+		if unavailableErr.Timeout {
+			return http.StatusGatewayTimeout
+		}
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteErrorResponse writes the response for an error returned by the dao or service packages, with the status
+// from ErrorStatus and the error as the message
+func WriteErrorResponse(w http.ResponseWriter, req *http.Request, err error) {
+	WriteJSONWithStatus(w, req, models.NewMessageResponse(err.Error()), ErrorStatus(err))
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/apperrors"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnitErrorStatus(t *testing.T) {
+	Convey("Errors are mapped to the HTTP status to respond with", t, func() {
+		So(ErrorStatus(apperrors.NotFound("case not found")), ShouldEqual, http.StatusNotFound)
+		So(ErrorStatus(apperrors.Conflict("case already exists")), ShouldEqual, http.StatusConflict)
+		So(ErrorStatus(apperrors.PreconditionFailed("etag does not match")), ShouldEqual, http.StatusPreconditionFailed)
+		So(ErrorStatus(apperrors.Closed("transaction closed")), ShouldEqual, http.StatusForbidden)
+		So(ErrorStatus(apperrors.Validation("invalid case")), ShouldEqual, http.StatusBadRequest)
+		So(ErrorStatus(&apperrors.UpstreamUnavailableError{Message: "unavailable"}), ShouldEqual, http.StatusServiceUnavailable)
+		So(ErrorStatus(&apperrors.UpstreamUnavailableError{Message: "timed out", Timeout: true}), ShouldEqual, http.StatusGatewayTimeout)
+		So(ErrorStatus(errors.New("unexpected")), ShouldEqual, http.StatusInternalServerError)
+	})
+
+	Convey("Wrapped errors are mapped by the error they wrap", t, func() {
+		So(ErrorStatus(fmt.Errorf("error checking transaction: [%w]", apperrors.NotFound("transaction not found"))), ShouldEqual, http.StatusNotFound)
+	})
+}
+
+func TestUnitWriteErrorResponse(t *testing.T) {
+	Convey("The error is written as the message with the mapped status", t, func() {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		WriteErrorResponse(w, r, apperrors.Conflict("case already exists"))
+
+		So(w.Code, ShouldEqual, http.StatusConflict)
+		So(w.Body.String(), ShouldEqual, "{\"message\":\"case already exists\"}\n")
+	})
+}
@@ -1,21 +1,20 @@
 package utils
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 
-	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/models"
 )
 
-// WriteJSONWithStatus writes the interface as a json string with the supplied status.
+// WriteJSONWithStatus writes the interface as a json string with the supplied status. A message response with an
+// error status is written as a problem instead when the client accepts application/problem+json.
 func WriteJSONWithStatus(w http.ResponseWriter, r *http.Request, data interface{}, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	err := json.NewEncoder(w).Encode(data)
-	if err != nil {
-		log.ErrorR(r, fmt.Errorf("error writing response: %v", err))
+	if m, ok := data.(*models.ResponseResource); ok && status >= http.StatusBadRequest && AcceptsProblemJSON(r) {
+		writeProblem(w, r, NewProblem(r, status, m.Message, nil))
+		return
 	}
+	writeJSON(w, r, data, "application/json", status)
 }
 
 // GetTransactionIDFromVars returns the transaction id from the supplied request vars.
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/models"
+)
+
+// ProblemContentType is the media type of an RFC 7807 error response
+const ProblemContentType = "application/problem+json"
+
+// requestIDHeader is the header carrying the ID given to each request, which is used as the problem instance
+const requestIDHeader = "X-Request-Id"
+
+// problemTypeBlank is the problem type used when the status alone describes the problem
+const problemTypeBlank = "about:blank"
+
+// AcceptsProblemJSON returns true if the Accept header of the request lists application/problem+json. Error
+// responses are otherwise written as a message response so that existing clients are unaffected
+func AcceptsProblemJSON(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || mediaType != ProblemContentType {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// NewProblem returns the problem describing an error response with the supplied status, detail and field errors
+func NewProblem(req *http.Request, status int, detail string, fieldErrs []models.FieldError) *models.ProblemResource {
+	return &models.ProblemResource{
+		Type:     problemTypeBlank,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: req.Header.Get(requestIDHeader),
+		Errors:   fieldErrs,
+	}
+}
+
+// WriteFieldErrors writes the response for a request body that failed validation, listing each field error
+// when the client accepts application/problem+json and joining them into the message otherwise
+func WriteFieldErrors(w http.ResponseWriter, req *http.Request, message string, fieldErrs []models.FieldError) {
+	if AcceptsProblemJSON(req) {
+		writeProblem(w, req, NewProblem(req, http.StatusBadRequest, message, fieldErrs))
+		return
+	}
+
+	writeJSON(w, req, models.NewMessageResponse(message+": "+FieldErrorMessages(fieldErrs)), "application/json", http.StatusBadRequest)
+}
+
+// FieldErrorMessages joins the message of each field error into a single human-readable string
+func FieldErrorMessages(fieldErrs []models.FieldError) string {
+	messages := make([]string, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+// writeProblem writes the problem as an application/problem+json response
+func writeProblem(w http.ResponseWriter, req *http.Request, problem *models.ProblemResource) {
+	writeJSON(w, req, problem, ProblemContentType, problem.Status)
+}
+
+// writeJSON writes the data as a json string with the supplied content type and status
+func writeJSON(w http.ResponseWriter, req *http.Request, data interface{}, contentType string, status int) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.ErrorR(req, fmt.Errorf("error writing response: %v", err))
+	}
+}
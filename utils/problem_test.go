@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/companieshouse/insolvency-api/models"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func problemRequest(accept string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	r.Header.Set("X-Request-Id", "request-id")
+	return r
+}
+
+func TestUnitAcceptsProblemJSON(t *testing.T) {
+	Convey("Problem json is only used when it is accepted", t, func() {
+		So(AcceptsProblemJSON(problemRequest("")), ShouldBeFalse)
+		So(AcceptsProblemJSON(problemRequest("application/json")), ShouldBeFalse)
+		So(AcceptsProblemJSON(problemRequest("application/problem+json")), ShouldBeTrue)
+		So(AcceptsProblemJSON(problemRequest("application/json, application/problem+json;q=0.5")), ShouldBeTrue)
+		So(AcceptsProblemJSON(problemRequest("application/problem+json;q=0")), ShouldBeFalse)
+	})
+}
+
+func TestUnitWriteJSONWithStatusProblem(t *testing.T) {
+	Convey("A message response is written in the legacy shape by default", t, func() {
+		w := httptest.NewRecorder()
+
+		WriteJSONWithStatus(w, problemRequest(""), models.NewMessageResponse("case not found"), http.StatusNotFound)
+
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+		So(w.Body.String(), ShouldEqual, "{\"message\":\"case not found\"}\n")
+	})
+
+	Convey("A message response is written as a problem when it is accepted", t, func() {
+		w := httptest.NewRecorder()
+
+		WriteJSONWithStatus(w, problemRequest(ProblemContentType), models.NewMessageResponse("case not found"), http.StatusNotFound)
+
+		var problem models.ProblemResource
+		So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(w.Header().Get("Content-Type"), ShouldEqual, ProblemContentType)
+		So(problem, ShouldResemble, models.ProblemResource{
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "case not found",
+			Instance: "request-id",
+		})
+	})
+
+	Convey("A successful response is not written as a problem", t, func() {
+		w := httptest.NewRecorder()
+
+		WriteJSONWithStatus(w, problemRequest(ProblemContentType), models.NewMessageResponse("ok"), http.StatusOK)
+
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+		So(w.Body.String(), ShouldEqual, "{\"message\":\"ok\"}\n")
+	})
+}
+
+func TestUnitWriteFieldErrors(t *testing.T) {
+	fieldErrs := []models.FieldError{
+		{Field: "company_number", Message: "company_number is a required field"},
+		{Field: "address.postal_code", Message: "postal_code is a required field"},
+	}
+
+	Convey("Field errors are joined into the message by default", t, func() {
+		w := httptest.NewRecorder()
+
+		WriteFieldErrors(w, problemRequest(""), "invalid request body", fieldErrs)
+
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(w.Body.String(), ShouldEqual, "{\"message\":\"invalid request body: company_number is a required field, postal_code is a required field\"}\n")
+	})
+
+	Convey("Field errors are listed in the problem when it is accepted", t, func() {
+		w := httptest.NewRecorder()
+
+		WriteFieldErrors(w, problemRequest(ProblemContentType), "invalid request body", fieldErrs)
+
+		var problem models.ProblemResource
+		So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(problem.Detail, ShouldEqual, "invalid request body")
+		So(problem.Errors, ShouldResemble, fieldErrs)
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/companieshouse/chs.go/log"
+	"github.com/companieshouse/insolvency-api/metrics"
+	"github.com/companieshouse/insolvency-api/models"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -17,9 +19,9 @@ import (
 
 // Validate takes in any request object and checks whether it has met
 // the validation criteria according to the annotations on that object.
-// If the object is invalid, the method returns a human-readable string
-// which can then be added to the message response for the API user
-func Validate(data interface{}) string {
+// If the object is invalid, the method returns a human-readable error
+// for each field that failed, which can then be returned to the API user
+func Validate(data interface{}) []models.FieldError {
 	v := validator.New()
 	v.RegisterTagNameFunc(extractJson)
 	english := en.New()
@@ -30,18 +32,26 @@ func Validate(data interface{}) string {
 	err := v.Struct(data)
 
 	if err == nil {
-		return ""
+		return nil
 	}
 
-	var errs []string
+	var errs []models.FieldError
 	validatorErrs := err.(validator.ValidationErrors)
 	for _, ve := range validatorErrs {
-		errFormat := ve.Translate(trans)
-		translatedErr := fmt.Errorf("%s", errFormat)
-		errs = append(errs, translatedErr.Error())
+		metrics.ValidationFailed(fmt.Sprintf("%s_%s", ve.Field(), ve.Tag()))
+		errs = append(errs, models.FieldError{Field: fieldPath(ve.Namespace()), Message: ve.Translate(trans)})
 	}
 
-	return strings.Join(errs, ", ")
+	return errs
+}
+
+// fieldPath returns the json path of a field from its namespace, which starts with the name of the validated struct
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
 }
 
 func extractJson(fld reflect.StructField) string {
@@ -54,7 +64,7 @@ func extractJson(fld reflect.StructField) string {
 	return name
 }
 
-func ValidateTransaction(helperService HelperService, req *http.Request, res http.ResponseWriter, startMessage string, checkIfTransactionClosedFn func(transactionID string, req *http.Request) (bool, error, int)) (string, bool) {
+func ValidateTransaction(helperService HelperService, req *http.Request, res http.ResponseWriter, startMessage string, checkIfTransactionClosedFn func(transactionID string, req *http.Request) (bool, error)) (string, bool) {
 
 	// Check transaction id exists in path
 	incomingTransactionId := GetTransactionIDFromVars(mux.Vars(req))
@@ -68,8 +78,8 @@ func ValidateTransaction(helperService HelperService, req *http.Request, res htt
 	log.InfoR(req, fmt.Sprintf("start %s request for submit "+startMessage+" with transaction id: %s", reqMethod, transactionID))
 
 	// Check if transaction is checkIfTransactionClosedFn
-	var isTransactionClosed, err, httpStatus = checkIfTransactionClosedFn(transactionID, req)
-	isValidTransaction = helperService.HandleTransactionNotClosedValidation(res, req, transactionID, isTransactionClosed, httpStatus, err)
+	isTransactionClosed, err := checkIfTransactionClosedFn(transactionID, req)
+	isValidTransaction = helperService.HandleTransactionNotClosedValidation(res, req, transactionID, isTransactionClosed, err)
 	if !isValidTransaction {
 		return transactionID, isValidTransaction
 	}